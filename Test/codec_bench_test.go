@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	cmddb "github.com/beijian128/protoc-gen-redis/generated"
+	"github.com/beijian128/protoc-gen-redis/generated/table"
+)
+
+// codec=table 与默认（逐字段生成）的编解码对比：
+//
+//	go test ./Test -run '^$' -bench 'Codec' -benchmem
+//
+// 比较速度与分配；编译耗时与产物大小见根目录的 BenchmarkCodecBuild。
+
+func BenchmarkCodecMarshal(b *testing.B) {
+	b.Run("inline", func(b *testing.B) {
+		u := newTestUser()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := u.MarshalRedisProto(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("table", func(b *testing.B) {
+		u := newTableTestUser(b)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := u.MarshalRedisProto(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkCodecUnmarshal(b *testing.B) {
+	data, err := newTestUser().MarshalRedisProto()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("inline", func(b *testing.B) {
+		var u cmddb.DBUserBaseInfo
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := u.UnmarshalRedisProto(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("table", func(b *testing.B) {
+		var u table.DBUserBaseInfo
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := u.UnmarshalRedisProto(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -2,155 +2,2532 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"net"
 	"os"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	cmddb "github.com/beijian128/protoc-gen-redis/generated"
+	"github.com/beijian128/protoc-gen-redis/generated/attach"
+	"github.com/beijian128/protoc-gen-redis/generated/convert"
+	"github.com/beijian128/protoc-gen-redis/generated/game"
+	cmddbgoredis "github.com/beijian128/protoc-gen-redis/generated/goredis"
+	"github.com/beijian128/protoc-gen-redis/generated/perf"
+	"github.com/beijian128/protoc-gen-redis/generated/perfrt"
+	"github.com/beijian128/protoc-gen-redis/generated/rt"
+	"github.com/beijian128/protoc-gen-redis/generated/table"
+	"github.com/beijian128/protoc-gen-redis/generated/tablert"
+	"github.com/beijian128/protoc-gen-redis/redisrt"
+	"github.com/beijian128/protoc-gen-redis/redisrt/goredisexec"
+	"github.com/beijian128/protoc-gen-redis/redisrt/redigoexec"
+	"github.com/beijian128/protoc-gen-redis/redistest"
 	"github.com/gomodule/redigo/redis"
+	goredis "github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
-// 集成测试：需要本机 Redis（默认 127.0.0.1:6379，可用 bin/config.json 覆盖）。
-// 连不上时自动跳过，不影响 go test ./... 在无 Redis 环境下的运行。
+// 集成测试：默认连接进程内的 redistest 替身（TestMain 启动），不依赖外部服务；
+// 存在 bin/config.json 时改为连接其中配置的真实 Redis，连不上时自动跳过。
 
 const testREDBKey uint32 = 424242
 
+// fakeRedis 是未配置真实 Redis 时使用的进程内替身。
+var fakeRedis *redistest.Server
+
+func TestMain(m *testing.M) {
+	if _, err := os.Stat("../bin/config.json"); err != nil {
+		srv, err := redistest.NewServer()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fakeRedis = srv
+	}
+	code := m.Run()
+	if fakeRedis != nil {
+		fakeRedis.Close()
+	}
+	os.Exit(code)
+}
+
+// redisAddr 返回测试用 Redis 地址与密码：bin/config.json 中配置的真实 Redis，否则为进程内替身。
+func redisAddr() (addr, password string) {
+	if fakeRedis != nil {
+		return fakeRedis.Addr(), ""
+	}
+	addr = "127.0.0.1:6379"
+	if data, err := os.ReadFile("../bin/config.json"); err == nil {
+		var cfg struct {
+			RedisCfg struct {
+				Address  string `json:"address"`
+				Password string `json:"password"`
+			} `json:"redis"`
+		}
+		if json.Unmarshal(data, &cfg) == nil && cfg.RedisCfg.Address != "" {
+			addr = cfg.RedisCfg.Address
+			password = cfg.RedisCfg.Password
+		}
+	}
+	return addr, password
+}
+
 func dialRedis(t *testing.T) redis.Conn {
 	t.Helper()
-	addr := "127.0.0.1:6379"
-	password := ""
-	if data, err := os.ReadFile("../bin/config.json"); err == nil {
-		var cfg struct {
-			RedisCfg struct {
-				Address  string `json:"address"`
-				Password string `json:"password"`
-			} `json:"redis"`
+	addr, password := redisAddr()
+	conn, err := redis.Dial("tcp", addr, redis.DialPassword(password))
+	if err != nil {
+		t.Skipf("Redis 不可用，跳过集成测试: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// dialGoRedis 返回 go-redis 客户端（executor=goredis 生成代码的集成测试用），连不上时跳过。
+func dialGoRedis(t *testing.T) goredis.UniversalClient {
+	t.Helper()
+	addr, password := redisAddr()
+	client := goredis.NewClient(&goredis.Options{Addr: addr, Password: password})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		t.Skipf("Redis 不可用，跳过集成测试: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// newTestUser 返回一个全字段填满的测试数据，覆盖所有类型映射与边界值。
+func newTestUser() *cmddb.DBUserBaseInfo {
+	return &cmddb.DBUserBaseInfo{
+		UserId:      -2147483648, // int32 最小值
+		Username:    "测试用户-中文",
+		AvatarUrl:   "https://example.com/a.png?x=1&y=2",
+		Gender:      cmddb.Gender_GENDER_FEMALE,
+		Level:       99,
+		Exp:         9223372036854775807, // int64 最大值
+		Balance:     3.5,
+		Friends:     cmddb.DBUserBaseInfo_DBFriends{Items: []string{"alice", "bob", ""}},
+		Settings:    cmddb.DBUserBaseInfo_DBSettings{Kv: map[string]string{"sound": "80", "lang": "zh-CN"}},
+		LoginSource: cmddb.LoginSource_SOURCE_MINI_PROGRAM,
+		Int32List:   cmddb.DBUserBaseInfo_DBInt32List{Items: []int32{1, -2, 3}},
+		Weapons: cmddb.DBUserBaseInfo_DBWeapons{Items: []cmddb.DBWeapon{
+			{Name: "sword", Damage: 10, Element: "fire"},
+			{Name: "bow", Damage: 8, Element: "ice"},
+		}},
+		Weapon: cmddb.DBWeapon{Name: "knife", Damage: 5, Element: "poison"},
+		WeaponMap: cmddb.DBUserBaseInfo_DBWeaponMap{Items: map[int32]cmddb.DBWeapon{
+			1: {Name: "w1", Damage: 1, Element: "e1"},
+			2: {Name: "w2", Damage: 2, Element: "e2"},
+		}},
+		Coin:     4294967295,           // uint32 最大值
+		Gem:      18446744073709551615, // uint64 最大值
+		Vip:      true,
+		Score:    3.25,
+		Token:    []byte{0x00, 0x01, 0xFF}, // 含不可打印字节
+		Profile:  cmddb.DBUserBaseInfo_DBProfile{Nickname: "nick", Age: 30},
+		VipLevel: cmddb.DBUserBaseInfo_VIP_2,
+	}
+}
+
+// TestFullRoundTrip 全字段写入 + 全字段读取，逐一字段比对。
+func TestFullRoundTrip(t *testing.T) {
+	conn := dialRedis(t)
+	t.Cleanup(func() { conn.Do("DEL", fmt.Sprintf("REDB#%d:1:0", testREDBKey)) })
+
+	want := newTestUser()
+	if err := want.SetFields(conn, testREDBKey, 1, 0); err != nil {
+		t.Fatalf("SetFields(全字段): %v", err)
+	}
+
+	got := &cmddb.DBUserBaseInfo{}
+	if err := got.GetFields(conn, testREDBKey, 1, 0); err != nil {
+		t.Fatalf("GetFields(全字段): %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("全字段回读不一致:\n got = %#v\nwant = %#v", got, want)
+	}
+}
+
+// TestPartialFields 只写部分字段，只读部分字段；未写字段保持零值。
+func TestPartialFields(t *testing.T) {
+	conn := dialRedis(t)
+	t.Cleanup(func() { conn.Do("DEL", fmt.Sprintf("REDB#%d:2:0", testREDBKey)) })
+
+	u := &cmddb.DBUserBaseInfo{UserId: 7, Username: "partial", Vip: true}
+	if err := u.SetFields(conn, testREDBKey, 2, 0,
+		cmddb.FieldDBUserBaseInfo_UserId, cmddb.FieldDBUserBaseInfo_Username, cmddb.FieldDBUserBaseInfo_Vip); err != nil {
+		t.Fatalf("SetFields(局部): %v", err)
+	}
+
+	got := &cmddb.DBUserBaseInfo{}
+	if err := got.GetFields(conn, testREDBKey, 2, 0,
+		cmddb.FieldDBUserBaseInfo_UserId, cmddb.FieldDBUserBaseInfo_Username, cmddb.FieldDBUserBaseInfo_Vip,
+		cmddb.FieldDBUserBaseInfo_Level, // 顺带读一个从未写入的字段
+	); err != nil {
+		t.Fatalf("GetFields(局部): %v", err)
+	}
+	if got.UserId != 7 || got.Username != "partial" || !got.Vip {
+		t.Errorf("局部字段回读错误: %#v", got)
+	}
+	if got.Level != 0 {
+		t.Errorf("未写入字段 Level 应为零值, got %d", got.Level)
+	}
+}
+
+// TestSingleField 单字段写入与读取。
+func TestSingleField(t *testing.T) {
+	conn := dialRedis(t)
+	t.Cleanup(func() { conn.Do("DEL", fmt.Sprintf("REDB#%d:3:0", testREDBKey)) })
+
+	u := &cmddb.DBUserBaseInfo{Exp: 1234567890123}
+	if err := u.SetFields(conn, testREDBKey, 3, 0, cmddb.FieldDBUserBaseInfo_Exp); err != nil {
+		t.Fatalf("SetFields(单字段): %v", err)
+	}
+
+	got := &cmddb.DBUserBaseInfo{}
+	if err := got.GetFields(conn, testREDBKey, 3, 0, cmddb.FieldDBUserBaseInfo_Exp); err != nil {
+		t.Fatalf("GetFields(单字段): %v", err)
+	}
+	if got.Exp != 1234567890123 {
+		t.Errorf("Exp = %d, want 1234567890123", got.Exp)
+	}
+}
+
+// TestMissingFieldIsZero 读一个从未写入的键/字段，应得到零值而非报错。
+func TestMissingFieldIsZero(t *testing.T) {
+	conn := dialRedis(t)
+	t.Cleanup(func() { conn.Do("DEL", fmt.Sprintf("REDB#%d:4:0", testREDBKey)) })
+
+	got := &cmddb.DBUserBaseInfo{}
+	if err := got.GetFields(conn, testREDBKey, 4, 0); err != nil {
+		t.Fatalf("GetFields(空键): %v", err)
+	}
+	want := &cmddb.DBUserBaseInfo{}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("空键读取应全零: %#v", got)
+	}
+}
+
+// TestGoRedisExecutorRoundTrip executor=goredis 生成代码经 go-redis 全字段读写，
+// 并与 redigo 版本写入的数据互通（两者存储格式一致）。
+func TestGoRedisExecutorRoundTrip(t *testing.T) {
+	client := dialGoRedis(t)
+	conn := dialRedis(t)
+	t.Cleanup(func() { conn.Do("DEL", fmt.Sprintf("REDB#%d:8:0", testREDBKey)) })
+
+	u := &cmddbgoredis.DBUserBaseInfo{
+		UserId:   -5,
+		Username: "goredis",
+		Gender:   cmddbgoredis.Gender_GENDER_MALE,
+		Balance:  1.5,
+		Vip:      true,
+		Token:    []byte{0x00, 0xFF},
+		Friends:  cmddbgoredis.DBUserBaseInfo_DBFriends{Items: []string{"a", "b"}},
+		Profile:  cmddbgoredis.DBUserBaseInfo_DBProfile{Nickname: "n", Age: 3},
+	}
+	if err := u.SetFields(client, testREDBKey, 8, 0); err != nil {
+		t.Fatalf("SetFields(go-redis): %v", err)
+	}
+	got := &cmddbgoredis.DBUserBaseInfo{}
+	if err := got.GetFields(client, testREDBKey, 8, 0); err != nil {
+		t.Fatalf("GetFields(go-redis): %v", err)
+	}
+	if !reflect.DeepEqual(got, u) {
+		t.Errorf("go-redis 回读不一致:\n got = %#v\nwant = %#v", got, u)
+	}
+
+	// redigo 版本读取 go-redis 写入的数据
+	viaRedigo := &cmddb.DBUserBaseInfo{}
+	if err := viaRedigo.GetFields(conn, testREDBKey, 8, 0); err != nil {
+		t.Fatalf("GetFields(redigo): %v", err)
+	}
+	if viaRedigo.Username != "goredis" || viaRedigo.Gender != cmddb.Gender_GENDER_MALE || viaRedigo.Profile.Age != 3 {
+		t.Errorf("redigo 读取 go-redis 写入的数据不一致: %#v", viaRedigo)
+	}
+
+	// Pipeline / Multi 回复归一为 redigo 风格
+	exec := cmddbgoredis.NewGoRedisExecutor(client)
+	key := fmt.Sprintf("REDB#%d:8:0", testREDBKey)
+	replies, err := exec.Multi(context.Background(), []cmddbgoredis.RedisCmd{
+		{Name: "HGET", Args: []interface{}{key, 2}},
+		{Name: "HGET", Args: []interface{}{key, 999}},
+	})
+	if err != nil {
+		t.Fatalf("Multi: %v", err)
+	}
+	if len(replies) != 2 || !bytes.Equal(replies[0].([]byte), []byte("goredis")) || replies[1] != nil {
+		t.Errorf("Multi 回复 = %#v, want [goredis nil]", replies)
+	}
+}
+
+// newAttachTestUser 是 newTestUser 对应的 protoc-gen-go 类型（mode=attach 生成代码操作的类型）。
+func newAttachTestUser() *attach.DBUserBaseInfo {
+	return &attach.DBUserBaseInfo{
+		UserId:      -2147483648,
+		Username:    "测试用户-中文",
+		AvatarUrl:   "https://example.com/a.png?x=1&y=2",
+		Gender:      attach.Gender_GENDER_FEMALE,
+		Level:       99,
+		Exp:         9223372036854775807,
+		Balance:     3.5,
+		Friends:     &attach.DBUserBaseInfo_DBFriends{Items: []string{"alice", "bob", ""}},
+		Settings:    &attach.DBUserBaseInfo_DBSettings{Kv: map[string]string{"sound": "80", "lang": "zh-CN"}},
+		LoginSource: attach.LoginSource_SOURCE_MINI_PROGRAM,
+		Int32List:   &attach.DBUserBaseInfo_DBInt32List{Items: []int32{1, -2, 3}},
+		Weapons: &attach.DBUserBaseInfo_DBWeapons{Items: []*attach.DBWeapon{
+			{Name: "sword", Damage: 10, Element: "fire"},
+			{Name: "bow", Damage: 8, Element: "ice"},
+		}},
+		Weapon: &attach.DBWeapon{Name: "knife", Damage: 5, Element: "poison"},
+		WeaponMap: &attach.DBUserBaseInfo_DBWeaponMap{Items: map[int32]*attach.DBWeapon{
+			1: {Name: "w1", Damage: 1, Element: "e1"},
+			2: {Name: "w2", Damage: 2, Element: "e2"},
+		}},
+		Coin:     4294967295,
+		Gem:      18446744073709551615,
+		Vip:      true,
+		Score:    3.25,
+		Token:    []byte{0x00, 0x01, 0xFF},
+		Profile:  &attach.DBUserBaseInfo_DBProfile{Nickname: "nick", Age: 30},
+		VipLevel: attach.DBUserBaseInfo_VIP_2,
+	}
+}
+
+// TestAttachModeInterop mode=attach 生成代码直接读写 protoc-gen-go 的类型，
+// 与默认模式（自包含结构体）写入的数据双向互通（两者 Redis 布局一致）。
+func TestAttachModeInterop(t *testing.T) {
+	conn := dialRedis(t)
+	t.Cleanup(func() {
+		conn.Do("DEL", fmt.Sprintf("REDB#%d:12:0", testREDBKey), fmt.Sprintf("REDB#%d:13:0", testREDBKey))
+	})
+
+	// 默认模式写入，attach 模式读取
+	if err := newTestUser().SetFields(conn, testREDBKey, 12, 0); err != nil {
+		t.Fatalf("SetFields(默认模式): %v", err)
+	}
+	got := &attach.DBUserBaseInfo{}
+	if err := got.GetFields(conn, testREDBKey, 12, 0); err != nil {
+		t.Fatalf("GetFields(attach): %v", err)
+	}
+	if want := newAttachTestUser(); !proto.Equal(got, want) {
+		t.Errorf("attach 读取默认模式写入的数据不一致:\n got = %v\nwant = %v", got, want)
+	}
+
+	// attach 模式写入，默认模式读取
+	if err := newAttachTestUser().SetFields(conn, testREDBKey, 13, 0); err != nil {
+		t.Fatalf("SetFields(attach): %v", err)
+	}
+	back := &cmddb.DBUserBaseInfo{}
+	if err := back.GetFields(conn, testREDBKey, 13, 0); err != nil {
+		t.Fatalf("GetFields(默认模式): %v", err)
+	}
+	if want := newTestUser(); !reflect.DeepEqual(back, want) {
+		t.Errorf("默认模式读取 attach 写入的数据不一致:\n got = %#v\nwant = %#v", back, want)
+	}
+
+	// 局部读写；nil 的 message 字段写入空值，读回为空 message
+	partial := &attach.DBUserBaseInfo{Level: 7}
+	if err := partial.SetFieldsExec(context.Background(), attach.NewRedigoExecutor(conn), testREDBKey, 13, 0,
+		attach.FieldDBUserBaseInfo_Level, attach.FieldDBUserBaseInfo_Profile); err != nil {
+		t.Fatalf("SetFieldsExec(局部): %v", err)
+	}
+	reread := &attach.DBUserBaseInfo{}
+	if err := reread.GetFields(conn, testREDBKey, 13, 0, attach.FieldDBUserBaseInfo_Level, attach.FieldDBUserBaseInfo_Profile, attach.FieldDBUserBaseInfo_Username); err != nil {
+		t.Fatalf("GetFields(局部): %v", err)
+	}
+	if reread.Level != 7 || reread.Profile == nil || reread.Profile.Nickname != "" || reread.Username != "测试用户-中文" {
+		t.Errorf("局部回读错误: %v", reread)
+	}
+	if err := reread.GetFields(conn, testREDBKey, 13, 0, attach.FieldDBUserBaseInfo(999)); err == nil {
+		t.Error("未知字段编号应报错")
+	}
+}
+
+// TestConvertProto pb_package 生成的 ToProto / From<Message>Proto 与 protoc-gen-go 的类型互相转换不丢失数据：
+// 从 protoc-gen-go 类型转换得到的结构体写入 Redis，以默认模式读回与 newTestUser 一致；再转换回去与原值相等。
+func TestConvertProto(t *testing.T) {
+	conn := dialRedis(t)
+	t.Cleanup(func() { conn.Do("DEL", fmt.Sprintf("REDB#%d:14:0", testREDBKey)) })
+
+	user := convert.FromDBUserBaseInfoProto(newAttachTestUser())
+	if err := user.SetFields(conn, testREDBKey, 14, 0); err != nil {
+		t.Fatalf("SetFields(convert): %v", err)
+	}
+	got := &cmddb.DBUserBaseInfo{}
+	if err := got.GetFields(conn, testREDBKey, 14, 0); err != nil {
+		t.Fatalf("GetFields(默认模式): %v", err)
+	}
+	if want := newTestUser(); !reflect.DeepEqual(got, want) {
+		t.Errorf("From<Message>Proto 转换结果不一致:\n got = %#v\nwant = %#v", got, want)
+	}
+	if back, want := user.ToProto(), newAttachTestUser(); !proto.Equal(back, want) {
+		t.Errorf("ToProto 转换结果不一致:\n got = %v\nwant = %v", back, want)
+	}
+
+	// nil 与空值
+	if (*convert.DBUserBaseInfo)(nil).ToProto() != nil {
+		t.Error("nil 的 ToProto 应返回 nil")
+	}
+	if empty := convert.FromDBUserBaseInfoProto(nil); !reflect.DeepEqual(empty, convert.NewDBUserBaseInfo()) {
+		t.Errorf("FromDBUserBaseInfoProto(nil) = %#v, want 空结构体", empty)
+	}
+}
+
+// TestRuntimeHelpers helpers=runtime 生成的代码（经 redisrt 编解码与执行）与默认模式的存储布局一致：
+// 集合、message 列表与压缩字段互相读写不丢失数据；唯一冲突错误即 *redisrt.UniqueConflictError。
+func TestRuntimeHelpers(t *testing.T) {
+	dialRedis(t) // Redis 不可用时跳过
+	addr, password := redisAddr()
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+		return redis.Dial("tcp", addr, redis.DialPassword(password))
+	}}
+	t.Cleanup(func() { pool.Close() })
+	ctx := context.Background()
+	store, standalone := rt.NewDBPlayerStore(pool, testREDBKey), game.NewDBPlayerStore(pool, testREDBKey)
+	t.Cleanup(func() {
+		for idb := uint64(0); idb <= 2; idb++ {
+			store.Delete(ctx, 15, idb)
+		}
+	})
+
+	journal := []string{strings.Repeat("勇者踏上旅途。", 8), "出生"} // 超过 compress_min_size，压缩存储
+	if err := store.Set(ctx, 15, 0, &rt.DBPlayer{
+		Name:    "runtime",
+		Level:   3,
+		Friends: rt.DBPlayer_DBFriends{Items: []uint64{7}},
+		Items:   rt.DBPlayer_DBItems{Items: map[int32]int64{1: 10, 2: -20}},
+		Mails:   rt.DBPlayer_DBMails{Items: []rt.DBMail{{Title: "hi", SentAt: 1}}},
+		Journal: rt.DBPlayer_DBJournal{Items: journal},
+	}); err != nil {
+		t.Fatalf("Set(runtime): %v", err)
+	}
+	got, err := standalone.Get(ctx, 15, 0)
+	if err != nil {
+		t.Fatalf("Get(默认模式): %v", err)
+	}
+	want := &game.DBPlayer{
+		Name:    "runtime",
+		Level:   3,
+		Friends: game.DBPlayer_DBFriends{Items: []uint64{7}},
+		Items:   game.DBPlayer_DBItems{Items: map[int32]int64{1: 10, 2: -20}},
+		Mails:   game.DBPlayer_DBMails{Items: []game.DBMail{{Title: "hi", SentAt: 1}}},
+		Journal: game.DBPlayer_DBJournal{Items: journal},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("默认模式读取 runtime 写入的数据不一致:\n got  %#v\n want %#v", got, want)
+	}
+
+	// 默认模式写入，runtime 读取
+	want.Name = "standalone"
+	if err := standalone.Set(ctx, 15, 1, want); err != nil {
+		t.Fatalf("Set(默认模式): %v", err)
+	}
+	back, err := store.Get(ctx, 15, 1)
+	if err != nil {
+		t.Fatalf("Get(runtime): %v", err)
+	}
+	if back.Name != "standalone" || back.Level != 3 || !reflect.DeepEqual(back.Journal.Items, journal) ||
+		!reflect.DeepEqual(back.Mails.Items, []rt.DBMail{{Title: "hi", SentAt: 1}}) {
+		t.Errorf("runtime 读取默认模式写入的数据不一致: %#v", back)
+	}
+
+	// 唯一索引：两种模式共用同一张索引 hash，冲突错误的类型来自 redisrt
+	err = store.Set(ctx, 15, 2, &rt.DBPlayer{Name: "standalone"}, rt.FieldDBPlayer_Name)
+	var conflict *redisrt.UniqueConflictError
+	if !errors.As(err, &conflict) || conflict.Field != "Name" || conflict.Ida != 15 || conflict.Idb != 1 {
+		t.Errorf("冲突写入 err = %v, want *redisrt.UniqueConflictError{Name 15:1}", err)
+	}
+
+	// 内存实现同样来自 redisrt
+	mem := rt.NewDBPlayerMemRepository()
+	if err := mem.Set(ctx, 1, 0, &rt.DBPlayer{Name: "mem", Journal: rt.DBPlayer_DBJournal{Items: journal}}); err != nil {
+		t.Fatalf("Set(mem): %v", err)
+	}
+	if got, err := mem.Get(ctx, 1, 0); err != nil || got.Name != "mem" || !reflect.DeepEqual(got.Journal.Items, journal) {
+		t.Errorf("Get(mem) = %#v, %v", got, err)
+	}
+}
+
+// recordingExecutor 是内存中的 RedisExecutor 实现（单个 hash key）：记录命令，
+// 支持 HSET/HMGET/HDEL/DEL/HINCRBY/HINCRBYFLOAT。
+type recordingExecutor struct {
+	cmds   []string
+	fields map[string][]byte
+}
+
+func (e *recordingExecutor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	e.cmds = append(e.cmds, cmd)
+	switch cmd {
+	case "HSET":
+		for i := 1; i+1 < len(args); i += 2 {
+			if b, ok := args[i+1].([]byte); ok {
+				e.fields[fmt.Sprint(args[i])] = b
+			} else {
+				e.fields[fmt.Sprint(args[i])] = []byte(fmt.Sprint(args[i+1]))
+			}
+		}
+		return int64(len(args) / 2), nil
+	case "HDEL":
+		for _, f := range args[1:] {
+			delete(e.fields, fmt.Sprint(f))
+		}
+		return int64(len(args) - 1), nil
+	case "DEL":
+		e.fields = map[string][]byte{}
+		return int64(1), nil
+	case "HINCRBY":
+		n, _ := strconv.ParseInt(string(e.fields[fmt.Sprint(args[1])]), 10, 64)
+		n += args[2].(int64)
+		e.fields[fmt.Sprint(args[1])] = []byte(strconv.FormatInt(n, 10))
+		return n, nil
+	case "HINCRBYFLOAT":
+		f, _ := strconv.ParseFloat(string(e.fields[fmt.Sprint(args[1])]), 64)
+		f += args[2].(float64)
+		e.fields[fmt.Sprint(args[1])] = []byte(strconv.FormatFloat(f, 'f', -1, 64))
+		return e.fields[fmt.Sprint(args[1])], nil
+	case "HMGET":
+		values := make([]interface{}, 0, len(args)-1)
+		for _, f := range args[1:] {
+			if v, ok := e.fields[fmt.Sprint(f)]; ok {
+				values = append(values, v)
+			} else {
+				values = append(values, nil)
+			}
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("unexpected command %s", cmd)
+}
+
+func (e *recordingExecutor) Pipeline(ctx context.Context, cmds []cmddb.RedisCmd) ([]interface{}, error) {
+	return nil, fmt.Errorf("unexpected pipeline")
+}
+
+func (e *recordingExecutor) Multi(ctx context.Context, cmds []cmddb.RedisCmd) ([]interface{}, error) {
+	return nil, fmt.Errorf("unexpected multi")
+}
+
+// newTableTestUser 返回与 newTestUser 相同的 codec=table 结构体（经 protobuf 字节转换）
+func newTableTestUser(tb testing.TB) *table.DBUserBaseInfo {
+	tb.Helper()
+	b, err := newTestUser().MarshalRedisProto()
+	if err != nil {
+		tb.Fatalf("MarshalRedisProto: %v", err)
+	}
+	u := &table.DBUserBaseInfo{}
+	if err := u.UnmarshalRedisProto(b); err != nil {
+		tb.Fatalf("UnmarshalRedisProto(table): %v", err)
+	}
+	return u
+}
+
+// TestTableCodec codec=table 生成的代码（按字段表编解码）与默认模式的编码一致：不含 map 的 message 逐字节相同，
+// 含 map 时互相解码得到相同的值；repeated 标量接受 packed 编码；两种模式写入 Redis Hash 的数据互相读取不丢失。
+func TestTableCodec(t *testing.T) {
+	noMaps := newTestUser()
+	noMaps.Settings.Kv, noMaps.WeaponMap.Items = nil, nil
+	want, err := noMaps.MarshalRedisProto()
+	if err != nil {
+		t.Fatalf("MarshalRedisProto: %v", err)
+	}
+	var u table.DBUserBaseInfo
+	if err := u.UnmarshalRedisProto(want); err != nil {
+		t.Fatalf("UnmarshalRedisProto(table): %v", err)
+	}
+	if got, err := u.MarshalRedisProto(); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("table 编码与默认模式不一致 (err %v):\n got  %x\n want %x", err, got, want)
+	}
+	if err := u.UnmarshalRedisProto(want[:len(want)-1]); err == nil {
+		t.Error("截断的字节流应报错")
+	}
+	var ru tablert.DBUserBaseInfo // helpers=runtime：字段表编解码在 redisrt
+	if err := ru.UnmarshalRedisProto(want); err != nil {
+		t.Fatalf("UnmarshalRedisProto(tablert): %v", err)
+	}
+	if got, err := ru.MarshalRedisProto(); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("tablert 编码与默认模式不一致 (err %v):\n got  %x\n want %x", err, got, want)
+	}
+
+	b, err := newTableTestUser(t).MarshalRedisProto()
+	if err != nil {
+		t.Fatalf("MarshalRedisProto(table): %v", err)
+	}
+	var back cmddb.DBUserBaseInfo
+	if err := back.UnmarshalRedisProto(b); err != nil || !reflect.DeepEqual(&back, newTestUser()) {
+		t.Errorf("默认模式解码 table 编码不一致 (err %v):\n got  %#v\n want %#v", err, &back, newTestUser())
+	}
+
+	// 其他语言的 protoc 实现对 repeated 标量默认打包编码
+	minusOne := int32(-1)
+	packed := protowire.AppendVarint(protowire.AppendVarint(nil, 7), uint64(minusOne))
+	var list table.DBUserBaseInfo_DBInt32List
+	if err := list.UnmarshalRedisProto(protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), packed)); err != nil ||
+		!reflect.DeepEqual(list.Items, []int32{7, -1}) {
+		t.Errorf("packed 解码 = %v, %v, want [7 -1]", list.Items, err)
+	}
+
+	conn := dialRedis(t)
+	t.Cleanup(func() {
+		conn.Do("DEL", fmt.Sprintf("REDB#%d:16:0", testREDBKey), fmt.Sprintf("REDB#%d:16:1", testREDBKey))
+	})
+	if err := newTableTestUser(t).SetFields(conn, testREDBKey, 16, 0); err != nil {
+		t.Fatalf("SetFields(table): %v", err)
+	}
+	var got cmddb.DBUserBaseInfo
+	if err := got.GetFields(conn, testREDBKey, 16, 0); err != nil || !reflect.DeepEqual(&got, newTestUser()) {
+		t.Errorf("默认模式读取 table 写入的数据不一致 (err %v):\n got  %#v", err, &got)
+	}
+	if err := newTestUser().SetFields(conn, testREDBKey, 16, 1); err != nil {
+		t.Fatalf("SetFields(默认模式): %v", err)
+	}
+	var tgot table.DBUserBaseInfo
+	if err := tgot.GetFields(conn, testREDBKey, 16, 1); err != nil || !reflect.DeepEqual(&tgot, newTableTestUser(t)) {
+		t.Errorf("table 读取默认模式写入的数据不一致 (err %v):\n got  %#v", err, &tgot)
+	}
+}
+
+// newPerfTestUser 返回与 newTestUser 相同的 perf=true 结构体（经 protobuf 字节转换）
+func newPerfTestUser(tb testing.TB) *perf.DBUserBaseInfo {
+	tb.Helper()
+	b, err := newTestUser().MarshalRedisProto()
+	if err != nil {
+		tb.Fatalf("MarshalRedisProto: %v", err)
+	}
+	u := &perf.DBUserBaseInfo{}
+	if err := u.UnmarshalRedisProto(b); err != nil {
+		tb.Fatalf("UnmarshalRedisProto(perf): %v", err)
+	}
+	return u
+}
+
+// TestPerf perf=true 生成的代码与默认模式的编码逐字节相同（嵌套 message 超过 127 字节时长度前缀后移），
+// MarshalRedisProtoAppend 追加到已有内容之后；不拷贝的解码不与输入 alias；写入 Redis Hash 的数据与默认模式互相读取不丢失。
+func TestPerf(t *testing.T) {
+	noMaps := newTestUser()
+	noMaps.Settings.Kv, noMaps.WeaponMap.Items = nil, nil
+	noMaps.Weapon.Element = strings.Repeat("长", 100) // 300 字节，长度前缀占 2 字节
+	want, err := noMaps.MarshalRedisProto()
+	if err != nil {
+		t.Fatalf("MarshalRedisProto: %v", err)
+	}
+	var u perf.DBUserBaseInfo
+	if err := u.UnmarshalRedisProto(want); err != nil {
+		t.Fatalf("UnmarshalRedisProto(perf): %v", err)
+	}
+	if got, err := u.MarshalRedisProto(); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("perf 编码与默认模式不一致 (err %v):\n got  %x\n want %x", err, got, want)
+	}
+	if got, err := u.MarshalRedisProtoAppend([]byte("head")); err != nil || !bytes.Equal(got, append([]byte("head"), want...)) {
+		t.Errorf("MarshalRedisProtoAppend 应追加到 buf 之后 (err %v)", err)
+	}
+	var ru perfrt.DBUserBaseInfo // codec=table 且 helpers=runtime
+	if err := ru.UnmarshalRedisProto(want); err != nil {
+		t.Fatalf("UnmarshalRedisProto(perfrt): %v", err)
+	}
+	if got, err := ru.MarshalRedisProtoAppend(nil); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("perfrt 编码与默认模式不一致 (err %v)", err)
+	}
+
+	// 解码结果不引用输入：清零输入后重新编码，结果不变
+	data := append([]byte(nil), want...)
+	var view perf.DBUserBaseInfo
+	if err := view.UnmarshalRedisProto(data); err != nil {
+		t.Fatalf("UnmarshalRedisProto(perf): %v", err)
+	}
+	clear(data)
+	if again, err := view.MarshalRedisProto(); err != nil || !bytes.Equal(again, want) {
+		t.Errorf("清零输入后解码结果变化 (err %v)", err)
+	}
+
+	conn := dialRedis(t)
+	t.Cleanup(func() {
+		conn.Do("DEL", fmt.Sprintf("REDB#%d:17:0", testREDBKey), fmt.Sprintf("REDB#%d:17:1", testREDBKey))
+	})
+	if err := newPerfTestUser(t).SetFields(conn, testREDBKey, 17, 0); err != nil {
+		t.Fatalf("SetFields(perf): %v", err)
+	}
+	var got cmddb.DBUserBaseInfo
+	if err := got.GetFields(conn, testREDBKey, 17, 0); err != nil || !reflect.DeepEqual(&got, newTestUser()) {
+		t.Errorf("默认模式读取 perf 写入的数据不一致 (err %v):\n got  %#v", err, &got)
+	}
+	if err := newTestUser().SetFields(conn, testREDBKey, 17, 1); err != nil {
+		t.Fatalf("SetFields(默认模式): %v", err)
+	}
+	for i := 0; i < 3; i++ { // 参数缓冲复用后结果不变
+		var pgot perf.DBUserBaseInfo
+		if err := pgot.GetFields(conn, testREDBKey, 17, 1); err != nil || !reflect.DeepEqual(&pgot, newPerfTestUser(t)) {
+			t.Errorf("perf 读取默认模式写入的数据不一致 (err %v):\n got  %#v", err, &pgot)
+		}
+	}
+}
+
+// TestCustomExecutor GetFieldsExec/SetFieldsExec 可经任意 RedisExecutor 实现执行（不依赖 Redis）：
+// 字段编号以 uint32、枚举以整数形式传给执行器，客户端无需识别生成的命名类型。
+func TestCustomExecutor(t *testing.T) {
+	exec := &recordingExecutor{fields: map[string][]byte{}}
+	u := &cmddb.DBUserBaseInfo{UserId: 9, Username: "mock", Gender: cmddb.Gender_GENDER_FEMALE}
+	if err := u.SetFieldsExec(context.Background(), exec, testREDBKey, 1, 0,
+		cmddb.FieldDBUserBaseInfo_UserId, cmddb.FieldDBUserBaseInfo_Username, cmddb.FieldDBUserBaseInfo_Gender); err != nil {
+		t.Fatalf("SetFieldsExec: %v", err)
+	}
+	if v := string(exec.fields["4"]); v != "2" {
+		t.Errorf("枚举字段写入值 = %q, want \"2\"", v)
+	}
+
+	got := &cmddb.DBUserBaseInfo{}
+	if err := got.GetFieldsExec(context.Background(), exec, testREDBKey, 1, 0,
+		cmddb.FieldDBUserBaseInfo_UserId, cmddb.FieldDBUserBaseInfo_Username, cmddb.FieldDBUserBaseInfo_Gender, cmddb.FieldDBUserBaseInfo_Level); err != nil {
+		t.Fatalf("GetFieldsExec: %v", err)
+	}
+	if got.UserId != 9 || got.Username != "mock" || got.Gender != cmddb.Gender_GENDER_FEMALE || got.Level != 0 {
+		t.Errorf("自定义执行器回读错误: %#v", got)
+	}
+	if want := []string{"HSET", "HMGET"}; !reflect.DeepEqual(exec.cmds, want) {
+		t.Errorf("执行的命令 = %v, want %v", exec.cmds, want)
+	}
+}
+
+// TestStoreWithExecutor <Message>Store 的 Get/Set/Delete/Update/Incr（经自定义执行器，不依赖 Redis）。
+func TestStoreWithExecutor(t *testing.T) {
+	ctx := context.Background()
+	exec := &recordingExecutor{fields: map[string][]byte{}}
+	store := cmddb.NewDBUserBaseInfoStoreExec(exec, testREDBKey)
+
+	if err := store.Set(ctx, 1, 0, &cmddb.DBUserBaseInfo{Username: "store", Level: 3, Balance: 1.5}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	got, err := store.Get(ctx, 1, 0, cmddb.FieldDBUserBaseInfo_Username, cmddb.FieldDBUserBaseInfo_Level)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Username != "store" || got.Level != 3 || got.Balance != 0 {
+		t.Errorf("Get 只应读回指定字段: %#v", got)
+	}
+
+	level, err := store.IncrLevel(ctx, 1, 0, 5)
+	if err != nil || level != 8 {
+		t.Errorf("IncrLevel = %d, %v, want 8", level, err)
+	}
+	balance, err := store.IncrBalance(ctx, 1, 0, 0.25)
+	if err != nil || balance != 1.75 {
+		t.Errorf("IncrBalance = %v, %v, want 1.75", balance, err)
+	}
+
+	// 自增结果超出字段类型范围时报错，并撤销已写入服务端的越界值，记录仍可读取
+	if err := store.Set(ctx, 1, 0, &cmddb.DBUserBaseInfo{Coin: 4294967295}, cmddb.FieldDBUserBaseInfo_Coin); err != nil {
+		t.Fatalf("Set(Coin): %v", err)
+	}
+	if _, err := store.IncrCoin(ctx, 1, 0, 1); err == nil || !strings.Contains(err.Error(), "已撤销本次自增") {
+		t.Errorf("Coin 已是 uint32 最大值，自增越界应报错并撤销, got %v", err)
+	}
+	if got, err := store.Get(ctx, 1, 0, cmddb.FieldDBUserBaseInfo_Coin); err != nil || got.Coin != 4294967295 {
+		t.Errorf("越界自增撤销后 Get = %v, %v, want Coin 4294967295", got, err)
+	}
+	if err := store.Delete(ctx, 1, 0, cmddb.FieldDBUserBaseInfo_Coin); err != nil {
+		t.Fatalf("Delete(Coin): %v", err)
+	}
+
+	updated, err := store.Update(ctx, 1, 0, func(v *cmddb.DBUserBaseInfo) error {
+		v.Username += "-updated"
+		return nil
+	}, cmddb.FieldDBUserBaseInfo_Username)
+	if err != nil || updated.Username != "store-updated" {
+		t.Fatalf("Update = %#v, %v", updated, err)
+	}
+	// fn 返回错误时不写回
+	if _, err := store.Update(ctx, 1, 0, func(v *cmddb.DBUserBaseInfo) error {
+		v.Username = "discarded"
+		return errors.New("abort")
+	}, cmddb.FieldDBUserBaseInfo_Username); err == nil {
+		t.Error("fn 返回错误时 Update 应返回错误")
+	}
+	if got, _ := store.Get(ctx, 1, 0, cmddb.FieldDBUserBaseInfo_Username); got.Username != "store-updated" {
+		t.Errorf("fn 出错后不应写回, Username = %q", got.Username)
+	}
+
+	if err := store.Delete(ctx, 1, 0, cmddb.FieldDBUserBaseInfo_Level); err != nil {
+		t.Fatalf("Delete(字段): %v", err)
+	}
+	if got, _ := store.Get(ctx, 1, 0); got.Level != 0 || got.Username != "store-updated" {
+		t.Errorf("HDEL 只应删除 Level: %#v", got)
+	}
+	if err := store.Delete(ctx, 1, 0); err != nil {
+		t.Fatalf("Delete(整个 key): %v", err)
+	}
+	if got, _ := store.Get(ctx, 1, 0); !reflect.DeepEqual(got, &cmddb.DBUserBaseInfo{}) {
+		t.Errorf("DEL 后应全零: %#v", got)
+	}
+}
+
+// TestMemRepository 验证内存 Repository 与 Redis 语义一致：全字段往返、缺失字段为零值、
+// 未知字段报错、自增与删除，且多个 key 互不影响。
+func TestMemRepository(t *testing.T) {
+	ctx := context.Background()
+	var repo cmddb.DBUserBaseInfoRepository = cmddb.NewDBUserBaseInfoMemRepository()
+
+	want := newTestUser()
+	if err := repo.Set(ctx, 1, 2, want); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	got, err := repo.Get(ctx, 1, 2)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("全字段往返不一致:\n got  %#v\n want %#v", got, want)
+	}
+	if got, err := repo.Get(ctx, 1, 3); err != nil || !reflect.DeepEqual(got, &cmddb.DBUserBaseInfo{}) {
+		t.Errorf("未写入的 key 应读回全零: %#v, %v", got, err)
+	}
+
+	if _, err := repo.Get(ctx, 1, 2, cmddb.FieldDBUserBaseInfo(999)); err == nil {
+		t.Error("未知字段编号应报错")
+	}
+	if err := repo.Set(ctx, 1, 2, want, cmddb.FieldDBUserBaseInfo(999)); err == nil {
+		t.Error("未知字段编号应报错")
+	}
+
+	if level, err := repo.IncrLevel(ctx, 5, 0, 7); err != nil || level != 7 {
+		t.Errorf("IncrLevel(不存在的字段) = %d, %v, want 7", level, err)
+	}
+	if score, err := repo.IncrScore(ctx, 5, 0, 0.1); err != nil || score != 0.1 {
+		t.Errorf("IncrScore = %v, %v, want 0.1", score, err)
+	}
+	if _, err := repo.IncrUserId(ctx, 1, 2, -1); err == nil {
+		t.Error("UserId 已是 int32 最小值，自减越界应报错")
+	}
+
+	if err := repo.Delete(ctx, 1, 2, cmddb.FieldDBUserBaseInfo_Username); err != nil {
+		t.Fatalf("Delete(字段): %v", err)
+	}
+	got, err = repo.Get(ctx, 1, 2, cmddb.FieldDBUserBaseInfo_Username, cmddb.FieldDBUserBaseInfo_Level)
+	if err != nil || got.Username != "" || got.Level != want.Level {
+		t.Errorf("HDEL 只应删除 Username: %#v, %v", got, err)
+	}
+	if err := repo.Delete(ctx, 1, 2); err != nil {
+		t.Fatalf("Delete(整个 key): %v", err)
+	}
+	if got, _ := repo.Get(ctx, 1, 2); !reflect.DeepEqual(got, &cmddb.DBUserBaseInfo{}) {
+		t.Errorf("DEL 后应全零: %#v", got)
+	}
+	if got, _ := repo.Get(ctx, 5, 0, cmddb.FieldDBUserBaseInfo_Level); got.Level != 7 {
+		t.Errorf("其他 key 不应受影响, Level = %d", got.Level)
+	}
+}
+
+// TestNativeStorage 验证 storage=STORAGE_NATIVE 字段（proto/game.proto）：整体读写与元素级方法，
+// 分别经 redigo 连接池连接 Redis 与内存 Repository 运行，两者行为一致。
+func TestNativeStorage(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		dialRedis(t) // Redis 不可用时跳过
+		addr, password := redisAddr()
+		pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr, redis.DialPassword(password))
+		}}
+		t.Cleanup(func() { pool.Close() })
+		store := game.NewDBPlayerStore(pool, testREDBKey)
+		t.Cleanup(func() { store.Delete(context.Background(), 1, 0) })
+		testNativeRepository(t, store)
+
+		// 集合存于 Hash key 后接 ":<tag>" 的独立 key，类型与选项对应
+		if err := store.Set(context.Background(), 1, 0, &game.DBPlayer{
+			Friends: game.DBPlayer_DBFriends{Items: []uint64{1}},
+			Bag:     game.DBPlayer_DBBag{Items: []string{"a"}},
+			Items:   game.DBPlayer_DBItems{Items: map[int32]int64{1: 1}},
+			Mails:   game.DBPlayer_DBMails{Items: []game.DBMail{{Title: "a"}}},
+		}); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		conn := dialRedis(t)
+		for tag, typ := range map[int]string{3: "set", 4: "list", 5: "hash", 6: "list"} {
+			key := fmt.Sprintf("REDB#%d:1:0:%d", testREDBKey, tag)
+			if got, _ := redis.String(conn.Do("TYPE", key)); got != typ {
+				t.Errorf("TYPE %s = %q, want %q", key, got, typ)
+			}
+		}
+		if ok, _ := redis.Bool(conn.Do("HEXISTS", fmt.Sprintf("REDB#%d:1:0", testREDBKey), 3)); ok {
+			t.Error("原生存储字段不应写入 Hash")
+		}
+	})
+	t.Run("mem", func(t *testing.T) {
+		testNativeRepository(t, game.NewDBPlayerMemRepository())
+	})
+}
+
+func testNativeRepository(t *testing.T, repo game.DBPlayerRepository) {
+	t.Helper()
+	ctx := context.Background()
+	want := &game.DBPlayer{
+		Name:    "native",
+		Level:   3,
+		Friends: game.DBPlayer_DBFriends{Items: []uint64{7}},
+		Bag:     game.DBPlayer_DBBag{Items: []string{"sword", "potion", "sword"}},
+		Items:   game.DBPlayer_DBItems{Items: map[int32]int64{1: 10, 2: -20}},
+		Mails:   game.DBPlayer_DBMails{Items: []game.DBMail{{Title: "hi", SentAt: 1}, {Title: "bye", SentAt: 2}}},
+		Tags:    game.DBPlayer_DBTags{Items: []string{"a"}},
+	}
+	if err := repo.Set(ctx, 1, 0, want); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	got, err := repo.Get(ctx, 1, 0)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("全字段往返不一致:\n got  %#v\n want %#v", got, want)
+	}
+
+	// 元素级方法只改动单个元素
+	if err := repo.PutFriends(ctx, 1, 0, 8, 9, 8); err != nil {
+		t.Fatalf("PutFriends: %v", err)
+	}
+	if n, err := repo.LenFriends(ctx, 1, 0); err != nil || n != 3 {
+		t.Errorf("LenFriends = %d, %v, want 3（set 去重）", n, err)
+	}
+	if ok, _ := repo.ContainsFriends(ctx, 1, 0, 9); !ok {
+		t.Error("ContainsFriends(9) 应为 true")
+	}
+	if err := repo.RemoveFriends(ctx, 1, 0, 7); err != nil {
+		t.Fatalf("RemoveFriends: %v", err)
+	}
+	if ok, _ := repo.ContainsFriends(ctx, 1, 0, 7); ok {
+		t.Error("RemoveFriends 后 ContainsFriends(7) 应为 false")
+	}
+
+	if err := repo.PutBag(ctx, 1, 0, "shield"); err != nil {
+		t.Fatalf("PutBag: %v", err)
+	}
+	if err := repo.RemoveBag(ctx, 1, 0, "sword"); err != nil {
+		t.Fatalf("RemoveBag: %v", err)
+	}
+	var bag []string
+	if err := repo.RangeBag(ctx, 1, 0, func(e string) bool { bag = append(bag, e); return true }); err != nil {
+		t.Fatalf("RangeBag: %v", err)
+	}
+	if !reflect.DeepEqual(bag, []string{"potion", "shield"}) {
+		t.Errorf("RangeBag = %v，期望按顺序且删除全部 sword", bag)
+	}
+	if ok, _ := repo.ContainsBag(ctx, 1, 0, "shield"); !ok {
+		t.Error("ContainsBag(shield) 应为 true")
+	}
+
+	if err := repo.PutItems(ctx, 1, 0, 3, 30); err != nil {
+		t.Fatalf("PutItems: %v", err)
+	}
+	if v, ok, err := repo.GetItems(ctx, 1, 0, 2); err != nil || !ok || v != -20 {
+		t.Errorf("GetItems(2) = %d, %v, %v", v, ok, err)
+	}
+	if _, ok, err := repo.GetItems(ctx, 1, 0, 99); err != nil || ok {
+		t.Errorf("GetItems(不存在的键) = %v, %v", ok, err)
+	}
+	if err := repo.RemoveItems(ctx, 1, 0, 1); err != nil {
+		t.Fatalf("RemoveItems: %v", err)
+	}
+	items := map[int32]int64{}
+	if err := repo.RangeItems(ctx, 1, 0, func(k int32, v int64) bool { items[k] = v; return true }); err != nil {
+		t.Fatalf("RangeItems: %v", err)
+	}
+	if !reflect.DeepEqual(items, map[int32]int64{2: -20, 3: 30}) {
+		t.Errorf("RangeItems = %v", items)
+	}
+
+	if ok, _ := repo.ContainsMails(ctx, 1, 0, game.DBMail{Title: "bye", SentAt: 2}); !ok {
+		t.Error("ContainsMails 应按 protobuf 编码比较")
+	}
+	if err := repo.RemoveMails(ctx, 1, 0, game.DBMail{Title: "hi", SentAt: 1}); err != nil {
+		t.Fatalf("RemoveMails: %v", err)
+	}
+	if n, _ := repo.LenMails(ctx, 1, 0); n != 1 {
+		t.Errorf("LenMails = %d, want 1", n)
+	}
+
+	// 整体写入覆盖原有元素；空集合读回 nil
+	if err := repo.Set(ctx, 1, 0, &game.DBPlayer{Bag: game.DBPlayer_DBBag{Items: []string{"only"}}}, game.FieldDBPlayer_Bag, game.FieldDBPlayer_Items); err != nil {
+		t.Fatalf("Set(Bag, Items): %v", err)
+	}
+	got, err = repo.Get(ctx, 1, 0, game.FieldDBPlayer_Bag, game.FieldDBPlayer_Items, game.FieldDBPlayer_Friends)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !reflect.DeepEqual(got.Bag.Items, []string{"only"}) || got.Items.Items != nil || len(got.Friends.Items) != 2 {
+		t.Errorf("整体覆盖结果不符: %#v", got)
+	}
+
+	// 删除字段删除其独立 key；删除整条记录连同全部独立 key
+	if err := repo.Delete(ctx, 1, 0, game.FieldDBPlayer_Friends, game.FieldDBPlayer_Name); err != nil {
+		t.Fatalf("Delete(字段): %v", err)
+	}
+	if got, _ := repo.Get(ctx, 1, 0, game.FieldDBPlayer_Friends, game.FieldDBPlayer_Name, game.FieldDBPlayer_Level); got.Friends.Items != nil || got.Name != "" || got.Level != 3 {
+		t.Errorf("Delete(Friends, Name) 结果不符: %#v", got)
+	}
+	if err := repo.Delete(ctx, 1, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, _ := repo.Get(ctx, 1, 0); !reflect.DeepEqual(got, &game.DBPlayer{}) {
+		t.Errorf("Delete 后应全零: %#v", got)
+	}
+}
+
+// TestZSetTable 验证 sorted set 表（proto/game.proto 的 DBRank / DBGuildRank）：
+// 分别经 redigo 连接池连接 Redis 与内存 Repository 运行，两者行为一致。
+func TestZSetTable(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		dialRedis(t) // Redis 不可用时跳过
+		addr, password := redisAddr()
+		pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr, redis.DialPassword(password))
+		}}
+		t.Cleanup(func() { pool.Close() })
+		ranks := game.NewDBRankStore(pool, testREDBKey)
+		guilds := game.NewDBGuildRankStore(pool, testREDBKey)
+		t.Cleanup(func() {
+			ranks.Delete(context.Background(), 1, 0)
+			guilds.Delete(context.Background(), 1, 0)
+		})
+		testZSetRepository(t, ranks, guilds)
+
+		// 分数在 sorted set 中，其余字段在伴随 hash 中
+		conn := dialRedis(t)
+		ranks.Add(context.Background(), 1, 0, &game.DBRank{UserId: 9, Score: 1, Name: "x"})
+		if typ, _ := redis.String(conn.Do("TYPE", fmt.Sprintf("REDB#%d:1:0", testREDBKey))); typ != "zset" {
+			t.Errorf("TYPE = %q, want zset", typ)
+		}
+		if ok, _ := redis.Bool(conn.Do("HEXISTS", fmt.Sprintf("REDB#%d:1:0:payload", testREDBKey), 9)); !ok {
+			t.Error("伴随 hash 中应有成员 9 的数据")
+		}
+	})
+	t.Run("mem", func(t *testing.T) {
+		testZSetRepository(t, game.NewDBRankMemRepository(), game.NewDBGuildRankMemRepository())
+	})
+}
+
+func testZSetRepository(t *testing.T, ranks game.DBRankRepository, guilds game.DBGuildRankRepository) {
+	t.Helper()
+	ctx := context.Background()
+	for _, r := range []*game.DBRank{
+		{UserId: 1, Score: 100, Name: "alice", Level: 10},
+		{UserId: 2, Score: 300, Name: "bob", Level: 20},
+		{UserId: 3, Score: 200, Name: "carol", Level: 30},
+	} {
+		if err := ranks.Add(ctx, 1, 0, r); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	top, err := ranks.RevRange(ctx, 1, 0, 0, 1)
+	if err != nil {
+		t.Fatalf("RevRange: %v", err)
+	}
+	want := []*game.DBRank{
+		{UserId: 2, Score: 300, Name: "bob", Level: 20},
+		{UserId: 3, Score: 200, Name: "carol", Level: 30},
+	}
+	if !reflect.DeepEqual(top, want) {
+		t.Errorf("RevRange(0, 1) = %+v", top)
+	}
+	if got, err := ranks.RevRange(ctx, 1, 5, 0, -1); err != nil || got != nil {
+		t.Errorf("空表 RevRange = %v, %v", got, err)
+	}
+
+	if score, err := ranks.IncrScore(ctx, 1, 0, 1, 250); err != nil || score != 350 {
+		t.Errorf("IncrScore = %d, %v, want 350", score, err)
+	}
+	if rank, ok, err := ranks.RevRank(ctx, 1, 0, 1); err != nil || !ok || rank != 0 {
+		t.Errorf("RevRank(1) = %d, %v, %v, want 0", rank, ok, err)
+	}
+	if rank, ok, err := ranks.Rank(ctx, 1, 0, 1); err != nil || !ok || rank != 2 {
+		t.Errorf("Rank(1) = %d, %v, %v, want 2", rank, ok, err)
+	}
+	if _, ok, err := ranks.Rank(ctx, 1, 0, 42); err != nil || ok {
+		t.Errorf("不存在的成员 Rank ok = %v, %v", ok, err)
+	}
+	got, ok, err := ranks.Get(ctx, 1, 0, 1)
+	if err != nil || !ok || !reflect.DeepEqual(got, &game.DBRank{UserId: 1, Score: 350, Name: "alice", Level: 10}) {
+		t.Errorf("Get(1) = %+v, %v, %v", got, ok, err)
+	}
+	// 只 ZINCRBY 过的成员没有伴随数据，其余字段为零值
+	if _, err := ranks.IncrScore(ctx, 1, 0, 4, 5); err != nil {
+		t.Fatalf("IncrScore: %v", err)
+	}
+	if got, ok, _ := ranks.Get(ctx, 1, 0, 4); !ok || !reflect.DeepEqual(got, &game.DBRank{UserId: 4, Score: 5}) {
+		t.Errorf("Get(4) = %+v, %v", got, ok)
+	}
+
+	if err := ranks.Remove(ctx, 1, 0, 2, 4); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if n, err := ranks.Len(ctx, 1, 0); err != nil || n != 2 {
+		t.Errorf("Len = %d, %v, want 2", n, err)
+	}
+	if _, ok, _ := ranks.Get(ctx, 1, 0, 2); ok {
+		t.Error("Remove 后 Get 应不存在")
+	}
+	if err := ranks.Delete(ctx, 1, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if n, _ := ranks.Len(ctx, 1, 0); n != 0 {
+		t.Errorf("Delete 后 Len = %d", n)
+	}
+
+	// string 成员 + 浮点分数
+	guilds.Add(ctx, 1, 0, &game.DBGuildRank{Guild: "red", Power: 1.5, Leader: "alice"})
+	guilds.Add(ctx, 1, 0, &game.DBGuildRank{Guild: "blue", Power: 2.25, Leader: "bob"})
+	if power, err := guilds.IncrPower(ctx, 1, 0, "red", 1); err != nil || power != 2.5 {
+		t.Errorf("IncrPower = %v, %v, want 2.5", power, err)
+	}
+	list, err := guilds.RevRange(ctx, 1, 0, 0, -1)
+	if err != nil || len(list) != 2 || list[0].Guild != "red" || list[1].Leader != "bob" || list[1].Power != 2.25 {
+		t.Errorf("RevRange = %+v, %v", list, err)
+	}
+	guilds.Delete(ctx, 1, 0)
+}
+
+// TestZSetIndex 验证 zset_index：SetFields / Incr<Field> 在同一事务中同步更新 sorted set 索引，
+// Top<Field> / RevRank<Field> 读取索引，Delete 移出索引。
+func TestZSetIndex(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		dialRedis(t) // Redis 不可用时跳过
+		addr, password := redisAddr()
+		pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr, redis.DialPassword(password))
+		}}
+		t.Cleanup(func() { pool.Close() })
+		store := game.NewDBPlayerStore(pool, testREDBKey)
+		t.Cleanup(func() {
+			for _, id := range [][2]uint64{{1, 1}, {1, 2}, {1, 3}, {2, 1}} {
+				store.Delete(context.Background(), id[0], id[1])
+			}
+		})
+		testIndexRepository(t, store)
+
+		conn := dialRedis(t)
+		key := fmt.Sprintf("REDB#%d:1:rank:level", testREDBKey)
+		store.Set(context.Background(), 1, 1, &game.DBPlayer{Level: 7}, game.FieldDBPlayer_Level)
+		if score, _ := redis.Int(conn.Do("ZSCORE", key, "1:1")); score != 7 {
+			t.Errorf("ZSCORE %s 1:1 = %d, want 7", key, score)
+		}
+	})
+	t.Run("mem", func(t *testing.T) {
+		testIndexRepository(t, game.NewDBPlayerMemRepository())
+	})
+}
+
+func testIndexRepository(t *testing.T, repo game.DBPlayerRepository) {
+	t.Helper()
+	ctx := context.Background()
+	for _, p := range []struct {
+		ida, idb uint64
+		level    int32
+		power    float64
+	}{{1, 1, 10, 1.5}, {1, 2, 30, 3.5}, {1, 3, 20, 0.5}, {2, 1, 99, 2.5}} {
+		v := &game.DBPlayer{Name: fmt.Sprintf("p%d-%d", p.ida, p.idb), Level: p.level, Power: p.power}
+		if err := repo.Set(ctx, p.ida, p.idb, v, game.FieldDBPlayer_Name, game.FieldDBPlayer_Level, game.FieldDBPlayer_Power); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	// 等级按 ida 分榜：ida=2 的玩家不在 ida=1 的榜上
+	top, err := repo.TopLevel(ctx, 1, 2)
+	want := []game.DBPlayerIndexEntry{{Ida: 1, Idb: 2, Score: 30}, {Ida: 1, Idb: 3, Score: 20}}
+	if err != nil || !reflect.DeepEqual(top, want) {
+		t.Errorf("TopLevel(1, 2) = %+v, %v", top, err)
+	}
+	// 战力是全服一张榜
+	power, err := repo.TopPower(ctx, 10)
+	if err != nil || len(power) != 4 || power[0] != (game.DBPlayerIndexEntry{Ida: 1, Idb: 2, Score: 3.5}) || power[3].Idb != 3 {
+		t.Errorf("TopPower(10) = %+v, %v", power, err)
+	}
+	if got, err := repo.TopLevel(ctx, 1, 0); err != nil || got != nil {
+		t.Errorf("TopLevel(n=0) = %v, %v", got, err)
+	}
+
+	// Incr<Field> 同步 ZINCRBY：1:1 从 10 升到 40，成为第一
+	if level, err := repo.IncrLevel(ctx, 1, 1, 30); err != nil || level != 40 {
+		t.Fatalf("IncrLevel = %d, %v, want 40", level, err)
+	}
+	if rank, ok, err := repo.RevRankLevel(ctx, 1, 1); err != nil || !ok || rank != 0 {
+		t.Errorf("RevRankLevel(1, 1) = %d, %v, %v, want 0", rank, ok, err)
+	}
+	if _, ok, err := repo.RevRankLevel(ctx, 1, 9); err != nil || ok {
+		t.Errorf("不在索引中的记录 RevRankLevel ok = %v, %v", ok, err)
+	}
+	// 自增越界：HINCRBY 与索引的 ZINCRBY 一并撤销，记录仍可读取，索引分数不变
+	if err := repo.Set(ctx, 2, 1, &game.DBPlayer{Level: math.MaxInt32}, game.FieldDBPlayer_Level); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := repo.IncrLevel(ctx, 2, 1, 1); err == nil || !strings.Contains(err.Error(), "超出 int32 范围，已撤销本次自增") {
+		t.Errorf("IncrLevel 越界应报错并撤销, got %v", err)
+	}
+	if got, err := repo.Get(ctx, 2, 1, game.FieldDBPlayer_Level); err != nil || got.Level != math.MaxInt32 {
+		t.Errorf("越界自增撤销后 Get = %v, %v, want Level MaxInt32", got, err)
+	}
+	if top, err := repo.TopLevel(ctx, 2, 1); err != nil || len(top) != 1 || top[0].Score != math.MaxInt32 {
+		t.Errorf("越界自增撤销后 TopLevel(2, 1) = %+v, %v", top, err)
+	}
+	// 自增量本身超出 int32 能及的范围时不发送命令
+	if _, err := repo.IncrLevel(ctx, 2, 1, math.MinInt64); err == nil || !strings.Contains(err.Error(), "的自增量") {
+		t.Errorf("IncrLevel(MinInt64) 应直接报错, got %v", err)
+	}
+	// 不含索引字段的写入不触碰索引
+	if err := repo.Set(ctx, 1, 3, &game.DBPlayer{Name: "renamed"}, game.FieldDBPlayer_Name); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if rank, _, _ := repo.RevRankLevel(ctx, 1, 3); rank != 2 {
+		t.Errorf("RevRankLevel(1, 3) = %d, want 2", rank)
+	}
+
+	// 删除索引字段或整条记录时移出索引
+	if err := repo.Delete(ctx, 1, 2, game.FieldDBPlayer_Level); err != nil {
+		t.Fatalf("Delete(Level): %v", err)
+	}
+	if _, ok, _ := repo.RevRankLevel(ctx, 1, 2); ok {
+		t.Error("删除 Level 后 1:2 仍在等级索引中")
+	}
+	if rank, ok, _ := repo.RevRankPower(ctx, 1, 2); !ok || rank != 0 {
+		t.Errorf("删除 Level 不应影响战力索引: rank = %d, ok = %v", rank, ok)
+	}
+	for _, id := range [][2]uint64{{1, 1}, {1, 2}, {1, 3}, {2, 1}} {
+		if err := repo.Delete(ctx, id[0], id[1]); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+	}
+	if got, err := repo.TopPower(ctx, 10); err != nil || len(got) != 0 {
+		t.Errorf("全部删除后 TopPower = %+v, %v", got, err)
+	}
+}
+
+// multiExecutor 是各生成包的 RedisExecutor 与 redisrt.Executor 的共同形状（命令类型 C 各不相同）
+type multiExecutor[C any] interface {
+	Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error)
+	Multi(ctx context.Context, cmds []C) ([]interface{}, error)
+}
+
+// testMultiCommandError 在事务中让第 2 条命令因 key 类型错误失败：Multi 应返回指明该命令的错误，
+// 且第 1 条命令照常生效（Redis 不回滚事务）。cmd 构造该执行器的命令类型。
+func testMultiCommandError[C any](t *testing.T, exec multiExecutor[C], cmd func(name string, args ...interface{}) C) {
+	t.Helper()
+	ctx := context.Background()
+	str := fmt.Sprintf("REDB#%d:multi:str", testREDBKey)
+	other := fmt.Sprintf("REDB#%d:multi:other", testREDBKey)
+	t.Cleanup(func() { exec.Do(context.Background(), "DEL", str, other) })
+	if _, err := exec.Do(ctx, "SET", str, "v"); err != nil {
+		t.Fatalf("SET: %v", err)
+	}
+	_, err := exec.Multi(ctx, []C{cmd("SET", other, "1"), cmd("HSET", str, "f", "v")})
+	if err == nil || !strings.Contains(err.Error(), "事务中第 2 条命令 HSET 失败") || !strings.Contains(err.Error(), "WRONGTYPE") {
+		t.Fatalf("Multi 中 HSET 失败应返回错误, got %v", err)
+	}
+	if v, err := exec.Do(ctx, "GET", other); err != nil || fmt.Sprintf("%s", v) != "1" {
+		t.Errorf("事务中其他命令应照常生效: GET = %v, %v", v, err)
+	}
+}
+
+// TestMultiCommandError 验证事务中单条命令失败时三种执行器（redigo、go-redis、内存）行为一致，都返回 *RedisTxError：
+// redigo 把 EXEC 中的命令错误放在回复数组里，不检查时 SetFields、Delete 与索引写入会在部分失败时报告成功。
+func TestMultiCommandError(t *testing.T) {
+	genCmd := func(name string, args ...interface{}) cmddb.RedisCmd { return cmddb.RedisCmd{Name: name, Args: args} }
+	rtCmd := func(name string, args ...interface{}) redisrt.Cmd { return redisrt.Cmd{Name: name, Args: args} }
+	t.Run("redigo", func(t *testing.T) {
+		testMultiCommandError(t, cmddb.NewRedigoExecutor(dialRedis(t)), genCmd)
+		testMultiCommandError(t, redigoexec.New(dialRedis(t)), rtCmd)
+	})
+	t.Run("goredis", func(t *testing.T) {
+		testMultiCommandError(t, cmddbgoredis.NewGoRedisExecutor(dialGoRedis(t)), func(name string, args ...interface{}) cmddbgoredis.RedisCmd {
+			return cmddbgoredis.RedisCmd{Name: name, Args: args}
+		})
+		testMultiCommandError(t, goredisexec.New(dialGoRedis(t)), rtCmd)
+	})
+	t.Run("mem", func(t *testing.T) {
+		testMultiCommandError(t, cmddb.NewRedisMemExecutor(), genCmd)
+		testMultiCommandError(t, redisrt.NewMemExecutor(), rtCmd)
+	})
+
+	// 写入索引字段时 sorted set 索引的 ZADD 失败：Set 返回 *RedisTxError，不再报告成功
+	testIndexWriteError := func(t *testing.T, store *game.DBPlayerStore, exec game.RedisExecutor) {
+		t.Helper()
+		ctx := context.Background()
+		rank := fmt.Sprintf("REDB#%d:31:rank:level", testREDBKey)
+		t.Cleanup(func() {
+			exec.Do(context.Background(), "DEL", rank)
+			store.Delete(context.Background(), 31, 1)
+		})
+		if _, err := exec.Do(ctx, "SET", rank, "not a zset"); err != nil {
+			t.Fatalf("SET: %v", err)
+		}
+		err := store.Set(ctx, 31, 1, &game.DBPlayer{Level: 5}, game.FieldDBPlayer_Level)
+		var txErr *game.RedisTxError
+		if !errors.As(err, &txErr) || txErr.Cmd != "ZADD" {
+			t.Errorf("索引 ZADD 失败时 Set 应返回 *RedisTxError, got %v", err)
+		}
+	}
+	t.Run("index/redigo", func(t *testing.T) {
+		testIndexWriteError(t, game.NewDBPlayerStoreExec(game.NewRedigoExecutor(dialRedis(t)), testREDBKey), game.NewRedigoExecutor(dialRedis(t)))
+	})
+	t.Run("index/mem", func(t *testing.T) {
+		exec := game.NewRedisMemExecutor()
+		testIndexWriteError(t, game.NewDBPlayerStoreExec(exec, testREDBKey), exec)
+	})
+}
+
+// TestIndexIncrPartialFailure 验证带 sorted set 索引的 Incr<Field>：HINCRBY 与 ZINCRBY 在同一事务中执行，
+// 其中一条失败时另一条已生效，生成代码减回 delta，字段值与索引分数都保持自增前的值。
+func TestIndexIncrPartialFailure(t *testing.T) {
+	test := func(t *testing.T, store *game.DBPlayerStore, exec game.RedisExecutor) {
+		ctx := context.Background()
+		key := fmt.Sprintf("REDB#%d:32:1", testREDBKey)
+		rank := fmt.Sprintf("REDB#%d:32:rank:level", testREDBKey)
+		level := fmt.Sprint(uint32(game.FieldDBPlayer_Level))
+		t.Cleanup(func() {
+			exec.Do(context.Background(), "DEL", key, rank)
+		})
+		if err := store.Set(ctx, 32, 1, &game.DBPlayer{Level: 5}, game.FieldDBPlayer_Level); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+
+		// HINCRBY 失败（字段值不是数字）：撤销已生效的 ZINCRBY
+		if _, err := exec.Do(ctx, "HSET", key, level, "abc"); err != nil {
+			t.Fatalf("HSET: %v", err)
+		}
+		_, err := store.IncrLevel(ctx, 32, 1, 3)
+		var txErr *game.RedisTxError
+		if !errors.As(err, &txErr) || txErr.Cmd != "HINCRBY" || !strings.Contains(err.Error(), "已撤销本次自增") {
+			t.Errorf("HINCRBY 失败时应撤销 ZINCRBY 并返回 *RedisTxError, got %v", err)
+		}
+		if score, err := exec.Do(ctx, "ZSCORE", rank, "32:1"); err != nil || fmt.Sprintf("%s", score) != "5" {
+			t.Errorf("HINCRBY 失败后索引分数 = %s, %v, want 5", score, err)
+		}
+
+		// ZINCRBY 失败（索引 key 类型错误）：撤销已生效的 HINCRBY
+		if _, err := exec.Do(ctx, "HSET", key, level, 5); err != nil {
+			t.Fatalf("HSET: %v", err)
+		}
+		if _, err := exec.Do(ctx, "SET", rank, "not a zset"); err != nil {
+			t.Fatalf("SET: %v", err)
+		}
+		_, err = store.IncrLevel(ctx, 32, 1, 3)
+		if !errors.As(err, &txErr) || txErr.Cmd != "ZINCRBY" || !strings.Contains(err.Error(), "已撤销本次自增") {
+			t.Errorf("ZINCRBY 失败时应撤销 HINCRBY 并返回 *RedisTxError, got %v", err)
+		}
+		if v, err := store.Get(ctx, 32, 1, game.FieldDBPlayer_Level); err != nil || v.Level != 5 {
+			t.Errorf("ZINCRBY 失败后 Level = %+v, %v, want 5", v, err)
+		}
+	}
+	t.Run("redigo", func(t *testing.T) {
+		exec := game.NewRedigoExecutor(dialRedis(t))
+		test(t, game.NewDBPlayerStoreExec(exec, testREDBKey), exec)
+	})
+	t.Run("mem", func(t *testing.T) {
+		exec := game.NewRedisMemExecutor()
+		test(t, game.NewDBPlayerStoreExec(exec, testREDBKey), exec)
+	})
+}
+
+// sendFailConn 包装 redigo 连接，第 fail 次 Send 返回错误（模拟事务中途写失败）
+type sendFailConn struct {
+	redis.Conn
+	sends, fail int
+}
+
+func (c *sendFailConn) Send(cmd string, args ...interface{}) error {
+	if c.sends++; c.sends == c.fail {
+		return errors.New("send failed")
+	}
+	return c.Conn.Send(cmd, args...)
+}
+
+func (c *sendFailConn) DoContext(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return redis.DoContext(c.Conn, ctx, cmd, args...)
+}
+
+func (c *sendFailConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	return redis.ReceiveContext(c.Conn, ctx)
+}
+
+// TestMultiSendFailureDiscards 发出 MULTI 后 Send 失败时执行器发送 DISCARD：连接不停留在事务状态，后续命令立即执行而不是 QUEUED。
+func TestMultiSendFailureDiscards(t *testing.T) {
+	ctx := context.Background()
+	multis := map[string]func(conn redis.Conn) error{
+		"generated": func(conn redis.Conn) error {
+			_, err := cmddb.NewRedigoExecutor(conn).Multi(ctx, []cmddb.RedisCmd{{Name: "PING"}})
+			return err
+		},
+		"redigoexec": func(conn redis.Conn) error {
+			_, err := redigoexec.New(conn).Multi(ctx, []redisrt.Cmd{{Name: "PING"}})
+			return err
+		},
+	}
+	for name, multi := range multis {
+		conn := &sendFailConn{Conn: dialRedis(t), fail: 2}
+		if err := multi(conn); err == nil {
+			t.Fatalf("%s: Send 失败时 Multi 应返回错误", name)
+		}
+		if reply, err := redis.String(conn.Do("PING")); err != nil || reply != "PONG" {
+			t.Errorf("%s: Multi 失败后 PING = %q, %v, 连接仍在事务中", name, reply, err)
+		}
+	}
+}
+
+// TestUniqueIndex 验证 unique_index：写入前占用唯一索引，值被其他记录占用时返回 *RedisUniqueConflictError 且不写入；
+// 改值释放旧条目，Delete 释放当前条目，FindDBPlayerByName / FindByName 按值找回记录。
+func TestUniqueIndex(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		dialRedis(t) // Redis 不可用时跳过
+		addr, password := redisAddr()
+		pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr, redis.DialPassword(password))
+		}}
+		t.Cleanup(func() { pool.Close() })
+		store := game.NewDBPlayerStore(pool, testREDBKey)
+		t.Cleanup(func() {
+			for idb := uint64(1); idb <= 3; idb++ {
+				store.Delete(context.Background(), 7, idb)
+			}
+		})
+		testUniqueRepository(t, store)
+
+		// 唯一索引是独立的 hash：field 为昵称，值为 "<ida>:<idb>"
+		conn := dialRedis(t)
+		store.Set(context.Background(), 7, 1, &game.DBPlayer{Name: "zed"}, game.FieldDBPlayer_Name)
+		key := fmt.Sprintf("REDB#%d:uniq:name", testREDBKey)
+		if owner, _ := redis.String(conn.Do("HGET", key, "zed")); owner != "7:1" {
+			t.Errorf("HGET %s zed = %q, want 7:1", key, owner)
+		}
+		conn2 := dialRedis(t)
+		if ida, idb, ok, err := game.FindDBPlayerByName(context.Background(), game.NewRedigoExecutor(conn2), testREDBKey, "zed"); err != nil || !ok || ida != 7 || idb != 1 {
+			t.Errorf("FindDBPlayerByName = %d, %d, %v, %v", ida, idb, ok, err)
+		}
+	})
+	t.Run("mem", func(t *testing.T) {
+		testUniqueRepository(t, game.NewDBPlayerMemRepository())
+	})
+}
+
+// TestUniqueConcurrentWrites 多个连接并发改写、删除同两条记录的唯一字段：冲突与重试耗尽之外的错误都不应出现，
+// 结束后唯一索引与记录互相对应——每个条目的占用者正持有该值，每条记录的值都由它自己占用，没有无主的条目。
+func TestUniqueConcurrentWrites(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		conn := dialRedis(t) // Redis 不可用时跳过
+		addr, password := redisAddr()
+		pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr, redis.DialPassword(password))
+		}}
+		t.Cleanup(func() { pool.Close() })
+		testUniqueConcurrentWrites(t, game.NewDBPlayerStore(pool, testREDBKey), game.NewRedigoExecutor(conn))
+	})
+	t.Run("mem", func(t *testing.T) {
+		exec := game.NewRedisMemExecutor()
+		testUniqueConcurrentWrites(t, game.NewDBPlayerStoreExec(exec, testREDBKey), exec)
+	})
+}
+
+func testUniqueConcurrentWrites(t *testing.T, store *game.DBPlayerStore, exec game.RedisExecutor) {
+	t.Helper()
+	ctx := context.Background()
+	const ida = 41
+	uniq := fmt.Sprintf("REDB#%d:uniq:name", testREDBKey)
+	names := []string{"n0", "n1", "n2", "n3"}
+	t.Cleanup(func() {
+		for idb := uint64(1); idb <= 2; idb++ {
+			store.Delete(context.Background(), ida, idb)
+		}
+		for _, name := range names {
+			exec.Do(context.Background(), "HDEL", uniq, name)
+		}
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				idb := uint64(1 + (w+i)%2)
+				var err error
+				if (w*25+i)%11 == 0 {
+					err = store.Delete(ctx, ida, idb)
+				} else {
+					err = store.Set(ctx, ida, idb, &game.DBPlayer{Name: names[(w*7+i)%len(names)]}, game.FieldDBPlayer_Name)
+				}
+				var conflict *game.RedisUniqueConflictError
+				if err != nil && !errors.As(err, &conflict) && !errors.Is(err, game.ErrRedisTxAborted) {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("并发写入: %v", err)
+	}
+
+	held := make(map[string]string) // 记录 "<ida>:<idb>" -> 当前昵称
+	for idb := uint64(1); idb <= 2; idb++ {
+		key := fmt.Sprintf("REDB#%d:%d:%d", testREDBKey, ida, idb)
+		name, err := redis.String(exec.Do(ctx, "HGET", key, fmt.Sprint(uint32(game.FieldDBPlayer_Name))))
+		if err != nil && !errors.Is(err, redis.ErrNil) {
+			t.Fatalf("HGET %s: %v", key, err)
+		}
+		if name != "" {
+			held[fmt.Sprintf("%d:%d", ida, idb)] = name
+		}
+	}
+	for _, name := range names {
+		owner, err := redis.String(exec.Do(ctx, "HGET", uniq, name))
+		if err != nil && !errors.Is(err, redis.ErrNil) {
+			t.Fatalf("HGET %s %s: %v", uniq, name, err)
+		}
+		if owner != "" && held[owner] != name {
+			t.Errorf("唯一索引 %s -> %s 无主：该记录的昵称为 %q", name, owner, held[owner])
+		}
+	}
+	for member, name := range held {
+		if owner, _ := redis.String(exec.Do(ctx, "HGET", uniq, name)); owner != member {
+			t.Errorf("记录 %s 的昵称 %s 由 %q 占用", member, name, owner)
+		}
+	}
+}
+
+// testWatchAbort 验证执行器的 Watch：fn 中 WATCH 的 key 被其他连接（other）修改时 Multi 返回 aborted 且不执行，
+// 未修改时正常提交；fn 未执行 Multi 就返回时 WATCH 随之解除，不影响同一执行器之后的事务。
+func testWatchAbort[C any](t *testing.T, exec, other multiExecutor[C], aborted error,
+	watch func(ctx context.Context, keys []string, fn func(exec multiExecutor[C]) error) error,
+	cmd func(name string, args ...interface{}) C) {
+	t.Helper()
+	ctx := context.Background()
+	key := fmt.Sprintf("REDB#%d:watch", testREDBKey)
+	t.Cleanup(func() { other.Do(context.Background(), "DEL", key) })
+	get := func() string {
+		t.Helper()
+		v, err := redis.String(other.Do(ctx, "GET", key))
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		return v
+	}
+	if _, err := other.Do(ctx, "SET", key, "a"); err != nil {
+		t.Fatalf("SET: %v", err)
+	}
+
+	err := watch(ctx, []string{key}, func(exec multiExecutor[C]) error {
+		if v, err := redis.String(exec.Do(ctx, "GET", key)); err != nil || v != "a" {
+			t.Errorf("WATCH 后 GET = %q, %v", v, err)
+		}
+		if _, err := other.Do(ctx, "SET", key, "b"); err != nil {
+			t.Fatalf("SET: %v", err)
+		}
+		_, err := exec.Multi(ctx, []C{cmd("SET", key, "c")})
+		return err
+	})
+	if !errors.Is(err, aborted) {
+		t.Errorf("WATCH 的 key 被修改后 Multi = %v, want %v", err, aborted)
+	}
+	if v := get(); v != "b" {
+		t.Errorf("事务被放弃后 GET = %q, want b", v)
+	}
+
+	if err := watch(ctx, []string{key}, func(exec multiExecutor[C]) error {
+		_, err := exec.Multi(ctx, []C{cmd("SET", key, "c")})
+		return err
+	}); err != nil {
+		t.Errorf("未修改时 Multi: %v", err)
+	}
+	if v := get(); v != "c" {
+		t.Errorf("提交后 GET = %q, want c", v)
+	}
+
+	if err := watch(ctx, []string{key}, func(exec multiExecutor[C]) error { return nil }); err != nil {
+		t.Errorf("Watch: %v", err)
+	}
+	if _, err := other.Do(ctx, "SET", key, "d"); err != nil {
+		t.Fatalf("SET: %v", err)
+	}
+	if _, err := exec.Multi(ctx, []C{cmd("SET", key, "e")}); err != nil {
+		t.Errorf("Watch 返回后 WATCH 应已解除, Multi: %v", err)
+	}
+	if v := get(); v != "e" {
+		t.Errorf("GET = %q, want e", v)
+	}
+}
+
+// TestWatchAbort 覆盖生成代码与 redisrt 的各执行器实现的 Watch（唯一索引写入依赖它检测并发修改）
+func TestWatchAbort(t *testing.T) {
+	genCmd := func(name string, args ...interface{}) cmddb.RedisCmd { return cmddb.RedisCmd{Name: name, Args: args} }
+	rtCmd := func(name string, args ...interface{}) redisrt.Cmd { return redisrt.Cmd{Name: name, Args: args} }
+	genWatch := func(exec cmddb.RedisExecutor) func(ctx context.Context, keys []string, fn func(exec multiExecutor[cmddb.RedisCmd]) error) error {
+		return func(ctx context.Context, keys []string, fn func(exec multiExecutor[cmddb.RedisCmd]) error) error {
+			return exec.(cmddb.RedisWatcher).Watch(ctx, keys, func(exec cmddb.RedisExecutor) error { return fn(exec) })
+		}
+	}
+	rtWatch := func(exec redisrt.Executor) func(ctx context.Context, keys []string, fn func(exec multiExecutor[redisrt.Cmd]) error) error {
+		return func(ctx context.Context, keys []string, fn func(exec multiExecutor[redisrt.Cmd]) error) error {
+			return exec.(redisrt.Watcher).Watch(ctx, keys, func(exec redisrt.Executor) error { return fn(exec) })
+		}
+	}
+	t.Run("redigo", func(t *testing.T) {
+		exec := cmddb.NewRedigoExecutor(dialRedis(t))
+		testWatchAbort(t, exec, cmddb.NewRedigoExecutor(dialRedis(t)), cmddb.ErrRedisTxAborted, genWatch(exec), genCmd)
+		rtExec := redigoexec.New(dialRedis(t))
+		testWatchAbort(t, rtExec, redigoexec.New(dialRedis(t)), redisrt.ErrTxAborted, rtWatch(rtExec), rtCmd)
+	})
+	t.Run("goredis", func(t *testing.T) {
+		goCmd := func(name string, args ...interface{}) cmddbgoredis.RedisCmd {
+			return cmddbgoredis.RedisCmd{Name: name, Args: args}
+		}
+		exec := cmddbgoredis.NewGoRedisExecutor(dialGoRedis(t))
+		testWatchAbort(t, exec, cmddbgoredis.NewGoRedisExecutor(dialGoRedis(t)), cmddbgoredis.ErrRedisTxAborted,
+			func(ctx context.Context, keys []string, fn func(exec multiExecutor[cmddbgoredis.RedisCmd]) error) error {
+				return exec.(cmddbgoredis.RedisWatcher).Watch(ctx, keys, func(exec cmddbgoredis.RedisExecutor) error { return fn(exec) })
+			}, goCmd)
+		rtExec := goredisexec.New(dialGoRedis(t))
+		testWatchAbort(t, rtExec, goredisexec.New(dialGoRedis(t)), redisrt.ErrTxAborted, rtWatch(rtExec), rtCmd)
+	})
+	t.Run("mem", func(t *testing.T) {
+		exec := cmddb.NewRedisMemExecutor()
+		testWatchAbort(t, exec, exec, cmddb.ErrRedisTxAborted, genWatch(exec), genCmd)
+		rtExec := redisrt.NewMemExecutor()
+		testWatchAbort(t, rtExec, rtExec, redisrt.ErrTxAborted, rtWatch(rtExec), rtCmd)
+	})
+}
+
+// TestBlobStorage 覆盖 storage=MESSAGE_STORAGE_BLOB：整条记录一个 string key，按字段读写、Load/Save、删除与从 hash 迁移。
+func TestBlobStorage(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		conn := dialRedis(t) // Redis 不可用时跳过
+		addr, password := redisAddr()
+		pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr, redis.DialPassword(password))
+		}}
+		t.Cleanup(func() { pool.Close() })
+		store := game.NewDBLoadoutStore(pool, testREDBKey)
+		t.Cleanup(func() {
+			for idb := uint64(1); idb <= 3; idb++ {
+				store.Delete(context.Background(), 9, idb)
+			}
+		})
+		testBlobRepository(t, store, game.NewRedigoExecutor(conn))
+
+		// 整条记录存为一个 string key，值为 protobuf 字节
+		store.Save(context.Background(), 9, 1, &game.DBLoadout{WeaponId: 3})
+		key := fmt.Sprintf("REDB#%d:9:1", testREDBKey)
+		if typ, _ := redis.String(conn.Do("TYPE", key)); typ != "string" {
+			t.Errorf("TYPE %s = %q, want string", key, typ)
+		}
+		if b, _ := redis.Bytes(conn.Do("GET", key)); !bytes.Equal(b, []byte{0x08, 0x03}) {
+			t.Errorf("GET %s = %x, want 0803", key, b)
+		}
+	})
+	t.Run("mem", func(t *testing.T) {
+		exec := game.NewRedisMemExecutor()
+		testBlobRepository(t, game.NewDBLoadoutStoreExec(exec, testREDBKey), exec)
+	})
+}
+
+// testBlobRepository 对 blob 存储的 Store 执行读写与迁移断言；exec 与 store 指向同一份数据，用于写入迁移前的 hash。
+func testBlobRepository(t *testing.T, store *game.DBLoadoutStore, exec game.RedisExecutor) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, ok, err := store.Load(ctx, 9, 1); err != nil || ok {
+		t.Fatalf("Load 不存在的记录 = %v, %v, want false", ok, err)
+	}
+	want := &game.DBLoadout{WeaponId: 3, Level: 12, Skin: "gold"}
+	if err := store.Save(ctx, 9, 1, want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, ok, err := store.Load(ctx, 9, 1)
+	if err != nil || !ok || !reflect.DeepEqual(got, want) {
+		t.Fatalf("Load = %+v, %v, %v, want %+v", got, ok, err, want)
+	}
+
+	// 按字段写入只覆盖这些字段，其余字段保持不变
+	if err := store.Set(ctx, 9, 1, &game.DBLoadout{Level: 13, Skin: "ignored"}, game.FieldDBLoadout_Level); err != nil {
+		t.Fatalf("Set Level: %v", err)
+	}
+	if got, err := store.Get(ctx, 9, 1); err != nil || got.Level != 13 || got.Skin != "gold" || got.WeaponId != 3 {
+		t.Errorf("按字段 Set 后 Get = %+v, %v", got, err)
+	}
+	if got, err := store.Get(ctx, 9, 1, game.FieldDBLoadout_Skin); err != nil || !reflect.DeepEqual(got, &game.DBLoadout{Skin: "gold"}) {
+		t.Errorf("Get Skin = %+v, %v", got, err)
+	}
+	if _, err := store.Get(ctx, 9, 1, 99); err == nil {
+		t.Error("未知字段编号应报错")
+	}
+	if got, err := store.Update(ctx, 9, 1, func(v *game.DBLoadout) error {
+		v.Level++
+		return nil
+	}, game.FieldDBLoadout_Level); err != nil || got.Level != 14 {
+		t.Errorf("Update = %+v, %v", got, err)
+	}
+
+	// 按字段删除把字段置为零值；不存在的记录按字段删除不会创建 key
+	if err := store.Delete(ctx, 9, 1, game.FieldDBLoadout_Skin); err != nil {
+		t.Fatalf("Delete Skin: %v", err)
+	}
+	if got, _, _ := store.Load(ctx, 9, 1); got == nil || got.Skin != "" || got.Level != 14 {
+		t.Errorf("Delete Skin 后 Load = %+v", got)
+	}
+	if err := store.Delete(ctx, 9, 2, game.FieldDBLoadout_Skin); err != nil {
+		t.Fatalf("Delete 不存在的记录: %v", err)
+	}
+	if _, ok, _ := store.Load(ctx, 9, 2); ok {
+		t.Error("按字段删除不存在的记录不应创建 key")
+	}
+	if err := store.Delete(ctx, 9, 1); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok, _ := store.Load(ctx, 9, 1); ok {
+		t.Error("Delete 后记录应不存在")
+	}
+
+	// 零值记录也占有 key（空字节），Load 返回 true
+	if err := store.Save(ctx, 9, 1, &game.DBLoadout{}); err != nil {
+		t.Fatalf("Save 零值: %v", err)
+	}
+	if got, ok, err := store.Load(ctx, 9, 1); err != nil || !ok || !reflect.DeepEqual(got, &game.DBLoadout{}) {
+		t.Errorf("Load 零值记录 = %+v, %v, %v", got, ok, err)
+	}
+
+	// 迁移：按字段存储的旧 hash 转换为 blob，已是 blob 或不存在时什么也不做
+	key := fmt.Sprintf("REDB#%d:9:3", testREDBKey)
+	if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBLoadout_WeaponId), 5, uint32(game.FieldDBLoadout_Skin), "red"); err != nil {
+		t.Fatalf("HSET: %v", err)
+	}
+	if _, err := store.Get(ctx, 9, 3); err == nil {
+		t.Error("迁移前按 blob 读取 hash key 应报错")
+	}
+	if migrated, err := store.MigrateToBlob(ctx, 9, 3); err != nil || !migrated {
+		t.Fatalf("MigrateToBlob = %v, %v, want true", migrated, err)
+	}
+	if got, ok, err := store.Load(ctx, 9, 3); err != nil || !ok || !reflect.DeepEqual(got, &game.DBLoadout{WeaponId: 5, Skin: "red"}) {
+		t.Errorf("迁移后 Load = %+v, %v, %v", got, ok, err)
+	}
+	if migrated, err := store.MigrateToBlob(ctx, 9, 3); err != nil || migrated {
+		t.Errorf("重复迁移 = %v, %v, want false", migrated, err)
+	}
+	if migrated, err := game.MigrateDBLoadoutToBlob(ctx, exec, testREDBKey, 9, 2); err != nil || migrated {
+		t.Errorf("迁移不存在的记录 = %v, %v, want false", migrated, err)
+	}
+}
+
+// TestBlobConcurrentWrites 验证 blob 存储的乐观锁：并发按字段写入不同字段时各自的写入都保留（与 Hash 表一致）；
+// 迁移在读出 hash 之后 hash 被写入时重新读取，迁移结果包含这次写入。
+func TestBlobConcurrentWrites(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		conn := dialRedis(t) // Redis 不可用时跳过
+		addr, password := redisAddr()
+		pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr, redis.DialPassword(password))
+		}}
+		t.Cleanup(func() { pool.Close() })
+		testBlobConcurrentWrites(t, game.NewDBLoadoutStore(pool, testREDBKey), game.NewRedigoExecutor(conn), game.NewRedigoExecutor(dialRedis(t)))
+	})
+	t.Run("mem", func(t *testing.T) {
+		exec := game.NewRedisMemExecutor()
+		testBlobConcurrentWrites(t, game.NewDBLoadoutStoreExec(exec, testREDBKey), exec, exec)
+	})
+}
+
+// hmgetHook 包装执行器：经它（含 Watch 交给 fn 的执行器）执行的第一条 HMGET 返回后调用一次 hook
+type hmgetHook struct {
+	game.RedisExecutor
+	once *sync.Once
+	hook func()
+}
+
+func (e *hmgetHook) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	reply, err := e.RedisExecutor.Do(ctx, cmd, args...)
+	if cmd == "HMGET" {
+		e.once.Do(e.hook)
+	}
+	return reply, err
+}
+
+func (e *hmgetHook) Watch(ctx context.Context, keys []string, fn func(exec game.RedisExecutor) error) error {
+	return e.RedisExecutor.(game.RedisWatcher).Watch(ctx, keys, func(exec game.RedisExecutor) error {
+		return fn(&hmgetHook{RedisExecutor: exec, once: e.once, hook: e.hook})
+	})
+}
+
+// testBlobConcurrentWrites 对 store 执行并发写入与迁移断言；exec 与 store 指向同一份数据，
+// migrate 是执行迁移用的另一个执行器（Redis 上为另一条连接）
+func testBlobConcurrentWrites(t *testing.T, store *game.DBLoadoutStore, exec, migrate game.RedisExecutor) {
+	t.Helper()
+	ctx := context.Background()
+	t.Cleanup(func() {
+		for idb := uint64(1); idb <= 2; idb++ {
+			store.Delete(context.Background(), 19, idb)
 		}
-		if json.Unmarshal(data, &cfg) == nil && cfg.RedisCfg.Address != "" {
-			addr = cfg.RedisCfg.Address
-			password = cfg.RedisCfg.Password
+	})
+
+	const n = 50
+	writes := []func(i int) (*game.DBLoadout, game.FieldDBLoadout){
+		func(i int) (*game.DBLoadout, game.FieldDBLoadout) {
+			return &game.DBLoadout{WeaponId: uint32(i)}, game.FieldDBLoadout_WeaponId
+		},
+		func(i int) (*game.DBLoadout, game.FieldDBLoadout) {
+			return &game.DBLoadout{Level: int32(i)}, game.FieldDBLoadout_Level
+		},
+		func(i int) (*game.DBLoadout, game.FieldDBLoadout) {
+			return &game.DBLoadout{Skin: fmt.Sprint(i)}, game.FieldDBLoadout_Skin
+		},
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, len(writes))
+	for _, write := range writes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 1; i <= n; i++ {
+				v, field := write(i)
+				err := store.Set(ctx, 19, 1, v, field)
+				for errors.Is(err, game.ErrRedisTxAborted) { // 重试次数用尽时再写一次，只检查写入是否丢失
+					err = store.Set(ctx, 19, 1, v, field)
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("并发按字段写入: %v", err)
+	}
+	if got, _, err := store.Load(ctx, 19, 1); err != nil || !reflect.DeepEqual(got, &game.DBLoadout{WeaponId: n, Level: n, Skin: fmt.Sprint(n)}) {
+		t.Errorf("并发写入不同字段后 Load = %+v, %v，有写入丢失", got, err)
+	}
+
+	// 迁移读出 hash 之后、事务之前另一个写入方 HSET 了 Level：事务被放弃，重新读取后迁移
+	key := fmt.Sprintf("REDB#%d:19:2", testREDBKey)
+	if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBLoadout_WeaponId), 5); err != nil {
+		t.Fatalf("HSET: %v", err)
+	}
+	hook := &hmgetHook{RedisExecutor: migrate, once: new(sync.Once), hook: func() {
+		if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBLoadout_Level), 7); err != nil {
+			t.Errorf("并发 HSET: %v", err)
 		}
+	}}
+	if migrated, err := game.MigrateDBLoadoutToBlob(ctx, hook, testREDBKey, 19, 2); err != nil || !migrated {
+		t.Fatalf("MigrateDBLoadoutToBlob = %v, %v, want true", migrated, err)
 	}
-	conn, err := redis.Dial("tcp", addr, redis.DialPassword(password))
-	if err != nil {
-		t.Skipf("Redis 不可用，跳过集成测试: %v", err)
+	if got, ok, err := store.Load(ctx, 19, 2); err != nil || !ok || !reflect.DeepEqual(got, &game.DBLoadout{WeaponId: 5, Level: 7}) {
+		t.Errorf("迁移后 Load = %+v, %v, %v，迁移期间的写入丢失", got, ok, err)
 	}
-	t.Cleanup(func() { conn.Close() })
-	return conn
 }
 
-// newTestUser 返回一个全字段填满的测试数据，覆盖所有类型映射与边界值。
-func newTestUser() *cmddb.DBUserBaseInfo {
-	return &cmddb.DBUserBaseInfo{
-		UserId:      -2147483648, // int32 最小值
-		Username:    "测试用户-中文",
-		AvatarUrl:   "https://example.com/a.png?x=1&y=2",
-		Gender:      cmddb.Gender_GENDER_FEMALE,
-		Level:       99,
-		Exp:         9223372036854775807, // int64 最大值
-		Balance:     3.5,
-		Friends:     cmddb.DBUserBaseInfo_DBFriends{Items: []string{"alice", "bob", ""}},
-		Settings:    cmddb.DBUserBaseInfo_DBSettings{Kv: map[string]string{"sound": "80", "lang": "zh-CN"}},
-		LoginSource: cmddb.LoginSource_SOURCE_MINI_PROGRAM,
-		Int32List:   cmddb.DBUserBaseInfo_DBInt32List{Items: []int32{1, -2, 3}},
-		Weapons: cmddb.DBUserBaseInfo_DBWeapons{Items: []cmddb.DBWeapon{
-			{Name: "sword", Damage: 10, Element: "fire"},
-			{Name: "bow", Damage: 8, Element: "ice"},
-		}},
-		Weapon: cmddb.DBWeapon{Name: "knife", Damage: 5, Element: "poison"},
-		WeaponMap: cmddb.DBUserBaseInfo_DBWeaponMap{Items: map[int32]cmddb.DBWeapon{
-			1: {Name: "w1", Damage: 1, Element: "e1"},
-			2: {Name: "w2", Damage: 2, Element: "e2"},
-		}},
-		Coin:     4294967295,           // uint32 最大值
-		Gem:      18446744073709551615, // uint64 最大值
-		Vip:      true,
-		Score:    3.25,
-		Token:    []byte{0x00, 0x01, 0xFF}, // 含不可打印字节
-		Profile:  cmddb.DBUserBaseInfo_DBProfile{Nickname: "nick", Age: 30},
-		VipLevel: cmddb.DBUserBaseInfo_VIP_2,
+// TestHashFieldNames 覆盖 hash_field=HASH_FIELD_NAME 与 tag_fallback：field 为字段名（或 redis_name），
+// 迁移窗口内旧的字段编号 field 可读，写入时搬到名字下。
+func TestHashFieldNames(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		conn := dialRedis(t) // Redis 不可用时跳过
+		exec := game.NewRedigoExecutor(conn)
+		store := game.NewDBProfileStoreExec(exec, testREDBKey)
+		t.Cleanup(func() {
+			for idb := uint64(1); idb <= 2; idb++ {
+				store.Delete(context.Background(), 11, idb)
+			}
+		})
+		testHashFieldNames(t, store, exec)
+	})
+	t.Run("mem", func(t *testing.T) {
+		exec := game.NewRedisMemExecutor()
+		testHashFieldNames(t, game.NewDBProfileStoreExec(exec, testREDBKey), exec)
+	})
+}
+
+// testHashFieldNames 对按字段名存储的 Store 执行断言；exec 与 store 指向同一份数据，用于直接检查与写入 hash field。
+func testHashFieldNames(t *testing.T, store *game.DBProfileStore, exec game.RedisExecutor) {
+	t.Helper()
+	ctx := context.Background()
+	hgetall := func(idb uint64) map[string]string {
+		t.Helper()
+		reply, err := exec.Do(ctx, "HGETALL", fmt.Sprintf("REDB#%d:11:%d", testREDBKey, idb))
+		values, _ := reply.([]interface{})
+		if err != nil || len(values)%2 != 0 {
+			t.Fatalf("HGETALL = %v, %v", reply, err)
+		}
+		m := make(map[string]string, len(values)/2)
+		for i := 0; i < len(values); i += 2 {
+			m[string(values[i].([]byte))] = string(values[i+1].([]byte))
+		}
+		return m
+	}
+
+	if err := store.Set(ctx, 11, 1, &game.DBProfile{Nickname: "ann", Level: 3, Gold: 50}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, want := hgetall(1), map[string]string{"nick": "ann", "level": "3", "gold": "50"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hash = %v, want %v", got, want)
+	}
+	if gold, err := store.IncrGold(ctx, 11, 1, 5); err != nil || gold != 55 {
+		t.Errorf("IncrGold = %d, %v, want 55", gold, err)
+	}
+
+	// 迁移窗口：旧程序按字段编号写入的记录仍可读，名字 field 优先
+	key := fmt.Sprintf("REDB#%d:11:2", testREDBKey)
+	if _, err := exec.Do(ctx, "HSET", key, 1, "old", 2, 7, 3, 100, "level", 8); err != nil {
+		t.Fatalf("HSET: %v", err)
+	}
+	if got, err := store.Get(ctx, 11, 2); err != nil || !reflect.DeepEqual(got, &game.DBProfile{Nickname: "old", Level: 8, Gold: 100}) {
+		t.Errorf("回退读取 Get = %+v, %v", got, err)
+	}
+	// 自增前把旧值搬到名字下，不会从 0 开始
+	if gold, err := store.IncrGold(ctx, 11, 2, 1); err != nil || gold != 101 {
+		t.Errorf("IncrGold = %d, %v, want 101", gold, err)
+	}
+	// 写入前搬迁：名字 field 已存在时保留它，编号 field 删除
+	if err := store.Set(ctx, 11, 2, &game.DBProfile{Nickname: "new"}, game.FieldDBProfile_Nickname, game.FieldDBProfile_Level); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, want := hgetall(2), map[string]string{"nick": "new", "level": "0", "gold": "101"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("搬迁后 hash = %v, want %v", got, want)
+	}
+	if _, err := exec.Do(ctx, "HSET", key, 1, "stale"); err != nil {
+		t.Fatalf("HSET: %v", err)
+	}
+	if err := store.Delete(ctx, 11, 2, game.FieldDBProfile_Nickname); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if got, err := store.Get(ctx, 11, 2, game.FieldDBProfile_Nickname); err != nil || got.Nickname != "" {
+		t.Errorf("Delete 应同时删除名字与编号 field, Get = %+v, %v", got, err)
 	}
 }
 
-// TestFullRoundTrip 全字段写入 + 全字段读取，逐一字段比对。
-func TestFullRoundTrip(t *testing.T) {
-	conn := dialRedis(t)
-	t.Cleanup(func() { conn.Do("DEL", fmt.Sprintf("REDB#%d:1:0", testREDBKey)) })
+// TestValueEncoding 覆盖 encoding=VALUE_ENCODING_JSON：message 字段以 proto3 JSON 存入 hash field，
+// 字段上覆盖为 VALUE_ENCODING_PROTO 的仍为 protobuf 字节；读取时两种编码都接受。
+func TestValueEncoding(t *testing.T) {
+	t.Run("redis", func(t *testing.T) {
+		conn := dialRedis(t) // Redis 不可用时跳过
+		exec := game.NewRedigoExecutor(conn)
+		store := game.NewDBGuildStoreExec(exec, testREDBKey)
+		t.Cleanup(func() { store.Delete(context.Background(), 12, 0) })
+		testValueEncoding(t, store, exec)
+	})
+	t.Run("mem", func(t *testing.T) {
+		exec := game.NewRedisMemExecutor()
+		testValueEncoding(t, game.NewDBGuildStoreExec(exec, testREDBKey), exec)
+	})
+}
 
-	want := newTestUser()
-	if err := want.SetFields(conn, testREDBKey, 1, 0); err != nil {
-		t.Fatalf("SetFields(全字段): %v", err)
+// TestEnumStorage 覆盖 enum_storage=ENUM_STORAGE_NAME：枚举写入 proto 中的名字，读取时名字与整数都接受，未知名字报错。
+func TestEnumStorage(t *testing.T) {
+	for name, exec := range map[string]game.RedisExecutor{
+		"redis": nil,
+		"mem":   game.NewRedisMemExecutor(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if exec == nil {
+				exec = game.NewRedigoExecutor(dialRedis(t)) // Redis 不可用时跳过
+			}
+			store := game.NewDBGuildStoreExec(exec, testREDBKey)
+			ctx := context.Background()
+			t.Cleanup(func() { store.Delete(context.Background(), 13, 0) })
+			key := fmt.Sprintf("REDB#%d:13:0", testREDBKey)
+
+			if err := store.Set(ctx, 13, 0, &game.DBGuild{DefaultRole: game.DBGuild_ROLE_ELDER}, game.FieldDBGuild_DefaultRole); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if reply, err := exec.Do(ctx, "HGET", key, uint32(game.FieldDBGuild_DefaultRole)); err != nil || string(reply.([]byte)) != "ROLE_ELDER" {
+				t.Errorf("HGET = %q, %v, want ROLE_ELDER", reply, err)
+			}
+			for raw, want := range map[string]game.DBGuild_Role{"2": game.DBGuild_ROLE_LEADER, "ROLE_MEMBER": game.DBGuild_ROLE_MEMBER, "9": 9} {
+				if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBGuild_DefaultRole), raw); err != nil {
+					t.Fatalf("HSET: %v", err)
+				}
+				if got, err := store.Get(ctx, 13, 0, game.FieldDBGuild_DefaultRole); err != nil || got.DefaultRole != want {
+					t.Errorf("读取 %q = %+v, %v, want %v", raw, got, err, want)
+				}
+			}
+			// 未知值写入整数，读取不丢失
+			if err := store.Set(ctx, 13, 0, &game.DBGuild{DefaultRole: 9}, game.FieldDBGuild_DefaultRole); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if got, err := store.Get(ctx, 13, 0, game.FieldDBGuild_DefaultRole); err != nil || got.DefaultRole != 9 {
+				t.Errorf("未知值往返 = %+v, %v", got, err)
+			}
+			if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBGuild_DefaultRole), "ROLE_KING"); err != nil {
+				t.Fatalf("HSET: %v", err)
+			}
+			_, err := store.Get(ctx, 13, 0, game.FieldDBGuild_DefaultRole)
+			if err == nil || !strings.Contains(err.Error(), `"ROLE_KING"`) || !strings.Contains(err.Error(), "ROLE_LEADER") {
+				t.Errorf("未知名字应报错并列出可选名字, got %v", err)
+			}
+		})
 	}
+}
 
-	got := &cmddb.DBUserBaseInfo{}
-	if err := got.GetFields(conn, testREDBKey, 1, 0); err != nil {
-		t.Fatalf("GetFields(全字段): %v", err)
+// TestCompression 覆盖 compression=COMPRESSION_FLATE：达到阈值的集合字段压缩后写入（1 字节头 + DEFLATE），
+// 未达到阈值的原样写入，读取时压缩与未压缩（开启压缩前写入）的值都接受。
+func TestCompression(t *testing.T) {
+	for name, exec := range map[string]game.RedisExecutor{
+		"redis": nil,
+		"mem":   game.NewRedisMemExecutor(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if exec == nil {
+				exec = game.NewRedigoExecutor(dialRedis(t)) // Redis 不可用时跳过
+			}
+			store := game.NewDBPlayerStoreExec(exec, testREDBKey)
+			ctx := context.Background()
+			t.Cleanup(func() { store.Delete(context.Background(), 14, 0) })
+			key := fmt.Sprintf("REDB#%d:14:0", testREDBKey)
+			hget := func() []byte {
+				t.Helper()
+				reply, err := exec.Do(ctx, "HGET", key, uint32(game.FieldDBPlayer_Journal))
+				if err != nil {
+					t.Fatalf("HGET: %v", err)
+				}
+				b, _ := reply.([]byte)
+				return b
+			}
+
+			large := &game.DBPlayer{}
+			for i := 0; i < 50; i++ {
+				large.Journal.Items = append(large.Journal.Items, fmt.Sprintf("第 %d 天：击败了巨龙，获得金币 100", i))
+			}
+			raw, err := large.Journal.MarshalRedisProto()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Set(ctx, 14, 0, large, game.FieldDBPlayer_Journal); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if b := hget(); len(b) == 0 || b[0] != 0x01 || len(b) >= len(raw) {
+				t.Errorf("达到阈值应压缩: 写入 %d 字节，编码后 %d 字节", len(b), len(raw))
+			}
+			got, err := store.Get(ctx, 14, 0, game.FieldDBPlayer_Journal)
+			if err != nil || !reflect.DeepEqual(got.Journal, large.Journal) {
+				t.Errorf("压缩值往返 = %v, %v", got, err)
+			}
+
+			small := &game.DBPlayer{Journal: game.DBPlayer_DBJournal{Items: []string{"出生"}}}
+			smallRaw, _ := small.Journal.MarshalRedisProto()
+			if err := store.Set(ctx, 14, 0, small, game.FieldDBPlayer_Journal); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if b := hget(); !bytes.Equal(b, smallRaw) {
+				t.Errorf("未达到阈值应原样写入: %x, want %x", b, smallRaw)
+			}
+
+			// 开启压缩前写入的未压缩值照常读取
+			if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBPlayer_Journal), raw); err != nil {
+				t.Fatalf("HSET: %v", err)
+			}
+			if got, err := store.Get(ctx, 14, 0, game.FieldDBPlayer_Journal); err != nil || !reflect.DeepEqual(got.Journal, large.Journal) {
+				t.Errorf("未压缩值读取 = %v, %v", got, err)
+			}
+
+			if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBPlayer_Journal), []byte{0x01, 0xff, 0xff}); err != nil {
+				t.Fatalf("HSET: %v", err)
+			}
+			if _, err := store.Get(ctx, 14, 0, game.FieldDBPlayer_Journal); err == nil || !strings.Contains(err.Error(), "解压字段 Journal 失败") {
+				t.Errorf("损坏的压缩值应报错, got %v", err)
+			}
+		})
 	}
-	if !reflect.DeepEqual(got, want) {
-		t.Errorf("全字段回读不一致:\n got = %#v\nwant = %#v", got, want)
+}
+
+// TestSensitive 覆盖 sensitive 字段：写入 Redis 的是带密钥 ID 的 AES-GCM 密文，读取时解密；轮换密钥后旧数据按各自的 ID 解密；
+// 密文挪到其他字段或记录时解密失败；只接受密文时拒绝明文；未设置密钥来源时拒绝写入；String() 不输出明文。
+func TestSensitive(t *testing.T) {
+	for name, exec := range map[string]game.RedisExecutor{
+		"redis": nil,
+		"mem":   game.NewRedisMemExecutor(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if exec == nil {
+				exec = game.NewRedigoExecutor(dialRedis(t)) // Redis 不可用时跳过
+			}
+			keys := &game.RedisStaticKeys{Current: "k1", Keys: map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}}
+			game.SetRedisKeyProvider(keys)
+			t.Cleanup(func() { game.SetRedisKeyProvider(nil) })
+			store := game.NewDBAccountStoreExec(exec, testREDBKey)
+			ctx := context.Background()
+			t.Cleanup(func() { store.Delete(context.Background(), 15, 0) })
+			key := fmt.Sprintf("REDB#%d:15:0", testREDBKey)
+			hget := func(field game.FieldDBAccount) []byte {
+				t.Helper()
+				reply, err := exec.Do(ctx, "HGET", key, uint32(field))
+				if err != nil {
+					t.Fatalf("HGET: %v", err)
+				}
+				b, _ := reply.([]byte)
+				return b
+			}
+
+			want := &game.DBAccount{
+				Login:    "alice",
+				Token:    []byte("secret-token"),
+				RealName: "爱丽丝",
+				Identity: game.DBAccount_DBIdentity{IdCard: "110101199003071234", VerifiedAt: 1700000000},
+			}
+			if err := store.Set(ctx, 15, 0, want); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			for _, field := range []game.FieldDBAccount{game.FieldDBAccount_Token, game.FieldDBAccount_RealName, game.FieldDBAccount_Identity} {
+				b := hget(field)
+				if len(b) < 4 || b[0] != 0x02 || string(b[2:2+int(b[1])]) != "k1" {
+					t.Errorf("字段 %d 应为带密钥 ID 的密文, got %x", field, b)
+				}
+				if bytes.Contains(b, []byte("secret")) || bytes.Contains(b, []byte("爱丽丝")) || bytes.Contains(b, []byte("1101011990")) {
+					t.Errorf("字段 %d 的值含明文: %q", field, b)
+				}
+			}
+			if got := string(hget(game.FieldDBAccount_Login)); got != "alice" {
+				t.Errorf("非敏感字段 = %q, want alice", got)
+			}
+			got, err := store.Get(ctx, 15, 0)
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("Get = %+v, %v, want %+v", got, err, want)
+			}
+
+			// 轮换：新值用 k2 加密，k1 加密的旧值仍可读取
+			game.SetRedisKeyProvider(&game.RedisStaticKeys{Current: "k2", Keys: map[string][]byte{
+				"k1": keys.Keys["k1"],
+				"k2": bytes.Repeat([]byte{2}, 16),
+			}})
+			if err := store.Set(ctx, 15, 0, &game.DBAccount{RealName: "鲍勃"}, game.FieldDBAccount_RealName); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if b := hget(game.FieldDBAccount_RealName); string(b[2:2+int(b[1])]) != "k2" {
+				t.Errorf("轮换后应使用新密钥 ID, got %x", b)
+			}
+			if got, err := store.Get(ctx, 15, 0); err != nil || got.RealName != "鲍勃" || !bytes.Equal(got.Token, want.Token) {
+				t.Errorf("轮换后读取 = %+v, %v", got, err)
+			}
+
+			// 开启加密前写入的明文照常读取；密文被挪到其他字段或被篡改时解密失败
+			if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBAccount_RealName), "旧数据"); err != nil {
+				t.Fatalf("HSET: %v", err)
+			}
+			if got, err := store.Get(ctx, 15, 0, game.FieldDBAccount_RealName); err != nil || got.RealName != "旧数据" {
+				t.Errorf("明文读取 = %+v, %v", got, err)
+			}
+			if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBAccount_RealName), hget(game.FieldDBAccount_Token)); err != nil {
+				t.Fatalf("HSET: %v", err)
+			}
+			if _, err := store.Get(ctx, 15, 0, game.FieldDBAccount_RealName); err == nil || !strings.Contains(err.Error(), "解密字段 RealName 失败") {
+				t.Errorf("挪用其他字段的密文应解密失败, got %v", err)
+			}
+			// 附加认证数据含 Hash key：其他记录同一字段的密文挪过来同样解密失败
+			t.Cleanup(func() { store.Delete(context.Background(), 15, 1) })
+			if err := store.Set(ctx, 15, 1, &game.DBAccount{Token: []byte("other")}, game.FieldDBAccount_Token); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			other, err := exec.Do(ctx, "HGET", fmt.Sprintf("REDB#%d:15:1", testREDBKey), uint32(game.FieldDBAccount_Token))
+			if err != nil {
+				t.Fatalf("HGET: %v", err)
+			}
+			if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBAccount_Token), other); err != nil {
+				t.Fatalf("HSET: %v", err)
+			}
+			if _, err := store.Get(ctx, 15, 0, game.FieldDBAccount_Token); err == nil || !strings.Contains(err.Error(), "解密字段 Token 失败") {
+				t.Errorf("挪用其他记录的密文应解密失败, got %v", err)
+			}
+
+			// SetRedisRequireEncrypted(true) 后拒绝明文（防止密文被换成明文），密文照常读取
+			game.SetRedisRequireEncrypted(true)
+			t.Cleanup(func() { game.SetRedisRequireEncrypted(false) })
+			if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBAccount_RealName), "明文"); err != nil {
+				t.Fatalf("HSET: %v", err)
+			}
+			if _, err := store.Get(ctx, 15, 0, game.FieldDBAccount_RealName); err == nil || !strings.Contains(err.Error(), "值未加密") {
+				t.Errorf("只接受密文时读到明文应报错, got %v", err)
+			}
+			if got, err := store.Get(ctx, 15, 1, game.FieldDBAccount_Token); err != nil || string(got.Token) != "other" {
+				t.Errorf("只接受密文时读取密文 = %+v, %v", got, err)
+			}
+			game.SetRedisRequireEncrypted(false)
+
+			game.SetRedisKeyProvider(nil)
+			if err := store.Set(ctx, 15, 0, want, game.FieldDBAccount_Token); err == nil || !strings.Contains(err.Error(), "SetRedisKeyProvider") {
+				t.Errorf("未设置密钥来源时应拒绝写入, got %v", err)
+			}
+			if _, err := store.Get(ctx, 15, 0, game.FieldDBAccount_Token); err == nil {
+				t.Error("未设置密钥来源时读取密文应报错")
+			}
+
+			for _, s := range []string{fmt.Sprint(want), fmt.Sprintf("%+v", *want), fmt.Sprintf("%v", got)} {
+				if strings.Contains(s, "secret") || strings.Contains(s, "爱丽丝") || strings.Contains(s, "110101") || !strings.Contains(s, "[REDACTED]") {
+					t.Errorf("String() 应隐藏敏感字段: %s", s)
+				}
+			}
+		})
 	}
 }
 
-// TestPartialFields 只写部分字段，只读部分字段；未写字段保持零值。
-func TestPartialFields(t *testing.T) {
-	conn := dialRedis(t)
-	t.Cleanup(func() { conn.Do("DEL", fmt.Sprintf("REDB#%d:2:0", testREDBKey)) })
+// testValueEncoding 对 JSON 编码字段的 Store 执行断言；exec 与 store 指向同一份数据，用于直接检查与写入 hash field。
+func testValueEncoding(t *testing.T, store *game.DBGuildStore, exec game.RedisExecutor) {
+	t.Helper()
+	ctx := context.Background()
+	key := fmt.Sprintf("REDB#%d:12:0", testREDBKey)
+	hget := func(field game.FieldDBGuild) []byte {
+		t.Helper()
+		reply, err := exec.Do(ctx, "HGET", key, uint32(field))
+		if err != nil {
+			t.Fatalf("HGET: %v", err)
+		}
+		b, _ := reply.([]byte)
+		return b
+	}
 
-	u := &cmddb.DBUserBaseInfo{UserId: 7, Username: "partial", Vip: true}
-	if err := u.SetFields(conn, testREDBKey, 2, 0,
-		cmddb.FieldDBUserBaseInfo_UserId, cmddb.FieldDBUserBaseInfo_Username, cmddb.FieldDBUserBaseInfo_Vip); err != nil {
-		t.Fatalf("SetFields(局部): %v", err)
+	want := &game.DBGuild{
+		Name:     "dragons",
+		Notice:   game.DBGuild_DBNotice{Text: "周五攻城", UpdatedAt: 1700000000000},
+		Members:  game.DBGuild_DBMembers{Items: map[uint64]game.DBGuild_Role{10001: game.DBGuild_ROLE_LEADER, 10002: game.DBGuild_ROLE_MEMBER}},
+		LastMail: game.DBMail{Title: "welcome", SentAt: 5},
+	}
+	if err := store.Set(ctx, 12, 0, want); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, w := string(hget(game.FieldDBGuild_Notice)), `{"text":"周五攻城","updatedAt":"1700000000000"}`; got != w {
+		t.Errorf("Notice 的 hash 值 = %s, want %s", got, w)
+	}
+	if got, w := string(hget(game.FieldDBGuild_Members)), `{"items":{"10001":"ROLE_LEADER","10002":"ROLE_MEMBER"}}`; got != w {
+		t.Errorf("Members 的 hash 值 = %s, want %s", got, w)
+	}
+	if mail, _ := want.LastMail.MarshalRedisProto(); !bytes.Equal(hget(game.FieldDBGuild_LastMail), mail) {
+		t.Errorf("LastMail 应为 protobuf 字节, got %q", hget(game.FieldDBGuild_LastMail))
+	}
+	if got, err := store.Get(ctx, 12, 0); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Get = %+v, %v, want %+v", got, err, want)
 	}
 
-	got := &cmddb.DBUserBaseInfo{}
-	if err := got.GetFields(conn, testREDBKey, 2, 0,
-		cmddb.FieldDBUserBaseInfo_UserId, cmddb.FieldDBUserBaseInfo_Username, cmddb.FieldDBUserBaseInfo_Vip,
-		cmddb.FieldDBUserBaseInfo_Level, // 顺带读一个从未写入的字段
-	); err != nil {
-		t.Fatalf("GetFields(局部): %v", err)
+	// 迁移：切换前写入的 protobuf 字节仍可读；LastMail 写成 JSON 也能读（从 JSON 迁回 protobuf 的窗口）
+	notice, _ := want.Notice.MarshalRedisProto()
+	mail, _ := want.LastMail.MarshalRedisJSON()
+	if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBGuild_Notice), notice, uint32(game.FieldDBGuild_LastMail), mail); err != nil {
+		t.Fatalf("HSET: %v", err)
 	}
-	if got.UserId != 7 || got.Username != "partial" || !got.Vip {
-		t.Errorf("局部字段回读错误: %#v", got)
+	if got, err := store.Get(ctx, 12, 0); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("混合编码 Get = %+v, %v, want %+v", got, err, want)
 	}
-	if got.Level != 0 {
-		t.Errorf("未写入字段 Level 应为零值, got %d", got.Level)
+	if _, err := exec.Do(ctx, "HSET", key, uint32(game.FieldDBGuild_Notice), `{"text":1}`); err != nil {
+		t.Fatalf("HSET: %v", err)
+	}
+	if _, err := store.Get(ctx, 12, 0, game.FieldDBGuild_Notice); err == nil {
+		t.Error("类型错误的 JSON 应报错")
 	}
 }
 
-// TestSingleField 单字段写入与读取。
-func TestSingleField(t *testing.T) {
-	conn := dialRedis(t)
-	t.Cleanup(func() { conn.Do("DEL", fmt.Sprintf("REDB#%d:3:0", testREDBKey)) })
+func testUniqueRepository(t *testing.T, repo game.DBPlayerRepository) {
+	t.Helper()
+	ctx := context.Background()
+	find := func(name string) (ida, idb uint64, ok bool) {
+		t.Helper()
+		ida, idb, ok, err := repo.FindByName(ctx, name)
+		if err != nil {
+			t.Fatalf("FindByName(%q): %v", name, err)
+		}
+		return ida, idb, ok
+	}
+	if err := repo.Set(ctx, 7, 1, &game.DBPlayer{Name: "alice", Level: 1}, game.FieldDBPlayer_Name, game.FieldDBPlayer_Level); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if ida, idb, ok := find("alice"); !ok || ida != 7 || idb != 1 {
+		t.Errorf("FindByName(alice) = %d, %d, %v, want 7, 1", ida, idb, ok)
+	}
+	// 同一记录重复写入同一个值不算冲突
+	if err := repo.Set(ctx, 7, 1, &game.DBPlayer{Name: "alice"}, game.FieldDBPlayer_Name); err != nil {
+		t.Errorf("重写自己的昵称: %v", err)
+	}
 
-	u := &cmddb.DBUserBaseInfo{Exp: 1234567890123}
-	if err := u.SetFields(conn, testREDBKey, 3, 0, cmddb.FieldDBUserBaseInfo_Exp); err != nil {
-		t.Fatalf("SetFields(单字段): %v", err)
+	// 其他记录占用同一个值：返回冲突错误，整次写入不生效
+	err := repo.Set(ctx, 7, 2, &game.DBPlayer{Name: "alice", Level: 9}, game.FieldDBPlayer_Name, game.FieldDBPlayer_Level)
+	var conflict *game.RedisUniqueConflictError
+	if !errors.As(err, &conflict) || conflict.Field != "Name" || conflict.Value != "alice" || conflict.Ida != 7 || conflict.Idb != 1 {
+		t.Fatalf("冲突写入 err = %v, want *RedisUniqueConflictError{Name alice 7:1}", err)
+	}
+	if got, _ := repo.Get(ctx, 7, 2); got.Level != 0 || got.Name != "" {
+		t.Errorf("冲突后 7:2 = %+v, 不应写入", got)
+	}
+	if _, ok, _ := repo.RevRankLevel(ctx, 7, 2); ok {
+		t.Error("冲突后 7:2 不应进入等级索引")
 	}
 
-	got := &cmddb.DBUserBaseInfo{}
-	if err := got.GetFields(conn, testREDBKey, 3, 0, cmddb.FieldDBUserBaseInfo_Exp); err != nil {
-		t.Fatalf("GetFields(单字段): %v", err)
+	// 改名释放旧值，旧值可被其他记录占用
+	if err := repo.Set(ctx, 7, 1, &game.DBPlayer{Name: "alice2"}, game.FieldDBPlayer_Name); err != nil {
+		t.Fatalf("改名: %v", err)
 	}
-	if got.Exp != 1234567890123 {
-		t.Errorf("Exp = %d, want 1234567890123", got.Exp)
+	if _, _, ok := find("alice"); ok {
+		t.Error("改名后旧昵称应被释放")
+	}
+	if err := repo.Set(ctx, 7, 2, &game.DBPlayer{Name: "alice"}, game.FieldDBPlayer_Name); err != nil {
+		t.Fatalf("占用已释放的昵称: %v", err)
+	}
+	if ida, idb, ok := find("alice"); !ok || ida != 7 || idb != 2 {
+		t.Errorf("FindByName(alice) = %d, %d, %v, want 7, 2", ida, idb, ok)
+	}
+
+	// 零值不占用索引：多条记录都可以没有昵称
+	for idb := uint64(1); idb <= 3; idb++ {
+		if err := repo.Set(ctx, 7, idb, &game.DBPlayer{}, game.FieldDBPlayer_Name); err != nil {
+			t.Fatalf("写入空昵称: %v", err)
+		}
+	}
+	if _, _, ok := find(""); ok {
+		t.Error("零值不应出现在唯一索引中")
+	}
+	if _, _, ok := find("alice2"); ok {
+		t.Error("昵称改为空后旧值应被释放")
+	}
+
+	// Delete 释放当前值（删除字段或整条记录）
+	repo.Set(ctx, 7, 1, &game.DBPlayer{Name: "bob"}, game.FieldDBPlayer_Name)
+	repo.Set(ctx, 7, 2, &game.DBPlayer{Name: "carol"}, game.FieldDBPlayer_Name)
+	if err := repo.Delete(ctx, 7, 1, game.FieldDBPlayer_Name); err != nil {
+		t.Fatalf("Delete(Name): %v", err)
+	}
+	if err := repo.Delete(ctx, 7, 2); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, name := range []string{"bob", "carol"} {
+		if _, _, ok := find(name); ok {
+			t.Errorf("删除后 %s 应被释放", name)
+		}
 	}
 }
 
-// TestMissingFieldIsZero 读一个从未写入的键/字段，应得到零值而非报错。
-func TestMissingFieldIsZero(t *testing.T) {
-	conn := dialRedis(t)
-	t.Cleanup(func() { conn.Do("DEL", fmt.Sprintf("REDB#%d:4:0", testREDBKey)) })
+// fakeConn 是基于 recordingExecutor 的 redigo 连接，记录是否已 Close（验证 Store 归还连接）。
+type fakeConn struct {
+	exec   *recordingExecutor
+	closed *int
+}
 
-	got := &cmddb.DBUserBaseInfo{}
-	if err := got.GetFields(conn, testREDBKey, 4, 0); err != nil {
-		t.Fatalf("GetFields(空键): %v", err)
+func (c fakeConn) Close() error { *c.closed++; return nil }
+func (c fakeConn) Err() error   { return nil }
+func (c fakeConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	return c.exec.Do(context.Background(), cmd, args...)
+}
+func (c fakeConn) DoContext(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return c.exec.Do(ctx, cmd, args...)
+}
+func (c fakeConn) Send(string, ...interface{}) error { return errors.New("unsupported") }
+func (c fakeConn) Flush() error                      { return errors.New("unsupported") }
+func (c fakeConn) Receive() (interface{}, error)     { return nil, errors.New("unsupported") }
+func (c fakeConn) ReceiveContext(context.Context) (interface{}, error) {
+	return nil, errors.New("unsupported")
+}
+
+// fakePool 是 RedisConnSource 实现，统计借出/归还次数。
+type fakePool struct {
+	exec          *recordingExecutor
+	gets, closeds int
+}
+
+func (p *fakePool) Get() redis.Conn {
+	p.gets++
+	return fakeConn{exec: p.exec, closed: &p.closeds}
+}
+
+// TestStoreReleasesConn Store 每次调用借出一个连接并归还。
+func TestStoreReleasesConn(t *testing.T) {
+	pool := &fakePool{exec: &recordingExecutor{fields: map[string][]byte{}}}
+	store := cmddb.NewDBUserBaseInfoStore(pool, testREDBKey)
+	ctx := context.Background()
+	if err := store.Set(ctx, 1, 0, &cmddb.DBUserBaseInfo{Coin: 1}, cmddb.FieldDBUserBaseInfo_Coin); err != nil {
+		t.Fatalf("Set: %v", err)
 	}
-	want := &cmddb.DBUserBaseInfo{}
+	if _, err := store.WithREDBKey(testREDBKey+1).IncrCoin(ctx, 1, 0, 2); err != nil {
+		t.Fatalf("IncrCoin: %v", err)
+	}
+	if _, err := store.Get(ctx, 1, 0); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if pool.gets != 3 || pool.closeds != 3 {
+		t.Errorf("借出 %d 次、归还 %d 次，期望各 3 次", pool.gets, pool.closeds)
+	}
+}
+
+// TestStoreWithPool Store 基于真实 *redis.Pool 的读写与自增。
+func TestStoreWithPool(t *testing.T) {
+	dialRedis(t) // Redis 不可用时跳过
+	addr, password := redisAddr()
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) {
+		return redis.Dial("tcp", addr, redis.DialPassword(password))
+	}}
+	t.Cleanup(func() { pool.Close() })
+	store := cmddb.NewDBUserBaseInfoStore(pool, testREDBKey)
+	ctx := context.Background()
+	t.Cleanup(func() { store.Delete(ctx, 9, 0) })
+
+	if err := store.Set(ctx, 9, 0, newTestUser()); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	exp, err := store.IncrExp(ctx, 9, 0, -7)
+	if err != nil || exp != 9223372036854775800 {
+		t.Errorf("IncrExp = %d, %v", exp, err)
+	}
+	got, err := store.Get(ctx, 9, 0)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	want := newTestUser()
+	want.Exp = 9223372036854775800
 	if !reflect.DeepEqual(got, want) {
-		t.Errorf("空键读取应全零: %#v", got)
+		t.Errorf("Store 回读不一致:\n got = %#v\nwant = %#v", got, want)
+	}
+	if pool.ActiveCount() != 0 {
+		t.Errorf("调用结束后仍有 %d 个连接未归还", pool.ActiveCount())
+	}
+}
+
+// stalledConn 返回一个 redigo 连接，对端读走全部请求但从不回复（模拟卡住的 Redis/Tendis 节点）。
+func stalledConn(t *testing.T) redis.Conn {
+	t.Helper()
+	client, server := net.Pipe()
+	go io.Copy(io.Discard, server)
+	t.Cleanup(func() { server.Close() })
+	return redis.NewConn(client, 0, 0)
+}
+
+// TestContextDeadline ctx 截止时间作用于 GetFieldsCtx/SetFieldsCtx 以及执行器的 Pipeline/Multi（不依赖 Redis）。
+func TestContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := (&cmddb.DBUserBaseInfo{}).GetFieldsCtx(ctx, stalledConn(t), testREDBKey, 1, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetFieldsCtx 超时应返回 context.DeadlineExceeded, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := (&cmddb.DBUserBaseInfo{UserId: 1}).SetFieldsCtx(ctx, stalledConn(t), testREDBKey, 1, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SetFieldsCtx 超时应返回 context.DeadlineExceeded, got %v", err)
+	}
+
+	cmds := []cmddb.RedisCmd{{Name: "HGET", Args: []interface{}{"k", 1}}, {Name: "HGET", Args: []interface{}{"k", 2}}}
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := cmddb.NewRedigoExecutor(stalledConn(t)).Pipeline(ctx, cmds); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Pipeline 超时应返回 context.DeadlineExceeded, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := cmddb.NewRedigoExecutor(stalledConn(t)).Multi(ctx, cmds); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Multi 超时应返回 context.DeadlineExceeded, got %v", err)
+	}
+
+	// 已取消的 ctx 不发送任何命令
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := cmddb.NewRedigoExecutor(stalledConn(t)).Multi(ctx, cmds); !errors.Is(err, context.Canceled) {
+		t.Errorf("已取消的 ctx 应返回 context.Canceled, got %v", err)
+	}
+}
+
+// cancelConn 包装 redigo 连接，在第 at 次 Send 之后取消 ctx（模拟大批量写入途中 RPC 被取消）
+type cancelConn struct {
+	redis.Conn
+	sends, at int
+	cancel    context.CancelFunc
+}
+
+func (c *cancelConn) Send(cmd string, args ...interface{}) error {
+	err := c.Conn.Send(cmd, args...)
+	if c.sends++; c.sends == c.at {
+		c.cancel()
+	}
+	return err
+}
+
+func (c *cancelConn) DoContext(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return redis.DoContext(c.Conn, ctx, cmd, args...)
+}
+
+func (c *cancelConn) ReceiveContext(ctx context.Context) (interface{}, error) {
+	return redis.ReceiveContext(c.Conn, ctx)
+}
+
+// TestContextCancelStopsSending pipeline / 事务写入途中 ctx 被取消：其余命令不再写出，已缓冲的命令被放弃（事务整体不执行），
+// 连接要么被关闭，要么不残留待读的回复。
+func TestContextCancelStopsSending(t *testing.T) {
+	check := dialRedis(t)
+	keys := make([]interface{}, 5)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("REDB#%d:cancel:%d", testREDBKey, i)
+	}
+	type run func(ctx context.Context, conn redis.Conn, keys []interface{}) error
+	gen := func(multi bool) run {
+		return func(ctx context.Context, conn redis.Conn, keys []interface{}) error {
+			cmds := make([]cmddb.RedisCmd, len(keys))
+			for i, k := range keys {
+				cmds[i] = cmddb.RedisCmd{Name: "SET", Args: []interface{}{k, i}}
+			}
+			exec := cmddb.NewRedigoExecutor(conn)
+			if multi {
+				_, err := exec.Multi(ctx, cmds)
+				return err
+			}
+			_, err := exec.Pipeline(ctx, cmds)
+			return err
+		}
+	}
+	rt := func(multi bool) run {
+		return func(ctx context.Context, conn redis.Conn, keys []interface{}) error {
+			cmds := make([]redisrt.Cmd, len(keys))
+			for i, k := range keys {
+				cmds[i] = redisrt.Cmd{Name: "SET", Args: []interface{}{k, i}}
+			}
+			exec := redigoexec.New(conn)
+			if multi {
+				_, err := exec.Multi(ctx, cmds)
+				return err
+			}
+			_, err := exec.Pipeline(ctx, cmds)
+			return err
+		}
+	}
+	cases := []struct {
+		name  string
+		run   run
+		multi bool
+	}{
+		{"generated/pipeline", gen(false), false},
+		{"generated/multi", gen(true), true},
+		{"redigoexec/pipeline", rt(false), false},
+		{"redigoexec/multi", rt(true), true},
+	}
+	for _, c := range cases {
+		check.Do("DEL", keys...)
+		ctx, cancel := context.WithCancel(context.Background())
+		conn := &cancelConn{Conn: dialRedis(t), at: 2, cancel: cancel}
+		if err := c.run(ctx, conn, keys); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: err = %v, want context.Canceled", c.name, err)
+		}
+		// 取消发生在第 2 次 Send 之后：pipeline 最多写出前 2 条，事务（MULTI 占 1 次）一条也不执行
+		written := 2
+		if c.multi {
+			written = 0
+		}
+		for i, k := range keys {
+			if n, _ := redis.Int(check.Do("EXISTS", k)); i >= written && n != 0 {
+				t.Errorf("%s: 取消后第 %d 条命令仍被执行", c.name, i+1)
+			}
+		}
+		if conn.Err() == nil {
+			if reply, err := redis.String(conn.Do("PING")); err != nil || reply != "PONG" {
+				t.Errorf("%s: 连接未关闭时应可继续使用, PING = %q, %v", c.name, reply, err)
+			}
+		}
+		cancel()
 	}
+	check.Do("DEL", keys...)
 }
 
 // TestKeyIsolation 不同 ida/idb 分片之间互不影响。
@@ -578,3 +2955,108 @@ func TestMarshalRedisProtoRoundTrip(t *testing.T) {
 		t.Errorf("protobuf 往返不一致:\n got = %#v\nwant = %#v", u, want)
 	}
 }
+
+// TestMarshalRedisJSONFloat 浮点字段的 JSON 与 protojson 逐字节一致：绝对值 ≥1e21 或 <1e-6 时为指数形式，
+// NaN / ±Inf 为字符串；float 按 32 位取最短表示。每个用例同时与 protojson 对 DoubleValue / FloatValue 的输出比对，并验证往返。
+func TestMarshalRedisJSONFloat(t *testing.T) {
+	for _, c := range []struct {
+		weight  float64
+		opacity float32
+		want    string
+	}{
+		{weight: 0.1, opacity: 0.1, want: `{"opacity":0.1,"weight":0.1}`},
+		{weight: -1.5, opacity: 16777216, want: `{"opacity":16777216,"weight":-1.5}`},
+		{weight: 1e20, opacity: 1e20, want: `{"opacity":100000000000000000000,"weight":100000000000000000000}`},
+		{weight: 1e21, opacity: 1e21, want: `{"opacity":1e+21,"weight":1e+21}`},
+		{weight: 1.2345678901234567e300, opacity: 3.4028235e38, want: `{"opacity":3.4028235e+38,"weight":1.2345678901234567e+300}`},
+		{weight: math.MaxFloat64, opacity: -math.MaxFloat32, want: `{"opacity":-3.4028235e+38,"weight":1.7976931348623157e+308}`},
+		{weight: 1e-6, opacity: 1e-6, want: `{"opacity":0.000001,"weight":0.000001}`},
+		{weight: 1e-7, opacity: 1e-7, want: `{"opacity":1e-7,"weight":1e-7}`},
+		{weight: -2.5e-10, opacity: 1.5e-45, want: `{"opacity":1e-45,"weight":-2.5e-10}`},
+		{weight: 5e-324, opacity: math.SmallestNonzeroFloat32, want: `{"opacity":1e-45,"weight":5e-324}`},
+		{weight: math.NaN(), opacity: float32(math.NaN()), want: `{"opacity":"NaN","weight":"NaN"}`},
+		{weight: math.Inf(1), opacity: float32(math.Inf(-1)), want: `{"opacity":"-Infinity","weight":"Infinity"}`},
+	} {
+		n := &game.DBGuild_DBNotice{Weight: c.weight, Opacity: c.opacity}
+		got, err := n.MarshalRedisJSON()
+		if err != nil || string(got) != c.want {
+			t.Errorf("MarshalRedisJSON(%v, %v) = %s, %v, want %s", c.weight, c.opacity, got, err, c.want)
+			continue
+		}
+		d, _ := protojson.Marshal(wrapperspb.Double(c.weight))
+		f, _ := protojson.Marshal(wrapperspb.Float(c.opacity))
+		if want := `{"opacity":` + string(f) + `,"weight":` + string(d) + `}`; string(got) != want {
+			t.Errorf("MarshalRedisJSON(%v, %v) = %s, protojson 为 %s", c.weight, c.opacity, got, want)
+		}
+		back := &game.DBGuild_DBNotice{}
+		if err := back.UnmarshalRedisJSON(got); err != nil {
+			t.Errorf("UnmarshalRedisJSON(%s): %v", got, err)
+		} else if fmt.Sprint(back.Weight, back.Opacity) != fmt.Sprint(c.weight, c.opacity) {
+			t.Errorf("UnmarshalRedisJSON(%s) = %v, %v", got, back.Weight, back.Opacity)
+		}
+	}
+	for _, bad := range []string{
+		`{"opacity":1e39}`,    // 超出 float 范围
+		`{"weight":"1e400"}`,  // 超出 double 范围
+		`{"weight":"nan"}`,    // 只接受 "NaN"
+		`{"weight":"-Inf"}`,   // 只接受 "-Infinity"
+		`{"weight":"0x1p-2"}`, // 不接受十六进制
+	} {
+		if err := (&game.DBGuild_DBNotice{}).UnmarshalRedisJSON([]byte(bad)); err == nil {
+			t.Errorf("UnmarshalRedisJSON(%s) 应报错", bad)
+		}
+	}
+}
+
+// TestMarshalRedisJSONConformance 用手写的 proto3 JSON 验证 MarshalRedisJSON / UnmarshalRedisJSON：
+// json_name、int64 为字符串、bytes 为 base64、枚举为名字（未知值为数字）、map 键排序、字符串转义，
+// 以及解码时接受 proto 字段名、数字字符串、枚举数字、null 与未知成员。
+func TestMarshalRedisJSONConformance(t *testing.T) {
+	g := &game.DBGuild{
+		Name:   "a\"<\n\x01",
+		Notice: game.DBGuild_DBNotice{UpdatedAt: -3, Icon: []byte{0xFB, 0xFF}},
+		Members: game.DBGuild_DBMembers{Items: map[uint64]game.DBGuild_Role{
+			10: game.DBGuild_ROLE_LEADER, 2: game.DBGuild_ROLE_MEMBER, 3: 7,
+		}},
+	}
+	got, err := g.MarshalRedisJSON()
+	if err != nil {
+		t.Fatalf("MarshalRedisJSON: %v", err)
+	}
+	want := `{"name":"a\"<\n\u0001","notice":{"updatedAt":"-3","icon":"+/8="},` +
+		`"members":{"items":{"2":"ROLE_MEMBER","3":7,"10":"ROLE_LEADER"}},"lastMail":{}}`
+	if string(got) != want {
+		t.Errorf("MarshalRedisJSON = %s\nwant             = %s", got, want)
+	}
+	if !json.Valid(got) {
+		t.Errorf("MarshalRedisJSON 输出不是合法 JSON: %s", got)
+	}
+	back := &game.DBGuild{}
+	if err := back.UnmarshalRedisJSON(got); err != nil || !reflect.DeepEqual(back, g) {
+		t.Errorf("JSON 往返 = %+v, %v, want %+v", back, err, g)
+	}
+
+	in := `{"name":null,"notice":{"updated_at":12,"icon":"-_8"},"members":{"items":{"5":1}},"last_mail":{"sentAt":"9"},"unknown":[1]}`
+	g = &game.DBGuild{Name: "stale"} // 反序列化前会被重置
+	if err := g.UnmarshalRedisJSON([]byte(in)); err != nil {
+		t.Fatalf("UnmarshalRedisJSON: %v", err)
+	}
+	want2 := &game.DBGuild{
+		Notice:   game.DBGuild_DBNotice{UpdatedAt: 12, Icon: []byte{0xFB, 0xFF}},
+		Members:  game.DBGuild_DBMembers{Items: map[uint64]game.DBGuild_Role{5: game.DBGuild_ROLE_ELDER}},
+		LastMail: game.DBMail{SentAt: 9},
+	}
+	if !reflect.DeepEqual(g, want2) {
+		t.Errorf("UnmarshalRedisJSON = %+v, want %+v", g, want2)
+	}
+	for _, bad := range []string{
+		`{"members":{"items":{"x":1}}}`,              // map 键不是整数
+		`{"members":{"items":{"1":"ROLE_UNKNOWN"}}}`, // 未知枚举名
+		`{"notice":{"updatedAt":"1.5"}}`,             // int64 不是整数
+		`[]`,                                         // 不是对象
+	} {
+		if err := (&game.DBGuild{}).UnmarshalRedisJSON([]byte(bad)); err == nil {
+			t.Errorf("UnmarshalRedisJSON(%s) 应报错", bad)
+		}
+	}
+}
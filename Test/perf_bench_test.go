@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	cmddb "github.com/beijian128/protoc-gen-redis/generated"
+	"github.com/beijian128/protoc-gen-redis/generated/perf"
+)
+
+// perf=true 与默认模式的 GetFields/SetFields 热路径对比：
+//
+//	go test ./Test -run '^$' -bench 'Perf' -benchmem
+//
+// 执行器回放事先录下的 HMGET 回复、丢弃 HSET，只计生成代码本身的耗时与分配（不含网络与客户端）。
+// all 为全部字段；tick 为玩家 tick 循环里常见的几个数值字段。
+
+// replayExecutor 对 HMGET 返回固定的回复，其余命令什么也不做；C 为生成包的 RedisCmd 类型
+type replayExecutor[C any] struct {
+	hmget []interface{}
+}
+
+func (e *replayExecutor[C]) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if cmd == "HMGET" {
+		return e.hmget[:len(args)-1], nil
+	}
+	return int64(len(args) / 2), nil
+}
+
+func (e *replayExecutor[C]) Pipeline(ctx context.Context, cmds []C) ([]interface{}, error) {
+	return nil, nil
+}
+
+func (e *replayExecutor[C]) Multi(ctx context.Context, cmds []C) ([]interface{}, error) {
+	return nil, nil
+}
+
+// perfBenchFields 是基准测试读写的字段（两种模式的字段编号相同）
+var perfBenchFields = []struct {
+	name   string
+	fields []cmddb.FieldDBUserBaseInfo
+}{
+	{"all", cmddb.FieldDBUserBaseInfoIDs},
+	{"tick", []cmddb.FieldDBUserBaseInfo{cmddb.FieldDBUserBaseInfo_Level, cmddb.FieldDBUserBaseInfo_Exp,
+		cmddb.FieldDBUserBaseInfo_Coin, cmddb.FieldDBUserBaseInfo_Score}},
+}
+
+// perfFields 把默认模式的字段编号转换为 perf 包的字段编号
+func perfFields(fields []cmddb.FieldDBUserBaseInfo) []perf.FieldDBUserBaseInfo {
+	out := make([]perf.FieldDBUserBaseInfo, len(fields))
+	for i, f := range fields {
+		out[i] = perf.FieldDBUserBaseInfo(f)
+	}
+	return out
+}
+
+// recordHMGET 经内存执行器写入 newTestUser 后取回 fields 的 HMGET 回复
+func recordHMGET(b *testing.B, fields []cmddb.FieldDBUserBaseInfo) []interface{} {
+	b.Helper()
+	ctx := context.Background()
+	mem := cmddb.NewRedisMemExecutor()
+	if err := newTestUser().SetFieldsExec(ctx, mem, testREDBKey, 1, 0, fields...); err != nil {
+		b.Fatal(err)
+	}
+	args := []interface{}{"REDB#424242:1:0"}
+	for _, f := range fields {
+		args = append(args, uint32(f))
+	}
+	reply, err := mem.Do(ctx, "HMGET", args...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return reply.([]interface{})
+}
+
+func BenchmarkPerfGetFields(b *testing.B) {
+	ctx := context.Background()
+	for _, bf := range perfBenchFields {
+		hmget := recordHMGET(b, bf.fields)
+		b.Run(bf.name+"/default", func(b *testing.B) {
+			exec := &replayExecutor[cmddb.RedisCmd]{hmget: hmget}
+			var u cmddb.DBUserBaseInfo
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := u.GetFieldsExec(ctx, exec, testREDBKey, 1, 0, bf.fields...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(bf.name+"/perf", func(b *testing.B) {
+			exec := &replayExecutor[perf.RedisCmd]{hmget: hmget}
+			fields := perfFields(bf.fields)
+			var u perf.DBUserBaseInfo
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := u.GetFieldsExec(ctx, exec, testREDBKey, 1, 0, fields...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPerfSetFields(b *testing.B) {
+	ctx := context.Background()
+	for _, bf := range perfBenchFields {
+		b.Run(bf.name+"/default", func(b *testing.B) {
+			exec := &replayExecutor[cmddb.RedisCmd]{}
+			u := newTestUser()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := u.SetFieldsExec(ctx, exec, testREDBKey, 1, 0, bf.fields...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(bf.name+"/perf", func(b *testing.B) {
+			exec := &replayExecutor[perf.RedisCmd]{}
+			fields := perfFields(bf.fields)
+			u := newPerfTestUser(b)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := u.SetFieldsExec(ctx, exec, testREDBKey, 1, 0, fields...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// MarshalRedisProtoAppend 复用同一块缓冲时，整条 message 的编码不再分配
+func BenchmarkPerfMarshal(b *testing.B) {
+	b.Run("default", func(b *testing.B) {
+		u := newTestUser()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := u.MarshalRedisProto(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("perf", func(b *testing.B) {
+		u := newPerfTestUser(b)
+		var buf []byte
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var err error
+			if buf, err = u.MarshalRedisProtoAppend(buf[:0]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkPerfUnmarshal(b *testing.B) {
+	data, err := newTestUser().MarshalRedisProto()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("default", func(b *testing.B) {
+		var u cmddb.DBUserBaseInfo
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := u.UnmarshalRedisProto(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("perf", func(b *testing.B) {
+		var u perf.DBUserBaseInfo
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := u.UnmarshalRedisProto(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -1,3 +1,4 @@
 
  go build -o protoc-gen-redis.exe .
- protoc --plugin=./protoc-gen-redis.exe --redis_out=./generated proto/user.proto
\ No newline at end of file
+ protoc --plugin=./protoc-gen-redis.exe --redis_out=./generated --redis_opt=mem=true proto/user.proto
+ protoc --plugin=./protoc-gen-redis.exe --redis_out=./generated/game --redis_opt=manifest=json,mem=true proto/game.proto
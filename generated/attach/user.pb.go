@@ -0,0 +1,855 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: proto/user.proto
+
+package attach
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Gender int32
+
+const (
+	Gender_GENDER_UNKNOWN Gender = 0
+	Gender_GENDER_MALE    Gender = 1
+	Gender_GENDER_FEMALE  Gender = 2
+)
+
+// Enum value maps for Gender.
+var (
+	Gender_name = map[int32]string{
+		0: "GENDER_UNKNOWN",
+		1: "GENDER_MALE",
+		2: "GENDER_FEMALE",
+	}
+	Gender_value = map[string]int32{
+		"GENDER_UNKNOWN": 0,
+		"GENDER_MALE":    1,
+		"GENDER_FEMALE":  2,
+	}
+)
+
+func (x Gender) Enum() *Gender {
+	p := new(Gender)
+	*p = x
+	return p
+}
+
+func (x Gender) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Gender) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_user_proto_enumTypes[0].Descriptor()
+}
+
+func (Gender) Type() protoreflect.EnumType {
+	return &file_proto_user_proto_enumTypes[0]
+}
+
+func (x Gender) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Gender.Descriptor instead.
+func (Gender) EnumDescriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{0}
+}
+
+type LoginSource int32
+
+const (
+	LoginSource_SOURCE_UNKNOWN      LoginSource = 0
+	LoginSource_SOURCE_APP          LoginSource = 1
+	LoginSource_SOURCE_H5           LoginSource = 2
+	LoginSource_SOURCE_MINI_PROGRAM LoginSource = 3
+)
+
+// Enum value maps for LoginSource.
+var (
+	LoginSource_name = map[int32]string{
+		0: "SOURCE_UNKNOWN",
+		1: "SOURCE_APP",
+		2: "SOURCE_H5",
+		3: "SOURCE_MINI_PROGRAM",
+	}
+	LoginSource_value = map[string]int32{
+		"SOURCE_UNKNOWN":      0,
+		"SOURCE_APP":          1,
+		"SOURCE_H5":           2,
+		"SOURCE_MINI_PROGRAM": 3,
+	}
+)
+
+func (x LoginSource) Enum() *LoginSource {
+	p := new(LoginSource)
+	*p = x
+	return p
+}
+
+func (x LoginSource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LoginSource) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_user_proto_enumTypes[1].Descriptor()
+}
+
+func (LoginSource) Type() protoreflect.EnumType {
+	return &file_proto_user_proto_enumTypes[1]
+}
+
+func (x LoginSource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LoginSource.Descriptor instead.
+func (LoginSource) EnumDescriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{1}
+}
+
+type DBUserBaseInfo_VipLevel int32
+
+const (
+	DBUserBaseInfo_VIP_NONE DBUserBaseInfo_VipLevel = 0
+	DBUserBaseInfo_VIP_1    DBUserBaseInfo_VipLevel = 1
+	DBUserBaseInfo_VIP_2    DBUserBaseInfo_VipLevel = 2
+)
+
+// Enum value maps for DBUserBaseInfo_VipLevel.
+var (
+	DBUserBaseInfo_VipLevel_name = map[int32]string{
+		0: "VIP_NONE",
+		1: "VIP_1",
+		2: "VIP_2",
+	}
+	DBUserBaseInfo_VipLevel_value = map[string]int32{
+		"VIP_NONE": 0,
+		"VIP_1":    1,
+		"VIP_2":    2,
+	}
+)
+
+func (x DBUserBaseInfo_VipLevel) Enum() *DBUserBaseInfo_VipLevel {
+	p := new(DBUserBaseInfo_VipLevel)
+	*p = x
+	return p
+}
+
+func (x DBUserBaseInfo_VipLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DBUserBaseInfo_VipLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_user_proto_enumTypes[2].Descriptor()
+}
+
+func (DBUserBaseInfo_VipLevel) Type() protoreflect.EnumType {
+	return &file_proto_user_proto_enumTypes[2]
+}
+
+func (x DBUserBaseInfo_VipLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DBUserBaseInfo_VipLevel.Descriptor instead.
+func (DBUserBaseInfo_VipLevel) EnumDescriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{0, 0}
+}
+
+type DBUserBaseInfo struct {
+	state         protoimpl.MessageState      `protogen:"open.v1"`
+	UserId        int32                       `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                      `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	AvatarUrl     string                      `protobuf:"bytes,3,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"`
+	Gender        Gender                      `protobuf:"varint,4,opt,name=gender,proto3,enum=user.Gender" json:"gender,omitempty"`
+	Level         int32                       `protobuf:"varint,5,opt,name=level,proto3" json:"level,omitempty"`
+	Exp           int64                       `protobuf:"varint,6,opt,name=exp,proto3" json:"exp,omitempty"`
+	Balance       float32                     `protobuf:"fixed32,7,opt,name=balance,proto3" json:"balance,omitempty"`
+	Friends       *DBUserBaseInfo_DBFriends   `protobuf:"bytes,8,opt,name=friends,proto3" json:"friends,omitempty"`
+	Settings      *DBUserBaseInfo_DBSettings  `protobuf:"bytes,9,opt,name=settings,proto3" json:"settings,omitempty"`
+	LoginSource   LoginSource                 `protobuf:"varint,10,opt,name=login_source,json=loginSource,proto3,enum=user.LoginSource" json:"login_source,omitempty"`
+	Int32List     *DBUserBaseInfo_DBInt32List `protobuf:"bytes,11,opt,name=int32_list,json=int32List,proto3" json:"int32_list,omitempty"`
+	Weapons       *DBUserBaseInfo_DBWeapons   `protobuf:"bytes,12,opt,name=weapons,proto3" json:"weapons,omitempty"`
+	Weapon        *DBWeapon                   `protobuf:"bytes,13,opt,name=weapon,proto3" json:"weapon,omitempty"`
+	WeaponMap     *DBUserBaseInfo_DBWeaponMap `protobuf:"bytes,14,opt,name=weapon_map,json=weaponMap,proto3" json:"weapon_map,omitempty"`
+	Coin          uint32                      `protobuf:"varint,15,opt,name=coin,proto3" json:"coin,omitempty"`
+	Gem           uint64                      `protobuf:"varint,16,opt,name=gem,proto3" json:"gem,omitempty"`
+	Vip           bool                        `protobuf:"varint,17,opt,name=vip,proto3" json:"vip,omitempty"`
+	Score         float64                     `protobuf:"fixed64,18,opt,name=score,proto3" json:"score,omitempty"`
+	Token         []byte                      `protobuf:"bytes,19,opt,name=token,proto3" json:"token,omitempty"`
+	Profile       *DBUserBaseInfo_DBProfile   `protobuf:"bytes,20,opt,name=profile,proto3" json:"profile,omitempty"`
+	VipLevel      DBUserBaseInfo_VipLevel     `protobuf:"varint,21,opt,name=vip_level,json=vipLevel,proto3,enum=user.DBUserBaseInfo_VipLevel" json:"vip_level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBUserBaseInfo) Reset() {
+	*x = DBUserBaseInfo{}
+	mi := &file_proto_user_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBUserBaseInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBUserBaseInfo) ProtoMessage() {}
+
+func (x *DBUserBaseInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBUserBaseInfo.ProtoReflect.Descriptor instead.
+func (*DBUserBaseInfo) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DBUserBaseInfo) GetUserId() int32 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *DBUserBaseInfo) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *DBUserBaseInfo) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+func (x *DBUserBaseInfo) GetGender() Gender {
+	if x != nil {
+		return x.Gender
+	}
+	return Gender_GENDER_UNKNOWN
+}
+
+func (x *DBUserBaseInfo) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *DBUserBaseInfo) GetExp() int64 {
+	if x != nil {
+		return x.Exp
+	}
+	return 0
+}
+
+func (x *DBUserBaseInfo) GetBalance() float32 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *DBUserBaseInfo) GetFriends() *DBUserBaseInfo_DBFriends {
+	if x != nil {
+		return x.Friends
+	}
+	return nil
+}
+
+func (x *DBUserBaseInfo) GetSettings() *DBUserBaseInfo_DBSettings {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *DBUserBaseInfo) GetLoginSource() LoginSource {
+	if x != nil {
+		return x.LoginSource
+	}
+	return LoginSource_SOURCE_UNKNOWN
+}
+
+func (x *DBUserBaseInfo) GetInt32List() *DBUserBaseInfo_DBInt32List {
+	if x != nil {
+		return x.Int32List
+	}
+	return nil
+}
+
+func (x *DBUserBaseInfo) GetWeapons() *DBUserBaseInfo_DBWeapons {
+	if x != nil {
+		return x.Weapons
+	}
+	return nil
+}
+
+func (x *DBUserBaseInfo) GetWeapon() *DBWeapon {
+	if x != nil {
+		return x.Weapon
+	}
+	return nil
+}
+
+func (x *DBUserBaseInfo) GetWeaponMap() *DBUserBaseInfo_DBWeaponMap {
+	if x != nil {
+		return x.WeaponMap
+	}
+	return nil
+}
+
+func (x *DBUserBaseInfo) GetCoin() uint32 {
+	if x != nil {
+		return x.Coin
+	}
+	return 0
+}
+
+func (x *DBUserBaseInfo) GetGem() uint64 {
+	if x != nil {
+		return x.Gem
+	}
+	return 0
+}
+
+func (x *DBUserBaseInfo) GetVip() bool {
+	if x != nil {
+		return x.Vip
+	}
+	return false
+}
+
+func (x *DBUserBaseInfo) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *DBUserBaseInfo) GetToken() []byte {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+func (x *DBUserBaseInfo) GetProfile() *DBUserBaseInfo_DBProfile {
+	if x != nil {
+		return x.Profile
+	}
+	return nil
+}
+
+func (x *DBUserBaseInfo) GetVipLevel() DBUserBaseInfo_VipLevel {
+	if x != nil {
+		return x.VipLevel
+	}
+	return DBUserBaseInfo_VIP_NONE
+}
+
+type DBWeapon struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Damage        int32                  `protobuf:"varint,2,opt,name=damage,proto3" json:"damage,omitempty"`
+	Element       string                 `protobuf:"bytes,3,opt,name=element,proto3" json:"element,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBWeapon) Reset() {
+	*x = DBWeapon{}
+	mi := &file_proto_user_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBWeapon) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBWeapon) ProtoMessage() {}
+
+func (x *DBWeapon) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBWeapon.ProtoReflect.Descriptor instead.
+func (*DBWeapon) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DBWeapon) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DBWeapon) GetDamage() int32 {
+	if x != nil {
+		return x.Damage
+	}
+	return 0
+}
+
+func (x *DBWeapon) GetElement() string {
+	if x != nil {
+		return x.Element
+	}
+	return ""
+}
+
+type DBUserBaseInfo_DBFriends struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []string               `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBUserBaseInfo_DBFriends) Reset() {
+	*x = DBUserBaseInfo_DBFriends{}
+	mi := &file_proto_user_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBUserBaseInfo_DBFriends) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBUserBaseInfo_DBFriends) ProtoMessage() {}
+
+func (x *DBUserBaseInfo_DBFriends) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBUserBaseInfo_DBFriends.ProtoReflect.Descriptor instead.
+func (*DBUserBaseInfo_DBFriends) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{0, 0}
+}
+
+func (x *DBUserBaseInfo_DBFriends) GetItems() []string {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type DBUserBaseInfo_DBSettings struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kv            map[string]string      `protobuf:"bytes,1,rep,name=kv,proto3" json:"kv,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBUserBaseInfo_DBSettings) Reset() {
+	*x = DBUserBaseInfo_DBSettings{}
+	mi := &file_proto_user_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBUserBaseInfo_DBSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBUserBaseInfo_DBSettings) ProtoMessage() {}
+
+func (x *DBUserBaseInfo_DBSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBUserBaseInfo_DBSettings.ProtoReflect.Descriptor instead.
+func (*DBUserBaseInfo_DBSettings) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{0, 1}
+}
+
+func (x *DBUserBaseInfo_DBSettings) GetKv() map[string]string {
+	if x != nil {
+		return x.Kv
+	}
+	return nil
+}
+
+type DBUserBaseInfo_DBInt32List struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []int32                `protobuf:"varint,1,rep,packed,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBUserBaseInfo_DBInt32List) Reset() {
+	*x = DBUserBaseInfo_DBInt32List{}
+	mi := &file_proto_user_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBUserBaseInfo_DBInt32List) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBUserBaseInfo_DBInt32List) ProtoMessage() {}
+
+func (x *DBUserBaseInfo_DBInt32List) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBUserBaseInfo_DBInt32List.ProtoReflect.Descriptor instead.
+func (*DBUserBaseInfo_DBInt32List) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{0, 2}
+}
+
+func (x *DBUserBaseInfo_DBInt32List) GetItems() []int32 {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type DBUserBaseInfo_DBWeapons struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*DBWeapon            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBUserBaseInfo_DBWeapons) Reset() {
+	*x = DBUserBaseInfo_DBWeapons{}
+	mi := &file_proto_user_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBUserBaseInfo_DBWeapons) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBUserBaseInfo_DBWeapons) ProtoMessage() {}
+
+func (x *DBUserBaseInfo_DBWeapons) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBUserBaseInfo_DBWeapons.ProtoReflect.Descriptor instead.
+func (*DBUserBaseInfo_DBWeapons) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{0, 3}
+}
+
+func (x *DBUserBaseInfo_DBWeapons) GetItems() []*DBWeapon {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type DBUserBaseInfo_DBWeaponMap struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         map[int32]*DBWeapon    `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBUserBaseInfo_DBWeaponMap) Reset() {
+	*x = DBUserBaseInfo_DBWeaponMap{}
+	mi := &file_proto_user_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBUserBaseInfo_DBWeaponMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBUserBaseInfo_DBWeaponMap) ProtoMessage() {}
+
+func (x *DBUserBaseInfo_DBWeaponMap) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBUserBaseInfo_DBWeaponMap.ProtoReflect.Descriptor instead.
+func (*DBUserBaseInfo_DBWeaponMap) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{0, 4}
+}
+
+func (x *DBUserBaseInfo_DBWeaponMap) GetItems() map[int32]*DBWeapon {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type DBUserBaseInfo_DBProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nickname      string                 `protobuf:"bytes,1,opt,name=nickname,proto3" json:"nickname,omitempty"`
+	Age           int32                  `protobuf:"varint,2,opt,name=age,proto3" json:"age,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DBUserBaseInfo_DBProfile) Reset() {
+	*x = DBUserBaseInfo_DBProfile{}
+	mi := &file_proto_user_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DBUserBaseInfo_DBProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DBUserBaseInfo_DBProfile) ProtoMessage() {}
+
+func (x *DBUserBaseInfo_DBProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DBUserBaseInfo_DBProfile.ProtoReflect.Descriptor instead.
+func (*DBUserBaseInfo_DBProfile) Descriptor() ([]byte, []int) {
+	return file_proto_user_proto_rawDescGZIP(), []int{0, 5}
+}
+
+func (x *DBUserBaseInfo_DBProfile) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+func (x *DBUserBaseInfo_DBProfile) GetAge() int32 {
+	if x != nil {
+		return x.Age
+	}
+	return 0
+}
+
+var File_proto_user_proto protoreflect.FileDescriptor
+
+const file_proto_user_proto_rawDesc = "" +
+	"\n" +
+	"\x10proto/user.proto\x12\x04user\"\xbf\b\n" +
+	"\x0eDBUserBaseInfo\x12\x0f\n" +
+	"\auser_id\x18\x01 \x01(\x05\x12\x10\n" +
+	"\busername\x18\x02 \x01(\t\x12\x12\n" +
+	"\n" +
+	"avatar_url\x18\x03 \x01(\t\x12\x1c\n" +
+	"\x06gender\x18\x04 \x01(\x0e2\f.user.Gender\x12\r\n" +
+	"\x05level\x18\x05 \x01(\x05\x12\v\n" +
+	"\x03exp\x18\x06 \x01(\x03\x12\x0f\n" +
+	"\abalance\x18\a \x01(\x02\x12/\n" +
+	"\afriends\x18\b \x01(\v2\x1e.user.DBUserBaseInfo.DBFriends\x121\n" +
+	"\bsettings\x18\t \x01(\v2\x1f.user.DBUserBaseInfo.DBSettings\x12'\n" +
+	"\flogin_source\x18\n" +
+	" \x01(\x0e2\x11.user.LoginSource\x124\n" +
+	"\n" +
+	"int32_list\x18\v \x01(\v2 .user.DBUserBaseInfo.DBInt32List\x12/\n" +
+	"\aweapons\x18\f \x01(\v2\x1e.user.DBUserBaseInfo.DBWeapons\x12\x1e\n" +
+	"\x06weapon\x18\r \x01(\v2\x0e.user.DBWeapon\x124\n" +
+	"\n" +
+	"weapon_map\x18\x0e \x01(\v2 .user.DBUserBaseInfo.DBWeaponMap\x12\f\n" +
+	"\x04coin\x18\x0f \x01(\r\x12\v\n" +
+	"\x03gem\x18\x10 \x01(\x04\x12\v\n" +
+	"\x03vip\x18\x11 \x01(\b\x12\r\n" +
+	"\x05score\x18\x12 \x01(\x01\x12\r\n" +
+	"\x05token\x18\x13 \x01(\f\x12/\n" +
+	"\aprofile\x18\x14 \x01(\v2\x1e.user.DBUserBaseInfo.DBProfile\x120\n" +
+	"\tvip_level\x18\x15 \x01(\x0e2\x1d.user.DBUserBaseInfo.VipLevel\x1a\x1a\n" +
+	"\tDBFriends\x12\r\n" +
+	"\x05items\x18\x01 \x03(\t\x1al\n" +
+	"\n" +
+	"DBSettings\x123\n" +
+	"\x02kv\x18\x01 \x03(\v2'.user.DBUserBaseInfo.DBSettings.KvEntry\x1a)\n" +
+	"\aKvEntry\x12\v\n" +
+	"\x03key\x18\x01 \x01(\t\x12\r\n" +
+	"\x05value\x18\x02 \x01(\t:\x028\x01\x1a\x1c\n" +
+	"\vDBInt32List\x12\r\n" +
+	"\x05items\x18\x01 \x03(\x05\x1a*\n" +
+	"\tDBWeapons\x12\x1d\n" +
+	"\x05items\x18\x01 \x03(\v2\x0e.user.DBWeapon\x1a\x87\x01\n" +
+	"\vDBWeaponMap\x12:\n" +
+	"\x05items\x18\x01 \x03(\v2+.user.DBUserBaseInfo.DBWeaponMap.ItemsEntry\x1a<\n" +
+	"\n" +
+	"ItemsEntry\x12\v\n" +
+	"\x03key\x18\x01 \x01(\x05\x12\x1d\n" +
+	"\x05value\x18\x02 \x01(\v2\x0e.user.DBWeapon:\x028\x01\x1a*\n" +
+	"\tDBProfile\x12\x10\n" +
+	"\bnickname\x18\x01 \x01(\t\x12\v\n" +
+	"\x03age\x18\x02 \x01(\x05\".\n" +
+	"\bVipLevel\x12\f\n" +
+	"\bVIP_NONE\x10\x00\x12\t\n" +
+	"\x05VIP_1\x10\x01\x12\t\n" +
+	"\x05VIP_2\x10\x02\"9\n" +
+	"\bDBWeapon\x12\f\n" +
+	"\x04name\x18\x01 \x01(\t\x12\x0e\n" +
+	"\x06damage\x18\x02 \x01(\x05\x12\x0f\n" +
+	"\aelement\x18\x03 \x01(\t*@\n" +
+	"\x06Gender\x12\x12\n" +
+	"\x0eGENDER_UNKNOWN\x10\x00\x12\x0f\n" +
+	"\vGENDER_MALE\x10\x01\x12\x11\n" +
+	"\rGENDER_FEMALE\x10\x02*Y\n" +
+	"\vLoginSource\x12\x12\n" +
+	"\x0eSOURCE_UNKNOWN\x10\x00\x12\x0e\n" +
+	"\n" +
+	"SOURCE_APP\x10\x01\x12\r\n" +
+	"\tSOURCE_H5\x10\x02\x12\x17\n" +
+	"\x13SOURCE_MINI_PROGRAM\x10\x03B9Z7github.com/beijian128/protoc-gen-redis/generated/attachb\x06proto3"
+
+var (
+	file_proto_user_proto_rawDescOnce sync.Once
+	file_proto_user_proto_rawDescData []byte
+)
+
+func file_proto_user_proto_rawDescGZIP() []byte {
+	file_proto_user_proto_rawDescOnce.Do(func() {
+		file_proto_user_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_user_proto_rawDesc), len(file_proto_user_proto_rawDesc)))
+	})
+	return file_proto_user_proto_rawDescData
+}
+
+var file_proto_user_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_proto_user_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_proto_user_proto_goTypes = []any{
+	(Gender)(0),                        // 0: user.Gender
+	(LoginSource)(0),                   // 1: user.LoginSource
+	(DBUserBaseInfo_VipLevel)(0),       // 2: user.DBUserBaseInfo.VipLevel
+	(*DBUserBaseInfo)(nil),             // 3: user.DBUserBaseInfo
+	(*DBWeapon)(nil),                   // 4: user.DBWeapon
+	(*DBUserBaseInfo_DBFriends)(nil),   // 5: user.DBUserBaseInfo.DBFriends
+	(*DBUserBaseInfo_DBSettings)(nil),  // 6: user.DBUserBaseInfo.DBSettings
+	(*DBUserBaseInfo_DBInt32List)(nil), // 7: user.DBUserBaseInfo.DBInt32List
+	(*DBUserBaseInfo_DBWeapons)(nil),   // 8: user.DBUserBaseInfo.DBWeapons
+	(*DBUserBaseInfo_DBWeaponMap)(nil), // 9: user.DBUserBaseInfo.DBWeaponMap
+	(*DBUserBaseInfo_DBProfile)(nil),   // 10: user.DBUserBaseInfo.DBProfile
+	nil,                                // 11: user.DBUserBaseInfo.DBSettings.KvEntry
+	nil,                                // 12: user.DBUserBaseInfo.DBWeaponMap.ItemsEntry
+}
+var file_proto_user_proto_depIdxs = []int32{
+	0,  // 0: user.DBUserBaseInfo.gender:type_name -> user.Gender
+	5,  // 1: user.DBUserBaseInfo.friends:type_name -> user.DBUserBaseInfo.DBFriends
+	6,  // 2: user.DBUserBaseInfo.settings:type_name -> user.DBUserBaseInfo.DBSettings
+	1,  // 3: user.DBUserBaseInfo.login_source:type_name -> user.LoginSource
+	7,  // 4: user.DBUserBaseInfo.int32_list:type_name -> user.DBUserBaseInfo.DBInt32List
+	8,  // 5: user.DBUserBaseInfo.weapons:type_name -> user.DBUserBaseInfo.DBWeapons
+	4,  // 6: user.DBUserBaseInfo.weapon:type_name -> user.DBWeapon
+	9,  // 7: user.DBUserBaseInfo.weapon_map:type_name -> user.DBUserBaseInfo.DBWeaponMap
+	10, // 8: user.DBUserBaseInfo.profile:type_name -> user.DBUserBaseInfo.DBProfile
+	2,  // 9: user.DBUserBaseInfo.vip_level:type_name -> user.DBUserBaseInfo.VipLevel
+	11, // 10: user.DBUserBaseInfo.DBSettings.kv:type_name -> user.DBUserBaseInfo.DBSettings.KvEntry
+	4,  // 11: user.DBUserBaseInfo.DBWeapons.items:type_name -> user.DBWeapon
+	12, // 12: user.DBUserBaseInfo.DBWeaponMap.items:type_name -> user.DBUserBaseInfo.DBWeaponMap.ItemsEntry
+	4,  // 13: user.DBUserBaseInfo.DBWeaponMap.ItemsEntry.value:type_name -> user.DBWeapon
+	14, // [14:14] is the sub-list for method output_type
+	14, // [14:14] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_proto_user_proto_init() }
+func file_proto_user_proto_init() {
+	if File_proto_user_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_user_proto_rawDesc), len(file_proto_user_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_user_proto_goTypes,
+		DependencyIndexes: file_proto_user_proto_depIdxs,
+		EnumInfos:         file_proto_user_proto_enumTypes,
+		MessageInfos:      file_proto_user_proto_msgTypes,
+	}.Build()
+	File_proto_user_proto = out.File
+	file_proto_user_proto_goTypes = nil
+	file_proto_user_proto_depIdxs = nil
+}
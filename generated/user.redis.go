@@ -3,6 +3,7 @@
 package cmddb
 
 import (
+	"context"
 	"fmt"
 	"github.com/gomodule/redigo/redis"
 	"math"
@@ -37,109 +38,6 @@ const (
 	LoginSource_SOURCE_MINI_PROGRAM LoginSource = 3
 )
 
-// --- protobuf wire format 辅助函数（语言无关序列化，规则见 https://protobuf.dev/programming-guides/encoding/） ---
-
-// redisProtoAppendVarint 追加一个 base-128 varint 编码的 uint64
-func redisProtoAppendVarint(buf []byte, v uint64) []byte {
-	for v >= 0x80 {
-		buf = append(buf, byte(v)|0x80)
-		v >>= 7
-	}
-	return append(buf, byte(v))
-}
-
-// redisProtoReadVarint 读取一个 varint，返回（值，消耗字节数）
-func redisProtoReadVarint(b []byte) (uint64, int, error) {
-	var v uint64
-	for i := 0; i < len(b) && i < 10; i++ {
-		v |= uint64(b[i]&0x7F) << (7 * i)
-		if b[i]&0x80 == 0 {
-			return v, i + 1, nil
-		}
-	}
-	return 0, 0, fmt.Errorf("protobuf varint 读取失败: 数据截断或过长")
-}
-
-// redisProtoAppendTag 追加字段 tag（field<<3 | wireType）
-func redisProtoAppendTag(buf []byte, field, wire int32) []byte {
-	return redisProtoAppendVarint(buf, uint64(field)<<3|uint64(wire))
-}
-
-// redisProtoAppendLen 追加 length-delimited 数据（长度前缀 + 数据）
-func redisProtoAppendLen(buf, payload []byte) []byte {
-	buf = redisProtoAppendVarint(buf, uint64(len(payload)))
-	return append(buf, payload...)
-}
-
-// redisProtoReadBytes 读取 length-delimited 数据，返回（数据拷贝，消耗字节数）；
-// 返回拷贝避免与输入缓冲区 alias。
-func redisProtoReadBytes(b []byte) ([]byte, int, error) {
-	n, k, err := redisProtoReadVarint(b)
-	if err != nil {
-		return nil, 0, err
-	}
-	if n > uint64(len(b)-k) {
-		return nil, 0, fmt.Errorf("protobuf length-delimited 数据截断: 期望 %d 字节, 剩余 %d", n, len(b)-k)
-	}
-	return append([]byte(nil), b[k:k+int(n)]...), k + int(n), nil
-}
-
-// redisProtoAppendFixed32 追加小端 4 字节
-func redisProtoAppendFixed32(buf []byte, v uint32) []byte {
-	return append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
-}
-
-// redisProtoReadFixed32 读取小端 4 字节
-func redisProtoReadFixed32(b []byte) (uint32, int, error) {
-	if len(b) < 4 {
-		return 0, 0, fmt.Errorf("protobuf fixed32 数据截断")
-	}
-	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24, 4, nil
-}
-
-// redisProtoAppendFixed64 追加小端 8 字节
-func redisProtoAppendFixed64(buf []byte, v uint64) []byte {
-	return append(buf,
-		byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
-		byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
-}
-
-// redisProtoReadFixed64 读取小端 8 字节
-func redisProtoReadFixed64(b []byte) (uint64, int, error) {
-	if len(b) < 8 {
-		return 0, 0, fmt.Errorf("protobuf fixed64 数据截断")
-	}
-	var v uint64
-	for i := 0; i < 8; i++ {
-		v |= uint64(b[i]) << (8 * i)
-	}
-	return v, 8, nil
-}
-
-// redisProtoSkip 跳过未知字段，返回消耗字节数
-func redisProtoSkip(b []byte, wire uint64) (int, error) {
-	switch wire {
-	case 0: // varint
-		_, n, err := redisProtoReadVarint(b)
-		return n, err
-	case 1: // fixed64
-		if len(b) < 8 {
-			return 0, fmt.Errorf("protobuf fixed64 数据截断")
-		}
-		return 8, nil
-	case 2: // length-delimited
-		_, n, err := redisProtoReadBytes(b)
-		return n, err
-	case 5: // fixed32
-		if len(b) < 4 {
-			return 0, fmt.Errorf("protobuf fixed32 数据截断")
-		}
-		return 4, nil
-	default:
-		return 0, fmt.Errorf("protobuf 未知 wire type %d", wire)
-	}
-}
-
 // --- Message: DBUserBaseInfo ---
 
 // FieldDBUserBaseInfo 用于标识 Redis Hash 中的字段编号
@@ -283,6 +181,11 @@ func NewDBUserBaseInfo() *DBUserBaseInfo {
 	return &DBUserBaseInfo{}
 }
 
+// redisKeyDBUserBaseInfo 按 key_format 生成 DBUserBaseInfo 对应的 Redis Hash key
+func redisKeyDBUserBaseInfo(REDBKey uint32, ida, idb uint64) string {
+	return fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+}
+
 // MarshalRedisProto 将 DBUserBaseInfo 序列化为 protobuf wire format 字节流。
 // 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
 // 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
@@ -784,7 +687,17 @@ func (p *DBUserBaseInfo) UnmarshalRedisProto(b []byte) error {
 //	如果 fields 为空（长度为 0），则默认读取所有字段（即 FieldDBUserBaseInfoIDs）
 //	集合字段（map/repeated）整体 protobuf 反序列化
 func (p *DBUserBaseInfo) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.GetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 HMGET（经 redis.DoContext）
+func (p *DBUserBaseInfo) GetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo) error {
+	return p.GetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsExec 与 GetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo) GetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo) error {
+	key := redisKeyDBUserBaseInfo(REDBKey, ida, idb)
 
 	// 决定要操作的字段列表
 	fieldsToUse := fields
@@ -795,19 +708,19 @@ func (p *DBUserBaseInfo) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
 	args := []interface{}{key}
 	for _, fieldID := range fieldsToUse {
-		args = append(args, fieldID)
+		args = append(args, uint32(fieldID))
 	}
 
 	// 一次 HMGET 获取所有字段值
-	reply, err := conn.Do("HMGET", args...)
+	reply, err := exec.Do(ctx, "HMGET", args...)
 	if err != nil {
-		return fmt.Errorf("HMGET 失败: %v", err)
+		return fmt.Errorf("HMGET 失败: %w", err)
 	}
 
 	// 解析返回的 []interface{} 列表
-	values, err := redis.Values(reply, nil)
-	if err != nil {
-		return fmt.Errorf("解析 HMGET 结果失败: %v", err)
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(fieldsToUse) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
 	}
 
 	// 逐一处理每个字段
@@ -1066,7 +979,17 @@ func (p *DBUserBaseInfo) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 //	如果 fields 为空（长度为 0），则默认存储所有字段（即 FieldDBUserBaseInfoIDs）
 //	集合字段（map/repeated）整体 protobuf 序列化后写入
 func (p *DBUserBaseInfo) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.SetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 HSET（经 redis.DoContext）
+func (p *DBUserBaseInfo) SetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo) error {
+	return p.SetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsExec 与 SetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo) SetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo) error {
+	key := redisKeyDBUserBaseInfo(REDBKey, ida, idb)
 	args := []interface{}{key}
 
 	// 决定要操作的字段列表
@@ -1081,37 +1004,37 @@ func (p *DBUserBaseInfo) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 		case FieldDBUserBaseInfo_UserId:
 
 			// --- 直存字段: UserId ---
-			args = append(args, fieldID, p.UserId)
+			args = append(args, uint32(fieldID), p.UserId)
 
 		case FieldDBUserBaseInfo_Username:
 
 			// --- 直存字段: Username ---
-			args = append(args, fieldID, p.Username)
+			args = append(args, uint32(fieldID), p.Username)
 
 		case FieldDBUserBaseInfo_AvatarUrl:
 
 			// --- 直存字段: AvatarUrl ---
-			args = append(args, fieldID, p.AvatarUrl)
+			args = append(args, uint32(fieldID), p.AvatarUrl)
 
 		case FieldDBUserBaseInfo_Gender:
 
-			// --- 直存字段: Gender ---
-			args = append(args, fieldID, p.Gender)
+			// --- 直存字段: Gender（枚举按整数写入）---
+			args = append(args, uint32(fieldID), int32(p.Gender))
 
 		case FieldDBUserBaseInfo_Level:
 
 			// --- 直存字段: Level ---
-			args = append(args, fieldID, p.Level)
+			args = append(args, uint32(fieldID), p.Level)
 
 		case FieldDBUserBaseInfo_Exp:
 
 			// --- 直存字段: Exp ---
-			args = append(args, fieldID, p.Exp)
+			args = append(args, uint32(fieldID), p.Exp)
 
 		case FieldDBUserBaseInfo_Balance:
 
 			// --- 直存字段: Balance ---
-			args = append(args, fieldID, p.Balance)
+			args = append(args, uint32(fieldID), p.Balance)
 
 		case FieldDBUserBaseInfo_Friends:
 
@@ -1121,7 +1044,7 @@ func (p *DBUserBaseInfo) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 				if err != nil {
 					return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Friends", err)
 				}
-				args = append(args, fieldID, b)
+				args = append(args, uint32(fieldID), b)
 			}
 
 		case FieldDBUserBaseInfo_Settings:
@@ -1132,13 +1055,13 @@ func (p *DBUserBaseInfo) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 				if err != nil {
 					return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Settings", err)
 				}
-				args = append(args, fieldID, b)
+				args = append(args, uint32(fieldID), b)
 			}
 
 		case FieldDBUserBaseInfo_LoginSource:
 
-			// --- 直存字段: LoginSource ---
-			args = append(args, fieldID, p.LoginSource)
+			// --- 直存字段: LoginSource（枚举按整数写入）---
+			args = append(args, uint32(fieldID), int32(p.LoginSource))
 
 		case FieldDBUserBaseInfo_Int32List:
 
@@ -1148,7 +1071,7 @@ func (p *DBUserBaseInfo) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 				if err != nil {
 					return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Int32List", err)
 				}
-				args = append(args, fieldID, b)
+				args = append(args, uint32(fieldID), b)
 			}
 
 		case FieldDBUserBaseInfo_Weapons:
@@ -1159,7 +1082,7 @@ func (p *DBUserBaseInfo) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 				if err != nil {
 					return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Weapons", err)
 				}
-				args = append(args, fieldID, b)
+				args = append(args, uint32(fieldID), b)
 			}
 
 		case FieldDBUserBaseInfo_Weapon:
@@ -1170,7 +1093,7 @@ func (p *DBUserBaseInfo) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 				if err != nil {
 					return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Weapon", err)
 				}
-				args = append(args, fieldID, b)
+				args = append(args, uint32(fieldID), b)
 			}
 
 		case FieldDBUserBaseInfo_WeaponMap:
@@ -1181,33 +1104,33 @@ func (p *DBUserBaseInfo) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 				if err != nil {
 					return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "WeaponMap", err)
 				}
-				args = append(args, fieldID, b)
+				args = append(args, uint32(fieldID), b)
 			}
 
 		case FieldDBUserBaseInfo_Coin:
 
 			// --- 直存字段: Coin ---
-			args = append(args, fieldID, p.Coin)
+			args = append(args, uint32(fieldID), p.Coin)
 
 		case FieldDBUserBaseInfo_Gem:
 
 			// --- 直存字段: Gem ---
-			args = append(args, fieldID, p.Gem)
+			args = append(args, uint32(fieldID), p.Gem)
 
 		case FieldDBUserBaseInfo_Vip:
 
 			// --- 直存字段: Vip ---
-			args = append(args, fieldID, p.Vip)
+			args = append(args, uint32(fieldID), p.Vip)
 
 		case FieldDBUserBaseInfo_Score:
 
 			// --- 直存字段: Score ---
-			args = append(args, fieldID, p.Score)
+			args = append(args, uint32(fieldID), p.Score)
 
 		case FieldDBUserBaseInfo_Token:
 
 			// --- 直存字段: Token ---
-			args = append(args, fieldID, p.Token)
+			args = append(args, uint32(fieldID), p.Token)
 
 		case FieldDBUserBaseInfo_Profile:
 
@@ -1217,13 +1140,13 @@ func (p *DBUserBaseInfo) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 				if err != nil {
 					return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Profile", err)
 				}
-				args = append(args, fieldID, b)
+				args = append(args, uint32(fieldID), b)
 			}
 
 		case FieldDBUserBaseInfo_VipLevel:
 
-			// --- 直存字段: VipLevel ---
-			args = append(args, fieldID, p.VipLevel)
+			// --- 直存字段: VipLevel（枚举按整数写入）---
+			args = append(args, uint32(fieldID), int32(p.VipLevel))
 
 		default:
 			return fmt.Errorf("未知字段编号: %d", fieldID)
@@ -1232,12 +1155,478 @@ func (p *DBUserBaseInfo) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uin
 
 	// 所有字段统一一次 HSET 写入
 	if len(args) > 1 {
-		_, err := conn.Do("HSET", args...)
+		_, err := exec.Do(ctx, "HSET", args...)
 		return err
 	}
 	return nil
 }
 
+// IncrUserId 对字段 UserId 执行 HINCRBY（服务端原子自增 delta），并把自增后的值写回 p.UserId
+func (p *DBUserBaseInfo) IncrUserId(conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrUserIdExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrUserIdCtx 与 IncrUserId 相同，ctx 的截止时间与取消作用于 HINCRBY（经 redis.DoContext）
+func (p *DBUserBaseInfo) IncrUserIdCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrUserIdExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrUserIdExec 与 IncrUserIdCtx 相同，但经任意 RedisExecutor 执行
+func (p *DBUserBaseInfo) IncrUserIdExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64) error {
+	// 字段的值在 int32 范围内，|delta| 超过 MaxUint32 时结果必然越界（也保证下面撤销用的 -delta 不溢出）
+	if delta < -math.MaxUint32 || delta > math.MaxUint32 {
+		return fmt.Errorf("字段 %s 的自增量 %d 超出 int32 范围", "UserId", delta)
+	}
+	reply, err := exec.Do(ctx, "HINCRBY", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_UserId), delta)
+	if err != nil {
+		return fmt.Errorf("HINCRBY 字段 %s 失败: %w", "UserId", err)
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return fmt.Errorf("解析 HINCRBY 结果失败: 意外的回复 %T", reply)
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return redisUndoIncrDBUserBaseInfo_UserId(ctx, exec, REDBKey, ida, idb, delta, fmt.Errorf("字段 %s 自增后的值 %d 超出 int32 范围", "UserId", n))
+	}
+	p.UserId = int32(n)
+	return nil
+}
+
+// redisUndoIncrDBUserBaseInfo_UserId 在 HINCRBY 的结果超出字段类型范围时减回 delta：
+// 越界的值已写入服务端，不撤销则该记录之后的读取都会失败。自增可交换，期间其他调用的自增不受影响；ctx 已取消时仍执行
+func redisUndoIncrDBUserBaseInfo_UserId(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64, cause error) error {
+	_, err := exec.Do(context.WithoutCancel(ctx), "HINCRBY", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_UserId), -delta)
+	if err != nil {
+		return fmt.Errorf("%w，撤销自增失败（需人工修正）: %v", cause, err)
+	}
+	return fmt.Errorf("%w，已撤销本次自增", cause)
+}
+
+// IncrLevel 对字段 Level 执行 HINCRBY（服务端原子自增 delta），并把自增后的值写回 p.Level
+func (p *DBUserBaseInfo) IncrLevel(conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrLevelExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrLevelCtx 与 IncrLevel 相同，ctx 的截止时间与取消作用于 HINCRBY（经 redis.DoContext）
+func (p *DBUserBaseInfo) IncrLevelCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrLevelExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrLevelExec 与 IncrLevelCtx 相同，但经任意 RedisExecutor 执行
+func (p *DBUserBaseInfo) IncrLevelExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64) error {
+	// 字段的值在 int32 范围内，|delta| 超过 MaxUint32 时结果必然越界（也保证下面撤销用的 -delta 不溢出）
+	if delta < -math.MaxUint32 || delta > math.MaxUint32 {
+		return fmt.Errorf("字段 %s 的自增量 %d 超出 int32 范围", "Level", delta)
+	}
+	reply, err := exec.Do(ctx, "HINCRBY", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_Level), delta)
+	if err != nil {
+		return fmt.Errorf("HINCRBY 字段 %s 失败: %w", "Level", err)
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return fmt.Errorf("解析 HINCRBY 结果失败: 意外的回复 %T", reply)
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return redisUndoIncrDBUserBaseInfo_Level(ctx, exec, REDBKey, ida, idb, delta, fmt.Errorf("字段 %s 自增后的值 %d 超出 int32 范围", "Level", n))
+	}
+	p.Level = int32(n)
+	return nil
+}
+
+// redisUndoIncrDBUserBaseInfo_Level 在 HINCRBY 的结果超出字段类型范围时减回 delta：
+// 越界的值已写入服务端，不撤销则该记录之后的读取都会失败。自增可交换，期间其他调用的自增不受影响；ctx 已取消时仍执行
+func redisUndoIncrDBUserBaseInfo_Level(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64, cause error) error {
+	_, err := exec.Do(context.WithoutCancel(ctx), "HINCRBY", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_Level), -delta)
+	if err != nil {
+		return fmt.Errorf("%w，撤销自增失败（需人工修正）: %v", cause, err)
+	}
+	return fmt.Errorf("%w，已撤销本次自增", cause)
+}
+
+// IncrExp 对字段 Exp 执行 HINCRBY（服务端原子自增 delta），并把自增后的值写回 p.Exp
+func (p *DBUserBaseInfo) IncrExp(conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrExpExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrExpCtx 与 IncrExp 相同，ctx 的截止时间与取消作用于 HINCRBY（经 redis.DoContext）
+func (p *DBUserBaseInfo) IncrExpCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrExpExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrExpExec 与 IncrExpCtx 相同，但经任意 RedisExecutor 执行
+func (p *DBUserBaseInfo) IncrExpExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64) error {
+	reply, err := exec.Do(ctx, "HINCRBY", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_Exp), delta)
+	if err != nil {
+		return fmt.Errorf("HINCRBY 字段 %s 失败: %w", "Exp", err)
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return fmt.Errorf("解析 HINCRBY 结果失败: 意外的回复 %T", reply)
+	}
+	p.Exp = int64(n)
+	return nil
+}
+
+// IncrBalance 对字段 Balance 执行 HINCRBYFLOAT（服务端原子自增 delta），并把自增后的值写回 p.Balance
+func (p *DBUserBaseInfo) IncrBalance(conn redis.Conn, REDBKey uint32, ida, idb uint64, delta float64) error {
+	return p.IncrBalanceExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrBalanceCtx 与 IncrBalance 相同，ctx 的截止时间与取消作用于 HINCRBYFLOAT（经 redis.DoContext）
+func (p *DBUserBaseInfo) IncrBalanceCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, delta float64) error {
+	return p.IncrBalanceExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrBalanceExec 与 IncrBalanceCtx 相同，但经任意 RedisExecutor 执行
+func (p *DBUserBaseInfo) IncrBalanceExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta float64) error {
+	reply, err := exec.Do(ctx, "HINCRBYFLOAT", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_Balance), delta)
+	if err != nil {
+		return fmt.Errorf("HINCRBYFLOAT 字段 %s 失败: %w", "Balance", err)
+	}
+	val, ok := reply.([]byte)
+	if !ok {
+		return fmt.Errorf("解析 HINCRBYFLOAT 结果失败: 意外的回复 %T", reply)
+	}
+	f, err := strconv.ParseFloat(string(val), 32)
+	if err != nil {
+		return redisUndoIncrDBUserBaseInfo_Balance(ctx, exec, REDBKey, ida, idb, delta, fmt.Errorf("解析字段 %s 失败: %v", "Balance", err))
+	}
+	p.Balance = float32(f)
+	return nil
+}
+
+// redisUndoIncrDBUserBaseInfo_Balance 在 HINCRBYFLOAT 的结果超出字段类型范围时减回 delta：
+// 越界的值已写入服务端，不撤销则该记录之后的读取都会失败。自增可交换，期间其他调用的自增不受影响；ctx 已取消时仍执行
+func redisUndoIncrDBUserBaseInfo_Balance(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta float64, cause error) error {
+	_, err := exec.Do(context.WithoutCancel(ctx), "HINCRBYFLOAT", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_Balance), -delta)
+	if err != nil {
+		return fmt.Errorf("%w，撤销自增失败（需人工修正）: %v", cause, err)
+	}
+	return fmt.Errorf("%w，已撤销本次自增", cause)
+}
+
+// IncrCoin 对字段 Coin 执行 HINCRBY（服务端原子自增 delta），并把自增后的值写回 p.Coin
+func (p *DBUserBaseInfo) IncrCoin(conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrCoinExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrCoinCtx 与 IncrCoin 相同，ctx 的截止时间与取消作用于 HINCRBY（经 redis.DoContext）
+func (p *DBUserBaseInfo) IncrCoinCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrCoinExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrCoinExec 与 IncrCoinCtx 相同，但经任意 RedisExecutor 执行
+func (p *DBUserBaseInfo) IncrCoinExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64) error {
+	// 字段的值在 uint32 范围内，|delta| 超过 MaxUint32 时结果必然越界（也保证下面撤销用的 -delta 不溢出）
+	if delta < -math.MaxUint32 || delta > math.MaxUint32 {
+		return fmt.Errorf("字段 %s 的自增量 %d 超出 uint32 范围", "Coin", delta)
+	}
+	reply, err := exec.Do(ctx, "HINCRBY", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_Coin), delta)
+	if err != nil {
+		return fmt.Errorf("HINCRBY 字段 %s 失败: %w", "Coin", err)
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return fmt.Errorf("解析 HINCRBY 结果失败: 意外的回复 %T", reply)
+	}
+	if n < 0 || n > math.MaxUint32 {
+		return redisUndoIncrDBUserBaseInfo_Coin(ctx, exec, REDBKey, ida, idb, delta, fmt.Errorf("字段 %s 自增后的值 %d 超出 uint32 范围", "Coin", n))
+	}
+	p.Coin = uint32(n)
+	return nil
+}
+
+// redisUndoIncrDBUserBaseInfo_Coin 在 HINCRBY 的结果超出字段类型范围时减回 delta：
+// 越界的值已写入服务端，不撤销则该记录之后的读取都会失败。自增可交换，期间其他调用的自增不受影响；ctx 已取消时仍执行
+func redisUndoIncrDBUserBaseInfo_Coin(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64, cause error) error {
+	_, err := exec.Do(context.WithoutCancel(ctx), "HINCRBY", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_Coin), -delta)
+	if err != nil {
+		return fmt.Errorf("%w，撤销自增失败（需人工修正）: %v", cause, err)
+	}
+	return fmt.Errorf("%w，已撤销本次自增", cause)
+}
+
+// IncrGem 对字段 Gem 执行 HINCRBY（服务端原子自增 delta），并把自增后的值写回 p.Gem
+func (p *DBUserBaseInfo) IncrGem(conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrGemExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrGemCtx 与 IncrGem 相同，ctx 的截止时间与取消作用于 HINCRBY（经 redis.DoContext）
+func (p *DBUserBaseInfo) IncrGemCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrGemExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrGemExec 与 IncrGemCtx 相同，但经任意 RedisExecutor 执行
+func (p *DBUserBaseInfo) IncrGemExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64) error {
+	if delta == math.MinInt64 {
+		return fmt.Errorf("字段 %s 的自增量 %d 超出 uint64 范围", "Gem", delta)
+	}
+	reply, err := exec.Do(ctx, "HINCRBY", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_Gem), delta)
+	if err != nil {
+		return fmt.Errorf("HINCRBY 字段 %s 失败: %w", "Gem", err)
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return fmt.Errorf("解析 HINCRBY 结果失败: 意外的回复 %T", reply)
+	}
+	if n < 0 {
+		return redisUndoIncrDBUserBaseInfo_Gem(ctx, exec, REDBKey, ida, idb, delta, fmt.Errorf("字段 %s 自增后的值 %d 超出 uint64 范围", "Gem", n))
+	}
+	p.Gem = uint64(n)
+	return nil
+}
+
+// redisUndoIncrDBUserBaseInfo_Gem 在 HINCRBY 的结果超出字段类型范围时减回 delta：
+// 越界的值已写入服务端，不撤销则该记录之后的读取都会失败。自增可交换，期间其他调用的自增不受影响；ctx 已取消时仍执行
+func redisUndoIncrDBUserBaseInfo_Gem(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64, cause error) error {
+	_, err := exec.Do(context.WithoutCancel(ctx), "HINCRBY", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_Gem), -delta)
+	if err != nil {
+		return fmt.Errorf("%w，撤销自增失败（需人工修正）: %v", cause, err)
+	}
+	return fmt.Errorf("%w，已撤销本次自增", cause)
+}
+
+// IncrScore 对字段 Score 执行 HINCRBYFLOAT（服务端原子自增 delta），并把自增后的值写回 p.Score
+func (p *DBUserBaseInfo) IncrScore(conn redis.Conn, REDBKey uint32, ida, idb uint64, delta float64) error {
+	return p.IncrScoreExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrScoreCtx 与 IncrScore 相同，ctx 的截止时间与取消作用于 HINCRBYFLOAT（经 redis.DoContext）
+func (p *DBUserBaseInfo) IncrScoreCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, delta float64) error {
+	return p.IncrScoreExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrScoreExec 与 IncrScoreCtx 相同，但经任意 RedisExecutor 执行
+func (p *DBUserBaseInfo) IncrScoreExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta float64) error {
+	reply, err := exec.Do(ctx, "HINCRBYFLOAT", redisKeyDBUserBaseInfo(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_Score), delta)
+	if err != nil {
+		return fmt.Errorf("HINCRBYFLOAT 字段 %s 失败: %w", "Score", err)
+	}
+	val, ok := reply.([]byte)
+	if !ok {
+		return fmt.Errorf("解析 HINCRBYFLOAT 结果失败: 意外的回复 %T", reply)
+	}
+	f, err := strconv.ParseFloat(string(val), 64)
+	if err != nil {
+		return fmt.Errorf("解析字段 %s 失败: %v", "Score", err)
+	}
+	p.Score = float64(f)
+	return nil
+}
+
+// DBUserBaseInfoStore 是绑定连接来源的 DBUserBaseInfo 存取入口：每次调用自行借出并归还连接，
+// REDBKey 在创建时固定（WithREDBKey 可切换），方法只需传 ida/idb。
+// 单元测试可用 NewDBUserBaseInfoStoreExec 注入自定义 RedisExecutor。
+type DBUserBaseInfoStore struct {
+	acquire redisAcquireFunc
+	REDBKey uint32
+}
+
+// NewDBUserBaseInfoStore 基于连接来源（如 *redis.Pool）创建 Store：每次调用 Get 一个连接，用完 Close 归还
+func NewDBUserBaseInfoStore(pool RedisConnSource, REDBKey uint32) *DBUserBaseInfoStore {
+	return &DBUserBaseInfoStore{acquire: redisPoolAcquire(pool), REDBKey: REDBKey}
+}
+
+// NewDBUserBaseInfoStoreExec 基于任意 RedisExecutor（自定义客户端、mock 等）创建 Store，不涉及连接借还
+func NewDBUserBaseInfoStoreExec(exec RedisExecutor, REDBKey uint32) *DBUserBaseInfoStore {
+	return &DBUserBaseInfoStore{acquire: redisExecAcquire(exec), REDBKey: REDBKey}
+}
+
+// DBUserBaseInfoRepository 是 DBUserBaseInfo 的数据访问接口，方法与 DBUserBaseInfoStore 一致。
+// 业务代码依赖该接口，生产环境传 DBUserBaseInfoStore，单元测试传 NewDBUserBaseInfoMemRepository()（mem=true）。
+type DBUserBaseInfoRepository interface {
+	Get(ctx context.Context, ida, idb uint64, fields ...FieldDBUserBaseInfo) (*DBUserBaseInfo, error)
+	Set(ctx context.Context, ida, idb uint64, v *DBUserBaseInfo, fields ...FieldDBUserBaseInfo) error
+	Delete(ctx context.Context, ida, idb uint64, fields ...FieldDBUserBaseInfo) error
+	Update(ctx context.Context, ida, idb uint64, fn func(v *DBUserBaseInfo) error, fields ...FieldDBUserBaseInfo) (*DBUserBaseInfo, error)
+	IncrUserId(ctx context.Context, ida, idb uint64, delta int64) (int32, error)
+	IncrLevel(ctx context.Context, ida, idb uint64, delta int64) (int32, error)
+	IncrExp(ctx context.Context, ida, idb uint64, delta int64) (int64, error)
+	IncrBalance(ctx context.Context, ida, idb uint64, delta float64) (float32, error)
+	IncrCoin(ctx context.Context, ida, idb uint64, delta int64) (uint32, error)
+	IncrGem(ctx context.Context, ida, idb uint64, delta int64) (uint64, error)
+	IncrScore(ctx context.Context, ida, idb uint64, delta float64) (float64, error)
+}
+
+var _ DBUserBaseInfoRepository = (*DBUserBaseInfoStore)(nil)
+
+// NewDBUserBaseInfoMemRepository 返回基于内存的 DBUserBaseInfoRepository（不需要 Redis）。
+// 它就是运行在 NewRedisMemExecutor 上的 DBUserBaseInfoStore，读写、编解码与错误路径和真实 Redis 完全相同：
+// 未写入的字段读回零值、未知字段编号报错、数值解析失败报错。
+func NewDBUserBaseInfoMemRepository() DBUserBaseInfoRepository {
+	return NewDBUserBaseInfoStoreExec(NewRedisMemExecutor(), 0)
+}
+
+// WithREDBKey 返回绑定到另一个 REDBKey 的 Store（共享同一连接来源）
+func (s *DBUserBaseInfoStore) WithREDBKey(REDBKey uint32) *DBUserBaseInfoStore {
+	c := *s
+	c.REDBKey = REDBKey
+	return &c
+}
+
+// Get 读取 ida/idb 对应的 DBUserBaseInfo；fields 为空时读取全部字段，不存在的字段为零值
+func (s *DBUserBaseInfoStore) Get(ctx context.Context, ida, idb uint64, fields ...FieldDBUserBaseInfo) (*DBUserBaseInfo, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	v := NewDBUserBaseInfo()
+	if err := v.GetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Set 写入 v 的指定字段；fields 为空时写入全部字段
+func (s *DBUserBaseInfoStore) Set(ctx context.Context, ida, idb uint64, v *DBUserBaseInfo, fields ...FieldDBUserBaseInfo) error {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return v.SetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...)
+}
+
+// Delete 删除指定字段（HDEL）；fields 为空时删除整个 key（DEL）
+func (s *DBUserBaseInfoStore) Delete(ctx context.Context, ida, idb uint64, fields ...FieldDBUserBaseInfo) error {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	key := redisKeyDBUserBaseInfo(s.REDBKey, ida, idb)
+	if len(fields) == 0 {
+		_, err = exec.Do(ctx, "DEL", key)
+		return err
+	}
+	args := []interface{}{key}
+	for _, fieldID := range fields {
+		args = append(args, uint32(fieldID))
+	}
+	_, err = exec.Do(ctx, "HDEL", args...)
+	return err
+}
+
+// Update 读-改-写：读取 fields（为空时全部字段）交给 fn 修改，再把同一组字段写回，返回写回后的值。
+// 读与写之间不加锁，并发修改同一字段时最后写入者胜出；fn 返回错误时不写回。
+func (s *DBUserBaseInfoStore) Update(ctx context.Context, ida, idb uint64, fn func(v *DBUserBaseInfo) error, fields ...FieldDBUserBaseInfo) (*DBUserBaseInfo, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	v := NewDBUserBaseInfo()
+	if err := v.GetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...); err != nil {
+		return nil, err
+	}
+	if err := fn(v); err != nil {
+		return nil, err
+	}
+	if err := v.SetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// IncrUserId 原子自增字段 UserId（HINCRBY），返回自增后的值
+func (s *DBUserBaseInfoStore) IncrUserId(ctx context.Context, ida, idb uint64, delta int64) (int32, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	v := NewDBUserBaseInfo()
+	if err := v.IncrUserIdExec(ctx, exec, s.REDBKey, ida, idb, delta); err != nil {
+		return 0, err
+	}
+	return v.UserId, nil
+}
+
+// IncrLevel 原子自增字段 Level（HINCRBY），返回自增后的值
+func (s *DBUserBaseInfoStore) IncrLevel(ctx context.Context, ida, idb uint64, delta int64) (int32, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	v := NewDBUserBaseInfo()
+	if err := v.IncrLevelExec(ctx, exec, s.REDBKey, ida, idb, delta); err != nil {
+		return 0, err
+	}
+	return v.Level, nil
+}
+
+// IncrExp 原子自增字段 Exp（HINCRBY），返回自增后的值
+func (s *DBUserBaseInfoStore) IncrExp(ctx context.Context, ida, idb uint64, delta int64) (int64, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	v := NewDBUserBaseInfo()
+	if err := v.IncrExpExec(ctx, exec, s.REDBKey, ida, idb, delta); err != nil {
+		return 0, err
+	}
+	return v.Exp, nil
+}
+
+// IncrBalance 原子自增字段 Balance（HINCRBYFLOAT），返回自增后的值
+func (s *DBUserBaseInfoStore) IncrBalance(ctx context.Context, ida, idb uint64, delta float64) (float32, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	v := NewDBUserBaseInfo()
+	if err := v.IncrBalanceExec(ctx, exec, s.REDBKey, ida, idb, delta); err != nil {
+		return 0, err
+	}
+	return v.Balance, nil
+}
+
+// IncrCoin 原子自增字段 Coin（HINCRBY），返回自增后的值
+func (s *DBUserBaseInfoStore) IncrCoin(ctx context.Context, ida, idb uint64, delta int64) (uint32, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	v := NewDBUserBaseInfo()
+	if err := v.IncrCoinExec(ctx, exec, s.REDBKey, ida, idb, delta); err != nil {
+		return 0, err
+	}
+	return v.Coin, nil
+}
+
+// IncrGem 原子自增字段 Gem（HINCRBY），返回自增后的值
+func (s *DBUserBaseInfoStore) IncrGem(ctx context.Context, ida, idb uint64, delta int64) (uint64, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	v := NewDBUserBaseInfo()
+	if err := v.IncrGemExec(ctx, exec, s.REDBKey, ida, idb, delta); err != nil {
+		return 0, err
+	}
+	return v.Gem, nil
+}
+
+// IncrScore 原子自增字段 Score（HINCRBYFLOAT），返回自增后的值
+func (s *DBUserBaseInfoStore) IncrScore(ctx context.Context, ida, idb uint64, delta float64) (float64, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	v := NewDBUserBaseInfo()
+	if err := v.IncrScoreExec(ctx, exec, s.REDBKey, ida, idb, delta); err != nil {
+		return 0, err
+	}
+	return v.Score, nil
+}
+
 // ---------- 字段级 protobuf 编码/解码模板块（MarshalRedisProto / UnmarshalRedisProto
 // 与集合字段的字段级序列化方法共用） ----------
 //
@@ -1267,6 +1656,11 @@ func NewDBUserBaseInfo_DBFriends() *DBUserBaseInfo_DBFriends {
 	return &DBUserBaseInfo_DBFriends{}
 }
 
+// redisKeyDBUserBaseInfo_DBFriends 按 key_format 生成 DBUserBaseInfo_DBFriends 对应的 Redis Hash key
+func redisKeyDBUserBaseInfo_DBFriends(REDBKey uint32, ida, idb uint64) string {
+	return fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+}
+
 // MarshalRedisProto 将 DBUserBaseInfo_DBFriends 序列化为 protobuf wire format 字节流。
 // 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
 // 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
@@ -1376,7 +1770,17 @@ func (p *DBUserBaseInfo_DBFriends) UnmarshalRedisProtoItems(b []byte) error {
 //	如果 fields 为空（长度为 0），则默认读取所有字段（即 FieldDBUserBaseInfo_DBFriendsIDs）
 //	集合字段（map/repeated）整体 protobuf 反序列化
 func (p *DBUserBaseInfo_DBFriends) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBFriends) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.GetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 HMGET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBFriends) GetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBFriends) error {
+	return p.GetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsExec 与 GetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBFriends) GetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBFriends) error {
+	key := redisKeyDBUserBaseInfo_DBFriends(REDBKey, ida, idb)
 
 	// 决定要操作的字段列表
 	fieldsToUse := fields
@@ -1387,19 +1791,19 @@ func (p *DBUserBaseInfo_DBFriends) GetFields(conn redis.Conn, REDBKey uint32, id
 	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
 	args := []interface{}{key}
 	for _, fieldID := range fieldsToUse {
-		args = append(args, fieldID)
+		args = append(args, uint32(fieldID))
 	}
 
 	// 一次 HMGET 获取所有字段值
-	reply, err := conn.Do("HMGET", args...)
+	reply, err := exec.Do(ctx, "HMGET", args...)
 	if err != nil {
-		return fmt.Errorf("HMGET 失败: %v", err)
+		return fmt.Errorf("HMGET 失败: %w", err)
 	}
 
 	// 解析返回的 []interface{} 列表
-	values, err := redis.Values(reply, nil)
-	if err != nil {
-		return fmt.Errorf("解析 HMGET 结果失败: %v", err)
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(fieldsToUse) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
 	}
 
 	// 逐一处理每个字段
@@ -1434,7 +1838,17 @@ func (p *DBUserBaseInfo_DBFriends) GetFields(conn redis.Conn, REDBKey uint32, id
 //	如果 fields 为空（长度为 0），则默认存储所有字段（即 FieldDBUserBaseInfo_DBFriendsIDs）
 //	集合字段（map/repeated）整体 protobuf 序列化后写入
 func (p *DBUserBaseInfo_DBFriends) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBFriends) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.SetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 HSET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBFriends) SetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBFriends) error {
+	return p.SetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsExec 与 SetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBFriends) SetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBFriends) error {
+	key := redisKeyDBUserBaseInfo_DBFriends(REDBKey, ida, idb)
 	args := []interface{}{key}
 
 	// 决定要操作的字段列表
@@ -1453,7 +1867,7 @@ func (p *DBUserBaseInfo_DBFriends) SetFields(conn redis.Conn, REDBKey uint32, id
 			if err != nil {
 				return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Items", err)
 			}
-			args = append(args, fieldID, b)
+			args = append(args, uint32(fieldID), b)
 
 		default:
 			return fmt.Errorf("未知字段编号: %d", fieldID)
@@ -1462,7 +1876,7 @@ func (p *DBUserBaseInfo_DBFriends) SetFields(conn redis.Conn, REDBKey uint32, id
 
 	// 所有字段统一一次 HSET 写入
 	if len(args) > 1 {
-		_, err := conn.Do("HSET", args...)
+		_, err := exec.Do(ctx, "HSET", args...)
 		return err
 	}
 	return nil
@@ -1497,6 +1911,11 @@ func NewDBUserBaseInfo_DBSettings() *DBUserBaseInfo_DBSettings {
 	return &DBUserBaseInfo_DBSettings{}
 }
 
+// redisKeyDBUserBaseInfo_DBSettings 按 key_format 生成 DBUserBaseInfo_DBSettings 对应的 Redis Hash key
+func redisKeyDBUserBaseInfo_DBSettings(REDBKey uint32, ida, idb uint64) string {
+	return fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+}
+
 // MarshalRedisProto 将 DBUserBaseInfo_DBSettings 序列化为 protobuf wire format 字节流。
 // 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
 // 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
@@ -1710,7 +2129,17 @@ func (p *DBUserBaseInfo_DBSettings) UnmarshalRedisProtoKv(b []byte) error {
 //	如果 fields 为空（长度为 0），则默认读取所有字段（即 FieldDBUserBaseInfo_DBSettingsIDs）
 //	集合字段（map/repeated）整体 protobuf 反序列化
 func (p *DBUserBaseInfo_DBSettings) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBSettings) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.GetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 HMGET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBSettings) GetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBSettings) error {
+	return p.GetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsExec 与 GetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBSettings) GetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBSettings) error {
+	key := redisKeyDBUserBaseInfo_DBSettings(REDBKey, ida, idb)
 
 	// 决定要操作的字段列表
 	fieldsToUse := fields
@@ -1721,19 +2150,19 @@ func (p *DBUserBaseInfo_DBSettings) GetFields(conn redis.Conn, REDBKey uint32, i
 	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
 	args := []interface{}{key}
 	for _, fieldID := range fieldsToUse {
-		args = append(args, fieldID)
+		args = append(args, uint32(fieldID))
 	}
 
 	// 一次 HMGET 获取所有字段值
-	reply, err := conn.Do("HMGET", args...)
+	reply, err := exec.Do(ctx, "HMGET", args...)
 	if err != nil {
-		return fmt.Errorf("HMGET 失败: %v", err)
+		return fmt.Errorf("HMGET 失败: %w", err)
 	}
 
 	// 解析返回的 []interface{} 列表
-	values, err := redis.Values(reply, nil)
-	if err != nil {
-		return fmt.Errorf("解析 HMGET 结果失败: %v", err)
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(fieldsToUse) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
 	}
 
 	// 逐一处理每个字段
@@ -1768,7 +2197,17 @@ func (p *DBUserBaseInfo_DBSettings) GetFields(conn redis.Conn, REDBKey uint32, i
 //	如果 fields 为空（长度为 0），则默认存储所有字段（即 FieldDBUserBaseInfo_DBSettingsIDs）
 //	集合字段（map/repeated）整体 protobuf 序列化后写入
 func (p *DBUserBaseInfo_DBSettings) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBSettings) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.SetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 HSET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBSettings) SetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBSettings) error {
+	return p.SetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsExec 与 SetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBSettings) SetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBSettings) error {
+	key := redisKeyDBUserBaseInfo_DBSettings(REDBKey, ida, idb)
 	args := []interface{}{key}
 
 	// 决定要操作的字段列表
@@ -1787,7 +2226,7 @@ func (p *DBUserBaseInfo_DBSettings) SetFields(conn redis.Conn, REDBKey uint32, i
 			if err != nil {
 				return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Kv", err)
 			}
-			args = append(args, fieldID, b)
+			args = append(args, uint32(fieldID), b)
 
 		default:
 			return fmt.Errorf("未知字段编号: %d", fieldID)
@@ -1796,7 +2235,7 @@ func (p *DBUserBaseInfo_DBSettings) SetFields(conn redis.Conn, REDBKey uint32, i
 
 	// 所有字段统一一次 HSET 写入
 	if len(args) > 1 {
-		_, err := conn.Do("HSET", args...)
+		_, err := exec.Do(ctx, "HSET", args...)
 		return err
 	}
 	return nil
@@ -1831,6 +2270,11 @@ func NewDBUserBaseInfo_DBInt32List() *DBUserBaseInfo_DBInt32List {
 	return &DBUserBaseInfo_DBInt32List{}
 }
 
+// redisKeyDBUserBaseInfo_DBInt32List 按 key_format 生成 DBUserBaseInfo_DBInt32List 对应的 Redis Hash key
+func redisKeyDBUserBaseInfo_DBInt32List(REDBKey uint32, ida, idb uint64) string {
+	return fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+}
+
 // MarshalRedisProto 将 DBUserBaseInfo_DBInt32List 序列化为 protobuf wire format 字节流。
 // 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
 // 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
@@ -1974,7 +2418,17 @@ func (p *DBUserBaseInfo_DBInt32List) UnmarshalRedisProtoItems(b []byte) error {
 //	如果 fields 为空（长度为 0），则默认读取所有字段（即 FieldDBUserBaseInfo_DBInt32ListIDs）
 //	集合字段（map/repeated）整体 protobuf 反序列化
 func (p *DBUserBaseInfo_DBInt32List) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBInt32List) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.GetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 HMGET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBInt32List) GetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBInt32List) error {
+	return p.GetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsExec 与 GetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBInt32List) GetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBInt32List) error {
+	key := redisKeyDBUserBaseInfo_DBInt32List(REDBKey, ida, idb)
 
 	// 决定要操作的字段列表
 	fieldsToUse := fields
@@ -1985,19 +2439,19 @@ func (p *DBUserBaseInfo_DBInt32List) GetFields(conn redis.Conn, REDBKey uint32,
 	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
 	args := []interface{}{key}
 	for _, fieldID := range fieldsToUse {
-		args = append(args, fieldID)
+		args = append(args, uint32(fieldID))
 	}
 
 	// 一次 HMGET 获取所有字段值
-	reply, err := conn.Do("HMGET", args...)
+	reply, err := exec.Do(ctx, "HMGET", args...)
 	if err != nil {
-		return fmt.Errorf("HMGET 失败: %v", err)
+		return fmt.Errorf("HMGET 失败: %w", err)
 	}
 
 	// 解析返回的 []interface{} 列表
-	values, err := redis.Values(reply, nil)
-	if err != nil {
-		return fmt.Errorf("解析 HMGET 结果失败: %v", err)
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(fieldsToUse) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
 	}
 
 	// 逐一处理每个字段
@@ -2032,7 +2486,17 @@ func (p *DBUserBaseInfo_DBInt32List) GetFields(conn redis.Conn, REDBKey uint32,
 //	如果 fields 为空（长度为 0），则默认存储所有字段（即 FieldDBUserBaseInfo_DBInt32ListIDs）
 //	集合字段（map/repeated）整体 protobuf 序列化后写入
 func (p *DBUserBaseInfo_DBInt32List) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBInt32List) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.SetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 HSET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBInt32List) SetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBInt32List) error {
+	return p.SetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsExec 与 SetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBInt32List) SetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBInt32List) error {
+	key := redisKeyDBUserBaseInfo_DBInt32List(REDBKey, ida, idb)
 	args := []interface{}{key}
 
 	// 决定要操作的字段列表
@@ -2051,7 +2515,7 @@ func (p *DBUserBaseInfo_DBInt32List) SetFields(conn redis.Conn, REDBKey uint32,
 			if err != nil {
 				return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Items", err)
 			}
-			args = append(args, fieldID, b)
+			args = append(args, uint32(fieldID), b)
 
 		default:
 			return fmt.Errorf("未知字段编号: %d", fieldID)
@@ -2060,7 +2524,7 @@ func (p *DBUserBaseInfo_DBInt32List) SetFields(conn redis.Conn, REDBKey uint32,
 
 	// 所有字段统一一次 HSET 写入
 	if len(args) > 1 {
-		_, err := conn.Do("HSET", args...)
+		_, err := exec.Do(ctx, "HSET", args...)
 		return err
 	}
 	return nil
@@ -2095,6 +2559,11 @@ func NewDBUserBaseInfo_DBWeapons() *DBUserBaseInfo_DBWeapons {
 	return &DBUserBaseInfo_DBWeapons{}
 }
 
+// redisKeyDBUserBaseInfo_DBWeapons 按 key_format 生成 DBUserBaseInfo_DBWeapons 对应的 Redis Hash key
+func redisKeyDBUserBaseInfo_DBWeapons(REDBKey uint32, ida, idb uint64) string {
+	return fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+}
+
 // MarshalRedisProto 将 DBUserBaseInfo_DBWeapons 序列化为 protobuf wire format 字节流。
 // 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
 // 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
@@ -2220,7 +2689,17 @@ func (p *DBUserBaseInfo_DBWeapons) UnmarshalRedisProtoItems(b []byte) error {
 //	如果 fields 为空（长度为 0），则默认读取所有字段（即 FieldDBUserBaseInfo_DBWeaponsIDs）
 //	集合字段（map/repeated）整体 protobuf 反序列化
 func (p *DBUserBaseInfo_DBWeapons) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeapons) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.GetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 HMGET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBWeapons) GetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeapons) error {
+	return p.GetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsExec 与 GetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBWeapons) GetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeapons) error {
+	key := redisKeyDBUserBaseInfo_DBWeapons(REDBKey, ida, idb)
 
 	// 决定要操作的字段列表
 	fieldsToUse := fields
@@ -2231,19 +2710,19 @@ func (p *DBUserBaseInfo_DBWeapons) GetFields(conn redis.Conn, REDBKey uint32, id
 	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
 	args := []interface{}{key}
 	for _, fieldID := range fieldsToUse {
-		args = append(args, fieldID)
+		args = append(args, uint32(fieldID))
 	}
 
 	// 一次 HMGET 获取所有字段值
-	reply, err := conn.Do("HMGET", args...)
+	reply, err := exec.Do(ctx, "HMGET", args...)
 	if err != nil {
-		return fmt.Errorf("HMGET 失败: %v", err)
+		return fmt.Errorf("HMGET 失败: %w", err)
 	}
 
 	// 解析返回的 []interface{} 列表
-	values, err := redis.Values(reply, nil)
-	if err != nil {
-		return fmt.Errorf("解析 HMGET 结果失败: %v", err)
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(fieldsToUse) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
 	}
 
 	// 逐一处理每个字段
@@ -2278,7 +2757,17 @@ func (p *DBUserBaseInfo_DBWeapons) GetFields(conn redis.Conn, REDBKey uint32, id
 //	如果 fields 为空（长度为 0），则默认存储所有字段（即 FieldDBUserBaseInfo_DBWeaponsIDs）
 //	集合字段（map/repeated）整体 protobuf 序列化后写入
 func (p *DBUserBaseInfo_DBWeapons) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeapons) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.SetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 HSET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBWeapons) SetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeapons) error {
+	return p.SetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsExec 与 SetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBWeapons) SetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeapons) error {
+	key := redisKeyDBUserBaseInfo_DBWeapons(REDBKey, ida, idb)
 	args := []interface{}{key}
 
 	// 决定要操作的字段列表
@@ -2297,7 +2786,7 @@ func (p *DBUserBaseInfo_DBWeapons) SetFields(conn redis.Conn, REDBKey uint32, id
 			if err != nil {
 				return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Items", err)
 			}
-			args = append(args, fieldID, b)
+			args = append(args, uint32(fieldID), b)
 
 		default:
 			return fmt.Errorf("未知字段编号: %d", fieldID)
@@ -2306,7 +2795,7 @@ func (p *DBUserBaseInfo_DBWeapons) SetFields(conn redis.Conn, REDBKey uint32, id
 
 	// 所有字段统一一次 HSET 写入
 	if len(args) > 1 {
-		_, err := conn.Do("HSET", args...)
+		_, err := exec.Do(ctx, "HSET", args...)
 		return err
 	}
 	return nil
@@ -2341,6 +2830,11 @@ func NewDBUserBaseInfo_DBWeaponMap() *DBUserBaseInfo_DBWeaponMap {
 	return &DBUserBaseInfo_DBWeaponMap{}
 }
 
+// redisKeyDBUserBaseInfo_DBWeaponMap 按 key_format 生成 DBUserBaseInfo_DBWeaponMap 对应的 Redis Hash key
+func redisKeyDBUserBaseInfo_DBWeaponMap(REDBKey uint32, ida, idb uint64) string {
+	return fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+}
+
 // MarshalRedisProto 将 DBUserBaseInfo_DBWeaponMap 序列化为 protobuf wire format 字节流。
 // 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
 // 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
@@ -2566,7 +3060,17 @@ func (p *DBUserBaseInfo_DBWeaponMap) UnmarshalRedisProtoItems(b []byte) error {
 //	如果 fields 为空（长度为 0），则默认读取所有字段（即 FieldDBUserBaseInfo_DBWeaponMapIDs）
 //	集合字段（map/repeated）整体 protobuf 反序列化
 func (p *DBUserBaseInfo_DBWeaponMap) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeaponMap) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.GetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 HMGET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBWeaponMap) GetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeaponMap) error {
+	return p.GetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsExec 与 GetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBWeaponMap) GetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeaponMap) error {
+	key := redisKeyDBUserBaseInfo_DBWeaponMap(REDBKey, ida, idb)
 
 	// 决定要操作的字段列表
 	fieldsToUse := fields
@@ -2577,19 +3081,19 @@ func (p *DBUserBaseInfo_DBWeaponMap) GetFields(conn redis.Conn, REDBKey uint32,
 	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
 	args := []interface{}{key}
 	for _, fieldID := range fieldsToUse {
-		args = append(args, fieldID)
+		args = append(args, uint32(fieldID))
 	}
 
 	// 一次 HMGET 获取所有字段值
-	reply, err := conn.Do("HMGET", args...)
+	reply, err := exec.Do(ctx, "HMGET", args...)
 	if err != nil {
-		return fmt.Errorf("HMGET 失败: %v", err)
+		return fmt.Errorf("HMGET 失败: %w", err)
 	}
 
 	// 解析返回的 []interface{} 列表
-	values, err := redis.Values(reply, nil)
-	if err != nil {
-		return fmt.Errorf("解析 HMGET 结果失败: %v", err)
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(fieldsToUse) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
 	}
 
 	// 逐一处理每个字段
@@ -2624,7 +3128,17 @@ func (p *DBUserBaseInfo_DBWeaponMap) GetFields(conn redis.Conn, REDBKey uint32,
 //	如果 fields 为空（长度为 0），则默认存储所有字段（即 FieldDBUserBaseInfo_DBWeaponMapIDs）
 //	集合字段（map/repeated）整体 protobuf 序列化后写入
 func (p *DBUserBaseInfo_DBWeaponMap) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeaponMap) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.SetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 HSET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBWeaponMap) SetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeaponMap) error {
+	return p.SetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsExec 与 SetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBWeaponMap) SetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBWeaponMap) error {
+	key := redisKeyDBUserBaseInfo_DBWeaponMap(REDBKey, ida, idb)
 	args := []interface{}{key}
 
 	// 决定要操作的字段列表
@@ -2643,7 +3157,7 @@ func (p *DBUserBaseInfo_DBWeaponMap) SetFields(conn redis.Conn, REDBKey uint32,
 			if err != nil {
 				return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "Items", err)
 			}
-			args = append(args, fieldID, b)
+			args = append(args, uint32(fieldID), b)
 
 		default:
 			return fmt.Errorf("未知字段编号: %d", fieldID)
@@ -2652,7 +3166,7 @@ func (p *DBUserBaseInfo_DBWeaponMap) SetFields(conn redis.Conn, REDBKey uint32,
 
 	// 所有字段统一一次 HSET 写入
 	if len(args) > 1 {
-		_, err := conn.Do("HSET", args...)
+		_, err := exec.Do(ctx, "HSET", args...)
 		return err
 	}
 	return nil
@@ -2693,6 +3207,11 @@ func NewDBUserBaseInfo_DBProfile() *DBUserBaseInfo_DBProfile {
 	return &DBUserBaseInfo_DBProfile{}
 }
 
+// redisKeyDBUserBaseInfo_DBProfile 按 key_format 生成 DBUserBaseInfo_DBProfile 对应的 Redis Hash key
+func redisKeyDBUserBaseInfo_DBProfile(REDBKey uint32, ida, idb uint64) string {
+	return fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+}
+
 // MarshalRedisProto 将 DBUserBaseInfo_DBProfile 序列化为 protobuf wire format 字节流。
 // 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
 // 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
@@ -2777,7 +3296,17 @@ func (p *DBUserBaseInfo_DBProfile) UnmarshalRedisProto(b []byte) error {
 //	如果 fields 为空（长度为 0），则默认读取所有字段（即 FieldDBUserBaseInfo_DBProfileIDs）
 //	集合字段（map/repeated）整体 protobuf 反序列化
 func (p *DBUserBaseInfo_DBProfile) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBProfile) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.GetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 HMGET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBProfile) GetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBProfile) error {
+	return p.GetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsExec 与 GetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBProfile) GetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBProfile) error {
+	key := redisKeyDBUserBaseInfo_DBProfile(REDBKey, ida, idb)
 
 	// 决定要操作的字段列表
 	fieldsToUse := fields
@@ -2788,19 +3317,19 @@ func (p *DBUserBaseInfo_DBProfile) GetFields(conn redis.Conn, REDBKey uint32, id
 	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
 	args := []interface{}{key}
 	for _, fieldID := range fieldsToUse {
-		args = append(args, fieldID)
+		args = append(args, uint32(fieldID))
 	}
 
 	// 一次 HMGET 获取所有字段值
-	reply, err := conn.Do("HMGET", args...)
+	reply, err := exec.Do(ctx, "HMGET", args...)
 	if err != nil {
-		return fmt.Errorf("HMGET 失败: %v", err)
+		return fmt.Errorf("HMGET 失败: %w", err)
 	}
 
 	// 解析返回的 []interface{} 列表
-	values, err := redis.Values(reply, nil)
-	if err != nil {
-		return fmt.Errorf("解析 HMGET 结果失败: %v", err)
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(fieldsToUse) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
 	}
 
 	// 逐一处理每个字段
@@ -2848,7 +3377,17 @@ func (p *DBUserBaseInfo_DBProfile) GetFields(conn redis.Conn, REDBKey uint32, id
 //	如果 fields 为空（长度为 0），则默认存储所有字段（即 FieldDBUserBaseInfo_DBProfileIDs）
 //	集合字段（map/repeated）整体 protobuf 序列化后写入
 func (p *DBUserBaseInfo_DBProfile) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBProfile) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.SetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 HSET（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBProfile) SetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBProfile) error {
+	return p.SetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsExec 与 SetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBUserBaseInfo_DBProfile) SetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBUserBaseInfo_DBProfile) error {
+	key := redisKeyDBUserBaseInfo_DBProfile(REDBKey, ida, idb)
 	args := []interface{}{key}
 
 	// 决定要操作的字段列表
@@ -2863,12 +3402,12 @@ func (p *DBUserBaseInfo_DBProfile) SetFields(conn redis.Conn, REDBKey uint32, id
 		case FieldDBUserBaseInfo_DBProfile_Nickname:
 
 			// --- 直存字段: Nickname ---
-			args = append(args, fieldID, p.Nickname)
+			args = append(args, uint32(fieldID), p.Nickname)
 
 		case FieldDBUserBaseInfo_DBProfile_Age:
 
 			// --- 直存字段: Age ---
-			args = append(args, fieldID, p.Age)
+			args = append(args, uint32(fieldID), p.Age)
 
 		default:
 			return fmt.Errorf("未知字段编号: %d", fieldID)
@@ -2877,12 +3416,53 @@ func (p *DBUserBaseInfo_DBProfile) SetFields(conn redis.Conn, REDBKey uint32, id
 
 	// 所有字段统一一次 HSET 写入
 	if len(args) > 1 {
-		_, err := conn.Do("HSET", args...)
+		_, err := exec.Do(ctx, "HSET", args...)
 		return err
 	}
 	return nil
 }
 
+// IncrAge 对字段 Age 执行 HINCRBY（服务端原子自增 delta），并把自增后的值写回 p.Age
+func (p *DBUserBaseInfo_DBProfile) IncrAge(conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrAgeExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrAgeCtx 与 IncrAge 相同，ctx 的截止时间与取消作用于 HINCRBY（经 redis.DoContext）
+func (p *DBUserBaseInfo_DBProfile) IncrAgeCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrAgeExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrAgeExec 与 IncrAgeCtx 相同，但经任意 RedisExecutor 执行
+func (p *DBUserBaseInfo_DBProfile) IncrAgeExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64) error {
+	// 字段的值在 int32 范围内，|delta| 超过 MaxUint32 时结果必然越界（也保证下面撤销用的 -delta 不溢出）
+	if delta < -math.MaxUint32 || delta > math.MaxUint32 {
+		return fmt.Errorf("字段 %s 的自增量 %d 超出 int32 范围", "Age", delta)
+	}
+	reply, err := exec.Do(ctx, "HINCRBY", redisKeyDBUserBaseInfo_DBProfile(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_DBProfile_Age), delta)
+	if err != nil {
+		return fmt.Errorf("HINCRBY 字段 %s 失败: %w", "Age", err)
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return fmt.Errorf("解析 HINCRBY 结果失败: 意外的回复 %T", reply)
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return redisUndoIncrDBUserBaseInfo_DBProfile_Age(ctx, exec, REDBKey, ida, idb, delta, fmt.Errorf("字段 %s 自增后的值 %d 超出 int32 范围", "Age", n))
+	}
+	p.Age = int32(n)
+	return nil
+}
+
+// redisUndoIncrDBUserBaseInfo_DBProfile_Age 在 HINCRBY 的结果超出字段类型范围时减回 delta：
+// 越界的值已写入服务端，不撤销则该记录之后的读取都会失败。自增可交换，期间其他调用的自增不受影响；ctx 已取消时仍执行
+func redisUndoIncrDBUserBaseInfo_DBProfile_Age(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64, cause error) error {
+	_, err := exec.Do(context.WithoutCancel(ctx), "HINCRBY", redisKeyDBUserBaseInfo_DBProfile(REDBKey, ida, idb), uint32(FieldDBUserBaseInfo_DBProfile_Age), -delta)
+	if err != nil {
+		return fmt.Errorf("%w，撤销自增失败（需人工修正）: %v", cause, err)
+	}
+	return fmt.Errorf("%w，已撤销本次自增", cause)
+}
+
 // ---------- 字段级 protobuf 编码/解码模板块（MarshalRedisProto / UnmarshalRedisProto
 // 与集合字段的字段级序列化方法共用） ----------
 //
@@ -2924,6 +3504,11 @@ func NewDBWeapon() *DBWeapon {
 	return &DBWeapon{}
 }
 
+// redisKeyDBWeapon 按 key_format 生成 DBWeapon 对应的 Redis Hash key
+func redisKeyDBWeapon(REDBKey uint32, ida, idb uint64) string {
+	return fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+}
+
 // MarshalRedisProto 将 DBWeapon 序列化为 protobuf wire format 字节流。
 // 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
 // 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
@@ -3027,7 +3612,17 @@ func (p *DBWeapon) UnmarshalRedisProto(b []byte) error {
 //	如果 fields 为空（长度为 0），则默认读取所有字段（即 FieldDBWeaponIDs）
 //	集合字段（map/repeated）整体 protobuf 反序列化
 func (p *DBWeapon) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBWeapon) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.GetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 HMGET（经 redis.DoContext）
+func (p *DBWeapon) GetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBWeapon) error {
+	return p.GetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsExec 与 GetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBWeapon) GetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBWeapon) error {
+	key := redisKeyDBWeapon(REDBKey, ida, idb)
 
 	// 决定要操作的字段列表
 	fieldsToUse := fields
@@ -3038,19 +3633,19 @@ func (p *DBWeapon) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, f
 	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
 	args := []interface{}{key}
 	for _, fieldID := range fieldsToUse {
-		args = append(args, fieldID)
+		args = append(args, uint32(fieldID))
 	}
 
 	// 一次 HMGET 获取所有字段值
-	reply, err := conn.Do("HMGET", args...)
+	reply, err := exec.Do(ctx, "HMGET", args...)
 	if err != nil {
-		return fmt.Errorf("HMGET 失败: %v", err)
+		return fmt.Errorf("HMGET 失败: %w", err)
 	}
 
 	// 解析返回的 []interface{} 列表
-	values, err := redis.Values(reply, nil)
-	if err != nil {
-		return fmt.Errorf("解析 HMGET 结果失败: %v", err)
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(fieldsToUse) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
 	}
 
 	// 逐一处理每个字段
@@ -3107,7 +3702,17 @@ func (p *DBWeapon) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, f
 //	如果 fields 为空（长度为 0），则默认存储所有字段（即 FieldDBWeaponIDs）
 //	集合字段（map/repeated）整体 protobuf 序列化后写入
 func (p *DBWeapon) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBWeapon) error {
-	key := fmt.Sprintf("REDB#%d:%d:%d", REDBKey, ida, idb)
+	return p.SetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 HSET（经 redis.DoContext）
+func (p *DBWeapon) SetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...FieldDBWeapon) error {
+	return p.SetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsExec 与 SetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *DBWeapon) SetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...FieldDBWeapon) error {
+	key := redisKeyDBWeapon(REDBKey, ida, idb)
 	args := []interface{}{key}
 
 	// 决定要操作的字段列表
@@ -3122,17 +3727,17 @@ func (p *DBWeapon) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, f
 		case FieldDBWeapon_Name:
 
 			// --- 直存字段: Name ---
-			args = append(args, fieldID, p.Name)
+			args = append(args, uint32(fieldID), p.Name)
 
 		case FieldDBWeapon_Damage:
 
 			// --- 直存字段: Damage ---
-			args = append(args, fieldID, p.Damage)
+			args = append(args, uint32(fieldID), p.Damage)
 
 		case FieldDBWeapon_Element:
 
 			// --- 直存字段: Element ---
-			args = append(args, fieldID, p.Element)
+			args = append(args, uint32(fieldID), p.Element)
 
 		default:
 			return fmt.Errorf("未知字段编号: %d", fieldID)
@@ -3141,12 +3746,176 @@ func (p *DBWeapon) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, f
 
 	// 所有字段统一一次 HSET 写入
 	if len(args) > 1 {
-		_, err := conn.Do("HSET", args...)
+		_, err := exec.Do(ctx, "HSET", args...)
 		return err
 	}
 	return nil
 }
 
+// IncrDamage 对字段 Damage 执行 HINCRBY（服务端原子自增 delta），并把自增后的值写回 p.Damage
+func (p *DBWeapon) IncrDamage(conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrDamageExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrDamageCtx 与 IncrDamage 相同，ctx 的截止时间与取消作用于 HINCRBY（经 redis.DoContext）
+func (p *DBWeapon) IncrDamageCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, delta int64) error {
+	return p.IncrDamageExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// IncrDamageExec 与 IncrDamageCtx 相同，但经任意 RedisExecutor 执行
+func (p *DBWeapon) IncrDamageExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64) error {
+	// 字段的值在 int32 范围内，|delta| 超过 MaxUint32 时结果必然越界（也保证下面撤销用的 -delta 不溢出）
+	if delta < -math.MaxUint32 || delta > math.MaxUint32 {
+		return fmt.Errorf("字段 %s 的自增量 %d 超出 int32 范围", "Damage", delta)
+	}
+	reply, err := exec.Do(ctx, "HINCRBY", redisKeyDBWeapon(REDBKey, ida, idb), uint32(FieldDBWeapon_Damage), delta)
+	if err != nil {
+		return fmt.Errorf("HINCRBY 字段 %s 失败: %w", "Damage", err)
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return fmt.Errorf("解析 HINCRBY 结果失败: 意外的回复 %T", reply)
+	}
+	if n < math.MinInt32 || n > math.MaxInt32 {
+		return redisUndoIncrDBWeapon_Damage(ctx, exec, REDBKey, ida, idb, delta, fmt.Errorf("字段 %s 自增后的值 %d 超出 int32 范围", "Damage", n))
+	}
+	p.Damage = int32(n)
+	return nil
+}
+
+// redisUndoIncrDBWeapon_Damage 在 HINCRBY 的结果超出字段类型范围时减回 delta：
+// 越界的值已写入服务端，不撤销则该记录之后的读取都会失败。自增可交换，期间其他调用的自增不受影响；ctx 已取消时仍执行
+func redisUndoIncrDBWeapon_Damage(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta int64, cause error) error {
+	_, err := exec.Do(context.WithoutCancel(ctx), "HINCRBY", redisKeyDBWeapon(REDBKey, ida, idb), uint32(FieldDBWeapon_Damage), -delta)
+	if err != nil {
+		return fmt.Errorf("%w，撤销自增失败（需人工修正）: %v", cause, err)
+	}
+	return fmt.Errorf("%w，已撤销本次自增", cause)
+}
+
+// DBWeaponStore 是绑定连接来源的 DBWeapon 存取入口：每次调用自行借出并归还连接，
+// REDBKey 在创建时固定（WithREDBKey 可切换），方法只需传 ida/idb。
+// 单元测试可用 NewDBWeaponStoreExec 注入自定义 RedisExecutor。
+type DBWeaponStore struct {
+	acquire redisAcquireFunc
+	REDBKey uint32
+}
+
+// NewDBWeaponStore 基于连接来源（如 *redis.Pool）创建 Store：每次调用 Get 一个连接，用完 Close 归还
+func NewDBWeaponStore(pool RedisConnSource, REDBKey uint32) *DBWeaponStore {
+	return &DBWeaponStore{acquire: redisPoolAcquire(pool), REDBKey: REDBKey}
+}
+
+// NewDBWeaponStoreExec 基于任意 RedisExecutor（自定义客户端、mock 等）创建 Store，不涉及连接借还
+func NewDBWeaponStoreExec(exec RedisExecutor, REDBKey uint32) *DBWeaponStore {
+	return &DBWeaponStore{acquire: redisExecAcquire(exec), REDBKey: REDBKey}
+}
+
+// DBWeaponRepository 是 DBWeapon 的数据访问接口，方法与 DBWeaponStore 一致。
+// 业务代码依赖该接口，生产环境传 DBWeaponStore，单元测试传 NewDBWeaponMemRepository()（mem=true）。
+type DBWeaponRepository interface {
+	Get(ctx context.Context, ida, idb uint64, fields ...FieldDBWeapon) (*DBWeapon, error)
+	Set(ctx context.Context, ida, idb uint64, v *DBWeapon, fields ...FieldDBWeapon) error
+	Delete(ctx context.Context, ida, idb uint64, fields ...FieldDBWeapon) error
+	Update(ctx context.Context, ida, idb uint64, fn func(v *DBWeapon) error, fields ...FieldDBWeapon) (*DBWeapon, error)
+	IncrDamage(ctx context.Context, ida, idb uint64, delta int64) (int32, error)
+}
+
+var _ DBWeaponRepository = (*DBWeaponStore)(nil)
+
+// NewDBWeaponMemRepository 返回基于内存的 DBWeaponRepository（不需要 Redis）。
+// 它就是运行在 NewRedisMemExecutor 上的 DBWeaponStore，读写、编解码与错误路径和真实 Redis 完全相同：
+// 未写入的字段读回零值、未知字段编号报错、数值解析失败报错。
+func NewDBWeaponMemRepository() DBWeaponRepository {
+	return NewDBWeaponStoreExec(NewRedisMemExecutor(), 0)
+}
+
+// WithREDBKey 返回绑定到另一个 REDBKey 的 Store（共享同一连接来源）
+func (s *DBWeaponStore) WithREDBKey(REDBKey uint32) *DBWeaponStore {
+	c := *s
+	c.REDBKey = REDBKey
+	return &c
+}
+
+// Get 读取 ida/idb 对应的 DBWeapon；fields 为空时读取全部字段，不存在的字段为零值
+func (s *DBWeaponStore) Get(ctx context.Context, ida, idb uint64, fields ...FieldDBWeapon) (*DBWeapon, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	v := NewDBWeapon()
+	if err := v.GetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Set 写入 v 的指定字段；fields 为空时写入全部字段
+func (s *DBWeaponStore) Set(ctx context.Context, ida, idb uint64, v *DBWeapon, fields ...FieldDBWeapon) error {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return v.SetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...)
+}
+
+// Delete 删除指定字段（HDEL）；fields 为空时删除整个 key（DEL）
+func (s *DBWeaponStore) Delete(ctx context.Context, ida, idb uint64, fields ...FieldDBWeapon) error {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	key := redisKeyDBWeapon(s.REDBKey, ida, idb)
+	if len(fields) == 0 {
+		_, err = exec.Do(ctx, "DEL", key)
+		return err
+	}
+	args := []interface{}{key}
+	for _, fieldID := range fields {
+		args = append(args, uint32(fieldID))
+	}
+	_, err = exec.Do(ctx, "HDEL", args...)
+	return err
+}
+
+// Update 读-改-写：读取 fields（为空时全部字段）交给 fn 修改，再把同一组字段写回，返回写回后的值。
+// 读与写之间不加锁，并发修改同一字段时最后写入者胜出；fn 返回错误时不写回。
+func (s *DBWeaponStore) Update(ctx context.Context, ida, idb uint64, fn func(v *DBWeapon) error, fields ...FieldDBWeapon) (*DBWeapon, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	v := NewDBWeapon()
+	if err := v.GetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...); err != nil {
+		return nil, err
+	}
+	if err := fn(v); err != nil {
+		return nil, err
+	}
+	if err := v.SetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// IncrDamage 原子自增字段 Damage（HINCRBY），返回自增后的值
+func (s *DBWeaponStore) IncrDamage(ctx context.Context, ida, idb uint64, delta int64) (int32, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	v := NewDBWeapon()
+	if err := v.IncrDamageExec(ctx, exec, s.REDBKey, ida, idb, delta); err != nil {
+		return 0, err
+	}
+	return v.Damage, nil
+}
+
 // ---------- 字段级 protobuf 编码/解码模板块（MarshalRedisProto / UnmarshalRedisProto
 // 与集合字段的字段级序列化方法共用） ----------
 //
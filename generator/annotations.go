@@ -0,0 +1,611 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/beijian128/protoc-gen-redis/redisopt"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fieldOptions 返回字段上的 (redisopt.field) 选项，未设置时返回 nil（getter 对 nil 安全，得到默认值）。
+// redisopt 包在插件内已注册，protoc 传来的扩展会被解析为 *redisopt.FieldOptions。
+func fieldOptions(f *protogen.Field) *redisopt.FieldOptions {
+	opts, _ := proto.GetExtension(f.Desc.Options(), redisopt.E_Field).(*redisopt.FieldOptions)
+	return opts
+}
+
+// messageOptions 返回 message 上的 (redisopt.message) 选项，未设置时返回 nil。
+func messageOptions(m *protogen.Message) *redisopt.MessageOptions {
+	opts, _ := proto.GetExtension(m.Desc.Options(), redisopt.E_Message).(*redisopt.MessageOptions)
+	return opts
+}
+
+// fileOptions 返回文件上的 (redisopt.file) 选项，未设置时返回 nil。
+func fileOptions(f *protogen.File) *redisopt.FileOptions {
+	opts, _ := proto.GetExtension(f.Desc.Options(), redisopt.E_File).(*redisopt.FileOptions)
+	return opts
+}
+
+// hashFieldName 返回字段在 Redis Hash 中的 field 名，按字段编号存储时返回 ""：
+// redis_name 优先；否则 message 的 hash_field（未设置时取文件级）为 HASH_FIELD_NAME 时用 proto 字段名。
+// 原生存储字段不占用 hash field，恒返回 ""。
+func hashFieldName(file *protogen.File, m *protogen.Message, f *protogen.Field) string {
+	opts := fieldOptions(f)
+	if opts.GetStorage() == redisopt.Storage_STORAGE_NATIVE {
+		return ""
+	}
+	if name := opts.GetRedisName(); name != "" {
+		return name
+	}
+	naming := messageOptions(m).GetHashField()
+	if naming == redisopt.HashFieldNaming_HASH_FIELD_DEFAULT {
+		naming = fileOptions(file).GetHashField()
+	}
+	if naming == redisopt.HashFieldNaming_HASH_FIELD_NAME {
+		return string(f.Desc.Name())
+	}
+	return ""
+}
+
+// tagFallback 报告 message 是否处于按名字存储的迁移窗口（文件级或 message 级 tag_fallback 任一开启）
+func tagFallback(file *protogen.File, m *protogen.Message) bool {
+	return fileOptions(file).GetTagFallback() || messageOptions(m).GetTagFallback()
+}
+
+// jsonEncoded 报告字段的值是否以 proto3 JSON 存入 Redis Hash：字段上的 encoding 优先，未设置时取 message 级；
+// 只作用于 message 字段与集合字段（map/repeated），原生存储字段与标量字段恒为 false。
+func jsonEncoded(m *protogen.Message, f *protogen.Field) bool {
+	if f.Desc.Cardinality() != protoreflect.Repeated && f.Message == nil {
+		return false
+	}
+	if fieldOptions(f).GetStorage() == redisopt.Storage_STORAGE_NATIVE {
+		return false
+	}
+	encoding := fieldOptions(f).GetEncoding()
+	if encoding == redisopt.ValueEncoding_VALUE_ENCODING_DEFAULT {
+		encoding = messageOptions(m).GetEncoding()
+	}
+	return encoding == redisopt.ValueEncoding_VALUE_ENCODING_JSON
+}
+
+// jsonCodec 报告文件中是否有 message 或字段设置了 encoding（含显式的 VALUE_ENCODING_PROTO）：
+// 设置了时为文件内全部 message 生成 JSON 编解码，读取 message / 集合字段时两种编码都接受，便于来回迁移。
+func jsonCodec(file *protogen.File) bool {
+	found := false
+	walkMessages(file.Messages, func(m *protogen.Message) {
+		if messageOptions(m).GetEncoding() != redisopt.ValueEncoding_VALUE_ENCODING_DEFAULT {
+			found = true
+		}
+		for _, f := range m.Fields {
+			if fieldOptions(f).GetEncoding() != redisopt.ValueEncoding_VALUE_ENCODING_DEFAULT {
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// enumStorage 返回单值枚举字段在 Redis Hash 中的存储形式：字段上的 enum_storage 优先，未设置时取 message 级；
+// 非枚举字段与 repeated 字段恒返回 ENUM_STORAGE_DEFAULT。
+func enumStorage(m *protogen.Message, f *protogen.Field) redisopt.EnumStorage {
+	if f.Enum == nil || f.Desc.Cardinality() == protoreflect.Repeated {
+		return redisopt.EnumStorage_ENUM_STORAGE_DEFAULT
+	}
+	storage := fieldOptions(f).GetEnumStorage()
+	if storage == redisopt.EnumStorage_ENUM_STORAGE_DEFAULT {
+		storage = messageOptions(m).GetEnumStorage()
+	}
+	return storage
+}
+
+// enumNameMaps 报告文件是否需要枚举的名字对照表（<Enum>_name / <Enum>_value）：设置了 encoding（JSON 编码以名字表示枚举）
+// 或有 message / 字段设置了 enum_storage。
+func enumNameMaps(file *protogen.File) bool {
+	found := jsonCodec(file)
+	walkMessages(file.Messages, func(m *protogen.Message) {
+		if messageOptions(m).GetEnumStorage() != redisopt.EnumStorage_ENUM_STORAGE_DEFAULT {
+			found = true
+		}
+		for _, f := range m.Fields {
+			if fieldOptions(f).GetEnumStorage() != redisopt.EnumStorage_ENUM_STORAGE_DEFAULT {
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// defaultCompressMinSize 是 compress_min_size 未设置时的压缩阈值（字节）：更小的值压缩收益有限，不值得额外的 CPU
+const defaultCompressMinSize = 512
+
+// compressMinSize 返回 message 字段与集合字段的压缩阈值（字节）：字段上的 compression 优先，未设置时取 message 级；
+// 不压缩（未设置、COMPRESSION_NONE、标量字段、原生存储字段）时返回 0。
+func compressMinSize(m *protogen.Message, f *protogen.Field) int {
+	if f.Desc.Cardinality() != protoreflect.Repeated && f.Message == nil {
+		return 0
+	}
+	if fieldOptions(f).GetStorage() == redisopt.Storage_STORAGE_NATIVE {
+		return 0
+	}
+	compression := fieldOptions(f).GetCompression()
+	if compression == redisopt.Compression_COMPRESSION_DEFAULT {
+		compression = messageOptions(m).GetCompression()
+	}
+	if compression != redisopt.Compression_COMPRESSION_FLATE {
+		return 0
+	}
+	size := fieldOptions(f).GetCompressMinSize()
+	if size == 0 {
+		size = messageOptions(m).GetCompressMinSize()
+	}
+	if size == 0 {
+		return defaultCompressMinSize
+	}
+	return int(size)
+}
+
+// compressCodec 报告文件中是否有 message 或字段设置了 compression（含显式的 COMPRESSION_NONE）：
+// 设置了时读取 message / 集合字段先识别压缩头，压缩与未压缩的值都接受，开启或关闭压缩都不需要迁移数据。
+func compressCodec(file *protogen.File) bool {
+	found := false
+	walkMessages(file.Messages, func(m *protogen.Message) {
+		if messageOptions(m).GetCompression() != redisopt.Compression_COMPRESSION_DEFAULT {
+			found = true
+		}
+		for _, f := range m.Fields {
+			if fieldOptions(f).GetCompression() != redisopt.Compression_COMPRESSION_DEFAULT {
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// sensitiveCodec 报告文件中是否有字段设置了 sensitive：设置了时生成 RedisKeyProvider 与 AES-GCM 加解密辅助函数。
+func sensitiveCodec(file *protogen.File) bool {
+	found := false
+	walkMessages(file.Messages, func(m *protogen.Message) {
+		for _, f := range m.Fields {
+			if fieldOptions(f).GetSensitive() {
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// fieldByProtoName 按 proto 字段名查找字段，不存在时返回 nil。
+func fieldByProtoName(m *protogen.Message, name string) *protogen.Field {
+	for _, f := range m.Fields {
+		if string(f.Desc.Name()) == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// zsetScoreKind 与 zsetMemberKind 列出 sorted set 表的分数字段与成员字段允许的类型。
+var (
+	zsetScoreKind  = map[string]bool{"int32": true, "int64": true, "uint32": true, "uint64": true, "float32": true, "float64": true}
+	zsetMemberKind = map[string]bool{"string": true, "int32": true, "int64": true, "uint32": true, "uint64": true}
+)
+
+// singularScalar 返回非 repeated 标量字段的 Go 类型（枚举、message、bytes 返回 ""）。
+func singularScalar(f *protogen.Field) string {
+	if f.Desc.Cardinality() == protoreflect.Repeated || f.Enum != nil || f.Message != nil || f.Desc.Kind() == protoreflect.BytesKind {
+		return ""
+	}
+	return scalarGoType(f.Desc.Kind())
+}
+
+// indexKeyParts 是 zset_index.key 模板中的占位符及其在生成代码中的 Go 表达式（key 函数的参数为 REDBKey、ida、idb）。
+var indexKeyParts = map[string]string{
+	"redbkey": "strconv.FormatUint(uint64(REDBKey), 10)",
+	"ida":     "strconv.FormatUint(ida, 10)",
+	"idb":     "strconv.FormatUint(idb, 10)",
+}
+
+// parseKeyTemplate 把 zset_index / unique_index 的 key 模板转换为拼接 key 的 Go 表达式，并记录模板是否引用了 {ida} / {idb}。
+// 如 "REDB#{redbkey}:rank" -> `"REDB#" + strconv.FormatUint(uint64(REDBKey), 10) + ":rank"`。
+func parseKeyTemplate(tmpl string) (*KeyTemplate, error) {
+	if tmpl == "" {
+		return nil, fmt.Errorf("key 不能为空")
+	}
+	var parts []string
+	var byIda, byIdb bool
+	for rest := tmpl; rest != ""; {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			parts = append(parts, strconv.Quote(rest))
+			break
+		}
+		if open > 0 {
+			parts = append(parts, strconv.Quote(rest[:open]))
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if rest[open] == '}' || end < 0 {
+			return nil, fmt.Errorf("key %q 的花括号不成对", tmpl)
+		}
+		name := rest[open+1 : open+end]
+		part, ok := indexKeyParts[name]
+		if !ok {
+			return nil, fmt.Errorf("key %q 引用了未知占位符 {%s}（可用 {redbkey}、{ida}、{idb}）", tmpl, name)
+		}
+		byIda = byIda || name == "ida"
+		byIdb = byIdb || name == "idb"
+		parts = append(parts, part)
+		rest = rest[open+end+1:]
+	}
+	return &KeyTemplate{Template: tmpl, Expr: strings.Join(parts, " + "), ByIda: byIda, ByIdb: byIdb}, nil
+}
+
+// nativeCollection 返回 STORAGE_NATIVE 字段所包裹的集合字段（包裹 message 的唯一字段）。
+// 字段不是"只含一个 map/repeated 字段的包裹 message"时返回 nil。
+func nativeCollection(f *protogen.Field) *protogen.Field {
+	if f.Desc.Cardinality() == protoreflect.Repeated || f.Message == nil {
+		return nil
+	}
+	if len(f.Message.Fields) != 1 {
+		return nil
+	}
+	inner := f.Message.Fields[0]
+	if inner.Desc.Cardinality() != protoreflect.Repeated {
+		return nil
+	}
+	return inner
+}
+
+// ValidateOptions 校验文件中 redisopt 选项的用法，返回全部违规（每条规则在每个 message / 字段上报告第一处），
+// 每个错误都是指向违规 message / 字段定义处的 *Error：
+//
+//  1. storage=STORAGE_NATIVE 只能用于包裹 message 字段（包裹 message 只含一个 map/repeated 字段）；
+//  2. unique 只能与 STORAGE_NATIVE 的 repeated 一起使用，且元素不能是 message
+//     （set 按编码后的字节去重，message 编码不保证唯一）；
+//  3. zset 只能用于顶层 message，score 须为数值字段、member 须为 string 或整型字段，两者不能相同，
+//     且 sorted set 表中不能有 STORAGE_NATIVE 字段（记录不对应 Hash key）；
+//  4. zset_index 只能用于 Hash 表（顶层、非 sorted set 表）的数值字段，key 模板只能引用 {redbkey}、{ida}、{idb}；
+//  5. unique_index 只能用于 Hash 表的 string 或整型字段，key 模板规则同上；
+//  6. storage=MESSAGE_STORAGE_BLOB 只能用于顶层、非 sorted set 表的 message，且其字段不能设置
+//     STORAGE_NATIVE、zset_index、unique_index（整条记录只有一个 string key，无法按字段维护）；
+//  7. 按名字存储的 hash field 在 message 内不能重名，也不能是十进制数字（会与字段编号 field 混淆），
+//     原生存储字段不占用 hash field，不能设置 redis_name；
+//  8. encoding 只能用于 Hash 表（顶层、非 sorted set 表、非 blob 存储的 message），字段上的 encoding 只能用于
+//     message 字段与集合字段（原生存储字段除外）；JSON 编码的字段引用到的 message / 枚举须在本文件中声明
+//     （JSON 编解码只为本文件的类型生成）；
+//  9. enum_storage 只能用于 Hash 表，字段上的 enum_storage 只能用于单值枚举字段，且枚举须在本文件中声明
+//     （名字对照表只为本文件的枚举生成）；
+//  10. compression / compress_min_size 只能用于 Hash 表，字段上只能用于 message 字段与集合字段（原生存储字段除外），
+//     compress_min_size 只能与 compression 一起设置（同一级或 message 级）；
+//  11. sensitive 只能用于 Hash 表的 string、bytes、message 与集合字段（原生存储字段除外），且不能与 unique_index
+//     同时设置（唯一索引以明文为 field）；
+//  12. message 上的 key_format 只能用于顶层 message，且须恰好含 3 个 %d（依次填入 REDBKey、ida、idb），不能有其他占位符。
+func ValidateOptions(file *protogen.File) []error {
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, m := range CollectMessages(file) {
+		add(validateZSet(m))
+		add(validateBlob(m))
+		add(validateHashNames(file, m))
+		add(validateEncoding(file, m))
+		add(validateEnumStorage(file, m))
+		add(validateCompression(m))
+		add(validateSensitive(m))
+		add(validateKeyFormat(m))
+		for _, f := range m.Fields {
+			add(validateNative(m, f))
+			add(validateIndex(m, f))
+			add(validateUnique(m, f))
+		}
+	}
+	return errs
+}
+
+// validateNative 校验字段上的 storage=STORAGE_NATIVE 与 unique 选项（见 ValidateOptions 第 1、2 条）。
+func validateNative(m *protogen.Message, f *protogen.Field) error {
+	opts := fieldOptions(f)
+	native := opts.GetStorage() == redisopt.Storage_STORAGE_NATIVE
+	inner := nativeCollection(f)
+	if native && inner == nil {
+		return errorAt(f.Desc, "message %q 的字段 %q 设置了 storage=STORAGE_NATIVE，但它不是包裹 message（只含一个 map/repeated 字段的 message）",
+			m.Desc.Name(), f.Desc.Name())
+	}
+	if !opts.GetUnique() {
+		return nil
+	}
+	switch {
+	case !native:
+		return errorAt(f.Desc, "message %q 的字段 %q 设置了 unique，但 unique 只能与 storage=STORAGE_NATIVE 一起使用",
+			m.Desc.Name(), f.Desc.Name())
+	case inner.Desc.IsMap():
+		return errorAt(f.Desc, "message %q 的字段 %q 包裹的是 map，unique 只适用于 repeated（map 的键本身唯一）",
+			m.Desc.Name(), f.Desc.Name())
+	case inner.Desc.Kind() == protoreflect.MessageKind:
+		return errorAt(f.Desc, "message %q 的字段 %q 的元素是 message，不能设置 unique（set 按编码字节去重，message 编码不保证唯一）",
+			m.Desc.Name(), f.Desc.Name())
+	}
+	return nil
+}
+
+// validateZSet 校验 message 上的 zset 选项（见 ValidateOptions 第 3 条）。
+func validateZSet(m *protogen.Message) error {
+	zset := messageOptions(m).GetZset()
+	if zset == nil {
+		return nil
+	}
+	if _, topLevel := m.Desc.Parent().(protoreflect.FileDescriptor); !topLevel {
+		return errorAt(m.Desc, "message %q 设置了 zset，但 zset 只能用于顶层 message", m.Desc.Name())
+	}
+	score := fieldByProtoName(m, zset.GetScore())
+	if score == nil || !zsetScoreKind[singularScalar(score)] {
+		return errorAt(m.Desc, "message %q 的 zset.score %q 必须是本 message 的数值字段（int32/int64/uint32/uint64/float/double）",
+			m.Desc.Name(), zset.GetScore())
+	}
+	member := fieldByProtoName(m, zset.GetMember())
+	if member == nil || !zsetMemberKind[singularScalar(member)] {
+		return errorAt(m.Desc, "message %q 的 zset.member %q 必须是本 message 的 string 或整型字段",
+			m.Desc.Name(), zset.GetMember())
+	}
+	if score == member {
+		return errorAt(m.Desc, "message %q 的 zset.score 与 zset.member 不能是同一个字段 %q", m.Desc.Name(), zset.GetScore())
+	}
+	for _, f := range m.Fields {
+		if fieldOptions(f).GetStorage() == redisopt.Storage_STORAGE_NATIVE {
+			return errorAt(f.Desc, "message %q 是 sorted set 表，字段 %q 不能设置 storage=STORAGE_NATIVE", m.Desc.Name(), f.Desc.Name())
+		}
+	}
+	return nil
+}
+
+// validateIndex 校验字段上的 zset_index 选项（见 ValidateOptions 第 4 条）。
+func validateIndex(m *protogen.Message, f *protogen.Field) error {
+	index := fieldOptions(f).GetZsetIndex()
+	if index == nil {
+		return nil
+	}
+	_, topLevel := m.Desc.Parent().(protoreflect.FileDescriptor)
+	if !topLevel || messageOptions(m).GetZset() != nil {
+		return errorAt(f.Desc, "message %q 的字段 %q 设置了 zset_index，但 zset_index 只能用于 Hash 表（顶层且不是 sorted set 表的 message）",
+			m.Desc.Name(), f.Desc.Name())
+	}
+	if !zsetScoreKind[singularScalar(f)] {
+		return errorAt(f.Desc, "message %q 的字段 %q 设置了 zset_index，但它不是数值字段（int32/int64/uint32/uint64/float/double）",
+			m.Desc.Name(), f.Desc.Name())
+	}
+	if _, err := parseKeyTemplate(index.GetKey()); err != nil {
+		return errorAt(f.Desc, "message %q 的字段 %q 的 zset_index: %v", m.Desc.Name(), f.Desc.Name(), err)
+	}
+	return nil
+}
+
+// validateUnique 校验字段上的 unique_index 选项（见 ValidateOptions 第 5 条）。
+func validateUnique(m *protogen.Message, f *protogen.Field) error {
+	unique := fieldOptions(f).GetUniqueIndex()
+	if unique == nil {
+		return nil
+	}
+	_, topLevel := m.Desc.Parent().(protoreflect.FileDescriptor)
+	if !topLevel || messageOptions(m).GetZset() != nil {
+		return errorAt(f.Desc, "message %q 的字段 %q 设置了 unique_index，但 unique_index 只能用于 Hash 表（顶层且不是 sorted set 表的 message）",
+			m.Desc.Name(), f.Desc.Name())
+	}
+	if !zsetMemberKind[singularScalar(f)] {
+		return errorAt(f.Desc, "message %q 的字段 %q 设置了 unique_index，但它不是 string 或整型字段", m.Desc.Name(), f.Desc.Name())
+	}
+	if _, err := parseKeyTemplate(unique.GetKey()); err != nil {
+		return errorAt(f.Desc, "message %q 的字段 %q 的 unique_index: %v", m.Desc.Name(), f.Desc.Name(), err)
+	}
+	return nil
+}
+
+// validateBlob 校验 message 上的 storage=MESSAGE_STORAGE_BLOB 选项（见 ValidateOptions 第 6 条）。
+func validateBlob(m *protogen.Message) error {
+	if messageOptions(m).GetStorage() != redisopt.MessageStorage_MESSAGE_STORAGE_BLOB {
+		return nil
+	}
+	_, topLevel := m.Desc.Parent().(protoreflect.FileDescriptor)
+	if !topLevel || messageOptions(m).GetZset() != nil {
+		return errorAt(m.Desc, "message %q 设置了 storage=MESSAGE_STORAGE_BLOB，但它只能用于顶层且不是 sorted set 表的 message", m.Desc.Name())
+	}
+	for _, f := range m.Fields {
+		opts := fieldOptions(f)
+		if opts.GetStorage() == redisopt.Storage_STORAGE_NATIVE || opts.GetZsetIndex() != nil || opts.GetUniqueIndex() != nil {
+			return errorAt(f.Desc, "message %q 是 blob 存储，字段 %q 不能设置 storage=STORAGE_NATIVE、zset_index 或 unique_index",
+				m.Desc.Name(), f.Desc.Name())
+		}
+	}
+	return nil
+}
+
+// validateHashNames 校验 message 中按名字存储的 hash field（见 ValidateOptions 第 7 条）。
+func validateHashNames(file *protogen.File, m *protogen.Message) error {
+	seen := make(map[string]string)
+	for _, f := range m.Fields {
+		if fieldOptions(f).GetRedisName() != "" && fieldOptions(f).GetStorage() == redisopt.Storage_STORAGE_NATIVE {
+			return errorAt(f.Desc, "message %q 的字段 %q 是原生存储字段（不占用 hash field），不能设置 redis_name", m.Desc.Name(), f.Desc.Name())
+		}
+		name := hashFieldName(file, m, f)
+		if name == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(name, 10, 64); err == nil {
+			return errorAt(f.Desc, "message %q 的字段 %q 的 hash field 名 %q 是数字，会与字段编号 field 混淆", m.Desc.Name(), f.Desc.Name(), name)
+		}
+		if other, ok := seen[name]; ok {
+			return errorAt(f.Desc, "message %q 的字段 %q 与 %q 的 hash field 名都是 %q", m.Desc.Name(), other, f.Desc.Name(), name)
+		}
+		seen[name] = string(f.Desc.Name())
+	}
+	return nil
+}
+
+// validateEncoding 校验 message 与字段上的 encoding 选项（见 ValidateOptions 第 8 条）。
+func validateEncoding(file *protogen.File, m *protogen.Message) error {
+	_, topLevel := m.Desc.Parent().(protoreflect.FileDescriptor)
+	hashTable := topLevel && messageOptions(m).GetZset() == nil &&
+		messageOptions(m).GetStorage() != redisopt.MessageStorage_MESSAGE_STORAGE_BLOB
+	if !hashTable && messageOptions(m).GetEncoding() != redisopt.ValueEncoding_VALUE_ENCODING_DEFAULT {
+		return errorAt(m.Desc, "message %q 设置了 encoding，但 encoding 只能用于 Hash 表（顶层且不是 sorted set 表、blob 存储的 message）", m.Desc.Name())
+	}
+	for _, f := range m.Fields {
+		if fieldOptions(f).GetEncoding() != redisopt.ValueEncoding_VALUE_ENCODING_DEFAULT {
+			switch {
+			case !hashTable:
+				return errorAt(f.Desc, "message %q 的字段 %q 设置了 encoding，但 encoding 只能用于 Hash 表（顶层且不是 sorted set 表、blob 存储的 message）",
+					m.Desc.Name(), f.Desc.Name())
+			case f.Desc.Cardinality() != protoreflect.Repeated && f.Message == nil:
+				return errorAt(f.Desc, "message %q 的字段 %q 设置了 encoding，但它不是 message 字段或集合字段（标量按十进制/原样存储）",
+					m.Desc.Name(), f.Desc.Name())
+			case fieldOptions(f).GetStorage() == redisopt.Storage_STORAGE_NATIVE:
+				return errorAt(f.Desc, "message %q 的字段 %q 是原生存储字段，不能设置 encoding", m.Desc.Name(), f.Desc.Name())
+			}
+		}
+		if !jsonEncoded(m, f) {
+			continue
+		}
+		if name := foreignType(file, f.Desc, make(map[protoreflect.FullName]bool)); name != "" {
+			return errorAt(f.Desc, "message %q 的字段 %q 以 JSON 编码，但引用了其他文件的类型 %q（JSON 编解码只为本文件的类型生成）",
+				m.Desc.Name(), f.Desc.Name(), name)
+		}
+	}
+	return nil
+}
+
+// foreignType 返回字段（含 map 值与 message 的各层字段）引用到的第一个不在 file 中声明的 message / 枚举的全名，都在本文件时返回 ""。
+func foreignType(file *protogen.File, f protoreflect.FieldDescriptor, seen map[protoreflect.FullName]bool) protoreflect.FullName {
+	if f.IsMap() {
+		f = f.MapValue()
+	}
+	switch {
+	case f.Enum() != nil:
+		if f.Enum().ParentFile().Path() != file.Desc.Path() {
+			return f.Enum().FullName()
+		}
+	case f.Message() != nil:
+		msg := f.Message()
+		if msg.ParentFile().Path() != file.Desc.Path() {
+			return msg.FullName()
+		}
+		if seen[msg.FullName()] {
+			return ""
+		}
+		seen[msg.FullName()] = true
+		for i := 0; i < msg.Fields().Len(); i++ {
+			if name := foreignType(file, msg.Fields().Get(i), seen); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// validateEnumStorage 校验 message 与字段上的 enum_storage 选项（见 ValidateOptions 第 9 条）。
+func validateEnumStorage(file *protogen.File, m *protogen.Message) error {
+	_, topLevel := m.Desc.Parent().(protoreflect.FileDescriptor)
+	hashTable := topLevel && messageOptions(m).GetZset() == nil &&
+		messageOptions(m).GetStorage() != redisopt.MessageStorage_MESSAGE_STORAGE_BLOB
+	if !hashTable && messageOptions(m).GetEnumStorage() != redisopt.EnumStorage_ENUM_STORAGE_DEFAULT {
+		return errorAt(m.Desc, "message %q 设置了 enum_storage，但 enum_storage 只能用于 Hash 表（顶层且不是 sorted set 表、blob 存储的 message）", m.Desc.Name())
+	}
+	for _, f := range m.Fields {
+		if fieldOptions(f).GetEnumStorage() != redisopt.EnumStorage_ENUM_STORAGE_DEFAULT {
+			switch {
+			case !hashTable:
+				return errorAt(f.Desc, "message %q 的字段 %q 设置了 enum_storage，但 enum_storage 只能用于 Hash 表（顶层且不是 sorted set 表、blob 存储的 message）",
+					m.Desc.Name(), f.Desc.Name())
+			case f.Enum == nil || f.Desc.Cardinality() == protoreflect.Repeated:
+				return errorAt(f.Desc, "message %q 的字段 %q 设置了 enum_storage，但它不是单值枚举字段", m.Desc.Name(), f.Desc.Name())
+			}
+		}
+		if enumStorage(m, f) != redisopt.EnumStorage_ENUM_STORAGE_DEFAULT && f.Enum.Desc.ParentFile().Path() != file.Desc.Path() {
+			return errorAt(f.Desc, "message %q 的字段 %q 设置了 enum_storage，但枚举 %q 在其他文件中声明（名字对照表只为本文件的枚举生成）",
+				m.Desc.Name(), f.Desc.Name(), f.Enum.Desc.FullName())
+		}
+	}
+	return nil
+}
+
+// validateCompression 校验 message 与字段上的 compression / compress_min_size 选项（见 ValidateOptions 第 10 条）。
+func validateCompression(m *protogen.Message) error {
+	_, topLevel := m.Desc.Parent().(protoreflect.FileDescriptor)
+	hashTable := topLevel && messageOptions(m).GetZset() == nil &&
+		messageOptions(m).GetStorage() != redisopt.MessageStorage_MESSAGE_STORAGE_BLOB
+	mopts := messageOptions(m)
+	if mopts.GetCompression() != redisopt.Compression_COMPRESSION_DEFAULT || mopts.GetCompressMinSize() != 0 {
+		if !hashTable {
+			return errorAt(m.Desc, "message %q 设置了 compression，但 compression 只能用于 Hash 表（顶层且不是 sorted set 表、blob 存储的 message）", m.Desc.Name())
+		}
+		if mopts.GetCompression() == redisopt.Compression_COMPRESSION_DEFAULT {
+			return errorAt(m.Desc, "message %q 设置了 compress_min_size，但没有设置 compression", m.Desc.Name())
+		}
+	}
+	for _, f := range m.Fields {
+		opts := fieldOptions(f)
+		if opts.GetCompression() == redisopt.Compression_COMPRESSION_DEFAULT && opts.GetCompressMinSize() == 0 {
+			continue
+		}
+		switch {
+		case !hashTable:
+			return errorAt(f.Desc, "message %q 的字段 %q 设置了 compression，但 compression 只能用于 Hash 表（顶层且不是 sorted set 表、blob 存储的 message）",
+				m.Desc.Name(), f.Desc.Name())
+		case f.Desc.Cardinality() != protoreflect.Repeated && f.Message == nil:
+			return errorAt(f.Desc, "message %q 的字段 %q 设置了 compression，但它不是 message 字段或集合字段", m.Desc.Name(), f.Desc.Name())
+		case opts.GetStorage() == redisopt.Storage_STORAGE_NATIVE:
+			return errorAt(f.Desc, "message %q 的字段 %q 是原生存储字段，不能设置 compression", m.Desc.Name(), f.Desc.Name())
+		case opts.GetCompression() == redisopt.Compression_COMPRESSION_DEFAULT && mopts.GetCompression() == redisopt.Compression_COMPRESSION_DEFAULT:
+			return errorAt(f.Desc, "message %q 的字段 %q 设置了 compress_min_size，但没有设置 compression", m.Desc.Name(), f.Desc.Name())
+		}
+	}
+	return nil
+}
+
+// validateSensitive 校验字段上的 sensitive 选项（见 ValidateOptions 第 11 条）。
+func validateSensitive(m *protogen.Message) error {
+	_, topLevel := m.Desc.Parent().(protoreflect.FileDescriptor)
+	hashTable := topLevel && messageOptions(m).GetZset() == nil &&
+		messageOptions(m).GetStorage() != redisopt.MessageStorage_MESSAGE_STORAGE_BLOB
+	for _, f := range m.Fields {
+		opts := fieldOptions(f)
+		if !opts.GetSensitive() {
+			continue
+		}
+		kind := f.Desc.Kind()
+		switch {
+		case !hashTable:
+			return errorAt(f.Desc, "message %q 的字段 %q 设置了 sensitive，但 sensitive 只能用于 Hash 表（顶层且不是 sorted set 表、blob 存储的 message）",
+				m.Desc.Name(), f.Desc.Name())
+		case f.Desc.Cardinality() != protoreflect.Repeated && f.Message == nil &&
+			kind != protoreflect.StringKind && kind != protoreflect.BytesKind:
+			return errorAt(f.Desc, "message %q 的字段 %q 设置了 sensitive，但它不是 string、bytes、message 或集合字段", m.Desc.Name(), f.Desc.Name())
+		case opts.GetStorage() == redisopt.Storage_STORAGE_NATIVE:
+			return errorAt(f.Desc, "message %q 的字段 %q 是原生存储字段，不能设置 sensitive", m.Desc.Name(), f.Desc.Name())
+		case opts.GetUniqueIndex() != nil:
+			return errorAt(f.Desc, "message %q 的字段 %q 设置了 sensitive，不能同时设置 unique_index（唯一索引以明文为 field）", m.Desc.Name(), f.Desc.Name())
+		}
+	}
+	return nil
+}
+
+// validateKeyFormat 校验 message 上的 key_format 选项（见 ValidateOptions 第 12 条）。
+func validateKeyFormat(m *protogen.Message) error {
+	keyFormat := messageOptions(m).GetKeyFormat()
+	if keyFormat == "" {
+		return nil
+	}
+	if _, topLevel := m.Desc.Parent().(protoreflect.FileDescriptor); !topLevel {
+		return errorAt(m.Desc, "message %q 设置了 key_format，但 key_format 只能用于顶层 message", m.Desc.Name())
+	}
+	verbs := strings.ReplaceAll(keyFormat, "%%", "")
+	if strings.Count(verbs, "%d") != 3 || strings.Count(verbs, "%") != 3 {
+		return errorAt(m.Desc, "message %q 的 key_format %q 须恰好含 3 个 %%d（依次填入 REDBKey、ida、idb），不能有其他占位符",
+			m.Desc.Name(), keyFormat)
+	}
+	return nil
+}
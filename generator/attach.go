@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/beijian128/protoc-gen-redis/redisopt"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// protoPackage 是 mode=attach 生成代码中 proto.Marshal / proto.Unmarshal 所在的包
+const protoPackage = protogen.GoImportPath("google.golang.org/protobuf/proto")
+
+// attachInfo 是 codeTemplateAttach 的数据：ProtoMarshal / ProtoUnmarshal 为经 g 解析的函数引用（登记 import），
+// message 没有 message 字段时为空，不引入 proto 包。
+type attachInfo struct {
+	MessageInfo
+	ProtoMarshal   string
+	ProtoUnmarshal string
+}
+
+// GenerateAttachCode 为一个顶层 message 生成 mode=attach 的 Redis 存取代码：GetFields/SetFields 等方法
+// 直接定义在 protoc-gen-go 生成的类型上，生成文件须与 .pb.go 在同一 Go 包中。
+func GenerateAttachCode(gen *protogen.Plugin, file *protogen.File, msg *protogen.Message, g *protogen.GeneratedFile, opts *Options) ([]byte, error) {
+	info := attachInfo{MessageInfo: buildMessageInfo(gen, file, msg, g, opts)}
+	for _, f := range info.Fields {
+		if f.IsMsg {
+			info.ProtoMarshal = g.QualifiedGoIdent(protoPackage.Ident("Marshal"))
+			info.ProtoUnmarshal = g.QualifiedGoIdent(protoPackage.Ident("Unmarshal"))
+			break
+		}
+	}
+
+	tmpl, err := template.New("redis_attach").Parse(codeTemplateAttach)
+	if err != nil {
+		return nil, fmt.Errorf("解析模板失败: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, info); err != nil {
+		return nil, fmt.Errorf("渲染模板失败: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// attachMethods 是 mode=attach 在 protoc-gen-go 类型上生成的读取方法，字段 fields 等的 getter（GetFields）会与之重名
+var attachMethods = []string{"Fields", "FieldsCtx", "FieldsExec"}
+
+// ValidateAttach 校验文件能否以 mode=attach 生成，返回全部问题（每个 message / 字段报告第一处），每个错误都是 *Error。
+// 该模式只生成 Hash 表的 GetFields/SetFields：顶层 message 不能是 sorted set 表或 blob 存储，不能处于 tag_fallback 迁移窗口；
+// 字段只能是单值的标量、枚举与 message（集合用包裹 message），不能属于 oneof（含 proto3 optional），
+// 不能设置 storage=STORAGE_NATIVE、zset_index、unique_index、encoding=VALUE_ENCODING_JSON、enum_storage、compression、sensitive，
+// 字段的 getter 也不能与生成的 GetFields/GetFieldsCtx/GetFieldsExec 重名。嵌套 message 随父字段整体序列化，不检查。
+func ValidateAttach(file *protogen.File) []error {
+	var errs []error
+	for _, m := range file.Messages {
+		var unsupported string
+		switch {
+		case messageOptions(m).GetZset() != nil:
+			unsupported = "zset（sorted set 表）"
+		case messageOptions(m).GetStorage() == redisopt.MessageStorage_MESSAGE_STORAGE_BLOB:
+			unsupported = "storage=MESSAGE_STORAGE_BLOB"
+		case tagFallback(file, m):
+			unsupported = "tag_fallback"
+		}
+		if unsupported != "" {
+			errs = append(errs, errorAt(m.Desc, "mode=attach 不支持 message %q 上的 %s", m.Desc.Name(), unsupported))
+		}
+		for _, f := range m.Fields {
+			if err := validateAttachField(m, f); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+// validateAttachField 校验顶层 message 的一个字段（见 ValidateAttach）。
+func validateAttachField(m *protogen.Message, f *protogen.Field) error {
+	for _, method := range attachMethods {
+		if f.GoName == method {
+			return errorAt(f.Desc, "mode=attach 下 message %q 的字段 %q 的 getter Get%s 与生成的方法重名，请重命名该字段",
+				m.Desc.Name(), f.Desc.Name(), method)
+		}
+	}
+	opts := fieldOptions(f)
+	var unsupported string
+	switch {
+	case f.Desc.IsList() || f.Desc.IsMap():
+		unsupported = "直接定义的 repeated/map（集合字段请用嵌套 message 包起来）"
+	case f.Oneof != nil:
+		unsupported = "oneof 与 optional 字段"
+	case opts.GetStorage() == redisopt.Storage_STORAGE_NATIVE:
+		unsupported = "storage=STORAGE_NATIVE"
+	case opts.GetZsetIndex() != nil:
+		unsupported = "zset_index"
+	case opts.GetUniqueIndex() != nil:
+		unsupported = "unique_index"
+	case jsonEncoded(m, f):
+		unsupported = "encoding=VALUE_ENCODING_JSON"
+	case enumStorage(m, f) != redisopt.EnumStorage_ENUM_STORAGE_DEFAULT:
+		unsupported = "enum_storage"
+	case compressMinSize(m, f) > 0:
+		unsupported = "compression"
+	case opts.GetSensitive():
+		unsupported = "sensitive"
+	default:
+		return nil
+	}
+	return errorAt(f.Desc, "mode=attach 不支持 message %q 的字段 %q 上的 %s", m.Desc.Name(), f.Desc.Name(), unsupported)
+}
@@ -0,0 +1,258 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/beijian128/protoc-gen-redis/redisopt"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// CompatIssue 是一处会使已有 Redis 数据读取失败或读错的 schema 变更。
+type CompatIssue struct {
+	Pos     Position // 变更所在的定义在新版本中的位置，插件参数 key_format 的变更没有位置
+	Subject string   // 变更所在的 message / 字段 / 枚举全名（如 "game.DBPlayer.level"），key_format 变更为 "key_format"
+	Detail  string
+}
+
+// String 返回 "文件:行:列: 全名: 变化"，没有位置时省略位置。
+func (i CompatIssue) String() string {
+	if i.Pos.Filename == "" {
+		return i.Subject + ": " + i.Detail
+	}
+	return i.Pos.String() + ": " + i.Subject + ": " + i.Detail
+}
+
+// LoadDescriptorSet 读取旧版本的 FileDescriptorSet（protoc --descriptor_set_out --include_imports 的输出），
+// 按插件请求的方式解析为 protogen 文件，供 CheckCompat 复用与生成代码相同的选项解析。
+func LoadDescriptorSet(path string) (*protogen.Plugin, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取旧版本 descriptor 失败: %v", err)
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(b, set); err != nil {
+		return nil, fmt.Errorf("解析旧版本 descriptor %s 失败: %v", path, err)
+	}
+	prev, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{ProtoFile: set.File})
+	if err != nil {
+		return nil, fmt.Errorf("解析旧版本 descriptor %s 失败（须用 --include_imports 生成）: %v", path, err)
+	}
+	return prev, nil
+}
+
+// CheckCompat 比较 file 与其旧版本 prev，按声明顺序返回会破坏已有 Redis 数据的变更：
+//
+//  1. 字段编号被复用或改名、单值与 repeated/map 互换、类型变化（标量与 message 互换、message / 枚举类型更换；
+//     int32→int64、uint32→uint64 两种扩宽除外，十进制与 protobuf varint 编码都兼容）；
+//  2. 枚举值被删除，枚举按名字存储（encoding / enum_storage）时枚举值改名；
+//  3. Hash 表的存储方式（Hash / blob / sorted set 表及其 score、member）、hash field 名（开启 tag_fallback 的
+//     编号→名字迁移除外）、原生存储方式、zset_index / unique_index 的 key 变化；
+//  4. 顶层 message 上的 key_format 选项变化（两个版本都设置时比较；插件参数 key_format 的变化见 compat_key_format）；
+//  5. 旧数据依赖的解码被去掉：JSON 编码、压缩的字段所在文件不再设置 encoding / compression，
+//     枚举不再按名字解析，敏感字段不再解密。
+//
+// 删除字段与 message 不影响已有数据的读取，不作为不兼容变更。
+func CheckCompat(prev, file *protogen.File) []CompatIssue {
+	var issues []CompatIssue
+	oldMessages := make(map[protoreflect.FullName]*protogen.Message)
+	for _, m := range CollectMessages(prev) {
+		oldMessages[m.Desc.FullName()] = m
+	}
+	for _, m := range CollectMessages(file) {
+		if old := oldMessages[m.Desc.FullName()]; old != nil {
+			issues = append(issues, compatMessage(prev, old, file, m)...)
+		}
+	}
+
+	oldEnums := make(map[protoreflect.FullName]*protogen.Enum)
+	for _, e := range fileEnums(prev) {
+		oldEnums[e.Desc.FullName()] = e
+	}
+	byName := enumNameMaps(prev)
+	for _, e := range fileEnums(file) {
+		if old := oldEnums[e.Desc.FullName()]; old != nil {
+			issues = append(issues, compatEnum(old, e, byName)...)
+		}
+	}
+	return issues
+}
+
+// fileEnums 返回文件中声明的全部枚举（顶层与嵌套在 message 中的）
+func fileEnums(file *protogen.File) []*protogen.Enum {
+	enums := append([]*protogen.Enum(nil), file.Enums...)
+	walkMessages(file.Messages, func(m *protogen.Message) {
+		enums = append(enums, m.Enums...)
+	})
+	return enums
+}
+
+// storageMode 返回 message 的存储方式描述：嵌套 message 为 ""（随外层整体编码）
+func storageMode(m *protogen.Message) string {
+	if _, topLevel := m.Desc.Parent().(protoreflect.FileDescriptor); !topLevel {
+		return ""
+	}
+	opts := messageOptions(m)
+	switch {
+	case opts.GetZset() != nil:
+		return fmt.Sprintf("sorted set 表（score: %s, member: %s）", opts.GetZset().GetScore(), opts.GetZset().GetMember())
+	case opts.GetStorage() == redisopt.MessageStorage_MESSAGE_STORAGE_BLOB:
+		return "blob 存储"
+	}
+	return "Hash 表"
+}
+
+func compatMessage(prevFile *protogen.File, old *protogen.Message, file *protogen.File, m *protogen.Message) []CompatIssue {
+	var issues []CompatIssue
+	add := func(desc protoreflect.Descriptor, format string, args ...interface{}) {
+		issues = append(issues, CompatIssue{Pos: PositionOf(desc), Subject: string(desc.FullName()), Detail: fmt.Sprintf(format, args...)})
+	}
+	oldMode, mode := storageMode(old), storageMode(m)
+	if oldMode != mode {
+		add(m.Desc, "存储方式由 %s 改为 %s", oldMode, mode)
+	}
+	hashTable := oldMode == "Hash 表" && mode == "Hash 表"
+	if oldKey, key := messageOptions(old).GetKeyFormat(), messageOptions(m).GetKeyFormat(); oldKey != "" && key != "" && oldKey != key {
+		add(m.Desc, "key_format 由 %q 改为 %q（已有数据的 key 不再匹配）", oldKey, key)
+	}
+
+	for _, of := range old.Fields {
+		f := fieldByNumber(m, of.Desc.Number())
+		if f == nil {
+			continue
+		}
+		if of.Desc.Name() != f.Desc.Name() {
+			add(f.Desc, "编号 %d 由字段 %q 改为 %q（复用编号或改名）", f.Desc.Number(), of.Desc.Name(), f.Desc.Name())
+			continue
+		}
+		// 只扩宽旧类型：int32→int64 兼容，int64→int32 旧值可能越界，仍视为不兼容
+		if oldShape, shape := fieldShape(of.Desc, false), fieldShape(f.Desc, false); oldShape != shape && fieldShape(of.Desc, true) != shape {
+			add(f.Desc, "类型由 %s 改为 %s", oldShape, shape)
+			continue
+		}
+		if !hashTable {
+			continue
+		}
+
+		oldName, name := hashFieldName(prevFile, old, of), hashFieldName(file, m, f)
+		if oldName != name && !(oldName == "" && tagFallback(file, m)) {
+			add(f.Desc, "hash field 由 %s 改为 %s（旧数据读不到；编号改为名字时可开启 tag_fallback）",
+				hashFieldLabel(of, oldName), hashFieldLabel(f, name))
+		}
+		oldOpts, opts := fieldOptions(of), fieldOptions(f)
+		if oldOpts.GetStorage() != opts.GetStorage() || oldOpts.GetUnique() != opts.GetUnique() {
+			add(f.Desc, "存储方式由 %s 改为 %s", nativeLabel(oldOpts), nativeLabel(opts))
+		}
+		if oldKey, key := oldOpts.GetZsetIndex().GetKey(), opts.GetZsetIndex().GetKey(); oldKey != "" && key != "" && oldKey != key {
+			add(f.Desc, "zset_index 的 key 由 %q 改为 %q（已有记录不在新索引中）", oldKey, key)
+		}
+		if oldKey, key := oldOpts.GetUniqueIndex().GetKey(), opts.GetUniqueIndex().GetKey(); oldKey != "" && key != "" && oldKey != key {
+			add(f.Desc, "unique_index 的 key 由 %q 改为 %q（已占用的值不在新索引中）", oldKey, key)
+		}
+		if jsonEncoded(old, of) && !jsonCodec(file) {
+			add(f.Desc, "旧数据以 JSON 编码，但新版本文件没有设置 encoding，不再识别 JSON（改为 VALUE_ENCODING_PROTO 而不是删除选项）")
+		}
+		if compressMinSize(old, of) > 0 && !compressCodec(file) {
+			add(f.Desc, "旧数据可能是压缩的，但新版本文件没有设置 compression，不再解压（改为 COMPRESSION_NONE 而不是删除选项）")
+		}
+		if enumStorage(old, of) == redisopt.EnumStorage_ENUM_STORAGE_NAME && enumStorage(m, f) == redisopt.EnumStorage_ENUM_STORAGE_DEFAULT {
+			add(f.Desc, "旧数据以枚举值名存储，但新版本不再按名字解析（改为 ENUM_STORAGE_NUMBER 而不是删除选项）")
+		}
+		if oldOpts.GetSensitive() && !opts.GetSensitive() {
+			add(f.Desc, "旧数据是加密的，但新版本去掉了 sensitive，不再解密")
+		}
+	}
+	return issues
+}
+
+// fieldByNumber 按字段编号查找字段，不存在时返回 nil。
+func fieldByNumber(m *protogen.Message, number protoreflect.FieldNumber) *protogen.Field {
+	for _, f := range m.Fields {
+		if f.Desc.Number() == number {
+			return f
+		}
+	}
+	return nil
+}
+
+// fieldShape 返回字段在存储上的类型描述，如 "int64"、"repeated string"、"map<int32, message game.DBMail>"。
+// widen 为 true 时把 int32 记作 int64、uint32 记作 uint64：旧值按扩宽后的类型读取不出错也不失真，
+// 只用于旧版本一侧（新类型等于扩宽后的旧类型时视为相同，反过来收窄不行）。
+func fieldShape(f protoreflect.FieldDescriptor, widen bool) string {
+	if f.IsMap() {
+		return "map<" + kindShape(f.MapKey(), widen) + ", " + kindShape(f.MapValue(), widen) + ">"
+	}
+	if f.Cardinality() == protoreflect.Repeated {
+		return "repeated " + kindShape(f, widen)
+	}
+	return kindShape(f, widen)
+}
+
+func kindShape(f protoreflect.FieldDescriptor, widen bool) string {
+	switch f.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "message " + string(f.Message().FullName())
+	case protoreflect.EnumKind:
+		return "enum " + string(f.Enum().FullName())
+	case protoreflect.Int32Kind:
+		if widen {
+			return protoreflect.Int64Kind.String()
+		}
+	case protoreflect.Uint32Kind:
+		if widen {
+			return protoreflect.Uint64Kind.String()
+		}
+	}
+	return f.Kind().String()
+}
+
+// hashFieldLabel 返回 hash field 的可读描述：按名字存储时为带引号的名字，否则为字段编号
+func hashFieldLabel(f *protogen.Field, name string) string {
+	if name != "" {
+		return strconv.Quote(name)
+	}
+	return "编号 " + strconv.Itoa(int(f.Desc.Number()))
+}
+
+// nativeLabel 返回字段存储方式的可读描述
+func nativeLabel(opts *redisopt.FieldOptions) string {
+	switch {
+	case opts.GetStorage() != redisopt.Storage_STORAGE_NATIVE:
+		return "整体存入 hash field"
+	case opts.GetUnique():
+		return "原生存储（set）"
+	}
+	return "原生存储"
+}
+
+func compatEnum(old, e *protogen.Enum, byName bool) []CompatIssue {
+	var issues []CompatIssue
+	for _, ov := range old.Values {
+		v := e.Desc.Values().ByNumber(ov.Desc.Number())
+		switch {
+		case v == nil:
+			issues = append(issues, CompatIssue{Pos: PositionOf(e.Desc), Subject: string(e.Desc.FullName()),
+				Detail: fmt.Sprintf("枚举值 %s = %d 被删除（已有数据中的该值不再有名字）", ov.Desc.Name(), ov.Desc.Number())})
+		case byName && e.Desc.Values().ByName(ov.Desc.Name()) == nil:
+			issues = append(issues, CompatIssue{Pos: PositionOf(v), Subject: string(e.Desc.FullName()),
+				Detail: fmt.Sprintf("枚举值 %d 由 %s 改名为 %s（旧数据按名字存储）", ov.Desc.Number(), ov.Desc.Name(), v.Name())})
+		}
+	}
+	return issues
+}
+
+// AllowBreaking 报告不兼容变更是否已由 allow_breaking 放行：参数为 "*"、变更所在的全名或其外层 message / 包名时放行。
+func (o *Options) AllowBreaking(issue CompatIssue) bool {
+	for _, allowed := range o.AllowedBreaking {
+		if allowed == "*" || issue.Subject == allowed || strings.HasPrefix(issue.Subject, allowed+".") {
+			return true
+		}
+	}
+	return false
+}
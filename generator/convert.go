@@ -0,0 +1,154 @@
+package generator
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// pbImportPath 返回 proto 文件对应的 protoc-gen-go 生成代码的 Go 导入路径（pb_package 参数），未指定时返回 ""。
+func (o *Options) pbImportPath(file protoreflect.FileDescriptor) protogen.GoImportPath {
+	if path, ok := o.PBPackages[file.Path()]; ok {
+		return protogen.GoImportPath(path)
+	}
+	return protogen.GoImportPath(o.PBPackage)
+}
+
+// Convert 报告是否设置了 pb_package（生成与 protoc-gen-go 类型之间的转换函数）。
+func (o *Options) Convert() bool {
+	return o.PBPackage != "" || len(o.PBPackages) > 0
+}
+
+// converter 为一个 message 的字段计算 ToProto / From<Message>Proto 中的转换语句；
+// 生成代码的类型与 protoc-gen-go 的类型都经 g 解析，跨包引用会登记 import。
+type converter struct {
+	g    *protogen.GeneratedFile
+	opts *Options
+}
+
+// pbType 返回 message / 枚举在 protoc-gen-go 生成代码中的类型名（与生成代码同名，包为 pb_package）
+func (c converter) pbType(desc protoreflect.Descriptor, ident protogen.GoIdent) string {
+	return c.g.QualifiedGoIdent(protogen.GoIdent{GoName: ident.GoName, GoImportPath: c.opts.pbImportPath(desc.ParentFile())})
+}
+
+// fromFunc 返回 message 的 From<Message>Proto 函数名（与 message 的生成代码同包）
+func (c converter) fromFunc(m *protogen.Message) string {
+	return c.g.QualifiedGoIdent(protogen.GoIdent{GoName: "From" + m.GoIdent.GoName + "Proto", GoImportPath: m.GoIdent.GoImportPath})
+}
+
+// toValue / fromValue 返回单个值（字段、集合元素或 map 值）v 转换到 protoc-gen-go 类型 / 从其转换回来的表达式，
+// f 为描述该值类型的字段（map 值为 map entry 的 value 字段）。
+func (c converter) toValue(f *protogen.Field, v string) string {
+	switch {
+	case f.Message != nil:
+		return v + ".ToProto()"
+	case f.Enum != nil:
+		return c.pbType(f.Enum.Desc, f.Enum.GoIdent) + "(" + v + ")"
+	}
+	return v
+}
+
+func (c converter) fromValue(f *protogen.Field, v string) string {
+	switch {
+	case f.Message != nil:
+		return "*" + c.fromFunc(f.Message) + "(" + v + ")"
+	case f.Enum != nil:
+		return c.g.QualifiedGoIdent(f.Enum.GoIdent) + "(" + v + ")"
+	}
+	return v
+}
+
+// convertField 返回字段在 ToProto（p -> m）与 From<Message>Proto（m -> p）中的转换语句。
+// message 字段：生成代码中为值，protoc-gen-go 中为指针，nil 转为空 message；
+// proto3 optional 的标量与枚举在 protoc-gen-go 中为指针，ToProto 总是设置（生成代码不记录是否设置过）。
+func (c converter) convertField(f *protogen.Field) (to, from string) {
+	name := f.GoName
+	switch {
+	case f.Desc.IsMap():
+		key, val := f.Message.Fields[0], f.Message.Fields[1]
+		elemTo, elemFrom := c.toValue(val, "v"), c.fromValue(val, "v")
+		to = fmt.Sprintf("if p.%[1]s != nil {\n\tm.%[1]s = make(map[%[2]s]%[3]s, len(p.%[1]s))\n\tfor k, v := range p.%[1]s {\n\t\tm.%[1]s[k] = %[4]s\n\t}\n}",
+			name, mapKeyType(key.Desc.Kind()), c.pbElemType(val), elemTo)
+		from = fmt.Sprintf("if m.%[1]s != nil {\n\tp.%[1]s = make(map[%[2]s]%[3]s, len(m.%[1]s))\n\tfor k, v := range m.%[1]s {\n\t\tp.%[1]s[k] = %[4]s\n\t}\n}",
+			name, mapKeyType(key.Desc.Kind()), c.elemType(val), elemFrom)
+	case f.Desc.IsList() && f.Message == nil && f.Enum == nil:
+		to = fmt.Sprintf("m.%[1]s = append(m.%[1]s, p.%[1]s...)", name)
+		from = fmt.Sprintf("p.%[1]s = append(p.%[1]s, m.%[1]s...)", name)
+	case f.Desc.IsList():
+		to = fmt.Sprintf("for i := range p.%[1]s {\n\tm.%[1]s = append(m.%[1]s, %[2]s)\n}", name, c.toValue(f, "p."+name+"[i]"))
+		from = fmt.Sprintf("for _, v := range m.%[1]s {\n\tp.%[1]s = append(p.%[1]s, %[2]s)\n}", name, c.fromValue(f, "v"))
+	case f.Desc.HasOptionalKeyword() && f.Message == nil && f.Desc.Kind() != protoreflect.BytesKind:
+		to = fmt.Sprintf("{\n\tv := %s\n\tm.%s = &v\n}", c.toValue(f, "p."+name), name)
+		from = fmt.Sprintf("p.%s = %s", name, c.fromValue(f, "m.Get"+name+"()"))
+	default:
+		to = fmt.Sprintf("m.%s = %s", name, c.toValue(f, "p."+name))
+		from = fmt.Sprintf("p.%s = %s", name, c.fromValue(f, "m."+name))
+	}
+	return to, from
+}
+
+// elemType / pbElemType 返回 map 值在生成代码 / protoc-gen-go 中的 Go 类型
+func (c converter) elemType(f *protogen.Field) string {
+	switch {
+	case f.Message != nil:
+		return c.g.QualifiedGoIdent(f.Message.GoIdent)
+	case f.Enum != nil:
+		return c.g.QualifiedGoIdent(f.Enum.GoIdent)
+	case f.Desc.Kind() == protoreflect.BytesKind:
+		return "[]byte"
+	}
+	return scalarGoType(f.Desc.Kind())
+}
+
+func (c converter) pbElemType(f *protogen.Field) string {
+	switch {
+	case f.Message != nil:
+		return "*" + c.pbType(f.Message.Desc, f.Message.GoIdent)
+	case f.Enum != nil:
+		return c.pbType(f.Enum.Desc, f.Enum.GoIdent)
+	}
+	return c.elemType(f)
+}
+
+// ValidateConvert 校验设置了 pb_package 的文件能否生成转换函数，返回全部问题（每个字段报告第一处），每个错误都是 *Error：
+// pb_package 不能是生成代码自己的导入路径；不支持真正的 oneof（protoc-gen-go 中为接口，生成代码中是各自独立的字段，
+// 无法确定设置了哪一个；proto3 optional 支持）；字段引用的其他文件的 message / 枚举也须指定 pb_package。
+func ValidateConvert(file *protogen.File, opts *Options) []error {
+	pb := opts.pbImportPath(file.Desc)
+	if pb == "" {
+		return nil
+	}
+	if pb == file.GoImportPath {
+		return []error{errorAt(file.Desc, "pb_package %q 与生成代码的导入路径相同（go_package），protoc-gen-go 的类型须在另一个包中", pb)}
+	}
+	var errs []error
+	for _, m := range CollectMessages(file) {
+		for _, f := range m.Fields {
+			if f.Oneof != nil && !f.Oneof.Desc.IsSynthetic() {
+				errs = append(errs, errorAt(f.Desc, "message %q 的字段 %q 属于 oneof %q，pb_package 不支持 oneof（proto3 optional 除外）",
+					m.Desc.Name(), f.Desc.Name(), f.Oneof.Desc.Name()))
+				continue
+			}
+			if ref := convertRef(f); ref != nil && opts.pbImportPath(ref.ParentFile()) == "" {
+				errs = append(errs, errorAt(f.Desc, "message %q 的字段 %q 引用的 %s 所在的 %s 没有指定 pb_package（pb_package=%s=<导入路径>）",
+					m.Desc.Name(), f.Desc.Name(), ref.FullName(), ref.ParentFile().Path(), ref.ParentFile().Path()))
+			}
+		}
+	}
+	return errs
+}
+
+// convertRef 返回字段（map 为其值）引用的 message / 枚举，标量字段返回 nil
+func convertRef(f *protogen.Field) protoreflect.Descriptor {
+	if f.Desc.IsMap() {
+		f = f.Message.Fields[1]
+	}
+	switch {
+	case f.Message != nil:
+		return f.Message.Desc
+	case f.Enum != nil:
+		return f.Enum.Desc
+	}
+	return nil
+}
@@ -4,27 +4,50 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
+	"github.com/beijian128/protoc-gen-redis/redisopt"
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 // DefaultKeyFormat 是生成 Redis key 的默认格式，依次填入 REDBKey、ida、idb。
-// 可通过 --redis_opt=key_format=... 覆盖。
+// 可通过 --redis_opt=key_format=... 覆盖（见 Options）。
 const DefaultKeyFormat = "REDB#%d:%d:%d"
 
 // GenerateRedisCode 为一个 message 生成 Redis 存取代码。
-func GenerateRedisCode(gen *protogen.Plugin, file *protogen.File, msg *protogen.Message, g *protogen.GeneratedFile, keyFormat string) ([]byte, error) {
+func GenerateRedisCode(gen *protogen.Plugin, file *protogen.File, msg *protogen.Message, g *protogen.GeneratedFile, opts *Options) ([]byte, error) {
+	info := buildMessageInfo(gen, file, msg, g, opts)
+
+	tmpl, err := template.New("redis_code").Parse(codeTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("解析模板失败: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, info); err != nil {
+		return nil, fmt.Errorf("渲染模板失败: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// buildMessageInfo 由 message 的定义与选项计算模板使用的 MessageInfo（生成代码与 manifest 共用）。
+// message / 枚举类型名经 g 解析，跨包引用会登记到 g 的 import 中。
+func buildMessageInfo(gen *protogen.Plugin, file *protogen.File, msg *protogen.Message, g *protogen.GeneratedFile, opts *Options) MessageInfo {
 	fieldTypes := resolveFieldTypeNames(CollectMessages(file))
+	convert := opts.Mode != ModeAttach && opts.pbImportPath(file.Desc) != ""
 
 	fields := make([]FieldInfo, 0, len(msg.Fields))
 	for _, field := range msg.Fields {
 		ft := fieldTypeFor(gen, g, field)
-		fields = append(fields, FieldInfo{
+		info := FieldInfo{
 			Name:       field.GoName,
+			ProtoName:  string(field.Desc.Name()),
 			ProtoTag:   int(field.Desc.Number()),
+			ProtoType:  fieldShape(field.Desc, false),
 			GoType:     ft.goType,
 			Kind:       ft.kind,
 			KeyType:    ft.keyType,
@@ -33,28 +56,106 @@ func GenerateRedisCode(gen *protogen.Plugin, file *protogen.File, msg *protogen.
 			ElemIsEnum: ft.elemIsEnum,
 			IsMsg:      ft.wholeMsg,
 			IsEnum:     ft.isEnum,
-		})
+		}
+		if inner := nativeCollection(field); inner != nil {
+			info.Wrapped = fieldShape(inner.Desc, false)
+		}
+		if fieldOptions(field).GetStorage() == redisopt.Storage_STORAGE_NATIVE {
+			setNative(gen, g, &info, field)
+		}
+		// ValidateOptions 已校验 key 模板
+		if index := fieldOptions(field).GetZsetIndex(); index != nil {
+			info.Index, _ = parseKeyTemplate(index.GetKey())
+		}
+		if unique := fieldOptions(field).GetUniqueIndex(); unique != nil {
+			info.Unique, _ = parseKeyTemplate(unique.GetKey())
+		}
+		info.JSON = jsonEncoded(msg, field)
+		info.JSONName = field.Desc.JSONName()
+		info.JSONCases = strconv.Quote(info.JSONName)
+		if name := string(field.Desc.Name()); name != info.JSONName {
+			info.JSONCases += ", " + strconv.Quote(name)
+		}
+		switch enumStorage(msg, field) {
+		case redisopt.EnumStorage_ENUM_STORAGE_NAME:
+			info.EnumStorage = "name"
+		case redisopt.EnumStorage_ENUM_STORAGE_NUMBER:
+			info.EnumStorage = "number"
+		}
+		info.CompressMinSize = compressMinSize(msg, field)
+		if fieldOptions(field).GetSensitive() {
+			info.Sensitive = true
+			info.SensitiveAAD = string(field.Desc.FullName())
+		}
+		if convert {
+			info.ToProto, info.FromProto = converter{g: g, opts: opts}.convertField(field)
+		}
+		info.Perf = opts.Perf
+		info.HashName = hashFieldName(file, msg, field)
+		if info.HashName != "" {
+			info.HashField = strconv.Quote(info.HashName)
+		} else {
+			info.HashField = fmt.Sprintf("uint32(%s_%s)", fieldTypes[msg], field.GoName)
+		}
+		fields = append(fields, info)
 	}
 
+	_, topLevel := msg.Desc.Parent().(protoreflect.FileDescriptor)
 	info := MessageInfo{
 		PackageName: string(file.GoPackageName),
 		MessageName: string(msg.GoIdent.GoName),
+		FullName:    string(msg.Desc.FullName()),
 		FieldType:   fieldTypes[msg],
 		Fields:      fields,
-		KeyFormat:   keyFormat,
+		TopLevel:    topLevel,
+		KeyFormat:   opts.KeyFormat,
+		Executor:    opts.Executor,
 	}
-
-	tmpl, err := template.New("redis_code").Parse(codeTemplate)
-	if err != nil {
-		return nil, fmt.Errorf("解析模板失败: %v", err)
+	if keyFormat := messageOptions(msg).GetKeyFormat(); keyFormat != "" && topLevel {
+		info.KeyFormat = keyFormat
 	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, info); err != nil {
-		return nil, fmt.Errorf("渲染模板失败: %v", err)
+	info.Blob = topLevel && messageOptions(msg).GetStorage() == redisopt.MessageStorage_MESSAGE_STORAGE_BLOB
+	info.TagFallback = info.HasNamed() && tagFallback(file, msg)
+	if convert {
+		info.PBType = converter{g: g, opts: opts}.pbType(msg.Desc, msg.GoIdent)
+	}
+	info.TableCodec = opts.Codec == CodecTable
+	info.Perf = opts.Perf
+	info.Mem = opts.Mem
+	info.JSONCodec = jsonCodec(file)
+	info.Compressed = compressCodec(file)
+	if zset := messageOptions(msg).GetZset(); zset != nil && topLevel {
+		// ValidateOptions 已保证两个字段存在且类型合法
+		score, member := fieldByProtoName(msg, zset.GetScore()), fieldByProtoName(msg, zset.GetMember())
+		info.ZSet = &ZSetInfo{
+			Score:      score.GoName,
+			ScoreType:  singularScalar(score),
+			Member:     member.GoName,
+			MemberType: NativeType{GoType: singularScalar(member)},
+		}
 	}
+	return info
+}
 
-	return buf.Bytes(), nil
+// setNative 为 storage=STORAGE_NATIVE 的包裹 message 字段填充原生存储信息（ValidateOptions 已保证字段形态合法）：
+// map 存为 hash，repeated 存为 list，unique 的 repeated 存为 set。
+func setNative(gen *protogen.Plugin, g *protogen.GeneratedFile, info *FieldInfo, field *protogen.Field) {
+	inner := nativeCollection(field)
+	info.NativeItems = inner.GoName
+	info.NativeOwner = string(field.Parent.GoIdent.GoName)
+	elemDesc := inner.Desc
+	switch {
+	case inner.Desc.IsMap():
+		info.Native = "hash"
+		info.NativeKey = NativeType{GoType: mapKeyType(inner.Desc.MapKey().Kind())}
+		elemDesc = inner.Desc.MapValue()
+	case fieldOptions(field).GetUnique():
+		info.Native = "set"
+	default:
+		info.Native = "list"
+	}
+	elemType, isMsg, isEnum := elemTypeFor(gen, g, elemDesc)
+	info.NativeElem = NativeType{GoType: elemType, IsMsg: isMsg, IsEnum: isEnum}
 }
 
 // resolveFieldTypeNames 为每个 message 确定"字段编号类型"的名字（默认 Field<MessageName>）。
@@ -93,39 +194,36 @@ func resolveFieldTypeNames(messages []*protogen.Message) map[*protogen.Message]s
 	return names
 }
 
-// GenerateRedisCodeHeadWithEnums 生成文件头（package、imports、protobuf wire 辅助函数）
-// 以及本文件内声明的全部枚举。
-// 枚举只取当前 proto 文件声明的（含嵌套在 message 里的），
-// 引用其他文件的枚举时不会重复声明，而是带包前缀直接引用（见 typeForField）。
-func GenerateRedisCodeHeadWithEnums(file *protogen.File) ([]byte, error) {
-	enums := collectFileEnums(file)
-
-	needProto, needMath, needStrconv := scanImports(file)
-	imports := []string{
-		"fmt",
-		"github.com/gomodule/redigo/redis",
-	}
-	if needStrconv {
-		imports = append(imports, "strconv")
-	}
-	if needMath {
-		imports = append(imports, "math")
-	}
-	sort.Strings(imports)
-
-	info := MessageInfo{
-		PackageName: string(file.GoPackageName),
-		Imports:     imports,
+// GenerateRedisCodeHead 生成文件头：package 与 body 用到的 imports。imports 由 body 中引用的包名推导（见 importsFor），
+// 经 g.QualifiedGoIdent 引用的包由 protogen 另行登记，不在此列。
+func GenerateRedisCodeHead(packageName protogen.GoPackageName, body []byte, opts *Options) ([]byte, error) {
+	imports, err := importsFor(body, opts)
+	if err != nil {
+		return nil, err
 	}
-
 	tmplHead, err := template.New("redis_code_head").Parse(codeTemplateHead)
 	if err != nil {
 		return nil, err
 	}
 	var bufHead bytes.Buffer
-	if err := tmplHead.Execute(&bufHead, info); err != nil {
+	if err := tmplHead.Execute(&bufHead, MessageInfo{PackageName: string(packageName), Imports: imports}); err != nil {
 		return nil, err
 	}
+	return bufHead.Bytes(), nil
+}
+
+// GenerateRedisEnums 生成本文件内声明的全部枚举（mode=attach 时枚举由 protoc-gen-go 声明，返回空）。
+// 枚举只取当前 proto 文件声明的（含嵌套在 message 里的），
+// 引用其他文件的枚举时不会重复声明，而是带包前缀直接引用（见 typeForField）。
+func GenerateRedisEnums(file *protogen.File, opts *Options) ([]byte, error) {
+	if opts.Mode == ModeAttach {
+		return nil, nil
+	}
+	enums := collectFileEnums(file)
+	needNames := enumNameMaps(file)
+	for i := range enums {
+		enums[i].NameMaps = needNames
+	}
 
 	tmplEnums, err := template.New("redis_enum_consts").Parse(codeTemplateEnums)
 	if err != nil {
@@ -139,21 +237,7 @@ func GenerateRedisCodeHeadWithEnums(file *protogen.File) ([]byte, error) {
 	}); err != nil {
 		return nil, err
 	}
-
-	parts := [][]byte{bufHead.Bytes(), bufEnums.Bytes()}
-	if needProto {
-		tmplHelpers, err := template.New("redis_proto_helpers").Parse(codeTemplateProtoHelpers)
-		if err != nil {
-			return nil, err
-		}
-		var bufHelpers bytes.Buffer
-		if err := tmplHelpers.Execute(&bufHelpers, nil); err != nil {
-			return nil, err
-		}
-		parts = append(parts, bufHelpers.Bytes())
-	}
-
-	return bytes.Join(parts, []byte("\n")), nil
+	return bufEnums.Bytes(), nil
 }
 
 // CollectMessages 返回文件中所有需要生成代码的 message（顶层 + 嵌套，按声明顺序），
@@ -310,40 +394,28 @@ func mapKeyType(k protoreflect.Kind) string {
 }
 
 // scanImports 扫描文件中全部字段（含嵌套 message，跳过 map entry），
-// 判断生成代码需要哪些 stdlib import。
-// needProto：存在 message 或集合字段（map/repeated 整体 protobuf 序列化），
-// 需要生成 protobuf wire 辅助函数与每个 message 的 Marshal/Unmarshal 方法；
-// needMath：存在 float 字段（含集合元素）且文件需要生成 marshaler（编码需要 math.Float32bits/Float64bits）；
-// needStrconv：plain 数值/枚举字段以十进制字符串直存，读取解析需要 strconv。
-func scanImports(file *protogen.File) (needProto, needMath, needStrconv bool) {
+// 判断是否存在 message 或集合字段（map/repeated 整体 protobuf 序列化）、sorted set 表或 blob 存储的 message，
+// 存在时需要生成 protobuf wire 辅助函数与每个 message 的 Marshal/Unmarshal 方法。
+func scanImports(file *protogen.File) (needProto bool) {
 	walkMessages(file.Messages, func(m *protogen.Message) {
 		if m.Desc.IsMapEntry() {
 			return
 		}
 		for _, field := range m.Fields {
-			if field.Desc.Cardinality() == protoreflect.Repeated {
-				// 集合字段整体 protobuf wire format 序列化
-				needProto = true
-				k := field.Desc.Kind()
-				if field.Desc.IsMap() {
-					k = field.Desc.MapValue().Kind()
-				}
-				needMath = needMath || k == protoreflect.FloatKind || k == protoreflect.DoubleKind
-				continue
-			}
-			switch field.Desc.Kind() {
-			case protoreflect.MessageKind:
+			if field.Desc.Cardinality() == protoreflect.Repeated || field.Desc.Kind() == protoreflect.MessageKind {
 				needProto = true
-			case protoreflect.Uint32Kind, protoreflect.Uint64Kind, protoreflect.Int32Kind, protoreflect.Int64Kind,
-				protoreflect.FloatKind, protoreflect.DoubleKind, protoreflect.EnumKind:
-				needStrconv = true
-				needMath = needMath || field.Desc.Kind() == protoreflect.FloatKind ||
-					field.Desc.Kind() == protoreflect.DoubleKind
 			}
 		}
+		// sorted set 表的伴随数据是 protobuf 字节
+		if messageOptions(m).GetZset() != nil {
+			needProto = true
+		}
+		// blob 存储的 message 整条以 protobuf 字节存取
+		if messageOptions(m).GetStorage() == redisopt.MessageStorage_MESSAGE_STORAGE_BLOB {
+			needProto = true
+		}
 	})
-	// 没有 message/集合字段就不会生成 marshaler，math 只随 marshaler 一起引入
-	return needProto, needProto && needMath, needStrconv
+	return needProto
 }
 
 // collectFileEnums 收集本文件声明的全部枚举（含嵌套在 message 里的），按名字排序。
@@ -351,13 +423,18 @@ func collectFileEnums(file *protogen.File) []EnumInfo {
 	var enums []EnumInfo
 	add := func(e *protogen.Enum) {
 		values := make([]EnumValueInfo, 0, len(e.Values))
+		seen := make(map[int32]bool, len(e.Values))
 		for _, v := range e.Values {
+			n := int32(v.Desc.Number())
 			values = append(values, EnumValueInfo{
-				Name:  string(v.GoIdent.GoName),
-				Value: int32(v.Desc.Number()),
+				Name:      string(v.GoIdent.GoName),
+				Value:     n,
+				ProtoName: string(v.Desc.Name()),
+				Alias:     seen[n],
 			})
+			seen[n] = true
 		}
-		enums = append(enums, EnumInfo{Name: string(e.GoIdent.GoName), Values: values})
+		enums = append(enums, EnumInfo{Name: string(e.GoIdent.GoName), FullName: string(e.Desc.FullName()), Values: values})
 	}
 	for _, e := range file.Enums {
 		add(e)
@@ -0,0 +1,218 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"text/template"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// HelpersFilename 是每个 Go 包（同一 go_package 且输出到同一目录的文件）共用的辅助代码文件名（执行接口与适配器），
+// 与该包的 .redis.go 输出到同一目录。其余辅助函数按功能输出到各自的文件（见 HelperFilenames）。
+const HelpersFilename = "redis_helpers.redis.go"
+
+// 按功能拆分的辅助代码文件名。文件名固定、内容只取决于插件参数：同一 Go 包的 .proto 分几次 protoc 生成时，
+// 每次写出的同名文件内容相同，各次用到的功能文件都保留在目录中，后一次不会用只含部分辅助函数的文件覆盖前一次需要的。
+const (
+	protoHelpersFilename    = "redis_helpers_proto.redis.go"
+	tableHelpersFilename    = "redis_helpers_table.redis.go"
+	perfHelpersFilename     = "redis_helpers_perf.redis.go"
+	enumHelpersFilename     = "redis_helpers_enum.redis.go"
+	jsonHelpersFilename     = "redis_helpers_json.redis.go"
+	compressHelpersFilename = "redis_helpers_compress.redis.go"
+	cryptoHelpersFilename   = "redis_helpers_crypto.redis.go"
+)
+
+// HelperFilenames 是插件可能输出的全部辅助代码文件名（含 MemFilename），.proto 生成的文件不能与它们重名。
+var HelperFilenames = []string{
+	HelpersFilename, protoHelpersFilename, tableHelpersFilename, perfHelpersFilename,
+	enumHelpersFilename, jsonHelpersFilename, compressHelpersFilename, cryptoHelpersFilename, MemFilename,
+}
+
+// HelperFile 是一个辅助代码文件：Name 为文件名（与 .redis.go 同目录），Content 为完整的 Go 源码。
+type HelperFile struct {
+	Name    string
+	Content []byte
+}
+
+// MemFilename 是 mem=true 时每个 Go 包额外输出的内存执行器文件名（NewRedisMemExecutor），与 HelpersFilename 同目录。
+// 内存执行器只供单元测试使用，单独成文件且默认不生成，生产包不必编译它与它用到的 strconv、sync 等。
+const MemFilename = "redis_mem.redis.go"
+
+// helperSet 记录一个 Go 包需要的辅助函数，取包内全部文件所需的并集
+type helperSet struct {
+	Proto     bool // protobuf wire 辅助函数（message / 集合字段、sorted set 表与 blob 存储）
+	Table     bool // codec=table 的字段表编解码函数
+	Perf      bool // perf=true 的参数缓冲池与追加式编码函数
+	EnumNames bool // 枚举名字与整数互转（JSON 编码与 enum_storage）
+	JSON      bool
+	Compress  bool
+	Crypto    bool
+}
+
+func (h *helperSet) add(file *protogen.File, opts *Options) {
+	// attach 模式：message 字段经 proto.Marshal 编解码，不需要 wire 辅助函数；
+	// ValidateAttach 已排除 JSON、压缩、加密与 enum_storage
+	if opts.Mode != ModeAttach {
+		h.Proto = h.Proto || scanImports(file)
+		// codec=table：每个 message 的 MarshalRedisProto 都经字段表编解码
+		if opts.Codec == CodecTable && len(CollectMessages(file)) > 0 {
+			h.Proto, h.Table = true, true
+		}
+		// perf=true：GetFields/SetFields 使用参数缓冲池，编码函数依赖 wire 辅助函数
+		if opts.Perf && len(CollectMessages(file)) > 0 {
+			h.Proto, h.Perf = true, true
+		}
+	}
+	h.EnumNames = h.EnumNames || enumNameMaps(file)
+	h.JSON = h.JSON || jsonCodec(file)
+	h.Compress = h.Compress || compressCodec(file)
+	h.Crypto = h.Crypto || sensitiveCodec(file)
+}
+
+// GenerateHelpers 生成一个 Go 包共用的辅助代码：HelpersFilename 中是 RedisExecutor 接口与所选适配器，
+// files 中任一文件需要的 wire format、字段表编解码、热路径（perf=true）、枚举名字、JSON、压缩与加密辅助函数各占一个固定名字的文件。
+// helpers=runtime 时执行接口、wire format、字段表编解码与热路径辅助函数只生成转接到运行时包 redisrt 的声明（codeTemplateRuntime 等），其余不变。
+// files 为同一 Go 包中本次生成的全部文件，每个包只输出一次，多个 .proto 共用一个 go_package 时不会重复声明；
+// 分几次生成同一个包时各次输出的同名文件相同（见 protoHelpersFilename 等）。
+func GenerateHelpers(files []*protogen.File, opts *Options) ([]HelperFile, error) {
+	var need helperSet
+	for _, f := range files {
+		need.add(f, opts)
+	}
+
+	// helpers=runtime：执行接口与 wire format 辅助函数转接到运行时包（转接声明很短，不再按需裁剪）
+	executor := codeTemplateExecutor
+	if opts.Helpers == HelpersRuntime {
+		executor, need.Proto = codeTemplateRuntime, false
+	}
+	tmplExecutor, err := template.New("redis_executor").Parse(executor)
+	if err != nil {
+		return nil, err
+	}
+	var bufExecutor bytes.Buffer
+	if err := tmplExecutor.Execute(&bufExecutor, MessageInfo{Executor: opts.Executor}); err != nil {
+		return nil, err
+	}
+
+	table, perf := codeTemplateTableCodec, codeTemplatePerfHelpers
+	if opts.Helpers == HelpersRuntime {
+		table, perf = codeTemplateRuntimeTable, codeTemplateRuntimePerf
+	}
+	parts := []struct {
+		name string
+		need bool
+		body string
+	}{
+		{HelpersFilename, true, bufExecutor.String()},
+		{protoHelpersFilename, need.Proto, codeTemplateProtoHelpers},
+		{tableHelpersFilename, need.Table, table},
+		{perfHelpersFilename, need.Perf, perf},
+		{enumHelpersFilename, need.EnumNames, codeTemplateEnumHelpers},
+		{jsonHelpersFilename, need.JSON, codeTemplateJSONHelpers},
+		{compressHelpersFilename, need.Compress, codeTemplateCompressHelpers},
+		{cryptoHelpersFilename, need.Crypto, codeTemplateCryptoHelpers},
+	}
+	var out []HelperFile
+	for _, p := range parts {
+		if !p.need {
+			continue
+		}
+		head, err := GenerateRedisCodeHead(files[0].GoPackageName, []byte(p.body), opts)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, HelperFile{Name: p.name, Content: append(head, p.body...)})
+	}
+	return out, nil
+}
+
+// GenerateMem 生成 mem=true 时一个 Go 包共用的内存执行器（MemFilename）；helpers=runtime 时只是转接到 redisrt.NewMemExecutor。
+func GenerateMem(files []*protogen.File, opts *Options) ([]byte, error) {
+	body := []byte(codeTemplateMemExecutor)
+	if opts.Helpers == HelpersRuntime {
+		body = []byte(codeTemplateRuntimeMem)
+	}
+	head, err := GenerateRedisCodeHead(files[0].GoPackageName, body, opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(head, body...), nil
+}
+
+// knownImports 是生成代码中直接以包名引用的包（模板里写死的 fmt.Errorf、redisrt.Cmd 等），redis 按所选执行适配器对应不同的客户端
+var knownImports = map[string]string{
+	"aes":         "crypto/aes",
+	"base64":      "encoding/base64",
+	"bytes":       "bytes",
+	"cipher":      "crypto/cipher",
+	"context":     "context",
+	"errors":      "errors",
+	"flate":       "compress/flate",
+	"fmt":         "fmt",
+	"goredisexec": RuntimeImportPath + "/goredisexec",
+	"io":          "io",
+	"json":        "encoding/json",
+	"math":        "math",
+	"rand":        "crypto/rand",
+	"redigoexec":  RuntimeImportPath + "/redigoexec",
+	"redisrt":     RuntimeImportPath,
+	"sort":        "sort",
+	"strconv":     "strconv",
+	"strings":     "strings",
+	"sync":        "sync",
+	"time":        "time",
+	"unsafe":      "unsafe",
+	"utf8":        "unicode/utf8",
+}
+
+// importsFor 返回生成代码 body（不含 package 子句的声明）以包名引用到的包的导入路径（排序）。
+// 各文件只导入自己用到的包：辅助函数移到 HelpersFilename 后，固定的 import 列表会在 .redis.go 中留下未使用的导入。
+func importsFor(body []byte, opts *Options) ([]string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", append([]byte("package p\n"), body...), parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("解析生成代码失败: %v", err)
+	}
+	// 用 go/types 解析标识符：body 没有 import，包名都报 undefined（忽略这些错误），局部变量、参数与包内声明则记入 Uses
+	info := &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+	_, _ = (&types.Config{Error: func(error) {}}).Check("p", fset, []*ast.File{f}, info)
+	seen := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		// 没有解析到任何声明的标识符才是包名（同名的局部变量如 json 不算）
+		if id, ok := sel.X.(*ast.Ident); ok && info.Uses[id] == nil {
+			path := knownImports[id.Name]
+			if id.Name == "redis" {
+				path = redisImportPath(opts.Executor)
+			}
+			if path != "" {
+				seen[path] = true
+			}
+		}
+		return true
+	})
+	imports := make([]string, 0, len(seen))
+	for path := range seen {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	return imports, nil
+}
+
+// redisImportPath 返回执行适配器对应的客户端包；两者的包名都是 redis，生成代码里统一写 redis.Xxx
+func redisImportPath(executor string) string {
+	if executor == ExecutorGoRedis {
+		return "github.com/redis/go-redis/v9"
+	}
+	return "github.com/gomodule/redigo/redis"
+}
@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// ManifestVersion 是 manifest 的格式版本，字段含义变化（而不只是新增字段）时递增。
+const ManifestVersion = 1
+
+// manifest 描述一个 proto 文件生成代码的 Redis 存储布局，供其他语言的脚本直接读取，
+// 不必再从文档推导 key 格式、hash field 与值编码。
+type manifest struct {
+	Version  int               `json:"version"`
+	File     string            `json:"file"`    // proto 文件路径
+	Package  string            `json:"package"` // proto 包名
+	Messages []manifestMessage `json:"messages"`
+	Enums    []manifestEnum    `json:"enums"`
+}
+
+// manifestMessage 是一个顶层 message（一张"表"）的存储布局
+type manifestMessage struct {
+	Name          string              `json:"name"` // proto 全名
+	GoName        string              `json:"go_name"`
+	Storage       string              `json:"storage"`    // "hash"、"blob"（整条 protobuf 字节存入 string key）或 "zset"
+	KeyFormat     string              `json:"key_format"` // fmt.Sprintf / printf 格式，依次填入 key_dimensions
+	KeyDimensions []manifestDimension `json:"key_dimensions"`
+	TagFallback   bool                `json:"tag_fallback,omitempty"` // 按名字存储的 field 读不到时回退到字段编号
+	ZSet          *manifestZSet       `json:"zset,omitempty"`
+	Fields        []manifestField     `json:"fields"`
+}
+
+// manifestDimension 是 key_format 中一个 %d 对应的参数
+type manifestDimension struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// manifestKeyDimensions 是 key_format 依次填入的参数，与生成代码中 redisKey<Message> 的参数一致
+var manifestKeyDimensions = []manifestDimension{
+	{Name: "redbkey", Type: "uint32"},
+	{Name: "ida", Type: "uint64"},
+	{Name: "idb", Type: "uint64"},
+}
+
+// manifestZSet 描述 sorted set 表：key 为 sorted set，成员与分数取自两个字段，
+// 其余字段编码为 protobuf 字节存入伴随 hash（key 后接 payload_key_suffix，field 为成员）
+type manifestZSet struct {
+	Score            string `json:"score"`  // 分数字段的 proto 名
+	Member           string `json:"member"` // 成员字段的 proto 名
+	PayloadKeySuffix string `json:"payload_key_suffix"`
+}
+
+// manifestField 是一个字段的存储方式。hash_field 与 encoding 只对 Hash 表中存入 hash field 的字段给出；
+// blob 存储与 sorted set 表的字段随整条记录以 protobuf 编码，原生存储字段见 native。
+type manifestField struct {
+	Name      string `json:"name"` // proto 字段名
+	GoName    string `json:"go_name"`
+	Tag       int    `json:"tag"`
+	Kind      string `json:"kind"`               // "scalar"、"enum"、"message"、"map"、"repeated"
+	Type      string `json:"type"`               // proto 类型，如 "int64"、"enum game.DBGuild.Role"
+	Elements  string `json:"elements,omitempty"` // 包裹 message 中集合字段的类型，如 "repeated uint64"
+	HashField string `json:"hash_field,omitempty"`
+	// 值编码："decimal"（数值、bool 为 1/0、枚举的数值）、"raw"（string / bytes 原样）、"enum_name"（枚举值名，
+	// 也接受数值）、"protobuf"（message 与集合的 wire format 字节）、"json"（proto3 JSON，也接受 protobuf 字节）
+	Encoding    string               `json:"encoding,omitempty"`
+	Compression *manifestCompression `json:"compression,omitempty"`
+	Encryption  *manifestEncryption  `json:"encryption,omitempty"`
+	Native      *manifestNative      `json:"native,omitempty"`
+	ZSetIndex   string               `json:"zset_index,omitempty"`   // sorted set 索引的 key 模板，成员为 "<ida>:<idb>"
+	UniqueIndex string               `json:"unique_index,omitempty"` // 唯一索引 hash 的 key 模板，field 为字段值
+}
+
+// manifestCompression：编码后达到 min_size 字节的值以 1 字节头 0x01 加 DEFLATE（raw，RFC 1951）数据存储
+type manifestCompression struct {
+	Algorithm string `json:"algorithm"`
+	MinSize   int    `json:"min_size"`
+}
+
+// manifestEncryption：值以 AES-GCM 加密，格式为 0x02、密钥 ID 长度（1 字节）、密钥 ID、12 字节 nonce、密文与认证标签，
+// 附加认证数据为记录的 Hash key、一个 0x00 字节与 aad（字段的 proto 全名）拼接；加密在编码与压缩之后
+type manifestEncryption struct {
+	Algorithm string `json:"algorithm"`
+	AAD       string `json:"aad"`
+}
+
+// manifestNative 描述原生存储字段：集合存入 Hash key 后接 key_suffix 的独立 key，元素编码与 hash 中的标量一致，
+// message 元素为 protobuf 字节
+type manifestNative struct {
+	RedisType string `json:"redis_type"` // "hash"（map）、"list"（repeated）、"set"（unique repeated）
+	KeySuffix string `json:"key_suffix"`
+}
+
+type manifestEnum struct {
+	Name   string              `json:"name"` // proto 全名
+	GoName string              `json:"go_name"`
+	Values []manifestEnumValue `json:"values"`
+}
+
+type manifestEnumValue struct {
+	Name   string `json:"name"`
+	Number int32  `json:"number"`
+}
+
+// GenerateManifest 生成文件的存储清单（JSON）：全部顶层 message 的 key 格式与维度、各字段的 hash field、类型与值编码，
+// 以及本文件声明的枚举。内容取自生成代码时使用的 MessageInfo / EnumInfo，g 为该文件的 Go 生成文件（用于解析类型名）。
+func GenerateManifest(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, opts *Options) ([]byte, error) {
+	m := manifest{
+		Version:  ManifestVersion,
+		File:     file.Desc.Path(),
+		Package:  string(file.Desc.Package()),
+		Messages: make([]manifestMessage, 0, len(file.Messages)),
+		Enums:    []manifestEnum{},
+	}
+	for _, msg := range file.Messages {
+		m.Messages = append(m.Messages, manifestMessageOf(buildMessageInfo(gen, file, msg, g, opts)))
+	}
+	for _, e := range collectFileEnums(file) {
+		values := make([]manifestEnumValue, 0, len(e.Values))
+		for _, v := range e.Values {
+			values = append(values, manifestEnumValue{Name: v.ProtoName, Number: v.Value})
+		}
+		m.Enums = append(m.Enums, manifestEnum{Name: e.FullName, GoName: e.Name, Values: values})
+	}
+	// 不转义 <、>、&：类型描述中的 map<K, V> 保持可读
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func manifestMessageOf(info MessageInfo) manifestMessage {
+	m := manifestMessage{
+		Name:          info.FullName,
+		GoName:        info.MessageName,
+		Storage:       "hash",
+		KeyFormat:     info.KeyFormat,
+		KeyDimensions: manifestKeyDimensions,
+		TagFallback:   info.TagFallback,
+		Fields:        make([]manifestField, 0, len(info.Fields)),
+	}
+	protoName := make(map[string]string, len(info.Fields))
+	for _, f := range info.Fields {
+		protoName[f.Name] = f.ProtoName
+	}
+	switch {
+	case info.ZSet != nil:
+		m.Storage = "zset"
+		m.ZSet = &manifestZSet{Score: protoName[info.ZSet.Score], Member: protoName[info.ZSet.Member], PayloadKeySuffix: ":payload"}
+	case info.Blob:
+		m.Storage = "blob"
+	}
+	for _, f := range info.Fields {
+		m.Fields = append(m.Fields, manifestFieldOf(f, m.Storage == "hash"))
+	}
+	return m
+}
+
+// manifestFieldOf 返回字段的存储方式，hashTable 报告字段所在的 message 是否为 Hash 表
+func manifestFieldOf(f FieldInfo, hashTable bool) manifestField {
+	mf := manifestField{
+		Name:     f.ProtoName,
+		GoName:   f.Name,
+		Tag:      f.ProtoTag,
+		Kind:     manifestKind(f),
+		Type:     f.ProtoType,
+		Elements: f.Wrapped,
+	}
+	if f.Index != nil {
+		mf.ZSetIndex = f.Index.Template
+	}
+	if f.Unique != nil {
+		mf.UniqueIndex = f.Unique.Template
+	}
+	if !hashTable {
+		return mf
+	}
+	if f.Native != "" {
+		mf.Native = &manifestNative{RedisType: f.Native, KeySuffix: ":" + strconv.Itoa(f.ProtoTag)}
+		return mf
+	}
+	mf.HashField = f.HashName
+	if mf.HashField == "" {
+		mf.HashField = strconv.Itoa(f.ProtoTag)
+	}
+	mf.Encoding = manifestEncoding(f)
+	if f.CompressMinSize > 0 {
+		mf.Compression = &manifestCompression{Algorithm: "deflate", MinSize: f.CompressMinSize}
+	}
+	if f.Sensitive {
+		mf.Encryption = &manifestEncryption{Algorithm: "aes-gcm", AAD: f.SensitiveAAD}
+	}
+	return mf
+}
+
+func manifestKind(f FieldInfo) string {
+	switch {
+	case f.Kind == FieldMap:
+		return "map"
+	case f.Kind == FieldSlice:
+		return "repeated"
+	case f.IsMsg:
+		return "message"
+	case f.IsEnum:
+		return "enum"
+	}
+	return "scalar"
+}
+
+// manifestEncoding 返回 hash field 中值的编码（取值见 manifestField.Encoding）
+func manifestEncoding(f FieldInfo) string {
+	switch {
+	case f.Kind != FieldPlain || f.IsMsg:
+		if f.JSON {
+			return "json"
+		}
+		return "protobuf"
+	case f.IsEnum:
+		if f.EnumStorage == "name" {
+			return "enum_name"
+		}
+		return "decimal"
+	case f.GoType == "string" || f.GoType == "[]byte":
+		return "raw"
+	}
+	return "decimal"
+}
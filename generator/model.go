@@ -1,5 +1,11 @@
 package generator
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // FieldKind 字段存储形态
 type FieldKind string
 
@@ -14,10 +20,16 @@ const (
 
 // FieldInfo 描述 proto 中的一个字段
 type FieldInfo struct {
-	Name     string // 字段的 Go 名（camelCase），如 "UserId"
-	ProtoTag int    // proto tag，如 1
-	GoType   string // Go 类型，如 "uint64", "string", "Gender", "map[string]string"
-	Kind     FieldKind
+	Name      string // 字段的 Go 名（camelCase），如 "UserId"
+	ProtoName string // proto 字段名，如 "user_id"
+	ProtoTag  int    // proto tag，如 1
+	GoType    string // Go 类型，如 "uint64", "string", "Gender", "map[string]string"
+	Kind      FieldKind
+
+	// 存储上的 proto 类型描述，如 "int64"、"enum game.DBGuild.Role"、"message game.DBPlayer.DBFriends"；
+	// 字段是包裹 message 时 Wrapped 为其中集合字段的类型描述，如 "repeated uint64"（manifest 使用）
+	ProtoType string
+	Wrapped   string
 
 	IsMsg  bool // plain 字段：嵌套 message，整块 protobuf wire format 序列化
 	IsEnum bool // plain 字段：是否为枚举（GetFields 需按整数解析并转换）
@@ -27,24 +39,504 @@ type FieldInfo struct {
 	ElemType   string // 集合元素类型
 	ElemIsMsg  bool   // 元素为 message，单元素 protobuf wire format 序列化
 	ElemIsEnum bool   // 元素为枚举
+
+	// storage=STORAGE_NATIVE 的包裹 message 字段：集合不进 hash，存入独立 key（Hash key 后接 ":<tag>"）
+	Native      string     // 独立 key 的 Redis 类型："hash"（map）/ "list"（repeated）/ "set"（unique repeated），为空表示未启用
+	NativeItems string     // 包裹 message 中集合字段的 Go 名，如 "Items"
+	NativeKey   NativeType // Native 为 "hash" 时 map 键的类型
+	NativeElem  NativeType // 元素（map 为值）的类型
+	NativeOwner string     // 所属 message 的 Go 名（元素级方法模板块以字段为上下文，需要它拼出方法与 key 函数名）
+
+	// 设置了 zset_index 的数值字段：写入时同一事务内更新 sorted set 索引（成员为 "<ida>:<idb>"，分数为字段值）
+	Index *KeyTemplate
+	// 设置了 unique_index 的字段：值唯一，HSETNX 占用独立 hash 中的条目（field 为值，值为 "<ida>:<idb>"）
+	Unique *KeyTemplate
+
+	// 字段在 Redis Hash 中的 field：HashName 非空时按名字存储（hash_field=HASH_FIELD_NAME 或 redis_name），否则为字段编号；
+	// HashField 是生成代码中引用该 field 的 Go 表达式，如 `"user_name"` 或 "uint32(FieldDBUser_Name)"
+	HashName  string
+	HashField string
+
+	// message 字段与集合字段的值以 proto3 JSON 存入 Redis Hash（encoding=VALUE_ENCODING_JSON），否则为 protobuf 字节
+	JSON bool
+	// 字段的 json_name（lowerCamelCase）与 JSON 解码时接受的成员名（json_name 与 proto 字段名，Go case 标签形式）
+	JSONName  string
+	JSONCases string
+
+	// 单值枚举字段设置了 enum_storage（字段或 message 级）："name" 写入 proto 中的枚举值名，"number" 写入十进制整数；
+	// 两者读取时都接受名字与整数。为空表示未设置，按整数读写
+	EnumStorage string
+
+	// message 字段与集合字段设置了 compression=COMPRESSION_FLATE 时的压缩阈值（字节）：编码后达到该值才压缩；0 表示不压缩
+	CompressMinSize int
+
+	// 敏感字段（sensitive）：写入前以 AES-GCM 加密、读取后解密，String() 中显示为 [REDACTED]；
+	// SensitiveAAD 是字段的 proto 全名，与 Hash key 一起构成加密时的附加认证数据（见 redisAAD），密文不能被挪到其他字段或记录解密
+	Sensitive    bool
+	SensitiveAAD string
+
+	// perf=true：编码直接追加到调用方的缓冲（嵌套 message 经 MarshalRedisProtoAppend），解码读取 length-delimited 数据时不拷贝（见 ReadLen）
+	Perf bool
+
+	// 设置了 pb_package 时字段在 ToProto（p -> m）与 From<Message>Proto（m -> p）中的转换语句
+	ToProto   string
+	FromProto string
+}
+
+// JSONAppend 返回把本字段类型的值 v 以 proto3 JSON 追加到 buf 的表达式（plain 字段）
+func (f FieldInfo) JSONAppend(v string) string {
+	return jsonAppendExpr(f.GoType, f.IsMsg, f.IsEnum, v)
+}
+
+// JSONAppendElem 返回把集合元素（map 为值）v 以 proto3 JSON 追加到 buf 的表达式
+func (f FieldInfo) JSONAppendElem(v string) string {
+	return jsonAppendExpr(f.ElemType, f.ElemIsMsg, f.ElemIsEnum, v)
+}
+
+// JSONDecode 返回解析 JSON 值 v 的表达式（plain 标量/枚举字段），结果为 (值, error)，值需再转换为 GoType
+func (f FieldInfo) JSONDecode(v string) string {
+	return jsonDecodeExpr(f.GoType, f.IsEnum, v)
+}
+
+// JSONDecodeElem 与 JSONDecode 相同，作用于非 message 的集合元素（map 为值），值需再转换为 ElemType
+func (f FieldInfo) JSONDecodeElem(v string) string {
+	return jsonDecodeExpr(f.ElemType, f.ElemIsEnum, v)
+}
+
+// JSONKeyString 返回把 map 键 k 转换为 JSON 对象成员名的表达式（proto3 JSON 中 map 键一律为字符串）
+func (f FieldInfo) JSONKeyString(k string) string {
+	switch f.KeyType {
+	case "string":
+		return k
+	case "bool":
+		return "strconv.FormatBool(" + k + ")"
+	case "uint32", "uint64":
+		return "strconv.FormatUint(uint64(" + k + "), 10)"
+	default:
+		return "strconv.FormatInt(int64(" + k + "), 10)"
+	}
+}
+
+// JSONKeyParse 返回把 JSON 对象成员名 name 解析为 map 键的表达式（非 string 键），结果为 (值, error)，值需再转换为 KeyType
+func (f FieldInfo) JSONKeyParse(name string) string {
+	switch f.KeyType {
+	case "bool":
+		return "strconv.ParseBool(" + name + ")"
+	case "uint32":
+		return "strconv.ParseUint(" + name + ", 10, 32)"
+	case "uint64":
+		return "strconv.ParseUint(" + name + ", 10, 64)"
+	case "int32":
+		return "strconv.ParseInt(" + name + ", 10, 32)"
+	default:
+		return "strconv.ParseInt(" + name + ", 10, 64)"
+	}
+}
+
+// jsonAppendExpr 返回把类型为 goType 的值 v 以 proto3 JSON 追加到 buf 的表达式：
+// 32 位整数为数字，64 位整数为字符串，bytes 为 base64，枚举为名字（生成的 <Enum>_name 表），message 为对象
+func jsonAppendExpr(goType string, isMsg, isEnum bool, v string) string {
+	switch {
+	case isMsg:
+		return v + ".appendRedisJSON(buf)"
+	case isEnum:
+		return "redisJSONAppendEnum(buf, int32(" + v + "), " + goType + "_name)"
+	}
+	switch goType {
+	case "int32":
+		return "strconv.AppendInt(buf, int64(" + v + "), 10)"
+	case "uint32":
+		return "strconv.AppendUint(buf, uint64(" + v + "), 10)"
+	case "int64":
+		return "redisJSONAppendInt64(buf, " + v + ")"
+	case "uint64":
+		return "redisJSONAppendUint64(buf, " + v + ")"
+	case "float32":
+		return "redisJSONAppendFloat(buf, float64(" + v + "), 32)"
+	case "float64":
+		return "redisJSONAppendFloat(buf, " + v + ", 64)"
+	case "bool":
+		return "strconv.AppendBool(buf, " + v + ")"
+	case "[]byte":
+		return "redisJSONAppendBytes(buf, " + v + ")"
+	default:
+		return "redisJSONAppendString(buf, " + v + ")"
+	}
+}
+
+// jsonDecodeExpr 返回解析类型为 goType（非 message）的 JSON 值 v 的表达式，结果为 (值, error)
+func jsonDecodeExpr(goType string, isEnum bool, v string) string {
+	if isEnum {
+		return "redisJSONEnum(" + v + ", " + goType + "_value)"
+	}
+	switch goType {
+	case "int32":
+		return "redisJSONInt(" + v + ", 32)"
+	case "int64":
+		return "redisJSONInt(" + v + ", 64)"
+	case "uint32":
+		return "redisJSONUint(" + v + ", 32)"
+	case "uint64":
+		return "redisJSONUint(" + v + ", 64)"
+	case "float32":
+		return "redisJSONFloat(" + v + ", 32)"
+	case "float64":
+		return "redisJSONFloat(" + v + ", 64)"
+	case "bool":
+		return "redisJSONBool(" + v + ")"
+	case "[]byte":
+		return "redisJSONBytes(" + v + ")"
+	default:
+		return "redisJSONString(" + v + ")"
+	}
+}
+
+// ProtoTableEntry 返回 codec=table 时本字段在 message 字段表中的表项表达式（message 为字段所属 message 的 Go 名）：
+// 字段以 unsafe.Offsetof 的偏移定位，repeated 与 map 另以类型参数给出元素与键的 Go 类型（增长切片与读写 map 需要）
+func (f FieldInfo) ProtoTableEntry(message string) string {
+	offset := fmt.Sprintf("unsafe.Offsetof(%s{}.%s)", message, f.Name)
+	switch f.Kind {
+	case FieldMap:
+		return fmt.Sprintf("redisProtoMap[%s, %s](%d, %q, %s, %s, %s)", f.KeyType, f.ElemType, f.ProtoTag, f.Name, offset,
+			protoCodecExpr(f.KeyType, false, false), protoCodecExpr(f.ElemType, f.ElemIsMsg, f.ElemIsEnum))
+	case FieldSlice:
+		return fmt.Sprintf("redisProtoRepeated[%s](%d, %q, %s, %s)", f.ElemType, f.ProtoTag, f.Name, offset,
+			protoCodecExpr(f.ElemType, f.ElemIsMsg, f.ElemIsEnum))
+	default:
+		return fmt.Sprintf("redisProtoSingular(%d, %q, %s, %s)", f.ProtoTag, f.Name, offset, protoCodecExpr(f.GoType, f.IsMsg, f.IsEnum))
+	}
+}
+
+// protoCodecExpr 返回类型为 goType 的值在字段表中的编解码（*redisProtoCodec）表达式，wire type 与逐字段生成的编码一致：
+// 整型与枚举（int32）一律为 varint，float32 / float64 为 fixed32 / fixed64，string、bytes 与 message 为 length-delimited
+func protoCodecExpr(goType string, isMsg, isEnum bool) string {
+	switch {
+	case isMsg:
+		return "redisProtoNested[" + goType + "]()"
+	case isEnum:
+		return "redisProtoInt32"
+	}
+	switch goType {
+	case "string":
+		return "redisProtoString"
+	case "[]byte":
+		return "redisProtoBytes"
+	case "bool":
+		return "redisProtoBool"
+	case "float32":
+		return "redisProtoFloat32"
+	case "float64":
+		return "redisProtoFloat64"
+	case "int64":
+		return "redisProtoInt64"
+	case "uint32":
+		return "redisProtoUint32"
+	case "uint64":
+		return "redisProtoUint64"
+	default:
+		return "redisProtoInt32"
+	}
+}
+
+// HashArg 返回字段编号变量 v 所表示的本字段在 Redis Hash 中的 field 的 Go 表达式（switch 分支内使用）
+func (f FieldInfo) HashArg(v string) string {
+	if f.HashName != "" {
+		return f.HashField
+	}
+	return "uint32(" + v + ")"
+}
+
+// NativeType 描述原生存储集合中元素或 map 键的类型，决定它在独立 key 中的编码：
+// 与 hash 中的标量一致（string/bytes 原样，数值/枚举十进制，bool 为 1/0），message 为 protobuf 字节。
+type NativeType struct {
+	GoType string
+	IsMsg  bool
+	IsEnum bool
+}
+
+// NativeReadCmd 返回读取原生存储字段全部元素的命令
+func (f FieldInfo) NativeReadCmd() string {
+	switch f.Native {
+	case "hash":
+		return "HGETALL"
+	case "list":
+		return "LRANGE"
+	case "set":
+		return "SMEMBERS"
+	default:
+		return ""
+	}
+}
+
+// NativeWriteCmd 返回向原生存储字段写入元素的命令（list 追加到末尾）
+func (f FieldInfo) NativeWriteCmd() string {
+	switch f.Native {
+	case "hash":
+		return "HSET"
+	case "list":
+		return "RPUSH"
+	case "set":
+		return "SADD"
+	default:
+		return ""
+	}
+}
+
+// KeyTemplate 是由选项中的 key 模板（可引用 {redbkey}、{ida}、{idb}）生成的辅助 key
+type KeyTemplate struct {
+	Template string // 选项中的原始模板，如 "REDB#{redbkey}:uniq:name"
+	Expr     string // 拼接 key 的 Go 表达式（引用 REDBKey、ida、idb）
+	ByIda    bool   // 模板引用了 ida：查询方法需要传 ida
+	ByIdb    bool   // 模板引用了 idb：查询方法需要传 idb
+}
+
+// Params 返回查询方法（Top<Field>、Find<Message>By<Field>）的 ida / idb 参数声明：只包含模板引用到的，带尾随 ", "
+func (k KeyTemplate) Params() string {
+	switch {
+	case k.ByIda && k.ByIdb:
+		return "ida, idb uint64, "
+	case k.ByIda:
+		return "ida uint64, "
+	case k.ByIdb:
+		return "idb uint64, "
+	default:
+		return ""
+	}
+}
+
+// Args 返回查询方法调用 key 函数时的 ida, idb 实参：未引用的一方传 0
+func (k KeyTemplate) Args() string {
+	ida, idb := "0", "0"
+	if k.ByIda {
+		ida = "ida"
+	}
+	if k.ByIdb {
+		idb = "idb"
+	}
+	return ida + ", " + idb
+}
+
+// ParamNames 返回 Params 中声明的参数名，用于转调，如 "ida, "
+func (k KeyTemplate) ParamNames() string {
+	return strings.ReplaceAll(k.Params(), " uint64", "")
+}
+
+// Desc 返回模板引用到的 ida / idb 的说明文字，如 "ida、idb"，均未引用时为 ""
+func (k KeyTemplate) Desc() string {
+	switch {
+	case k.ByIda && k.ByIdb:
+		return "ida、idb"
+	case k.ByIda:
+		return "ida"
+	case k.ByIdb:
+		return "idb"
+	default:
+		return ""
+	}
+}
+
+// ReadLen 返回 fieldDecode 读取 length-delimited 数据（随即转换为 string、解码为 message 或逐个读取 packed 元素）的函数：
+// perf=true 时为不拷贝的 redisProtoReadBytesView，否则为 redisProtoReadBytes。原样保存的 bytes 值始终拷贝，不与输入 alias
+func (f FieldInfo) ReadLen() string {
+	if f.Perf {
+		return "redisProtoReadBytesView"
+	}
+	return "redisProtoReadBytes"
+}
+
+// IncrCmd 返回字段原子自增使用的命令：整型为 HINCRBY，浮点为 HINCRBYFLOAT，
+// 其余字段（枚举/bool/string/bytes/message/集合）不支持自增，返回 ""。
+func (f FieldInfo) IncrCmd() string {
+	if f.Kind != FieldPlain || f.IsEnum || f.IsMsg {
+		return ""
+	}
+	switch f.GoType {
+	case "int32", "int64", "uint32", "uint64":
+		return "HINCRBY"
+	case "float32", "float64":
+		return "HINCRBYFLOAT"
+	default:
+		return ""
+	}
+}
+
+// IncrBounds 返回 HINCRBY 结果 n 超出字段类型范围的条件（int64 不会越界，返回 ""）
+func (f FieldInfo) IncrBounds() string {
+	switch f.GoType {
+	case "int32":
+		return "n < math.MinInt32 || n > math.MaxInt32"
+	case "uint32":
+		return "n < 0 || n > math.MaxUint32"
+	case "uint64":
+		return "n < 0"
+	default:
+		return ""
+	}
 }
 
 // MessageInfo 描述一个 proto message
 type MessageInfo struct {
 	PackageName string
 	MessageName string
+	FullName    string // proto 全名，如 "game.DBPlayer"
 	FieldType   string // 字段编号类型名（默认 Field<MessageName>，命名冲突时带 X 后缀）
 	Fields      []FieldInfo
-	Imports     []string // 动态生成的 import 列表，如 []string{"math", "strconv", ...}
-	KeyFormat   string   // 生成 Redis key 用的 fmt.Sprintf 格式，如 "REDB#%d:%d:%d"
+	Imports     []string  // 动态生成的 import 列表，如 []string{"math", "strconv", ...}
+	TopLevel    bool      // 是否为顶层 message（对应一张 Redis Hash "表"，生成 <Message>Store）
+	KeyFormat   string    // 生成 Redis key 用的 fmt.Sprintf 格式，如 "REDB#%d:%d:%d"
+	Executor    string    // GetFields/SetFields 默认使用的执行适配器，如 "redigo"
+	ZSet        *ZSetInfo // 非 nil 时为 sorted set 表（message 选项 zset），生成排行榜 Store 取代 Hash 表 Store
+	Blob        bool      // 整条 message 以 protobuf 字节存入 string key（message 选项 storage=MESSAGE_STORAGE_BLOB）
+	TagFallback bool      // 迁移窗口：按名字存储的字段读取时回退到字段编号，写入前把编号 field 搬到名字下（选项 tag_fallback）
+	JSONCodec   bool      // 文件中设置了 encoding：生成 JSON 编解码，读取 message / 集合字段时按首字节识别 JSON 与 protobuf 字节
+	Compressed  bool      // 文件中设置了 compression：读取 message / 集合字段时先识别压缩头，压缩与未压缩的值都接受
+	TableCodec  bool      // codec=table：只生成字段表 redisProtoTable<Message>，MarshalRedisProto 等调用共用的编解码函数
+	Perf        bool      // perf=true：生成 redisAppendKey<Message> 与 MarshalRedisProtoAppend，Hash 表的 GetFields/SetFields 复用池中的参数缓冲
+	Mem         bool      // mem=true：为顶层 message 生成 New<Message>MemRepository（内存执行器在 MemFilename 中）
+	PBType      string    // 设置了 pb_package 时为 protoc-gen-go 生成的同名类型（如 "userpb.DBUser"），生成 ToProto / From<Message>Proto
+}
+
+// ZSetInfo 描述 sorted set 表的分数字段与成员字段
+type ZSetInfo struct {
+	Score      string     // 分数字段的 Go 名
+	ScoreType  string     // 分数字段的 Go 类型（整型或浮点）
+	Member     string     // 成员字段的 Go 名
+	MemberType NativeType // 成员字段的类型，成员按 nativeEncode / nativeDecode 编码
+}
+
+// IntScore 报告分数字段是否为整型（ZINCRBY 的 delta 为 int64，否则为 float64）
+func (z ZSetInfo) IntScore() bool {
+	return z.ScoreType != "float32" && z.ScoreType != "float64"
+}
+
+// HasIndex 报告是否存在设置了 zset_index 的字段
+func (m MessageInfo) HasIndex() bool {
+	for _, f := range m.Fields {
+		if f.Index != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasUnique 报告是否存在设置了 unique_index 的字段
+func (m MessageInfo) HasUnique() bool {
+	for _, f := range m.Fields {
+		if f.Unique != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSensitive 报告是否存在敏感字段（sensitive），存在时生成打码的 String()
+func (m MessageInfo) HasSensitive() bool {
+	for _, f := range m.Fields {
+		if f.Sensitive {
+			return true
+		}
+	}
+	return false
+}
+
+// HasNamed 报告是否存在按名字（而不是字段编号）存入 Redis Hash 的字段
+func (m MessageInfo) HasNamed() bool {
+	for _, f := range m.Fields {
+		if f.HashName != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HashFieldOf 返回字段编号变量 v 对应的 Redis Hash field 的 Go 表达式：
+// 存在按名字存储的字段时经 redisHashField<Message> 转换，否则直接为字段编号
+func (m MessageInfo) HashFieldOf(v string) string {
+	if m.HasNamed() {
+		return "redisHashField" + m.MessageName + "(" + v + ")"
+	}
+	return "uint32(" + v + ")"
+}
+
+// PooledArgs 报告 GetFields/SetFields 是否从池中取命令参数缓冲（perf=true 的 Hash 表；blob 存储整条读写，不在热路径上）
+func (m MessageInfo) PooledArgs() bool {
+	return m.Perf && !m.Blob
+}
+
+// BytesString 返回把 Redis 回复中的值 v（[]byte）交给 strconv 解析时的实参：
+// perf=true 时为不拷贝的 redisBytesString(v)（解析结果不引用 v），否则为 string(v)
+func (m MessageInfo) BytesString(v string) string {
+	if m.Perf {
+		return "redisBytesString(" + v + ")"
+	}
+	return "string(" + v + ")"
+}
+
+// KeyAppend 返回 redisAppendKey<Message> 的函数体：把 KeyFormat 展开为逐段追加，%d 经 strconv.AppendUint 写入；
+// KeyFormat 含 %d 与 %% 以外的占位符或 %d 不是 3 个（插件参数 key_format 不做校验）时退回 fmt.Appendf
+func (m MessageInfo) KeyAppend() string {
+	args := []string{"uint64(REDBKey)", "ida", "idb"}
+	var stmts []string
+	var lit strings.Builder
+	flush := func() {
+		switch s := lit.String(); {
+		case s == "":
+		case len(s) == 1 && s[0] >= 0x20 && s[0] < 0x7f:
+			stmts = append(stmts, "dst = append(dst, "+strconv.QuoteRune(rune(s[0]))+")")
+		default:
+			stmts = append(stmts, "dst = append(dst, "+strconv.Quote(s)+"...)")
+		}
+		lit.Reset()
+	}
+	verbs := 0
+	for i := 0; i < len(m.KeyFormat); i++ {
+		c := m.KeyFormat[i]
+		if c != '%' {
+			lit.WriteByte(c)
+			continue
+		}
+		i++
+		switch {
+		case i < len(m.KeyFormat) && m.KeyFormat[i] == '%':
+			lit.WriteByte('%')
+		case i < len(m.KeyFormat) && m.KeyFormat[i] == 'd' && verbs < len(args):
+			flush()
+			stmts = append(stmts, "dst = strconv.AppendUint(dst, "+args[verbs]+", 10)")
+			verbs++
+		default:
+			verbs = -1
+		}
+		if verbs < 0 {
+			break
+		}
+	}
+	if verbs != len(args) {
+		return fmt.Sprintf("return fmt.Appendf(dst, %q, REDBKey, ida, idb)", m.KeyFormat)
+	}
+	flush()
+	return strings.Join(append(stmts, "return dst"), "\n\t")
+}
+
+// HasNative 报告是否存在原生存储（独立 key）的集合字段
+func (m MessageInfo) HasNative() bool {
+	for _, f := range m.Fields {
+		if f.Native != "" {
+			return true
+		}
+	}
+	return false
 }
 
 type EnumInfo struct {
-	Name   string // 枚举类型的 Go 名，如 "Gender"、"ExtraMsg_State"
-	Values []EnumValueInfo
+	Name     string // 枚举类型的 Go 名，如 "Gender"、"ExtraMsg_State"
+	FullName string // proto 全名，如 "user.ExtraMsg.State"
+	Values   []EnumValueInfo
+	NameMaps bool // 生成名字与数值的对照表 <Enum>_name / <Enum>_value（JSON 编解码与 enum_storage 使用）
 }
 
 type EnumValueInfo struct {
-	Name  string // 枚举值完整的常量名，如 "Gender_GENDER_MALE"
-	Value int32  // 枚举值，如 1
+	Name      string // 枚举值完整的常量名，如 "Gender_GENDER_MALE"
+	Value     int32  // 枚举值，如 1
+	ProtoName string // proto 中的枚举值名，如 "GENDER_MALE"（JSON 中的枚举名）
+	Alias     bool   // 数值与前面的枚举值重复（allow_alias），<Enum>_name 中取第一个名字
 }
@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 执行适配器（--redis_opt=executor=...）：决定 GetFields/SetFields 第一个参数的连接类型。
+// 生成代码内部统一面向 RedisExecutor 接口，适配器只负责把具体客户端包装成该接口。
+const (
+	// ExecutorRedigo 使用 github.com/gomodule/redigo（默认，参数为 redis.Conn）
+	ExecutorRedigo = "redigo"
+	// ExecutorGoRedis 使用 github.com/redis/go-redis/v9（参数为 redis.UniversalClient）
+	ExecutorGoRedis = "goredis"
+)
+
+// ModeAttach 把 GetFields/SetFields 生成到 protoc-gen-go 的 message 类型上（--redis_opt=mode=attach），
+// 不再声明自己的结构体与枚举，生成文件须与 .pb.go 在同一 Go 包中（见 GenerateAttachCode）。
+const ModeAttach = "attach"
+
+// 辅助代码的来源（--redis_opt=helpers=...），见 GenerateHelpers。
+const (
+	// HelpersStandalone 把执行接口、适配器与 wire format 辅助函数完整生成到每个包的 redis_helpers.redis.go（默认），生成代码不依赖本模块
+	HelpersStandalone = "standalone"
+	// HelpersRuntime 改为调用运行时包 redisrt（RuntimeImportPath），redis_helpers.redis.go 只保留转接声明
+	HelpersRuntime = "runtime"
+)
+
+// RuntimeImportPath 是 helpers=runtime 时生成代码依赖的运行时包
+const RuntimeImportPath = "github.com/beijian128/protoc-gen-redis/redisrt"
+
+// protobuf 编解码的生成方式（--redis_opt=codec=...），两者的编码结果相同。
+const (
+	// CodecInline 为每个 message 逐字段生成 MarshalRedisProto / UnmarshalRedisProto（默认），速度最快
+	CodecInline = "inline"
+	// CodecTable 为每个 message 只生成字段表，编解码由辅助代码中共用的函数完成（见 codeTemplateTableCodec），生成代码小得多
+	CodecTable = "table"
+)
+
+// ManifestJSON 是存储清单（--redis_opt=manifest=json）的格式，输出为与生成代码同名的 .redis.manifest.json 文件。
+const ManifestJSON = "json"
+
+// Options 是插件参数（--redis_opt=k=v,...）解析后的生成选项。
+type Options struct {
+	KeyFormat string // 生成 Redis key 用的 fmt.Sprintf 格式，默认 DefaultKeyFormat
+	Executor  string // 默认执行适配器：ExecutorRedigo / ExecutorGoRedis
+	Mode      string // 生成模式：ModeAttach，为空时生成自包含的结构体与枚举
+	Manifest  string // 额外输出的存储清单格式（见 GenerateManifest）：ManifestJSON，为空时不输出
+	Helpers   string // 辅助代码的来源：HelpersStandalone / HelpersRuntime
+	Codec     string // protobuf 编解码的生成方式：CodecInline / CodecTable
+
+	// 热路径（--redis_opt=perf=true）：key 以 strconv 追加拼接，GetFields/SetFields 的命令参数与编码缓冲取自池，
+	// 生成 MarshalRedisProtoAppend，protobuf 解码在数据随即被拷贝或解析时不再先拷贝（见 codeTemplatePerfHelpers）
+	Perf bool
+
+	// 内存实现（--redis_opt=mem=true）：额外输出 MemFilename（NewRedisMemExecutor）并为每张表生成 New<Message>MemRepository，
+	// 供单元测试使用；默认不生成，生产包不携带内存执行器
+	Mem bool
+
+	// 转换函数（见 ValidateConvert）：PBPackage 为 protoc-gen-go 生成代码的 Go 导入路径，设置后为每个 message 生成
+	// ToProto / From<Message>Proto；PBPackages 按 proto 文件路径单独指定（pb_package=<文件>=<导入路径>），优先于 PBPackage
+	PBPackage  string
+	PBPackages map[string]string
+
+	// 兼容性检查（见 CheckCompat）：Compat 为旧版本 FileDescriptorSet 的路径，为空时不检查；
+	// CompatKeyFormat 为旧版本的 key_format（不在 descriptor 中），为空时不比较；
+	// AllowedBreaking 为放行的不兼容变更（全名或 "*"），可多次指定
+	Compat          string
+	CompatKeyFormat string
+	AllowedBreaking []string
+
+	// 约定规则（见 ValidateConventions）：Prefix 为 message 名前缀（为空时不检查），MaxFieldNumber 为字段编号上限，
+	// RuleLevels 为经 rule.<规则> 参数调整过级别的规则
+	Prefix         string
+	MaxFieldNumber int
+	RuleLevels     map[string]string
+}
+
+// DefaultOptions 返回未指定任何参数时的生成选项。
+func DefaultOptions() *Options {
+	return &Options{
+		KeyFormat: DefaultKeyFormat,
+		Executor:  ExecutorRedigo,
+		Helpers:   HelpersStandalone,
+		Codec:     CodecInline,
+		Prefix:    "DB",
+	}
+}
+
+// Set 解析单个插件参数，签名与 protogen.Options.ParamFunc 一致。
+// paths 等 protogen 自身识别的参数不会传到这里。
+func (o *Options) Set(name, value string) error {
+	switch name {
+	case "key_format":
+		o.KeyFormat = value
+	case "executor":
+		switch value {
+		case ExecutorRedigo, ExecutorGoRedis:
+			o.Executor = value
+		default:
+			return fmt.Errorf("参数 executor 取值 %q 无效，可选 %s / %s", value, ExecutorRedigo, ExecutorGoRedis)
+		}
+	case "mode":
+		if value != ModeAttach {
+			return fmt.Errorf("参数 mode 取值 %q 无效，可选 %s", value, ModeAttach)
+		}
+		o.Mode = value
+	case "manifest":
+		if value != ManifestJSON {
+			return fmt.Errorf("参数 manifest 取值 %q 无效，可选 %s", value, ManifestJSON)
+		}
+		o.Manifest = value
+	case "helpers":
+		switch value {
+		case HelpersStandalone, HelpersRuntime:
+			o.Helpers = value
+		default:
+			return fmt.Errorf("参数 helpers 取值 %q 无效，可选 %s / %s", value, HelpersStandalone, HelpersRuntime)
+		}
+	case "codec":
+		switch value {
+		case CodecInline, CodecTable:
+			o.Codec = value
+		default:
+			return fmt.Errorf("参数 codec 取值 %q 无效，可选 %s / %s", value, CodecInline, CodecTable)
+		}
+	case "perf":
+		perf, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("参数 perf 取值 %q 无效，可选 true / false", value)
+		}
+		o.Perf = perf
+	case "mem":
+		mem, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("参数 mem 取值 %q 无效，可选 true / false", value)
+		}
+		o.Mem = mem
+	case "pb_package":
+		file, path, perFile := strings.Cut(value, "=")
+		if !perFile {
+			file, path = "", value
+		}
+		if path == "" {
+			return fmt.Errorf("参数 pb_package 不能为空，取值为导入路径或 <proto 文件>=<导入路径>")
+		}
+		if !perFile {
+			o.PBPackage = path
+			break
+		}
+		if o.PBPackages == nil {
+			o.PBPackages = make(map[string]string)
+		}
+		o.PBPackages[file] = path
+	case "prefix":
+		o.Prefix = value
+	case "max_field_number":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("参数 max_field_number 取值 %q 无效，须为正整数", value)
+		}
+		o.MaxFieldNumber = n
+	case "compat":
+		o.Compat = value
+	case "compat_key_format":
+		o.CompatKeyFormat = value
+	case "allow_breaking":
+		if value == "" {
+			return fmt.Errorf("参数 allow_breaking 不能为空，取值为 message / 字段 / 枚举全名或 *")
+		}
+		o.AllowedBreaking = append(o.AllowedBreaking, value)
+	default:
+		if rule, ok := strings.CutPrefix(name, "rule."); ok {
+			return o.setRuleLevel(rule, value)
+		}
+		return fmt.Errorf("unknown parameter %q", name)
+	}
+	return nil
+}
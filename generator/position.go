@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Position 是 proto 源码中的位置，行列从 1 开始；descriptor 不带 SourceCodeInfo 时只有文件名。
+type Position struct {
+	Filename     string
+	Line, Column int
+}
+
+// String 返回 "文件:行:列"（IDE 与 CI 可识别为源码链接），没有行列信息时只返回文件名。
+func (p Position) String() string {
+	if p.Line == 0 {
+		return p.Filename
+	}
+	return p.Filename + ":" + strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// PositionOf 返回 message / 字段 / 枚举等定义在所在 proto 文件中的位置（取自 SourceCodeInfo）。
+func PositionOf(desc protoreflect.Descriptor) Position {
+	file := desc.ParentFile()
+	if file == nil {
+		return Position{}
+	}
+	pos := Position{Filename: file.Path()}
+	if loc := file.SourceLocations().ByDescriptor(desc); loc.Path != nil {
+		pos.Line, pos.Column = loc.StartLine+1, loc.StartColumn+1
+	}
+	return pos
+}
+
+// Error 是指向 proto 源码位置的生成错误。
+type Error struct {
+	Pos     Position
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + e.Message
+}
+
+// errorAt 返回位置为 desc 定义处的 *Error。
+func errorAt(desc protoreflect.Descriptor, format string, args ...interface{}) error {
+	return &Error{Pos: PositionOf(desc), Message: fmt.Sprintf(format, args...)}
+}
@@ -4,11 +4,13 @@ const codeTemplateHead = `
 // Code generated by protoc-gen-redis. DO NOT EDIT.
 
 package {{.PackageName}}
+{{- if .Imports}}
 
 import (
 	{{range .Imports}} "{{.}}"
 	{{end}}
 )
+{{- end}}
 
 `
 
@@ -22,888 +24,5537 @@ const (
 	{{$v.Name}} {{$e.Name}} = {{$v.Value}}
 	{{- end}}
 )
+{{- if $e.NameMaps}}
+
+// {{$e.Name}}_name 与 {{$e.Name}}_value 是枚举值与 proto 中名字的对照表（JSON 编码与 enum_storage=ENUM_STORAGE_NAME 中枚举以名字表示）
+var (
+	{{$e.Name}}_name = map[int32]string{
+		{{- range $v := $e.Values}}{{if not $v.Alias}}
+		{{$v.Value}}: {{printf "%q" $v.ProtoName}},
+		{{- end}}{{end}}
+	}
+	{{$e.Name}}_value = map[string]int32{
+		{{- range $v := $e.Values}}
+		{{printf "%q" $v.ProtoName}}: {{$v.Value}},
+		{{- end}}
+	}
+)
+{{- end}}
 {{end}}
 `
 
-// codeTemplateProtoHelpers 是 protobuf wire format 的辅助函数，
-// 文件内存在 message 或集合字段时随文件头输出一次（redisProto 前缀避免与用户代码冲突）。
-// 编码规则与 protobuf 规范一致：tag = field<<3 | wireType，
-// wireType：0=varint，1=fixed64，2=length-delimited，5=fixed32。
-const codeTemplateProtoHelpers = `
-// --- protobuf wire format 辅助函数（语言无关序列化，规则见 https://protobuf.dev/programming-guides/encoding/） ---
+// codeTemplateExecutor 是生成代码执行 Redis 命令的最小接口与所选客户端的适配器，每个 Go 包在辅助代码文件（HelpersFilename）中输出一次。
+// 读写方法的实现统一面向 RedisExecutor（GetFieldsExec/SetFieldsExec），
+// GetFields/SetFields 只是用 --redis_opt=executor=... 选定的适配器包装一层，保持既有调用方式不变。
+// 适配器负责把回复归一为 redigo 风格：bulk string 为 []byte、nil 回复为 nil、数组为 []interface{}；
+// 接口方法均接收 context.Context，截止时间与取消信号会传到每一条命令（含 pipeline 与事务）。
+const codeTemplateExecutor = `
+// --- Redis 命令执行接口 ---
 
-// redisProtoAppendVarint 追加一个 base-128 varint 编码的 uint64
-func redisProtoAppendVarint(buf []byte, v uint64) []byte {
-	for v >= 0x80 {
-		buf = append(buf, byte(v)|0x80)
-		v >>= 7
-	}
-	return append(buf, byte(v))
+// RedisCmd 是一条待执行的 Redis 命令
+type RedisCmd struct {
+	Name string
+	Args []interface{}
 }
 
-// redisProtoReadVarint 读取一个 varint，返回（值，消耗字节数）
-func redisProtoReadVarint(b []byte) (uint64, int, error) {
-	var v uint64
-	for i := 0; i < len(b) && i < 10; i++ {
-		v |= uint64(b[i]&0x7F) << (7 * i)
-		if b[i]&0x80 == 0 {
-			return v, i + 1, nil
-		}
-	}
-	return 0, 0, fmt.Errorf("protobuf varint 读取失败: 数据截断或过长")
+// RedisExecutor 是生成代码执行 Redis 命令所需的最小接口。
+// 回复遵循 redigo 约定：bulk string 为 []byte，不存在为 nil，数组为 []interface{}。
+// ctx 的截止时间与取消须作用于整次调用（pipeline/事务的全部命令）。
+// 自定义实现（如 mock、其他客户端）只需满足该接口即可调用 GetFieldsExec/SetFieldsExec。
+type RedisExecutor interface {
+	// Do 执行单条命令
+	Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error)
+	// Pipeline 一次往返批量发送多条命令（非原子），按顺序返回各命令的回复
+	Pipeline(ctx context.Context, cmds []RedisCmd) ([]interface{}, error)
+	// Multi 以 MULTI/EXEC 事务原子执行多条命令，按顺序返回各命令的回复；
+	// 事务中某条命令执行失败时返回 *RedisTxError（Redis 不回滚事务中其余命令的效果）
+	Multi(ctx context.Context, cmds []RedisCmd) ([]interface{}, error)
 }
 
-// redisProtoAppendTag 追加字段 tag（field<<3 | wireType）
-func redisProtoAppendTag(buf []byte, field, wire int32) []byte {
-	return redisProtoAppendVarint(buf, uint64(field)<<3|uint64(wire))
+// RedisTxError 表示事务（MULTI/EXEC）中第 Index 条命令执行失败，如对 sorted set 索引 key 执行 ZADD 时 key 的类型错误。
+// Redis 不回滚事务，其余命令照常生效；Err 为该命令的错误，可用 errors.Is / errors.As 识别
+type RedisTxError struct {
+	Index int    // 失败的命令在 cmds 中的下标
+	Cmd   string // 失败的命令名
+	Err   error
 }
 
-// redisProtoAppendLen 追加 length-delimited 数据（长度前缀 + 数据）
-func redisProtoAppendLen(buf, payload []byte) []byte {
-	buf = redisProtoAppendVarint(buf, uint64(len(payload)))
-	return append(buf, payload...)
+func (e *RedisTxError) Error() string {
+	return fmt.Sprintf("事务中第 %d 条命令 %s 失败: %v", e.Index+1, e.Cmd, e.Err)
 }
 
-// redisProtoReadBytes 读取 length-delimited 数据，返回（数据拷贝，消耗字节数）；
-// 返回拷贝避免与输入缓冲区 alias。
-func redisProtoReadBytes(b []byte) ([]byte, int, error) {
-	n, k, err := redisProtoReadVarint(b)
-	if err != nil {
-		return nil, 0, err
-	}
-	if n > uint64(len(b)-k) {
-		return nil, 0, fmt.Errorf("protobuf length-delimited 数据截断: 期望 %d 字节, 剩余 %d", n, len(b)-k)
-	}
-	return append([]byte(nil), b[k:k+int(n)]...), k + int(n), nil
-}
+func (e *RedisTxError) Unwrap() error { return e.Err }
 
-// redisProtoAppendFixed32 追加小端 4 字节
-func redisProtoAppendFixed32(buf []byte, v uint32) []byte {
-	return append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+// ErrRedisTxAborted 表示 WATCH 的 key 在 EXEC 之前被修改，事务被放弃、没有执行任何命令（见 RedisWatcher）
+var ErrRedisTxAborted = errors.New("redis: WATCH 的 key 已被修改，事务被放弃")
+
+// RedisWatcher 是支持乐观锁的执行器，内置的 redigo、go-redis 适配器与内存执行器均实现。
+// Watch 对 keys 执行 WATCH 后调用 fn：fn 收到的执行器与 WATCH 在同一连接上，其中的读取与随后的 Multi 构成一次 check-and-set，
+// keys 在 Multi 之前被修改时 Multi 返回 ErrRedisTxAborted；fn 返回后 WATCH 随之解除。
+// 写入唯一索引字段与删除记录时生成代码经它保证索引与记录一致（见 redisWatch），未实现它的自定义执行器不加锁。
+type RedisWatcher interface {
+	Watch(ctx context.Context, keys []string, fn func(exec RedisExecutor) error) error
 }
 
-// redisProtoReadFixed32 读取小端 4 字节
-func redisProtoReadFixed32(b []byte) (uint32, int, error) {
-	if len(b) < 4 {
-		return 0, 0, fmt.Errorf("protobuf fixed32 数据截断")
+// redisWatchRetries 是 redisWatch 因 WATCH 的 key 被并发修改而重新执行的次数上限
+const redisWatchRetries = 16
+
+// redisWatch 在支持 WATCH 的执行器上以乐观锁执行 fn，事务被放弃（ErrRedisTxAborted）时重新执行，
+// 超过 redisWatchRetries 次仍冲突时返回 ErrRedisTxAborted；执行器未实现 RedisWatcher 时直接执行一次 fn
+func redisWatch(ctx context.Context, exec RedisExecutor, keys []string, fn func(exec RedisExecutor) error) error {
+	w, ok := exec.(RedisWatcher)
+	if !ok {
+		return fn(exec)
+	}
+	for i := 1; ; i++ {
+		err := w.Watch(ctx, keys, fn)
+		if i == redisWatchRetries || !errors.Is(err, ErrRedisTxAborted) {
+			return err
+		}
 	}
-	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24, 4, nil
 }
 
-// redisProtoAppendFixed64 追加小端 8 字节
-func redisProtoAppendFixed64(buf []byte, v uint64) []byte {
-	return append(buf,
-		byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
-		byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
-}
+// redisAcquireFunc 为一次调用取得 RedisExecutor，调用结束后执行 release 归还底层连接（<Message>Store 使用）
+type redisAcquireFunc func(ctx context.Context) (exec RedisExecutor, release func(), err error)
 
-// redisProtoReadFixed64 读取小端 8 字节
-func redisProtoReadFixed64(b []byte) (uint64, int, error) {
-	if len(b) < 8 {
-		return 0, 0, fmt.Errorf("protobuf fixed64 数据截断")
-	}
-	var v uint64
-	for i := 0; i < 8; i++ {
-		v |= uint64(b[i]) << (8 * i)
+// redisExecAcquire 直接使用给定执行器，无需归还
+func redisExecAcquire(exec RedisExecutor) redisAcquireFunc {
+	return func(context.Context) (RedisExecutor, func(), error) {
+		return exec, func() {}, nil
 	}
-	return v, 8, nil
 }
 
-// redisProtoSkip 跳过未知字段，返回消耗字节数
-func redisProtoSkip(b []byte, wire uint64) (int, error) {
-	switch wire {
-	case 0: // varint
-		_, n, err := redisProtoReadVarint(b)
-		return n, err
-	case 1: // fixed64
-		if len(b) < 8 {
-			return 0, fmt.Errorf("protobuf fixed64 数据截断")
-		}
-		return 8, nil
-	case 2: // length-delimited
-		_, n, err := redisProtoReadBytes(b)
-		return n, err
-	case 5: // fixed32
-		if len(b) < 4 {
-			return 0, fmt.Errorf("protobuf fixed32 数据截断")
+// redisWithScores 把 ZRANGE / ZREVRANGE ... WITHSCORES 的回复解析为成员与分数交替的数组；
+// RESP3 下回复为 [成员, 分数] 数组的数组，展开为 RESP2 的交替形式
+func redisWithScores(cmd string, reply interface{}) ([]interface{}, error) {
+	values, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("解析 %s 结果失败: 意外的回复 %T", cmd, reply)
+	}
+	if len(values) > 0 {
+		if _, nested := values[0].([]interface{}); nested {
+			flat := make([]interface{}, 0, 2*len(values))
+			for _, pair := range values {
+				if p, ok := pair.([]interface{}); ok && len(p) == 2 {
+					flat = append(flat, p[0], p[1])
+				}
+			}
+			values = flat
 		}
-		return 4, nil
-	default:
-		return 0, fmt.Errorf("protobuf 未知 wire type %d", wire)
 	}
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("解析 %s 结果失败: 元素个数 %d 不是偶数", cmd, len(values))
+	}
+	return values, nil
 }
 
-`
-
-// codeTemplate 按 message 生成 Redis 存取代码。
-// 字段的 protobuf 编码/解码逻辑抽成 fieldEncode / fieldDecode 两个模板块，
-// 整体序列化（MarshalRedisProto / UnmarshalRedisProto）与集合字段（map/repeated）
-// 的字段级序列化方法（MarshalRedisProto<Field> / UnmarshalRedisProto<Field>）共用同一份逻辑。
-const codeTemplate = `
-// {{.FieldType}} 用于标识 Redis Hash 中的字段编号
-type {{.FieldType}} uint32
-
-{{range .Fields}}
-// {{$.FieldType}}_{{.Name}} 是字段 {{.Name}} 对应的 Redis Hash field 编号
-const {{$.FieldType}}_{{.Name}} {{$.FieldType}} = {{.ProtoTag}}
-{{end}}
+// redisRecordMember 是一条记录在 sorted set 索引与唯一索引中的成员："<ida>:<idb>"
+func redisRecordMember(ida, idb uint64) string {
+	return strconv.FormatUint(ida, 10) + ":" + strconv.FormatUint(idb, 10)
+}
 
-// {{.FieldType}}IDs 是所有字段编号常量的集合，类型为 []{{.FieldType}}
-var {{.FieldType}}IDs = []{{.FieldType}}{
-	{{range .Fields}}{{$.FieldType}}_{{.Name}},
-	{{end}}
+// redisParseRecordMember 是 redisRecordMember 的逆过程
+func redisParseRecordMember(member []byte) (ida, idb uint64, err error) {
+	a, b, ok := strings.Cut(string(member), ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("解析记录成员 %q 失败: 缺少分隔符", member)
+	}
+	if ida, err = strconv.ParseUint(a, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("解析记录成员 %q 失败: %v", member, err)
+	}
+	if idb, err = strconv.ParseUint(b, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("解析记录成员 %q 失败: %v", member, err)
+	}
+	return ida, idb, nil
 }
 
-// {{.MessageName}} 提供针对 {{.MessageName}} 消息的 Redis 存取操作
-type {{.MessageName}} struct {
-	{{range .Fields}}
-	{{.Name}} {{.GoType}}
-	{{end}}
+// RedisUniqueConflictError 表示唯一索引字段的值已被其他记录占用：写入该字段的 SetFields / Set 返回此错误，不修改任何数据
+type RedisUniqueConflictError struct {
+	Field string // 字段的 Go 名
+	Value string // 冲突的值
+	Ida   uint64 // 占用该值的记录
+	Idb   uint64
 }
 
-// New{{.MessageName}} 创建一个新的 {{.MessageName}} 实例
-func New{{.MessageName}}() *{{.MessageName}} {
-	return &{{.MessageName}}{}
+func (e *RedisUniqueConflictError) Error() string {
+	return fmt.Sprintf("字段 %s 的值 %q 已被记录 %d:%d 占用", e.Field, e.Value, e.Ida, e.Idb)
 }
 
-// MarshalRedisProto 将 {{.MessageName}} 序列化为 protobuf wire format 字节流。
-// 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
-// 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
-// repeated 逐元素编码（含零值），map 每键值对编码为子消息（field 1=key, field 2=value）。
-func (p *{{.MessageName}}) MarshalRedisProto() ([]byte, error) {
-	var buf []byte
-{{range .Fields}}{{template "fieldEncode" .}}{{end}}
-	return buf, nil
+// redisUniqueClaim 是写入唯一索引字段时对索引条目的占用请求
+type redisUniqueClaim struct {
+	Field     string      // 字段的 Go 名（冲突错误使用）
+	HashField interface{} // 字段在 Redis Hash 中的 field（读取旧值）
+	Key       string      // 唯一索引 hash 的 key
+	Value     []byte      // 新值的编码，零值为 nil（不占用索引）
 }
 
-// UnmarshalRedisProto 从 protobuf wire format 字节流反序列化到 {{.MessageName}}。
-// 反序列化前会先重置自身；未知字段跳过，缺失字段保持零值（proto3 语义）。
-func (p *{{.MessageName}}) UnmarshalRedisProto(b []byte) error {
-	*p = {{.MessageName}}{}
-	for len(b) > 0 {
-		tag, n, err := redisProtoReadVarint(b)
+// redisUniqueSet 写入带唯一索引字段的记录 key：占用 claims 的新值（HSETNX，值为 member），再在一个事务中执行 write 并释放旧值。
+// 执行器支持 WATCH 时全程 WATCH key（见 redisWatch），记录在读取旧值之后被其他调用修改时事务放弃，重新读取旧值后重试，
+// 不会按过期的旧值释放条目；值已被其他记录占用时返回 *RedisUniqueConflictError，不写入。
+// 失败时撤销本次新占用、且记录最终没有使用的条目（见 redisUniqueRollback）。
+func redisUniqueSet(ctx context.Context, exec RedisExecutor, key, member string, claims []redisUniqueClaim, write []RedisCmd) error {
+	var claimed []redisUniqueClaim
+	err := redisWatch(ctx, exec, []string{key}, func(exec RedisExecutor) error {
+		release, newly, err := redisUniqueAcquire(ctx, exec, key, member, claims)
+		claimed = append(claimed, newly...)
 		if err != nil {
-			return fmt.Errorf("protobuf 读取字段 tag 失败: %v", err)
+			return err
 		}
-		b = b[n:]
-		field := tag >> 3
-		wire := tag & 7
-		switch field {
-		{{range .Fields}}
-		case {{.ProtoTag}}: // {{.Name}}
-{{template "fieldDecode" .}}
-		{{end}}
-		default:
-			n, err = redisProtoSkip(b, wire)
-			if err != nil {
-				return err
+		cmds := make([]RedisCmd, 0, len(write)+len(release))
+		_, err = exec.Multi(ctx, append(append(cmds, write...), release...))
+		return err
+	})
+	if err != nil {
+		redisUniqueRollback(ctx, exec, key, member, claimed)
+	}
+	return err
+}
+
+// redisUniqueAcquire 为 claims 占用唯一索引条目，并找出改值后要释放的旧条目：
+// 读旧值、占用新值与读回占用者在一次往返中完成，release 是仍由 member 占用的旧条目的 HDEL（应与写入放在同一事务中），
+// claimed 是本次新占用的条目（出错时也返回，由调用方撤销）；值已被其他记录占用时返回 *RedisUniqueConflictError。
+func redisUniqueAcquire(ctx context.Context, exec RedisExecutor, key, member string, claims []redisUniqueClaim) (release []RedisCmd, claimed []redisUniqueClaim, err error) {
+	cmds := make([]RedisCmd, 0, 3*len(claims))
+	for _, c := range claims {
+		cmds = append(cmds, RedisCmd{Name: "HGET", Args: []interface{}{key, c.HashField} })
+		if c.Value != nil {
+			cmds = append(cmds,
+				RedisCmd{Name: "HSETNX", Args: []interface{}{c.Key, c.Value, member} },
+				RedisCmd{Name: "HGET", Args: []interface{}{c.Key, c.Value} })
+		}
+	}
+	replies, err := exec.Pipeline(ctx, cmds)
+	if err != nil {
+		return nil, nil, err
+	}
+	var conflict error
+	var stale []RedisCmd
+	for _, c := range claims {
+		old, _ := replies[0].([]byte)
+		replies = replies[1:]
+		if c.Value != nil {
+			n, _ := replies[0].(int64)
+			owner, _ := replies[1].([]byte)
+			replies = replies[2:]
+			if n == 1 {
+				claimed = append(claimed, c)
+			} else if string(owner) != member && conflict == nil {
+				ida, idb, _ := redisParseRecordMember(owner)
+				conflict = &RedisUniqueConflictError{Field: c.Field, Value: string(c.Value), Ida: ida, Idb: idb}
 			}
-			b = b[n:]
+		}
+		if len(old) > 0 && string(old) != string(c.Value) {
+			stale = append(stale, RedisCmd{Name: "HGET", Args: []interface{}{c.Key, old} })
 		}
 	}
-	return nil
+	if conflict != nil {
+		return nil, claimed, conflict
+	}
+	release, err = redisUniqueOwned(ctx, exec, member, stale)
+	return release, claimed, err
 }
 
-{{range .Fields}}
-{{if or (eq .Kind "map") (eq .Kind "slice")}}
-// MarshalRedisProto{{.Name}} 将字段 {{.Name}}（集合字段）整体序列化为 protobuf wire format 字节，
-// 即 {{.Name}} 在 Redis Hash 中的值（hash field = tag {{.ProtoTag}}）
-func (p *{{$.MessageName}}) MarshalRedisProto{{.Name}}() ([]byte, error) {
-	var buf []byte
-{{template "fieldEncode" .}}
-	return buf, nil
+// redisUniqueOwned 执行 gets（HGET 索引 key 与值），返回其中仍由 member 占用的条目的 HDEL 命令
+func redisUniqueOwned(ctx context.Context, exec RedisExecutor, member string, gets []RedisCmd) ([]RedisCmd, error) {
+	if len(gets) == 0 {
+		return nil, nil
+	}
+	replies, err := exec.Pipeline(ctx, gets)
+	if err != nil {
+		return nil, err
+	}
+	var release []RedisCmd
+	for i, reply := range replies {
+		if owner, _ := reply.([]byte); string(owner) == member {
+			release = append(release, RedisCmd{Name: "HDEL", Args: gets[i].Args})
+		}
+	}
+	return release, nil
 }
 
-// UnmarshalRedisProto{{.Name}} 从 {{.Name}} 字段的 protobuf wire format 字节反序列化
-// （字节须为 MarshalRedisProto{{.Name}} 的输出，或等价的单字段 protobuf 编码）
-func (p *{{$.MessageName}}) UnmarshalRedisProto{{.Name}}(b []byte) error {
-	p.{{.Name}} = nil
-	for len(b) > 0 {
-		tag, n, err := redisProtoReadVarint(b)
+// redisUniqueRollback 尽力撤销本次新占用的唯一索引条目（ctx 已取消时仍执行）：WATCH 记录 key 后读出字段当前值，
+// 跳过记录正在使用的值（同一记录的并发写入可能已把它写入记录），其余仍由 member 占用的条目在事务中 HDEL。
+// 失败时条目保留，需人工清理
+func redisUniqueRollback(ctx context.Context, exec RedisExecutor, key, member string, claimed []redisUniqueClaim) {
+	if len(claimed) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	_ = redisWatch(ctx, exec, []string{key}, func(exec RedisExecutor) error {
+		cmds := make([]RedisCmd, 0, 2*len(claimed))
+		for _, c := range claimed {
+			cmds = append(cmds,
+				RedisCmd{Name: "HGET", Args: []interface{}{key, c.HashField} },
+				RedisCmd{Name: "HGET", Args: []interface{}{c.Key, c.Value} })
+		}
+		replies, err := exec.Pipeline(ctx, cmds)
 		if err != nil {
 			return err
 		}
-		if tag>>3 != {{.ProtoTag}} {
-			return fmt.Errorf("protobuf 字段 %s tag 不匹配: %d", "{{.Name}}", tag>>3)
+		var dels []RedisCmd
+		for i, c := range claimed {
+			current, _ := replies[2*i].([]byte)
+			owner, _ := replies[2*i+1].([]byte)
+			if string(current) != string(c.Value) && string(owner) == member {
+				dels = append(dels, RedisCmd{Name: "HDEL", Args: []interface{}{c.Key, c.Value} })
+			}
 		}
-		b = b[n:]
-		{
-			wire := tag & 7
-{{template "fieldDecode" .}}
+		if len(dels) == 0 {
+			return nil
 		}
-	}
-	return nil
+		_, err = exec.Multi(ctx, dels)
+		return err
+	})
 }
-{{end}}
-{{end}}
 
-// GetFields 从 Redis Hash 中读取指定字段的值，填充到当前结构体实例中
-// conn: Redis 连接
-// REDBKey: 业务维度 Key
-// ida, idb: 用于组成唯一 Hash Key 的两个 uint64 分片维度
-// fields: 要读取的字段编号列表，如 {{.FieldType}}_Name, {{.FieldType}}_Age
-//          如果 fields 为空（长度为 0），则默认读取所有字段（即 {{.FieldType}}IDs）
-//          集合字段（map/repeated）整体 protobuf 反序列化
-func (p *{{.MessageName}}) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
-	key := fmt.Sprintf({{printf "%q" .KeyFormat}}, REDBKey, ida, idb)
+// redisHashMove 是 tag_fallback 迁移窗口中一个字段从字段编号 field 到名字 field 的搬迁
+type redisHashMove struct {
+	Tag  uint32 // 旧的字段编号 field
+	Name string // 新的名字 field
+}
 
-	// 决定要操作的字段列表
-	fieldsToUse := fields
-	if len(fieldsToUse) == 0 {
-		fieldsToUse = {{.FieldType}}IDs
+// redisMoveHashFields 把 key 中仍存于字段编号 field 下的值搬到名字 field：名字 field 已存在时保留它（HSETNX），随后删除编号 field。
+// 读出与搬迁之间不加锁，期间旧版本程序写入编号 field 的值会被删除，迁移窗口内应只有新版本程序写入。
+func redisMoveHashFields(ctx context.Context, exec RedisExecutor, key string, moves []redisHashMove) error {
+	if len(moves) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, 1+len(moves))
+	args = append(args, key)
+	for _, m := range moves {
+		args = append(args, m.Tag)
+	}
+	reply, err := exec.Do(ctx, "HMGET", args...)
+	if err != nil {
+		return fmt.Errorf("HMGET 失败: %w", err)
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(moves) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
 	}
+	var cmds []RedisCmd
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		cmds = append(cmds,
+			RedisCmd{Name: "HSETNX", Args: []interface{}{key, moves[i].Name, v} },
+			RedisCmd{Name: "HDEL", Args: []interface{}{key, moves[i].Tag} })
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	_, err = exec.Multi(ctx, cmds)
+	return err
+}
 
-	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
-	args := []interface{}{key}
-	for _, fieldID := range fieldsToUse {
-		args = append(args, fieldID)
+{{if eq .Executor "goredis"}}
+// NewGoRedisExecutor 把 go-redis v9 客户端（*redis.Client / *redis.ClusterClient / *redis.Ring 等）包装为 RedisExecutor
+func NewGoRedisExecutor(client redis.UniversalClient) RedisExecutor {
+	return redisGoRedisExecutor{client: client}
+}
+
+type redisGoRedisExecutor struct {
+	client redis.UniversalClient
+}
+
+func (e redisGoRedisExecutor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return redisGoRedisReply(e.client.Do(ctx, append([]interface{}{cmd}, args...)...).Result())
+}
+
+func (e redisGoRedisExecutor) Pipeline(ctx context.Context, cmds []RedisCmd) ([]interface{}, error) {
+	return redisGoRedisExec(ctx, e.client.Pipeline(), cmds, false)
+}
+
+func (e redisGoRedisExecutor) Multi(ctx context.Context, cmds []RedisCmd) ([]interface{}, error) {
+	return redisGoRedisExec(ctx, e.client.TxPipeline(), cmds, true)
+}
+
+// Watch 实现 RedisWatcher：经 client.Watch 在同一连接上 WATCH keys 并执行 fn（集群模式下 keys 须在同一 slot）
+func (e redisGoRedisExecutor) Watch(ctx context.Context, keys []string, fn func(exec RedisExecutor) error) error {
+	return e.client.Watch(ctx, func(tx *redis.Tx) error {
+		return fn(redisGoRedisWatched{tx: tx})
+	}, keys...)
+}
+
+// redisGoRedisWatched 是 Watch 交给 fn 的执行器，命令都在 WATCH 所在的连接上执行
+type redisGoRedisWatched struct {
+	tx *redis.Tx
+}
+
+func (e redisGoRedisWatched) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	c := redis.NewCmd(ctx, append([]interface{}{cmd}, args...)...)
+	_ = e.tx.Process(ctx, c)
+	return redisGoRedisReply(c.Result())
+}
+
+func (e redisGoRedisWatched) Pipeline(ctx context.Context, cmds []RedisCmd) ([]interface{}, error) {
+	return redisGoRedisExec(ctx, e.tx.Pipeline(), cmds, false)
+}
+
+func (e redisGoRedisWatched) Multi(ctx context.Context, cmds []RedisCmd) ([]interface{}, error) {
+	replies, err := redisGoRedisExec(ctx, e.tx.TxPipeline(), cmds, true)
+	if errors.Is(err, redis.TxFailedErr) {
+		return nil, ErrRedisTxAborted
 	}
+	return replies, err
+}
 
-	// 一次 HMGET 获取所有字段值
-	reply, err := conn.Do("HMGET", args...)
-	if err != nil {
-		return fmt.Errorf("HMGET 失败: %v", err)
+// redisGoRedisExec 在 pipe 中排队 cmds 并一次执行；单条命令的 nil 回复不视为错误。
+// 命令返回的错误（redis.Error）定位到第一条失败的命令，事务（tx）中包装为 *RedisTxError；连接等整体错误原样返回
+func redisGoRedisExec(ctx context.Context, pipe redis.Pipeliner, cmds []RedisCmd, tx bool) ([]interface{}, error) {
+	results := make([]*redis.Cmd, len(cmds))
+	for i, c := range cmds {
+		results[i] = pipe.Do(ctx, append([]interface{}{c.Name}, c.Args...)...)
+	}
+	_, execErr := pipe.Exec(ctx)
+	if _, ok := execErr.(redis.Error); execErr != nil && !ok {
+		return nil, execErr
+	}
+	replies := make([]interface{}, len(results))
+	for i, r := range results {
+		v, err := redisGoRedisReply(r.Result())
+		if err != nil {
+			if tx {
+				return nil, &RedisTxError{Index: i, Cmd: cmds[i].Name, Err: err}
+			}
+			return nil, err
+		}
+		replies[i] = v
 	}
+	if execErr != nil && execErr != redis.Nil {
+		return nil, execErr
+	}
+	return replies, nil
+}
 
-	// 解析返回的 []interface{} 列表
-	values, err := redis.Values(reply, nil)
+// redisGoRedisReply 把 go-redis 的回复归一为 redigo 风格：string / RESP3 double -> []byte，RESP3 map -> 键值交替数组，redis.Nil -> nil
+func redisGoRedisReply(reply interface{}, err error) (interface{}, error) {
+	if err == redis.Nil {
+		return nil, nil
+	}
 	if err != nil {
-		return fmt.Errorf("解析 HMGET 结果失败: %v", err)
+		return nil, err
 	}
-
-	// 逐一处理每个字段
-	fieldIndex := 0
-	for _, fieldID := range fieldsToUse {
-		switch fieldID {
-		{{range .Fields}}
-		case {{$.FieldType}}_{{.Name}}:
-			{{if eq .Kind "plain"}}
-			{{if .IsMsg}}
-			// --- Protobuf 反序列化字段: {{.Name}} ---
-			if val, ok := values[fieldIndex].([]byte); ok && val != nil {
-				if err := p.{{.Name}}.UnmarshalRedisProto(val); err != nil {
-					return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
-				}
-			}
-			{{else}}
-			// --- 直读字段: {{.Name}} ---
-			if val, ok := values[fieldIndex].([]byte); ok && val != nil {
-				{{if .IsEnum}}
-				intValue, err := strconv.ParseInt(string(val), 10, 64)
-				if err != nil {
-					return fmt.Errorf("解析枚举字段 %s 失败: %v", "{{.Name}}", err)
-				}
-				p.{{.Name}} = {{.GoType}}(int32(intValue))
-				{{else if eq .GoType "string"}}
-				p.{{.Name}} = string(val)
-				{{else if eq .GoType "uint64"}}
-				id, err := strconv.ParseUint(string(val), 10, 64)
-				if err != nil {
-					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
-				}
-				p.{{.Name}} = id
-				{{else if eq .GoType "int64"}}
-				id, err := strconv.ParseInt(string(val), 10, 64)
-				if err != nil {
-					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
-				}
-				p.{{.Name}} = id
-				{{else if eq .GoType "uint32"}}
-				id, err := strconv.ParseUint(string(val), 10, 32)
-				if err != nil {
-					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
-				}
-				p.{{.Name}} = uint32(id)
-				{{else if eq .GoType "int32"}}
-				id, err := strconv.ParseInt(string(val), 10, 32)
-				if err != nil {
-					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
-				}
-				p.{{.Name}} = int32(id)
-				{{else if eq .GoType "float64"}}
-				f, err := strconv.ParseFloat(string(val), 64)
-				if err != nil {
-					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
-				}
-				p.{{.Name}} = f
-				{{else if eq .GoType "float32"}}
-				f, err := strconv.ParseFloat(string(val), 32)
-				if err != nil {
-					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
-				}
-				p.{{.Name}} = float32(f)
-				{{else if eq .GoType "bool"}}
-				if len(val) > 0 && val[0] == '1' {
-					p.{{.Name}} = true
-				} else if len(val) > 0 && val[0] == '0' {
-					p.{{.Name}} = false
-				}
-				{{else if eq .GoType "[]byte"}}
-				p.{{.Name}} = val
-				{{else}}
-				// 兜底：尝试字符串
-				p.{{.Name}} = string(val)
-				{{end}}
+	switch v := reply.(type) {
+	case string:
+		return []byte(v), nil
+	case float64:
+		// RESP3 double（如 ZSCORE / ZINCRBY）按 RESP2 的 bulk string 返回
+		return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+	case []interface{}:
+		for i := range v {
+			v[i], _ = redisGoRedisReply(v[i], nil)
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		// RESP3 map（如 HGETALL）展开为 RESP2 的键值交替数组
+		flat := make([]interface{}, 0, 2*len(v))
+		for k, val := range v {
+			k, _ = redisGoRedisReply(k, nil)
+			val, _ = redisGoRedisReply(val, nil)
+			flat = append(flat, k, val)
+		}
+		return flat, nil
+	default:
+		return reply, nil
+	}
+}
+{{else}}
+// RedisConnSource 是 redigo 连接来源，*redis.Pool 即满足；<Message>Store 每次调用借出一个连接，用完 Close 归还
+type RedisConnSource interface {
+	Get() redis.Conn
+}
+
+// redisPoolAcquire 从 pool 借出连接；pool 实现 GetContext 时（如 *redis.Pool）借连接也遵循 ctx
+func redisPoolAcquire(pool RedisConnSource) redisAcquireFunc {
+	return func(ctx context.Context) (RedisExecutor, func(), error) {
+		var conn redis.Conn
+		if p, ok := pool.(interface {
+			GetContext(context.Context) (redis.Conn, error)
+		}); ok {
+			c, err := p.GetContext(ctx)
+			if err != nil {
+				return nil, nil, err
 			}
-			{{end}}
-			{{else}}
-			// --- 集合字段: {{.Name}}（整体 protobuf 反序列化）---
-			if val, ok := values[fieldIndex].([]byte); ok && val != nil {
-				if err := p.UnmarshalRedisProto{{.Name}}(val); err != nil {
-					return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
-				}
+			conn = c
+		} else {
+			conn = pool.Get()
+			if err := conn.Err(); err != nil {
+				conn.Close()
+				return nil, nil, err
 			}
-			{{end}}
-		{{end}}
-		default:
-			return fmt.Errorf("未知字段编号: %d", fieldID)
 		}
-		fieldIndex++
+		return NewRedigoExecutor(conn), func() { conn.Close() }, nil
 	}
+}
 
-	return nil
+// NewRedigoExecutor 把 redigo 连接包装为 RedisExecutor（连接的生命周期仍由调用方管理）。
+// ctx 经 redis.DoContext 生效，conn 须实现 redis.ConnWithContext（redis.Dial 与 redis.Pool 返回的连接均已实现）；
+// pipeline 与事务逐条写入发送缓冲前检查 ctx，缓冲中的命令在 DoContext 中写出并读回回复，超时或取消后 redigo 会关闭该连接，
+// 阻塞中的写入随之返回。发送缓冲（4 KB）写满时 Send 直接写向网络，这部分只受连接自身的写超时（redis.DialWriteTimeout）约束。
+func NewRedigoExecutor(conn redis.Conn) RedisExecutor {
+	return redisRedigoExecutor{conn: conn}
 }
 
-// SetFields 将当前结构体实例的字段值，存储到 Redis Hash 中
-// conn: Redis 连接
-// REDBKey: 业务维度 Key
-// ida, idb: 用于组成唯一 Hash Key 的两个 uint64 分片维度
-// fields: 要存储的字段编号列表，如 {{.FieldType}}_Name, {{.FieldType}}_Age
-//          如果 fields 为空（长度为 0），则默认存储所有字段（即 {{.FieldType}}IDs）
-//          集合字段（map/repeated）整体 protobuf 序列化后写入
-func (p *{{.MessageName}}) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
-	key := fmt.Sprintf({{printf "%q" .KeyFormat}}, REDBKey, ida, idb)
-	args := []interface{}{key}
+type redisRedigoExecutor struct {
+	conn redis.Conn
+}
 
-	// 决定要操作的字段列表
-	fieldsToUse := fields
-	if len(fieldsToUse) == 0 {
-		fieldsToUse = {{.FieldType}}IDs
+func (e redisRedigoExecutor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	reply, err := redis.DoContext(e.conn, ctx, cmd, args...)
+	if err != nil {
+		return nil, redisRedigoCtxErr(ctx, err)
 	}
+	return reply, nil
+}
 
-	for _, fieldID := range fieldsToUse {
-		switch fieldID {
-		{{range .Fields}}
-		case {{$.FieldType}}_{{.Name}}:
-			{{if eq .Kind "plain"}}
-			{{if .IsMsg}}
-			// --- Protobuf 序列化字段: {{.Name}} ---
-			{
-				b, err := p.{{.Name}}.MarshalRedisProto()
-				if err != nil {
-					return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
-				}
-				args = append(args, fieldID, b)
-			}
-			{{else}}
-			// --- 直存字段: {{.Name}} ---
-			args = append(args, fieldID, p.{{.Name}})
-			{{end}}
-			{{else}}
-			// --- 集合字段: {{.Name}}（整体 protobuf 序列化）---
-			b, err := p.MarshalRedisProto{{.Name}}()
-			if err != nil {
-				return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
-			}
-			args = append(args, fieldID, b)
-			{{end}}
-		{{end}}
-		default:
-			return fmt.Errorf("未知字段编号: %d", fieldID)
+func (e redisRedigoExecutor) Pipeline(ctx context.Context, cmds []RedisCmd) ([]interface{}, error) {
+	if len(cmds) == 0 {
+		return nil, ctx.Err()
+	}
+	if err := redisRedigoSend(ctx, e.conn, cmds, false); err != nil {
+		return nil, err
+	}
+	// 一次读回全部回复（出错也读完，避免残留回复错位到后续命令）；超时/取消时 redigo 已关闭连接
+	replies, err := redis.Values(redis.DoContext(e.conn, ctx, ""))
+	if err != nil {
+		return nil, redisRedigoCtxErr(ctx, err)
+	}
+	for _, reply := range replies {
+		if err, ok := reply.(redis.Error); ok {
+			return nil, err
 		}
 	}
+	return replies, nil
+}
 
-	// 所有字段统一一次 HSET 写入
-	if len(args) > 1 {
-		_, err := conn.Do("HSET", args...)
+func (e redisRedigoExecutor) Multi(ctx context.Context, cmds []RedisCmd) ([]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := e.conn.Send("MULTI"); err != nil {
+		return nil, err
+	}
+	if err := redisRedigoSend(ctx, e.conn, cmds, true); err != nil {
+		return nil, err
+	}
+	values, err := redis.Values(redis.DoContext(e.conn, ctx, "EXEC"))
+	if err == redis.ErrNil {
+		// EXEC 回复 nil：WATCH 的 key 已被修改
+		return nil, ErrRedisTxAborted
+	}
+	if err != nil {
+		return nil, redisRedigoCtxErr(ctx, err)
+	}
+	// redigo 把事务中单条命令的错误放在 EXEC 的回复数组中，而不是作为 err 返回
+	for i, v := range values {
+		if err, ok := v.(redis.Error); ok {
+			return nil, &RedisTxError{Index: i, Cmd: cmds[i].Name, Err: err}
+		}
+	}
+	return values, nil
+}
+
+// Watch 实现 RedisWatcher：在本连接上 WATCH keys 后执行 fn；fn 没有执行事务就返回时发送 UNWATCH，连接不带着 WATCH 被复用
+func (e redisRedigoExecutor) Watch(ctx context.Context, keys []string, fn func(exec RedisExecutor) error) error {
+	args := make([]interface{}, len(keys))
+	for i, k := range keys {
+		args[i] = k
+	}
+	if _, err := e.Do(ctx, "WATCH", args...); err != nil {
 		return err
 	}
-	return nil
+	w := &redisRedigoWatched{redisRedigoExecutor: e}
+	err := fn(w)
+	if !w.done {
+		e.conn.Do("UNWATCH")
+	}
+	return err
 }
 
-// ---------- 字段级 protobuf 编码/解码模板块（MarshalRedisProto / UnmarshalRedisProto
-// 与集合字段的字段级序列化方法共用） ----------
-//
-// 约定上下文变量：fieldEncode 向 buf 追加字节；fieldDecode 从 b 消费一个字段段，
-// 校验 wire 变量，解码结果写入 p.<Name>。
+// redisRedigoWatched 是 Watch 交给 fn 的执行器，记录事务是否已执行（EXEC 与 DISCARD 都会解除 WATCH）
+type redisRedigoWatched struct {
+	redisRedigoExecutor
+	done bool
+}
 
-{{define "fieldEncode"}}
-	// 字段 {{.Name}}（tag {{.ProtoTag}}）
-{{if eq .Kind "plain"}}
-{{if .IsMsg}}
-{
-	b, err := p.{{.Name}}.MarshalRedisProto()
-	if err != nil {
-		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+func (e *redisRedigoWatched) Multi(ctx context.Context, cmds []RedisCmd) ([]interface{}, error) {
+	e.done = true
+	return e.redisRedigoExecutor.Multi(ctx, cmds)
+}
+
+// redisRedigoSend 把 cmds 逐条写入连接的发送缓冲，每条之前检查 ctx。ctx 结束或写入失败时放弃已缓冲的命令：
+// 在 DoContext 中写出并读掉它们的回复（ctx 已结束时 redigo 直接关闭连接），inMulti 时先追加 DISCARD，
+// 连接不会残留待读的回复或停留在事务状态中被放回连接池
+func redisRedigoSend(ctx context.Context, conn redis.Conn, cmds []RedisCmd, inMulti bool) error {
+	for _, c := range cmds {
+		err := ctx.Err()
+		if err == nil {
+			err = conn.Send(c.Name, c.Args...)
+		}
+		if err != nil {
+			if inMulti {
+				conn.Send("DISCARD")
+			}
+			redis.DoContext(conn, ctx, "")
+			return redisRedigoCtxErr(ctx, err)
+		}
 	}
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
-	buf = redisProtoAppendLen(buf, b)
+	return nil
 }
-{{else if eq .GoType "string"}}
-if p.{{.Name}} != "" {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
-	buf = redisProtoAppendLen(buf, []byte(p.{{.Name}}))
+
+// redisRedigoCtxErr 在 ctx 已取消或到期时返回 ctx 的错误，否则原样返回 err。
+// redigo 把 ctx 截止时间设为读超时，到期时报的是 i/o timeout，这里统一还原为 context.DeadlineExceeded。
+func redisRedigoCtxErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return err
 }
-{{else if eq .GoType "[]byte"}}
-if len(p.{{.Name}}) > 0 {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
-	buf = redisProtoAppendLen(buf, p.{{.Name}})
+{{end}}
+`
+
+// codeTemplateMemExecutor 是进程内的 RedisExecutor 实现，只在 mem=true 时输出到每个 Go 包的 MemFilename（不含模板动作），
+// 默认不进入生产代码；helpers=runtime 时改为 codeTemplateRuntimeMem 转接到 redisrt.NewMemExecutor。
+const codeTemplateMemExecutor = `
+// NewRedisMemExecutor 返回进程内的 RedisExecutor 实现（并发安全），数据只存在内存中，
+// 用于单元测试与 New<Message>MemRepository：实现生成代码用到的 string、hash、list、set、sorted set 与 key 命令，
+// 参数按 redigo 的规则转成字节存储（整数/浮点为十进制、bool 为 1/0），回复与真实 Redis 一致。
+func NewRedisMemExecutor() RedisExecutor {
+	return &redisMemExecutor{keys: make(map[string]interface{})}
 }
-{{else if eq .GoType "float32"}}
-if p.{{.Name}} != 0 {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 5)
-	buf = redisProtoAppendFixed32(buf, math.Float32bits(p.{{.Name}}))
+
+// redisMemExecutor 按 Redis 类型保存每个 key 的值：
+// string 为 []byte，hash 为 map[string][]byte，list 为 [][]byte，set 为 map[string]struct{}，sorted set 为 map[string]float64（成员 -> 分数）；
+// 集合被删空时 key 随之删除。
+type redisMemExecutor struct {
+	mu   sync.Mutex
+	keys map[string]interface{}
 }
-{{else if eq .GoType "float64"}}
-if p.{{.Name}} != 0 {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 1)
-	buf = redisProtoAppendFixed64(buf, math.Float64bits(p.{{.Name}}))
+
+func (e *redisMemExecutor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.do(cmd, args)
 }
-{{else if eq .GoType "bool"}}
-if p.{{.Name}} {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 0)
-	buf = redisProtoAppendVarint(buf, 1)
+
+func (e *redisMemExecutor) Pipeline(ctx context.Context, cmds []RedisCmd) ([]interface{}, error) {
+	return e.run(ctx, cmds, false)
 }
-{{else}}
-// 枚举与整型（varint）
-if p.{{.Name}} != 0 {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 0)
-	buf = redisProtoAppendVarint(buf, uint64(p.{{.Name}}))
+
+func (e *redisMemExecutor) Multi(ctx context.Context, cmds []RedisCmd) ([]interface{}, error) {
+	return e.run(ctx, cmds, true)
 }
-{{end}}
-{{else if eq .Kind "slice"}}
-{{if .ElemIsMsg}}
-for _, v := range p.{{.Name}} {
-	b, err := v.MarshalRedisProto()
-	if err != nil {
-		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+
+// Watch 实现 RedisWatcher：记下 keys 当前的值，fn 中的 Multi 在同一把锁内先比较，有变化时放弃事务并返回 ErrRedisTxAborted
+func (e *redisMemExecutor) Watch(ctx context.Context, keys []string, fn func(exec RedisExecutor) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
-	buf = redisProtoAppendLen(buf, b)
+	e.mu.Lock()
+	watched := e.snapshot(keys)
+	e.mu.Unlock()
+	return fn(&redisMemWatched{redisMemExecutor: e, keys: keys, watched: watched})
 }
-{{else if eq .ElemType "string"}}
-for _, v := range p.{{.Name}} {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
-	buf = redisProtoAppendLen(buf, []byte(v))
+
+// snapshot 把 keys 的当前值格式化为字符串（fmt 按键排序输出 map），比较 WATCH 前后是否变化
+func (e *redisMemExecutor) snapshot(keys []string) string {
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%T%v\x00", e.keys[k], e.keys[k])
+	}
+	return b.String()
 }
-{{else if eq .ElemType "[]byte"}}
-for _, v := range p.{{.Name}} {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
-	buf = redisProtoAppendLen(buf, v)
+
+// redisMemWatched 是 Watch 交给 fn 的执行器：第一次 Multi 执行前核对 WATCH 的 key，之后 WATCH 解除
+type redisMemWatched struct {
+	*redisMemExecutor
+	keys    []string
+	watched string
 }
-{{else if eq .ElemType "float32"}}
-for _, v := range p.{{.Name}} {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 5)
-	buf = redisProtoAppendFixed32(buf, math.Float32bits(v))
+
+func (w *redisMemWatched) Multi(ctx context.Context, cmds []RedisCmd) ([]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	keys := w.keys
+	w.keys = nil
+	if keys != nil && w.snapshot(keys) != w.watched {
+		return nil, ErrRedisTxAborted
+	}
+	return w.exec(cmds, true)
 }
-{{else if eq .ElemType "float64"}}
-for _, v := range p.{{.Name}} {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 1)
-	buf = redisProtoAppendFixed64(buf, math.Float64bits(v))
+
+// run 在同一把锁内依次执行 cmds（见 exec）
+func (e *redisMemExecutor) run(ctx context.Context, cmds []RedisCmd, tx bool) ([]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.exec(cmds, tx)
 }
-{{else}}
-// 枚举与整型元素（varint）
-for _, v := range p.{{.Name}} {
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 0)
-	buf = redisProtoAppendVarint(buf, uint64(v))
+
+// exec 依次执行 cmds（调用方持有锁），其他调用看不到中间状态；与 Redis 一致，单条命令出错不回滚已执行的命令，
+// 全部执行后返回第一条出错命令的错误（事务中包装为 *RedisTxError）
+func (e *redisMemExecutor) exec(cmds []RedisCmd, tx bool) ([]interface{}, error) {
+	replies := make([]interface{}, len(cmds))
+	var firstErr error
+	for i, c := range cmds {
+		reply, err := e.do(c.Name, c.Args)
+		if err != nil && firstErr == nil {
+			firstErr = err
+			if tx {
+				firstErr = &RedisTxError{Index: i, Cmd: c.Name, Err: err}
+			}
+		}
+		replies[i] = reply
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return replies, nil
 }
-{{end}}
-{{else if eq .Kind "map"}}
-for k, v := range p.{{.Name}} {
-	var entry []byte
-{{if eq .KeyType "string"}}
-	entry = redisProtoAppendTag(entry, 1, 2)
-	entry = redisProtoAppendLen(entry, []byte(k))
-{{else if eq .KeyType "bool"}}
-	entry = redisProtoAppendTag(entry, 1, 0)
-	if k {
-		entry = redisProtoAppendVarint(entry, 1)
+
+func (e *redisMemExecutor) do(cmd string, args []interface{}) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, redisMemArity(cmd)
 	}
-{{else}}
-	entry = redisProtoAppendTag(entry, 1, 0)
-	entry = redisProtoAppendVarint(entry, uint64(k))
-{{end}}
-{{if .ElemIsMsg}}
-	b, err := v.MarshalRedisProto()
-	if err != nil {
-		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+	key := string(redisMemArg(args[0]))
+	switch cmd {
+	case "DEL":
+		var removed int64
+		for _, k := range args {
+			if _, ok := e.keys[string(redisMemArg(k))]; ok {
+				delete(e.keys, string(redisMemArg(k)))
+				removed++
+			}
+		}
+		return removed, nil
+	case "TYPE":
+		// 与 redigo 一致，状态回复为 string
+		switch e.keys[key].(type) {
+		case nil:
+			return "none", nil
+		case []byte:
+			return "string", nil
+		case map[string][]byte:
+			return "hash", nil
+		case [][]byte:
+			return "list", nil
+		case map[string]struct{}:
+			return "set", nil
+		default:
+			return "zset", nil
+		}
+	case "GET":
+		v, ok := e.keys[key].([]byte)
+		if !ok && e.keys[key] != nil {
+			return nil, redisMemWrongType()
+		}
+		if !ok {
+			return nil, nil
+		}
+		return append([]byte{}, v...), nil
+	case "SET":
+		if len(args) != 2 {
+			return nil, redisMemArity(cmd)
+		}
+		// SET 覆盖任意类型的旧值；空值也要占住 key（非 nil 的空切片）
+		e.keys[key] = append([]byte{}, redisMemArg(args[1])...)
+		return "OK", nil
+	case "HSET", "HSETNX", "HGET", "HMGET", "HGETALL", "HEXISTS", "HLEN", "HDEL", "HINCRBY", "HINCRBYFLOAT":
+		return e.doHash(cmd, key, args[1:])
+	case "RPUSH", "LRANGE", "LLEN", "LREM":
+		return e.doList(cmd, key, args[1:])
+	case "SADD", "SREM", "SMEMBERS", "SISMEMBER", "SCARD":
+		return e.doSet(cmd, key, args[1:])
+	case "ZADD", "ZINCRBY", "ZSCORE", "ZRANGE", "ZREVRANGE", "ZRANK", "ZREVRANK", "ZREM", "ZCARD":
+		return e.doZSet(cmd, key, args[1:])
+	default:
+		return nil, fmt.Errorf("ERR unknown command '%s'（RedisMemExecutor 未实现）", cmd)
 	}
-	entry = redisProtoAppendTag(entry, 2, 2)
-	entry = redisProtoAppendLen(entry, b)
-{{else if eq .ElemType "string"}}
-	entry = redisProtoAppendTag(entry, 2, 2)
-	entry = redisProtoAppendLen(entry, []byte(v))
-{{else if eq .ElemType "[]byte"}}
-	entry = redisProtoAppendTag(entry, 2, 2)
-	entry = redisProtoAppendLen(entry, v)
-{{else if eq .ElemType "float32"}}
-	entry = redisProtoAppendTag(entry, 2, 5)
-	entry = redisProtoAppendFixed32(entry, math.Float32bits(v))
-{{else if eq .ElemType "float64"}}
-	entry = redisProtoAppendTag(entry, 2, 1)
-	entry = redisProtoAppendFixed64(entry, math.Float64bits(v))
-{{else}}
-	// 枚举与整型值（varint）
-	entry = redisProtoAppendTag(entry, 2, 0)
-	entry = redisProtoAppendVarint(entry, uint64(v))
-{{end}}
-	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
-	buf = redisProtoAppendLen(buf, entry)
 }
-{{end}}
-{{end}}
 
-{{define "fieldDecode"}}
-{{if eq .Kind "plain"}}
-{{if .IsMsg}}
-if wire != 2 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-v, n, err := redisProtoReadBytes(b)
-if err != nil {
-	return err
-}
-b = b[n:]
-if err := p.{{.Name}}.UnmarshalRedisProto(v); err != nil {
-	return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
-}
-{{else if eq .GoType "string"}}
-if wire != 2 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-v, n, err := redisProtoReadBytes(b)
-if err != nil {
-	return err
-}
-b = b[n:]
-p.{{.Name}} = string(v)
-{{else if eq .GoType "[]byte"}}
-if wire != 2 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-v, n, err := redisProtoReadBytes(b)
-if err != nil {
-	return err
-}
-b = b[n:]
-p.{{.Name}} = v
-{{else if eq .GoType "float32"}}
-if wire != 5 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-v, n, err := redisProtoReadFixed32(b)
-if err != nil {
-	return err
-}
-b = b[n:]
-p.{{.Name}} = math.Float32frombits(v)
-{{else if eq .GoType "float64"}}
-if wire != 1 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-v, n, err := redisProtoReadFixed64(b)
-if err != nil {
-	return err
-}
-b = b[n:]
-p.{{.Name}} = math.Float64frombits(v)
-{{else if eq .GoType "bool"}}
-if wire != 0 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-v, n, err := redisProtoReadVarint(b)
-if err != nil {
-	return err
-}
-b = b[n:]
-p.{{.Name}} = v != 0
-{{else}}
-// 枚举与整型（varint）
-if wire != 0 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-v, n, err := redisProtoReadVarint(b)
-if err != nil {
-	return err
-}
-b = b[n:]
-p.{{.Name}} = {{.GoType}}(v)
-{{end}}
-{{else if eq .Kind "slice"}}
-{{if .ElemIsMsg}}
-if wire != 2 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-v, n, err := redisProtoReadBytes(b)
-if err != nil {
-	return err
-}
-b = b[n:]
-var elem {{.ElemType}}
-if err := elem.UnmarshalRedisProto(v); err != nil {
-	return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
-}
-p.{{.Name}} = append(p.{{.Name}}, elem)
-{{else if eq .ElemType "string"}}
-if wire != 2 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-v, n, err := redisProtoReadBytes(b)
-if err != nil {
-	return err
-}
-b = b[n:]
-p.{{.Name}} = append(p.{{.Name}}, string(v))
-{{else if eq .ElemType "[]byte"}}
-if wire != 2 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-v, n, err := redisProtoReadBytes(b)
-if err != nil {
-	return err
-}
-b = b[n:]
-p.{{.Name}} = append(p.{{.Name}}, v)
-{{else if eq .ElemType "float32"}}
-if wire == 5 {
-	v, n, err := redisProtoReadFixed32(b)
-	if err != nil {
-		return err
+func (e *redisMemExecutor) doHash(cmd, key string, args []interface{}) (interface{}, error) {
+	hash, ok := e.keys[key].(map[string][]byte)
+	if !ok && e.keys[key] != nil {
+		return nil, redisMemWrongType()
 	}
-	b = b[n:]
-	p.{{.Name}} = append(p.{{.Name}}, math.Float32frombits(v))
-} else if wire == 2 {
-	// packed：其他语言的 protoc 实现默认对 repeated 标量打包编码
-	payload, n, err := redisProtoReadBytes(b)
-	if err != nil {
-		return err
-	}
-	b = b[n:]
-	for len(payload) > 0 {
-		v, m, err := redisProtoReadFixed32(payload)
-		if err != nil {
-			return err
+	switch cmd {
+	case "HSET":
+		if len(args) < 2 || len(args)%2 != 0 {
+			return nil, redisMemArity(cmd)
 		}
-		payload = payload[m:]
-		p.{{.Name}} = append(p.{{.Name}}, math.Float32frombits(v))
+		if hash == nil {
+			hash = make(map[string][]byte)
+			e.keys[key] = hash
+		}
+		var added int64
+		for i := 0; i < len(args); i += 2 {
+			field := string(redisMemArg(args[i]))
+			if _, ok := hash[field]; !ok {
+				added++
+			}
+			hash[field] = redisMemArg(args[i+1])
+		}
+		return added, nil
+	case "HSETNX":
+		if len(args) != 2 {
+			return nil, redisMemArity(cmd)
+		}
+		field := string(redisMemArg(args[0]))
+		if _, ok := hash[field]; ok {
+			return int64(0), nil
+		}
+		if hash == nil {
+			hash = make(map[string][]byte)
+			e.keys[key] = hash
+		}
+		hash[field] = redisMemArg(args[1])
+		return int64(1), nil
+	case "HGET":
+		if len(args) != 1 {
+			return nil, redisMemArity(cmd)
+		}
+		if v, ok := hash[string(redisMemArg(args[0]))]; ok {
+			return append([]byte(nil), v...), nil
+		}
+		return nil, nil
+	case "HMGET":
+		values := make([]interface{}, 0, len(args))
+		for _, f := range args {
+			if v, ok := hash[string(redisMemArg(f))]; ok {
+				values = append(values, append([]byte(nil), v...))
+			} else {
+				values = append(values, nil)
+			}
+		}
+		return values, nil
+	case "HGETALL":
+		items := make([]interface{}, 0, 2*len(hash))
+		for f, v := range hash {
+			items = append(items, []byte(f), append([]byte(nil), v...))
+		}
+		return items, nil
+	case "HEXISTS":
+		if len(args) != 1 {
+			return nil, redisMemArity(cmd)
+		}
+		if _, ok := hash[string(redisMemArg(args[0]))]; ok {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "HLEN":
+		return int64(len(hash)), nil
+	case "HDEL":
+		var removed int64
+		for _, f := range args {
+			field := string(redisMemArg(f))
+			if _, ok := hash[field]; ok {
+				delete(hash, field)
+				removed++
+			}
+		}
+		if hash != nil && len(hash) == 0 {
+			delete(e.keys, key)
+		}
+		return removed, nil
 	}
-} else {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-{{else if eq .ElemType "float64"}}
-if wire == 1 {
-	v, n, err := redisProtoReadFixed64(b)
-	if err != nil {
-		return err
+	// HINCRBY / HINCRBYFLOAT
+	if len(args) != 2 {
+		return nil, redisMemArity(cmd)
 	}
-	b = b[n:]
-	p.{{.Name}} = append(p.{{.Name}}, math.Float64frombits(v))
-} else if wire == 2 {
-	payload, n, err := redisProtoReadBytes(b)
-	if err != nil {
-		return err
+	field := string(redisMemArg(args[0]))
+	cur, exists := hash[field]
+	if cmd == "HINCRBY" {
+		var n int64
+		if exists {
+			v, err := strconv.ParseInt(string(cur), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("ERR hash value is not an integer")
+			}
+			n = v
+		}
+		delta, err := strconv.ParseInt(string(redisMemArg(args[1])), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ERR value is not an integer or out of range")
+		}
+		if (delta > 0 && n > math.MaxInt64-delta) || (delta < 0 && n < math.MinInt64-delta) {
+			return nil, fmt.Errorf("ERR increment or decrement would overflow")
+		}
+		if hash == nil {
+			hash = make(map[string][]byte)
+			e.keys[key] = hash
+		}
+		hash[field] = []byte(strconv.FormatInt(n+delta, 10))
+		return n + delta, nil
 	}
-	b = b[n:]
-	for len(payload) > 0 {
-		v, m, err := redisProtoReadFixed64(payload)
+	var f float64
+	if exists {
+		v, err := strconv.ParseFloat(string(cur), 64)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf("ERR hash value is not a float")
 		}
-		payload = payload[m:]
-		p.{{.Name}} = append(p.{{.Name}}, math.Float64frombits(v))
+		f = v
 	}
-} else {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-{{else if eq .ElemType "bool"}}
-if wire == 0 {
-	v, n, err := redisProtoReadVarint(b)
+	delta, err := strconv.ParseFloat(string(redisMemArg(args[1])), 64)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("ERR value is not a valid float")
 	}
-	b = b[n:]
-	p.{{.Name}} = append(p.{{.Name}}, v != 0)
-} else if wire == 2 {
-	payload, n, err := redisProtoReadBytes(b)
-	if err != nil {
-		return err
+	if hash == nil {
+		hash = make(map[string][]byte)
+		e.keys[key] = hash
 	}
-	b = b[n:]
-	for len(payload) > 0 {
-		v, m, err := redisProtoReadVarint(payload)
+	hash[field] = []byte(strconv.FormatFloat(f+delta, 'f', -1, 64))
+	return append([]byte(nil), hash[field]...), nil
+}
+
+func (e *redisMemExecutor) doList(cmd, key string, args []interface{}) (interface{}, error) {
+	list, ok := e.keys[key].([][]byte)
+	if !ok && e.keys[key] != nil {
+		return nil, redisMemWrongType()
+	}
+	switch cmd {
+	case "RPUSH":
+		if len(args) == 0 {
+			return nil, redisMemArity(cmd)
+		}
+		for _, v := range args {
+			list = append(list, redisMemArg(v))
+		}
+		e.keys[key] = list
+		return int64(len(list)), nil
+	case "LRANGE":
+		if len(args) != 2 {
+			return nil, redisMemArity(cmd)
+		}
+		start, stop, err := redisMemRange(args, len(list))
 		if err != nil {
-			return err
+			return nil, err
 		}
-		payload = payload[m:]
-		p.{{.Name}} = append(p.{{.Name}}, v != 0)
+		items := []interface{}{}
+		for i := start; i <= stop; i++ {
+			items = append(items, append([]byte(nil), list[i]...))
+		}
+		return items, nil
+	case "LLEN":
+		return int64(len(list)), nil
 	}
-} else {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-{{else}}
-// 枚举与整型元素（varint，兼容 packed 编码）
-if wire == 0 {
-	v, n, err := redisProtoReadVarint(b)
-	if err != nil {
-		return err
+	// LREM key count value：count>0 从头删、count<0 从尾删，count=0 删除全部相等元素
+	if len(args) != 2 {
+		return nil, redisMemArity(cmd)
 	}
-	b = b[n:]
-	p.{{.Name}} = append(p.{{.Name}}, {{.ElemType}}(v))
-} else if wire == 2 {
-	payload, n, err := redisProtoReadBytes(b)
+	count, err := strconv.Atoi(string(redisMemArg(args[0])))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("ERR value is not an integer or out of range")
 	}
-	b = b[n:]
-	for len(payload) > 0 {
-		v, m, err := redisProtoReadVarint(payload)
-		if err != nil {
-			return err
+	target := string(redisMemArg(args[1]))
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	remove := make(map[int]bool)
+	for i := range list {
+		j := i
+		if count < 0 {
+			j = len(list) - 1 - i
+		}
+		if string(list[j]) == target {
+			remove[j] = true
+			if limit > 0 && len(remove) == limit {
+				break
+			}
 		}
-		payload = payload[m:]
-		p.{{.Name}} = append(p.{{.Name}}, {{.ElemType}}(v))
 	}
-} else {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-{{end}}
-{{else if eq .Kind "map"}}
-if wire != 2 {
-	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
-}
-entry, n, err := redisProtoReadBytes(b)
-if err != nil {
-	return err
+	kept := list[:0:0]
+	for i, v := range list {
+		if !remove[i] {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) == 0 {
+		delete(e.keys, key)
+	} else if len(remove) > 0 {
+		e.keys[key] = kept
+	}
+	return int64(len(remove)), nil
 }
-b = b[n:]
-var k {{.KeyType}}
-var val {{.ElemType}}
-for len(entry) > 0 {
-	t2, m, err := redisProtoReadVarint(entry)
-	if err != nil {
-		return err
+
+func (e *redisMemExecutor) doSet(cmd, key string, args []interface{}) (interface{}, error) {
+	set, ok := e.keys[key].(map[string]struct{})
+	if !ok && e.keys[key] != nil {
+		return nil, redisMemWrongType()
 	}
-	entry = entry[m:]
-	switch t2 >> 3 {
-	case 1: // map 键
-{{if eq .KeyType "string"}}
-		if t2&7 != 2 {
-			return fmt.Errorf("protobuf 字段 %s map 键 wire type 错误: %d", "{{.Name}}", t2&7)
+	switch cmd {
+	case "SADD":
+		if len(args) == 0 {
+			return nil, redisMemArity(cmd)
 		}
-		payload, m, err := redisProtoReadBytes(entry)
-		if err != nil {
-			return err
+		if set == nil {
+			set = make(map[string]struct{})
+			e.keys[key] = set
 		}
-		entry = entry[m:]
-		k = string(payload)
-{{else if eq .KeyType "bool"}}
-		if t2&7 != 0 {
-			return fmt.Errorf("protobuf 字段 %s map 键 wire type 错误: %d", "{{.Name}}", t2&7)
+		var added int64
+		for _, v := range args {
+			member := string(redisMemArg(v))
+			if _, ok := set[member]; !ok {
+				set[member] = struct{}{}
+				added++
+			}
 		}
-		kv, m, err := redisProtoReadVarint(entry)
-		if err != nil {
-			return err
+		return added, nil
+	case "SREM":
+		var removed int64
+		for _, v := range args {
+			member := string(redisMemArg(v))
+			if _, ok := set[member]; ok {
+				delete(set, member)
+				removed++
+			}
 		}
-		entry = entry[m:]
-		k = kv != 0
-{{else}}
-		if t2&7 != 0 {
-			return fmt.Errorf("protobuf 字段 %s map 键 wire type 错误: %d", "{{.Name}}", t2&7)
+		if set != nil && len(set) == 0 {
+			delete(e.keys, key)
 		}
-		kv, m, err := redisProtoReadVarint(entry)
-		if err != nil {
-			return err
+		return removed, nil
+	case "SMEMBERS":
+		members := make([]interface{}, 0, len(set))
+		for m := range set {
+			members = append(members, []byte(m))
 		}
-		entry = entry[m:]
-		k = {{.KeyType}}(kv)
-{{end}}
-	case 2: // map 值
-{{if .ElemIsMsg}}
-		if t2&7 != 2 {
-			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		return members, nil
+	case "SISMEMBER":
+		if len(args) != 1 {
+			return nil, redisMemArity(cmd)
 		}
-		payload, m, err := redisProtoReadBytes(entry)
-		if err != nil {
-			return err
+		if _, ok := set[string(redisMemArg(args[0]))]; ok {
+			return int64(1), nil
 		}
-		entry = entry[m:]
-		if err := val.UnmarshalRedisProto(payload); err != nil {
-			return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
+		return int64(0), nil
+	default: // SCARD
+		return int64(len(set)), nil
+	}
+}
+
+// doZSet 实现 sorted set 命令；成员按 (score, member) 升序排列，与 Redis 一致
+func (e *redisMemExecutor) doZSet(cmd, key string, args []interface{}) (interface{}, error) {
+	zset, ok := e.keys[key].(map[string]float64)
+	if !ok && e.keys[key] != nil {
+		return nil, redisMemWrongType()
+	}
+	switch cmd {
+	case "ZADD":
+		if len(args) == 0 || len(args)%2 != 0 {
+			return nil, redisMemArity(cmd)
 		}
-{{else if eq .ElemType "string"}}
-		if t2&7 != 2 {
-			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		scores := make([]float64, 0, len(args)/2)
+		for i := 0; i < len(args); i += 2 {
+			score, err := strconv.ParseFloat(string(redisMemArg(args[i])), 64)
+			if err != nil || math.IsNaN(score) {
+				return nil, fmt.Errorf("ERR value is not a valid float")
+			}
+			scores = append(scores, score)
 		}
-		payload, m, err := redisProtoReadBytes(entry)
-		if err != nil {
-			return err
+		if zset == nil {
+			zset = make(map[string]float64)
+			e.keys[key] = zset
 		}
-		entry = entry[m:]
-		val = string(payload)
-{{else if eq .ElemType "[]byte"}}
-		if t2&7 != 2 {
-			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		var added int64
+		for i, score := range scores {
+			member := string(redisMemArg(args[2*i+1]))
+			if _, ok := zset[member]; !ok {
+				added++
+			}
+			zset[member] = score
 		}
-		payload, m, err := redisProtoReadBytes(entry)
-		if err != nil {
-			return err
+		return added, nil
+	case "ZINCRBY":
+		if len(args) != 2 {
+			return nil, redisMemArity(cmd)
 		}
-		entry = entry[m:]
-		val = payload
-{{else if eq .ElemType "float32"}}
-		if t2&7 != 5 {
-			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		delta, err := strconv.ParseFloat(string(redisMemArg(args[0])), 64)
+		if err != nil || math.IsNaN(delta) {
+			return nil, fmt.Errorf("ERR value is not a valid float")
 		}
-		fv, m, err := redisProtoReadFixed32(entry)
-		if err != nil {
-			return err
+		if zset == nil {
+			zset = make(map[string]float64)
+			e.keys[key] = zset
 		}
-		entry = entry[m:]
-		val = math.Float32frombits(fv)
-{{else if eq .ElemType "float64"}}
-		if t2&7 != 1 {
-			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		member := string(redisMemArg(args[1]))
+		score := zset[member] + delta
+		if math.IsNaN(score) {
+			return nil, fmt.Errorf("ERR resulting score is not a number (NaN)")
 		}
-		fv, m, err := redisProtoReadFixed64(entry)
-		if err != nil {
-			return err
+		zset[member] = score
+		return strconv.AppendFloat(nil, score, 'g', -1, 64), nil
+	case "ZSCORE":
+		if len(args) != 1 {
+			return nil, redisMemArity(cmd)
 		}
-		entry = entry[m:]
-		val = math.Float64frombits(fv)
-{{else if eq .ElemType "bool"}}
-		if t2&7 != 0 {
-			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		score, ok := zset[string(redisMemArg(args[0]))]
+		if !ok {
+			return nil, nil
 		}
-		bv, m, err := redisProtoReadVarint(entry)
-		if err != nil {
-			return err
+		return strconv.AppendFloat(nil, score, 'g', -1, 64), nil
+	case "ZRANGE", "ZREVRANGE":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, redisMemArity(cmd)
 		}
-		entry = entry[m:]
-		val = bv != 0
-{{else}}
-		// 枚举与整型值（varint）
-		if t2&7 != 0 {
-			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		withScores := len(args) == 3
+		if withScores && !strings.EqualFold(string(redisMemArg(args[2])), "WITHSCORES") {
+			return nil, fmt.Errorf("ERR syntax error")
 		}
-		ev, m, err := redisProtoReadVarint(entry)
+		members := redisMemZSorted(zset, cmd == "ZREVRANGE")
+		start, stop, err := redisMemRange(args[:2], len(members))
 		if err != nil {
-			return err
+			return nil, err
 		}
-		entry = entry[m:]
-		val = {{.ElemType}}(ev)
-{{end}}
-	default:
-		m, err = redisProtoSkip(entry, t2&7)
-		if err != nil {
-			return err
+		items := []interface{}{}
+		for i := start; i <= stop; i++ {
+			items = append(items, []byte(members[i]))
+			if withScores {
+				items = append(items, strconv.AppendFloat(nil, zset[members[i]], 'g', -1, 64))
+			}
 		}
-		entry = entry[m:]
+		return items, nil
+	case "ZRANK", "ZREVRANK":
+		if len(args) != 1 {
+			return nil, redisMemArity(cmd)
+		}
+		member := string(redisMemArg(args[0]))
+		for i, m := range redisMemZSorted(zset, cmd == "ZREVRANK") {
+			if m == member {
+				return int64(i), nil
+			}
+		}
+		return nil, nil
+	case "ZREM":
+		if len(args) == 0 {
+			return nil, redisMemArity(cmd)
+		}
+		var removed int64
+		for _, v := range args {
+			member := string(redisMemArg(v))
+			if _, ok := zset[member]; ok {
+				delete(zset, member)
+				removed++
+			}
+		}
+		if zset != nil && len(zset) == 0 {
+			delete(e.keys, key)
+		}
+		return removed, nil
+	default: // ZCARD
+		return int64(len(zset)), nil
 	}
 }
-if p.{{.Name}} == nil {
-	p.{{.Name}} = make(map[{{.KeyType}}]{{.ElemType}})
+
+// redisMemZSorted 返回按 (score, member) 升序（rev 为 true 时降序）排列的成员
+func redisMemZSorted(zset map[string]float64, rev bool) []string {
+	members := make([]string, 0, len(zset))
+	for m := range zset {
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if rev {
+			a, b = b, a
+		}
+		if zset[a] != zset[b] {
+			return zset[a] < zset[b]
+		}
+		return a < b
+	})
+	return members
 }
-p.{{.Name}}[k] = val
-{{end}}
+
+// redisMemRange 解析 LRANGE/ZRANGE 的 start stop 参数：负数从末尾计，越界截断；区间为空时 start > stop
+func redisMemRange(args []interface{}, n int) (start, stop int, err error) {
+	start, err1 := strconv.Atoi(string(redisMemArg(args[0])))
+	stop, err2 := strconv.Atoi(string(redisMemArg(args[1])))
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("ERR value is not an integer or out of range")
+	}
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	return start, stop, nil
+}
+
+func redisMemArity(cmd string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", cmd)
+}
+
+func redisMemWrongType() error {
+	return fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value")
+}
+
+// redisMemArg 按 redigo 的规则把命令参数转为字节：[]byte/string 原样，bool 为 1/0，其余按十进制文本
+func redisMemArg(arg interface{}) []byte {
+	switch v := arg.(type) {
+	case []byte:
+		return append([]byte(nil), v...)
+	case string:
+		return []byte(v)
+	case bool:
+		if v {
+			return []byte("1")
+		}
+		return []byte("0")
+	case nil:
+		return []byte{}
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
+`
+
+// codeTemplateRuntime 是 helpers=runtime 时代替 codeTemplateExecutor 与 codeTemplateProtoHelpers 的转接声明：
+// 生成代码仍使用 RedisExecutor、redisProtoAppendVarint 等包内名字，实现全部在运行时包 redisrt 中，
+// 类型为别名、函数为一行的转发（可内联），升级运行时即可修复问题而不必重新生成。
+const codeTemplateRuntime = `
+// --- 运行时转接：实现位于 github.com/beijian128/protoc-gen-redis/redisrt（helpers=runtime） ---
+
+// RedisCmd 是一条待执行的 Redis 命令
+type RedisCmd = redisrt.Cmd
+
+// RedisExecutor 是生成代码执行 Redis 命令所需的最小接口，见 redisrt.Executor
+type RedisExecutor = redisrt.Executor
+
+// RedisTxError 表示事务中某条命令执行失败，见 redisrt.TxError
+type RedisTxError = redisrt.TxError
+
+// RedisUniqueConflictError 表示唯一索引字段的值已被其他记录占用，见 redisrt.UniqueConflictError
+type RedisUniqueConflictError = redisrt.UniqueConflictError
+
+// RedisWatcher 是支持乐观锁的执行器，见 redisrt.Watcher
+type RedisWatcher = redisrt.Watcher
+
+// ErrRedisTxAborted 表示 WATCH 的 key 在 EXEC 之前被修改，事务被放弃，见 redisrt.ErrTxAborted
+var ErrRedisTxAborted = redisrt.ErrTxAborted
+{{if eq .Executor "goredis"}}
+// NewGoRedisExecutor 把 go-redis v9 客户端包装为 RedisExecutor，见 goredisexec.New
+func NewGoRedisExecutor(client redis.UniversalClient) RedisExecutor { return goredisexec.New(client) }
+{{else}}
+// RedisConnSource 是 redigo 连接来源，*redis.Pool 即满足，见 redigoexec.ConnSource
+type RedisConnSource = redigoexec.ConnSource
+
+// NewRedigoExecutor 把 redigo 连接包装为 RedisExecutor，见 redigoexec.New
+func NewRedigoExecutor(conn redis.Conn) RedisExecutor { return redigoexec.New(conn) }
+
+func redisPoolAcquire(pool RedisConnSource) redisAcquireFunc { return redigoexec.PoolAcquire(pool) }
 {{end}}
+type (
+	redisAcquireFunc = redisrt.AcquireFunc
+	redisUniqueClaim = redisrt.UniqueClaim
+	redisHashMove    = redisrt.HashMove
+)
+
+func redisExecAcquire(exec RedisExecutor) redisAcquireFunc { return redisrt.ExecAcquire(exec) }
+
+func redisWithScores(cmd string, reply interface{}) ([]interface{}, error) {
+	return redisrt.WithScores(cmd, reply)
+}
+
+func redisRecordMember(ida, idb uint64) string { return redisrt.RecordMember(ida, idb) }
+
+func redisParseRecordMember(member []byte) (ida, idb uint64, err error) {
+	return redisrt.ParseRecordMember(member)
+}
+
+func redisWatch(ctx context.Context, exec RedisExecutor, keys []string, fn func(exec RedisExecutor) error) error {
+	return redisrt.Watch(ctx, exec, keys, fn)
+}
+
+func redisUniqueSet(ctx context.Context, exec RedisExecutor, key, member string, claims []redisUniqueClaim, write []RedisCmd) error {
+	return redisrt.UniqueSet(ctx, exec, key, member, claims, write)
+}
+
+func redisUniqueOwned(ctx context.Context, exec RedisExecutor, member string, gets []RedisCmd) ([]RedisCmd, error) {
+	return redisrt.UniqueOwned(ctx, exec, member, gets)
+}
+
+func redisMoveHashFields(ctx context.Context, exec RedisExecutor, key string, moves []redisHashMove) error {
+	return redisrt.MoveHashFields(ctx, exec, key, moves)
+}
+
+func redisProtoAppendVarint(buf []byte, v uint64) []byte { return redisrt.AppendVarint(buf, v) }
+
+func redisProtoReadVarint(b []byte) (uint64, int, error) { return redisrt.ReadVarint(b) }
+
+func redisProtoAppendTag(buf []byte, field, wire int32) []byte { return redisrt.AppendTag(buf, field, wire) }
+
+func redisProtoAppendLen(buf, payload []byte) []byte { return redisrt.AppendLen(buf, payload) }
+
+func redisProtoReadBytes(b []byte) ([]byte, int, error) { return redisrt.ReadBytes(b) }
+
+func redisProtoAppendFixed32(buf []byte, v uint32) []byte { return redisrt.AppendFixed32(buf, v) }
+
+func redisProtoReadFixed32(b []byte) (uint32, int, error) { return redisrt.ReadFixed32(b) }
+
+func redisProtoAppendFixed64(buf []byte, v uint64) []byte { return redisrt.AppendFixed64(buf, v) }
+
+func redisProtoReadFixed64(b []byte) (uint64, int, error) { return redisrt.ReadFixed64(b) }
+
+func redisProtoSkip(b []byte, wire uint64) (int, error) { return redisrt.Skip(b, wire) }
+`
+
+// codeTemplateRuntimeMem 是 helpers=runtime 且 mem=true 时代替 codeTemplateMemExecutor 的转接声明（不含模板动作）
+const codeTemplateRuntimeMem = `
+// NewRedisMemExecutor 返回进程内的 RedisExecutor 实现（并发安全），见 redisrt.NewMemExecutor
+func NewRedisMemExecutor() RedisExecutor { return redisrt.NewMemExecutor() }
+`
+
+// codeTemplateRuntimeTable 是 helpers=runtime 且 codec=table 时代替 codeTemplateTableCodec 的转接声明（不含模板动作）
+const codeTemplateRuntimeTable = `
+// --- 表驱动的 protobuf 编解码（codec=table），实现在 redisrt ---
+
+type redisProtoField = redisrt.Field
+
+var (
+	redisProtoBool    = redisrt.Bool
+	redisProtoInt32   = redisrt.Int32
+	redisProtoInt64   = redisrt.Int64
+	redisProtoUint32  = redisrt.Uint32
+	redisProtoUint64  = redisrt.Uint64
+	redisProtoFloat32 = redisrt.Float32
+	redisProtoFloat64 = redisrt.Float64
+	redisProtoString  = redisrt.String
+	redisProtoBytes   = redisrt.Bytes
+)
+
+func redisProtoNested[M any, PM interface {
+	*M
+	redisrt.Message
+}]() *redisrt.Codec {
+	return redisrt.Nested[M, PM]()
+}
+
+func redisProtoSingular(tag uint64, name string, offset uintptr, c *redisrt.Codec) redisrt.Field {
+	return redisrt.Singular(tag, name, offset, c)
+}
+
+func redisProtoRepeated[V any](tag uint64, name string, offset uintptr, c *redisrt.Codec) redisrt.Field {
+	return redisrt.Repeated[V](tag, name, offset, c)
+}
+
+func redisProtoMap[K comparable, V any](tag uint64, name string, offset uintptr, kc, vc *redisrt.Codec) redisrt.Field {
+	return redisrt.Map[K, V](tag, name, offset, kc, vc)
+}
+
+func redisProtoMarshal(buf []byte, p unsafe.Pointer, table []redisrt.Field) ([]byte, error) {
+	return redisrt.Marshal(buf, p, table)
+}
+
+func redisProtoUnmarshal(b []byte, p unsafe.Pointer, table []redisrt.Field) error {
+	return redisrt.Unmarshal(b, p, table)
+}
+
+func redisProtoMarshalField(buf []byte, p unsafe.Pointer, f *redisrt.Field) ([]byte, error) {
+	return redisrt.MarshalField(buf, p, f)
+}
+
+func redisProtoUnmarshalField(b []byte, p unsafe.Pointer, f *redisrt.Field) error {
+	return redisrt.UnmarshalField(b, p, f)
+}
+`
+
+// codeTemplateRuntimePerf 是 helpers=runtime 且 perf=true 时代替 codeTemplatePerfHelpers 的转接声明（不含模板动作）
+const codeTemplateRuntimePerf = `
+// --- 热路径辅助函数（perf=true），实现在 redisrt ---
+
+type redisArgs = redisrt.Args
+
+func redisGetArgs() *redisArgs { return redisrt.GetArgs() }
+
+func redisPutArgs(a *redisArgs) { redisrt.PutArgs(a) }
+
+func redisBytesString(b []byte) string { return redisrt.BytesString(b) }
+
+func redisProtoReadBytesView(b []byte) ([]byte, int, error) { return redisrt.ReadBytesView(b) }
+
+func redisProtoAppendString(buf []byte, s string) []byte { return redisrt.AppendString(buf, s) }
+
+func redisProtoOpenLen(buf []byte, field int32) []byte { return redisrt.OpenLen(buf, field) }
+
+func redisProtoCloseLen(buf []byte, start int) []byte { return redisrt.CloseLen(buf, start) }
+`
+
+// codeTemplateProtoHelpers 是 protobuf wire format 的辅助函数，
+// 包内任一文件存在 message 或集合字段时输出到辅助代码文件（redisProto 前缀避免与用户代码冲突）。
+// 编码规则与 protobuf 规范一致：tag = field<<3 | wireType，
+// wireType：0=varint，1=fixed64，2=length-delimited，5=fixed32。
+const codeTemplateProtoHelpers = `
+// --- protobuf wire format 辅助函数（语言无关序列化，规则见 https://protobuf.dev/programming-guides/encoding/） ---
+
+// redisProtoAppendVarint 追加一个 base-128 varint 编码的 uint64
+func redisProtoAppendVarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+// redisProtoReadVarint 读取一个 varint，返回（值，消耗字节数）
+func redisProtoReadVarint(b []byte) (uint64, int, error) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7F) << (7 * i)
+		if b[i]&0x80 == 0 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("protobuf varint 读取失败: 数据截断或过长")
+}
+
+// redisProtoAppendTag 追加字段 tag（field<<3 | wireType）
+func redisProtoAppendTag(buf []byte, field, wire int32) []byte {
+	return redisProtoAppendVarint(buf, uint64(field)<<3|uint64(wire))
+}
+
+// redisProtoAppendLen 追加 length-delimited 数据（长度前缀 + 数据）
+func redisProtoAppendLen(buf, payload []byte) []byte {
+	buf = redisProtoAppendVarint(buf, uint64(len(payload)))
+	return append(buf, payload...)
+}
+
+// redisProtoReadBytes 读取 length-delimited 数据，返回（数据拷贝，消耗字节数）；
+// 返回拷贝避免与输入缓冲区 alias。
+func redisProtoReadBytes(b []byte) ([]byte, int, error) {
+	n, k, err := redisProtoReadVarint(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n > uint64(len(b)-k) {
+		return nil, 0, fmt.Errorf("protobuf length-delimited 数据截断: 期望 %d 字节, 剩余 %d", n, len(b)-k)
+	}
+	return append([]byte(nil), b[k:k+int(n)]...), k + int(n), nil
+}
+
+// redisProtoReadBytesView 与 redisProtoReadBytes 相同，但返回的数据与 b 共用底层数组（不拷贝），
+// 只用于跳过字段，或随即转换为 string、解码为 message、逐个读取 packed 元素等不保留数据本身的场合
+func redisProtoReadBytesView(b []byte) ([]byte, int, error) {
+	n, k, err := redisProtoReadVarint(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n > uint64(len(b)-k) {
+		return nil, 0, fmt.Errorf("protobuf length-delimited 数据截断: 期望 %d 字节, 剩余 %d", n, len(b)-k)
+	}
+	end := k + int(n)
+	return b[k:end:end], end, nil
+}
+
+// redisProtoAppendFixed32 追加小端 4 字节
+func redisProtoAppendFixed32(buf []byte, v uint32) []byte {
+	return append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// redisProtoReadFixed32 读取小端 4 字节
+func redisProtoReadFixed32(b []byte) (uint32, int, error) {
+	if len(b) < 4 {
+		return 0, 0, fmt.Errorf("protobuf fixed32 数据截断")
+	}
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24, 4, nil
+}
+
+// redisProtoAppendFixed64 追加小端 8 字节
+func redisProtoAppendFixed64(buf []byte, v uint64) []byte {
+	return append(buf,
+		byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
+		byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
+}
+
+// redisProtoReadFixed64 读取小端 8 字节
+func redisProtoReadFixed64(b []byte) (uint64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, fmt.Errorf("protobuf fixed64 数据截断")
+	}
+	var v uint64
+	for i := 0; i < 8; i++ {
+		v |= uint64(b[i]) << (8 * i)
+	}
+	return v, 8, nil
+}
+
+// redisProtoSkip 跳过未知字段，返回消耗字节数
+func redisProtoSkip(b []byte, wire uint64) (int, error) {
+	switch wire {
+	case 0: // varint
+		_, n, err := redisProtoReadVarint(b)
+		return n, err
+	case 1: // fixed64
+		if len(b) < 8 {
+			return 0, fmt.Errorf("protobuf fixed64 数据截断")
+		}
+		return 8, nil
+	case 2: // length-delimited
+		_, n, err := redisProtoReadBytesView(b)
+		return n, err
+	case 5: // fixed32
+		if len(b) < 4 {
+			return 0, fmt.Errorf("protobuf fixed32 数据截断")
+		}
+		return 4, nil
+	default:
+		return 0, fmt.Errorf("protobuf 未知 wire type %d", wire)
+	}
+}
+
+`
+
+// codeTemplateTableCodec 是 codec=table 时共用的 protobuf 编解码函数：各 message 只生成字段表（redisProtoTable<Message>），
+// MarshalRedisProto 等方法调用这里的函数，编码结果与逐字段生成的代码相同。包内使用 codec=table 时输出到辅助代码文件（不含模板动作）。
+const codeTemplateTableCodec = `
+// --- 表驱动的 protobuf 编解码（codec=table） ---
+//
+// 每个 message 一张字段表（tag、值的种类与字段在结构体中的偏移），编解码由下面几个共用函数按表进行，
+// 不再为每个字段生成一段编码与解码代码。只有切片增长、map 读写与嵌套 message 的调用依赖具体类型，
+// 它们是很小的泛型函数与方法，按类型实例化。
+
+// redisProtoKind 是字段表中值的种类，决定 wire type 与 Go 内存表示
+type redisProtoKind uint8
+
+const (
+	redisProtoKindBool redisProtoKind = iota
+	redisProtoKindInt32 // int32 与枚举：负数按 64 位补码编码，与逐字段生成的编码一致
+	redisProtoKindInt64
+	redisProtoKindUint32
+	redisProtoKindUint64
+	redisProtoKindFloat32
+	redisProtoKindFloat64
+	redisProtoKindString
+	redisProtoKindBytes
+	redisProtoKindMessage
+)
+
+// redisProtoCodec 是一种值类型的编解码：标量由 kind 决定，message 经 marshal / unmarshal 调用其 MarshalRedisProto / UnmarshalRedisProto
+// （v 指向 message 结构体）
+type redisProtoCodec struct {
+	kind      redisProtoKind
+	wire      uint64
+	marshal   func(v unsafe.Pointer) ([]byte, error)
+	unmarshal func(v unsafe.Pointer, b []byte) error
+}
+
+// 标量类型的编解码：bool 与整型为 varint，float32 / float64 为 fixed32 / fixed64，string 与 bytes 为 length-delimited
+var (
+	redisProtoBool    = &redisProtoCodec{kind: redisProtoKindBool, wire: 0}
+	redisProtoInt32   = &redisProtoCodec{kind: redisProtoKindInt32, wire: 0}
+	redisProtoInt64   = &redisProtoCodec{kind: redisProtoKindInt64, wire: 0}
+	redisProtoUint32  = &redisProtoCodec{kind: redisProtoKindUint32, wire: 0}
+	redisProtoUint64  = &redisProtoCodec{kind: redisProtoKindUint64, wire: 0}
+	redisProtoFloat32 = &redisProtoCodec{kind: redisProtoKindFloat32, wire: 5}
+	redisProtoFloat64 = &redisProtoCodec{kind: redisProtoKindFloat64, wire: 1}
+	redisProtoString  = &redisProtoCodec{kind: redisProtoKindString, wire: 2}
+	redisProtoBytes   = &redisProtoCodec{kind: redisProtoKindBytes, wire: 2}
+)
+
+// redisProtoNested 返回 message M 的编解码：内容为 M 的 MarshalRedisProto，解码时由 UnmarshalRedisProto 先重置
+func redisProtoNested[M any, PM interface {
+	*M
+	MarshalRedisProto() ([]byte, error)
+	UnmarshalRedisProto(b []byte) error
+}]() *redisProtoCodec {
+	return &redisProtoCodec{
+		kind:      redisProtoKindMessage,
+		wire:      2,
+		marshal:   func(v unsafe.Pointer) ([]byte, error) { return PM((*M)(v)).MarshalRedisProto() },
+		unmarshal: func(v unsafe.Pointer, b []byte) error { return PM((*M)(v)).UnmarshalRedisProto(b) },
+	}
+}
+
+// empty 报告单值字段是否为不编码的零值（proto3）；message 恒编码
+func (c *redisProtoCodec) empty(v unsafe.Pointer) bool {
+	switch c.kind {
+	case redisProtoKindBool:
+		return !*(*bool)(v)
+	case redisProtoKindInt32, redisProtoKindUint32:
+		return *(*uint32)(v) == 0
+	case redisProtoKindInt64, redisProtoKindUint64:
+		return *(*uint64)(v) == 0
+	case redisProtoKindFloat32:
+		return *(*float32)(v) == 0
+	case redisProtoKindFloat64:
+		return *(*float64)(v) == 0
+	case redisProtoKindString:
+		return *(*string)(v) == ""
+	case redisProtoKindBytes:
+		return len(*(*[]byte)(v)) == 0
+	default:
+		return false
+	}
+}
+
+// append 把 v 指向的值编码后追加到 buf（不含 tag）
+func (c *redisProtoCodec) append(buf []byte, v unsafe.Pointer) ([]byte, error) {
+	if c.kind != redisProtoKindMessage {
+		return c.appendScalar(buf, v), nil
+	}
+	b, err := c.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return redisProtoAppendLen(buf, b), nil
+}
+
+// appendScalar 是 append 的标量部分。与 message 分开：v 不经接口调用传出，map 的键等临时变量可以留在栈上
+func (c *redisProtoCodec) appendScalar(buf []byte, v unsafe.Pointer) []byte {
+	switch c.kind {
+	case redisProtoKindBool:
+		if *(*bool)(v) {
+			return append(buf, 1)
+		}
+		return append(buf, 0)
+	case redisProtoKindInt32:
+		return redisProtoAppendVarint(buf, uint64(*(*int32)(v)))
+	case redisProtoKindInt64, redisProtoKindUint64:
+		return redisProtoAppendVarint(buf, *(*uint64)(v))
+	case redisProtoKindUint32:
+		return redisProtoAppendVarint(buf, uint64(*(*uint32)(v)))
+	case redisProtoKindFloat32:
+		return redisProtoAppendFixed32(buf, *(*uint32)(v))
+	case redisProtoKindFloat64:
+		return redisProtoAppendFixed64(buf, *(*uint64)(v))
+	case redisProtoKindString:
+		s := *(*string)(v)
+		return append(redisProtoAppendVarint(buf, uint64(len(s))), s...)
+	default:
+		return redisProtoAppendLen(buf, *(*[]byte)(v))
+	}
+}
+
+// read 从 b 解码一个值到 v 指向的位置，返回读取的字节数（b 不含 tag，wire type 已由调用方核对）
+func (c *redisProtoCodec) read(b []byte, v unsafe.Pointer) (int, error) {
+	if c.kind != redisProtoKindMessage {
+		return c.readScalar(b, v)
+	}
+	x, n, err := redisProtoReadBytes(b)
+	if err != nil {
+		return 0, err
+	}
+	return n, c.unmarshal(v, x)
+}
+
+// readScalar 是 read 的标量部分（与 appendScalar 同理与 message 分开）
+func (c *redisProtoCodec) readScalar(b []byte, v unsafe.Pointer) (int, error) {
+	switch c.kind {
+	case redisProtoKindFloat32:
+		x, n, err := redisProtoReadFixed32(b)
+		*(*uint32)(v) = x
+		return n, err
+	case redisProtoKindFloat64:
+		x, n, err := redisProtoReadFixed64(b)
+		*(*uint64)(v) = x
+		return n, err
+	case redisProtoKindString, redisProtoKindBytes:
+		x, n, err := redisProtoReadBytes(b)
+		if err != nil {
+			return 0, err
+		}
+		if c.kind == redisProtoKindString {
+			*(*string)(v) = string(x)
+		} else {
+			*(*[]byte)(v) = x
+		}
+		return n, nil
+	}
+	x, n, err := redisProtoReadVarint(b)
+	switch c.kind {
+	case redisProtoKindBool:
+		*(*bool)(v) = x != 0
+	case redisProtoKindInt32, redisProtoKindUint32:
+		*(*uint32)(v) = uint32(x)
+	default:
+		*(*uint64)(v) = x
+	}
+	return n, err
+}
+
+// redisProtoField 是字段表中的一项：单值字段 slice 与 mapping 均为 nil，repeated 与 map 分别经它们操作容器
+type redisProtoField struct {
+	tag     uint64
+	name    string
+	offset  uintptr          // 字段在结构体中的偏移（unsafe.Offsetof）
+	val     *redisProtoCodec // 值；repeated 为元素，map 为 value
+	key     *redisProtoCodec // map 的键
+	slice   redisProtoSliceOps
+	mapping redisProtoMapOps
+}
+
+// redisProtoSliceOps 操作 repeated 字段（s 指向切片）
+type redisProtoSliceOps interface {
+	elems(s unsafe.Pointer) (base unsafe.Pointer, n int, size uintptr)
+	grow(s unsafe.Pointer) unsafe.Pointer // 追加一个零值元素，返回其地址
+}
+
+// redisProtoMapOps 操作 map 字段（m 指向 map），键值对的编解码经 f 的 appendEntry / readEntry 进行
+type redisProtoMapOps interface {
+	appendEntries(buf []byte, m unsafe.Pointer, f *redisProtoField) ([]byte, error)
+	storeEntry(m unsafe.Pointer, f *redisProtoField, entry []byte) error
+}
+
+type redisProtoSliceOf[V any] struct{}
+
+func (redisProtoSliceOf[V]) elems(s unsafe.Pointer) (unsafe.Pointer, int, uintptr) {
+	v := *(*[]V)(s)
+	return unsafe.Pointer(unsafe.SliceData(v)), len(v), unsafe.Sizeof(*new(V))
+}
+
+func (redisProtoSliceOf[V]) grow(s unsafe.Pointer) unsafe.Pointer {
+	v := (*[]V)(s)
+	var zero V
+	*v = append(*v, zero)
+	return unsafe.Pointer(&(*v)[len(*v)-1])
+}
+
+type redisProtoMapOf[K comparable, V any] struct{}
+
+func (redisProtoMapOf[K, V]) appendEntries(buf []byte, m unsafe.Pointer, f *redisProtoField) ([]byte, error) {
+	var entry []byte
+	var k K // 在循环外声明：每个字段只分配一次键值，而不是每个键值对一次
+	var v V
+	for k, v = range *(*map[K]V)(m) {
+		var err error
+		if buf, entry, err = f.appendEntry(buf, entry, unsafe.Pointer(&k), unsafe.Pointer(&v)); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func (redisProtoMapOf[K, V]) storeEntry(m unsafe.Pointer, f *redisProtoField, entry []byte) error {
+	var k K
+	var v V
+	if err := f.readEntry(entry, unsafe.Pointer(&k), unsafe.Pointer(&v)); err != nil {
+		return err
+	}
+	mp := (*map[K]V)(m)
+	if *mp == nil {
+		*mp = make(map[K]V)
+	}
+	(*mp)[k] = v
+	return nil
+}
+
+// redisProtoSingular 返回单值字段的表项：零值标量不编码，message 恒编码
+func redisProtoSingular(tag uint64, name string, offset uintptr, c *redisProtoCodec) redisProtoField {
+	return redisProtoField{tag: tag, name: name, offset: offset, val: c}
+}
+
+// redisProtoRepeated 返回元素类型为 V 的 repeated 字段的表项：逐元素编码（含零值）；
+// 标量元素同时接受 packed 编码（其他语言的 protoc 实现默认对 repeated 标量打包编码）
+func redisProtoRepeated[V any](tag uint64, name string, offset uintptr, c *redisProtoCodec) redisProtoField {
+	return redisProtoField{tag: tag, name: name, offset: offset, val: c, slice: redisProtoSliceOf[V]{}}
+}
+
+// redisProtoMap 返回 map[K]V 字段的表项：每个键值对编码为一个子消息（field 1 = 键，field 2 = 值，值恒编码）
+func redisProtoMap[K comparable, V any](tag uint64, name string, offset uintptr, kc, vc *redisProtoCodec) redisProtoField {
+	return redisProtoField{tag: tag, name: name, offset: offset, val: vc, key: kc, mapping: redisProtoMapOf[K, V]{}}
+}
+
+// redisProtoMarshal 按字段表把 p 指向的 message 编码后追加到 buf
+func redisProtoMarshal(buf []byte, p unsafe.Pointer, table []redisProtoField) ([]byte, error) {
+	for i := range table {
+		var err error
+		if buf, err = table[i].encode(buf, unsafe.Add(p, table[i].offset)); err != nil {
+			return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %w", table[i].name, err)
+		}
+	}
+	return buf, nil
+}
+
+// redisProtoMarshalField 只编码字段表中的一项（集合字段在 Redis Hash 中的值）
+func redisProtoMarshalField(buf []byte, p unsafe.Pointer, f *redisProtoField) ([]byte, error) {
+	buf, err := f.encode(buf, unsafe.Add(p, f.offset))
+	if err != nil {
+		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %w", f.name, err)
+	}
+	return buf, nil
+}
+
+func (f *redisProtoField) encode(buf []byte, v unsafe.Pointer) ([]byte, error) {
+	var err error
+	switch {
+	case f.slice != nil:
+		base, n, size := f.slice.elems(v)
+		for i := 0; i < n; i++ {
+			buf = redisProtoAppendVarint(buf, f.tag<<3|f.val.wire)
+			if f.val.kind != redisProtoKindMessage {
+				buf = f.val.appendScalar(buf, unsafe.Add(base, uintptr(i)*size))
+			} else if buf, err = f.val.append(buf, unsafe.Add(base, uintptr(i)*size)); err != nil {
+				return nil, err
+			}
+		}
+	case f.mapping != nil:
+		buf, err = f.mapping.appendEntries(buf, v, f)
+	case f.val.kind != redisProtoKindMessage:
+		if !f.val.empty(v) {
+			buf = f.val.appendScalar(redisProtoAppendVarint(buf, f.tag<<3|f.val.wire), v)
+		}
+	default:
+		buf, err = f.val.append(redisProtoAppendVarint(buf, f.tag<<3|f.val.wire), v)
+	}
+	return buf, err
+}
+
+// appendEntry 把 map 的一个键值对编码为子消息追加到 buf，entry 为复用的子消息缓冲
+func (f *redisProtoField) appendEntry(buf, entry []byte, k, v unsafe.Pointer) ([]byte, []byte, error) {
+	entry = f.key.appendScalar(redisProtoAppendVarint(entry[:0], 1<<3|f.key.wire), k) // map 的键只能是标量
+	entry, err := f.val.append(redisProtoAppendVarint(entry, 2<<3|f.val.wire), v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return redisProtoAppendLen(redisProtoAppendVarint(buf, f.tag<<3|2), entry), entry, nil
+}
+
+// redisProtoUnmarshal 按字段表把 b 解码到 p 指向的 message（不先重置）：未知字段跳过，缺失字段保持原值
+func redisProtoUnmarshal(b []byte, p unsafe.Pointer, table []redisProtoField) error {
+	i := 0 // 上次命中的表项：字段通常按表中顺序出现（repeated 的元素连续出现），先试它与下一项，不中再查整张表
+	for len(b) > 0 {
+		tag, n, err := redisProtoReadVarint(b)
+		if err != nil {
+			return fmt.Errorf("protobuf 读取字段 tag 失败: %w", err)
+		}
+		b = b[n:]
+		field := tag >> 3
+		switch {
+		case i < len(table) && table[i].tag == field:
+		case i+1 < len(table) && table[i+1].tag == field:
+			i++
+		default:
+			for i = 0; i < len(table) && table[i].tag != field; i++ {
+			}
+		}
+		if i == len(table) {
+			n, err = redisProtoSkip(b, tag&7)
+			i = 0
+		} else {
+			n, err = table[i].decode(b, tag&7, unsafe.Add(p, table[i].offset))
+		}
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// redisProtoUnmarshalField 解码只含字段表中一项的字节（redisProtoMarshalField 的输出），出现其他字段时报错
+func redisProtoUnmarshalField(b []byte, p unsafe.Pointer, f *redisProtoField) error {
+	for len(b) > 0 {
+		tag, n, err := redisProtoReadVarint(b)
+		if err != nil {
+			return err
+		}
+		if tag>>3 != f.tag {
+			return fmt.Errorf("protobuf 字段 %s tag 不匹配: %d", f.name, tag>>3)
+		}
+		b = b[n:]
+		if n, err = f.decode(b, tag&7, unsafe.Add(p, f.offset)); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// decode 按 wire type 解码字段的一个值到 v 指向的字段：repeated 追加一个元素（packed 时追加多个），map 存入一个键值对
+func (f *redisProtoField) decode(b []byte, wire uint64, v unsafe.Pointer) (int, error) {
+	var n int
+	var err error
+	switch {
+	case f.mapping != nil && wire == 2:
+		var entry []byte
+		if entry, n, err = redisProtoReadBytes(b); err != nil {
+			return 0, err
+		}
+		err = f.mapping.storeEntry(v, f, entry)
+	case f.mapping != nil:
+		return 0, fmt.Errorf("protobuf 字段 %s wire type 错误: %d", f.name, wire)
+	case wire == f.val.wire && f.slice != nil:
+		n, err = f.val.read(b, f.slice.grow(v))
+	case wire == f.val.wire && f.val.kind != redisProtoKindMessage:
+		n, err = f.val.readScalar(b, v)
+	case wire == f.val.wire:
+		n, err = f.val.read(b, v)
+	case wire == 2 && f.slice != nil && f.val.wire != 2:
+		var packed []byte
+		if packed, n, err = redisProtoReadBytes(b); err != nil {
+			return 0, err
+		}
+		for len(packed) > 0 && err == nil {
+			var m int
+			m, err = f.val.read(packed, f.slice.grow(v))
+			packed = packed[m:]
+		}
+	default:
+		return 0, fmt.Errorf("protobuf 字段 %s wire type 错误: %d", f.name, wire)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("protobuf 反序列化字段 %s 失败: %w", f.name, err)
+	}
+	return n, nil
+}
+
+// readEntry 解码 map 的一个键值对子消息到 k、v（缺失的键或值保持零值）
+func (f *redisProtoField) readEntry(entry []byte, k, v unsafe.Pointer) error {
+	for len(entry) > 0 {
+		t, m, err := redisProtoReadVarint(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		switch {
+		case t>>3 == 1 && t&7 == f.key.wire:
+			m, err = f.key.readScalar(entry, k)
+		case t>>3 == 2 && t&7 == f.val.wire:
+			m, err = f.val.read(entry, v)
+		case t>>3 == 1 || t>>3 == 2:
+			return fmt.Errorf("map 键值 wire type 错误: %d", t&7)
+		default:
+			m, err = redisProtoSkip(entry, t&7)
+		}
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+	}
+	return nil
+}
+`
+
+// codeTemplatePerfHelpers 是 perf=true 时的热路径辅助函数：GetFields/SetFields 复用的参数缓冲池，以及直接编码在调用方缓冲中的
+// protobuf 辅助函数（依赖 codeTemplateProtoHelpers）。包内使用 perf=true 时输出到辅助代码文件（不含模板动作）。
+const codeTemplatePerfHelpers = `
+// --- 热路径辅助函数（perf=true） ---
+
+// redisArgs 是 GetFields/SetFields 一次命令的参数（Args）与字节缓冲（Buf，存放 key 与字段的编码结果），
+// 经 redisGetArgs 从池中取出，命令返回后由 redisPutArgs 归还。因此执行器不能在 Do / Pipeline / Multi 返回后继续持有参数，
+// 内置的适配器与内存执行器都满足这一点
+type redisArgs struct {
+	Args []interface{}
+	Buf  []byte
+}
+
+// redisArgsMaxBuf 是归还到池中的字节缓冲的容量上限：个别大 message 撑大的缓冲直接丢弃，池不长期占用大块内存
+const redisArgsMaxBuf = 64 << 10
+
+var redisArgsPool = sync.Pool{New: func() interface{} { return new(redisArgs) }}
+
+// redisGetArgs 从池中取出一组参数缓冲（Args 与 Buf 长度为 0）
+func redisGetArgs() *redisArgs {
+	return redisArgsPool.Get().(*redisArgs)
+}
+
+// redisPutArgs 归还参数缓冲，并清除 Args 中的引用
+func redisPutArgs(a *redisArgs) {
+	if cap(a.Buf) > redisArgsMaxBuf {
+		return
+	}
+	clear(a.Args[:cap(a.Args)])
+	a.Args, a.Buf = a.Args[:0], a.Buf[:0]
+	redisArgsPool.Put(a)
+}
+
+// redisBytesString 把 b 当作 string 使用而不拷贝，只用于交给 strconv 解析等不保留结果的场合
+func redisBytesString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// redisProtoAppendString 追加 length-delimited 的字符串，省去 []byte(s) 的转换
+func redisProtoAppendString(buf []byte, s string) []byte {
+	buf = redisProtoAppendVarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// redisProtoOpenLen 追加字段 tag（wire type 2）并为长度前缀预留 1 字节，随后的内容直接编码在 buf 中，
+// 结束时以内容的起始位置（此时的 len(buf)）调用 redisProtoCloseLen 补写长度
+func redisProtoOpenLen(buf []byte, field int32) []byte {
+	return append(redisProtoAppendTag(buf, field, 2), 0)
+}
+
+// redisProtoCloseLen 补写 redisProtoOpenLen 预留的长度前缀：内容不足 128 字节时正好占满预留的 1 字节，否则把内容后移腾出位置
+func redisProtoCloseLen(buf []byte, start int) []byte {
+	n := len(buf) - start
+	if n < 0x80 {
+		buf[start-1] = byte(n)
+		return buf
+	}
+	var prefix [10]byte
+	k := len(redisProtoAppendVarint(prefix[:0], uint64(n)))
+	buf = append(buf, prefix[1:k]...)
+	copy(buf[start+k-1:], buf[start:start+n])
+	copy(buf[start-1:], prefix[:k])
+	return buf
+}
+`
+
+// codeTemplateEnumHelpers 是枚举名字与整数互转的辅助函数，包内任一文件需要枚举名字对照表时输出到辅助代码文件（不含模板动作）。
+const codeTemplateEnumHelpers = `
+// redisEnumName 返回枚举值在 proto 中的名字；未知值（如较新的 .proto 增加的枚举值）返回十进制整数，读取时仍可解析
+func redisEnumName(v int32, names map[int32]string) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return strconv.FormatInt(int64(v), 10)
+}
+
+// redisParseEnum 解析 hash 中的枚举值：十进制整数或 proto 中的枚举值名，名字未知时返回列出可选名字的错误
+func redisParseEnum(b []byte, values map[string]int32) (int32, error) {
+	if n, err := strconv.ParseInt(string(b), 10, 32); err == nil {
+		return int32(n), nil
+	}
+	if n, ok := values[string(b)]; ok {
+		return n, nil
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return 0, fmt.Errorf("未知的枚举值名 %q（可选: %s）", b, strings.Join(names, ", "))
+}
+`
+
+// codeTemplateJSONHelpers 是 proto3 JSON 编解码的辅助函数，包内任一文件设置了 encoding 时输出到辅助代码文件（不含模板动作）。
+const codeTemplateJSONHelpers = `
+// --- proto3 JSON 辅助函数（encoding=VALUE_ENCODING_JSON 的字段，规则见 https://protobuf.dev/programming-guides/json/） ---
+
+// redisJSONValue 报告 Hash 中的值是否为 JSON（以 { 或 [ 开头）。protobuf 字节的首字节是字段 tag，
+// 0x7B / 0x5B 对应 wire type 3（group），proto3 编码中不会出现，因此两种编码可以按首字节区分
+func redisJSONValue(b []byte) bool {
+	return len(b) > 0 && (b[0] == '{' || b[0] == '[')
+}
+
+// redisJSONIsNull 报告 JSON 值是否为 null（proto3 JSON 中等同于字段未设置）
+func redisJSONIsNull(v []byte) bool {
+	return string(v) == "null"
+}
+
+// redisJSONAppendName 追加对象成员名 "name":，不是对象的第一个成员时先追加逗号
+func redisJSONAppendName(buf []byte, name string) []byte {
+	if buf[len(buf)-1] != '{' {
+		buf = append(buf, ',')
+	}
+	buf = redisJSONAppendString(buf, name)
+	return append(buf, ':')
+}
+
+// redisJSONAppendString 追加 JSON 字符串：转义引号、反斜杠与控制字符，非法 UTF-8 替换为 U+FFFD
+func redisJSONAppendString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf = append(buf, '\\', c)
+			case c == '\n':
+				buf = append(buf, '\\', 'n')
+			case c == '\r':
+				buf = append(buf, '\\', 'r')
+			case c == '\t':
+				buf = append(buf, '\\', 't')
+			case c < 0x20:
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			default:
+				buf = append(buf, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, "\ufffd"...)
+		} else {
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
+
+// redisJSONAppendBytes 追加 bytes：标准 base64（带填充）字符串
+func redisJSONAppendBytes(buf, v []byte) []byte {
+	buf = append(buf, '"')
+	buf = append(buf, base64.StdEncoding.EncodeToString(v)...)
+	return append(buf, '"')
+}
+
+// redisJSONAppendInt64 追加 int64：以字符串表示（JavaScript 等语言的数字只有 53 位精度）
+func redisJSONAppendInt64(buf []byte, v int64) []byte {
+	buf = strconv.AppendInt(append(buf, '"'), v, 10)
+	return append(buf, '"')
+}
+
+// redisJSONAppendUint64 追加 uint64：以字符串表示，同 redisJSONAppendInt64
+func redisJSONAppendUint64(buf []byte, v uint64) []byte {
+	buf = strconv.AppendUint(append(buf, '"'), v, 10)
+	return append(buf, '"')
+}
+
+// redisJSONAppendFloat 追加浮点数，格式与 protojson 一致：NaN 与 ±Inf 为字符串 "NaN"、"Infinity"、"-Infinity"，
+// 绝对值小于 1e-6 或不小于 1e21 时为指数形式（如 1e-7、1e+21），其余为不带指数的最短十进制
+func redisJSONAppendFloat(buf []byte, v float64, bits int) []byte {
+	switch {
+	case math.IsNaN(v):
+		return append(buf, "\"NaN\""...)
+	case math.IsInf(v, 1):
+		return append(buf, "\"Infinity\""...)
+	case math.IsInf(v, -1):
+		return append(buf, "\"-Infinity\""...)
+	}
+	format := byte('f')
+	if abs := math.Abs(v); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	buf = strconv.AppendFloat(buf, v, format, -1, bits)
+	if n := len(buf); format == 'e' && n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+		// 与 encoding/json 相同，把 e-09 写成 e-9
+		buf[n-2] = buf[n-1]
+		buf = buf[:n-1]
+	}
+	return buf
+}
+
+// redisJSONAppendEnum 追加枚举：已知值为名字字符串，未知值为数字
+func redisJSONAppendEnum(buf []byte, v int32, names map[int32]string) []byte {
+	if name, ok := names[v]; ok {
+		return redisJSONAppendString(buf, name)
+	}
+	return strconv.AppendInt(buf, int64(v), 10)
+}
+
+// redisJSONNumber 返回数值的文本：proto3 JSON 中数值既可以是数字，也可以是字符串
+func redisJSONNumber(v []byte) (string, error) {
+	if len(v) > 0 && v[0] == '"' {
+		return redisJSONString(v)
+	}
+	return string(v), nil
+}
+
+// redisJSONInt 解析有符号整数（数字或十进制字符串），bits 为位宽
+func redisJSONInt(v []byte, bits int) (int64, error) {
+	s, err := redisJSONNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, bits)
+}
+
+// redisJSONUint 解析无符号整数（数字或十进制字符串），bits 为位宽
+func redisJSONUint(v []byte, bits int) (uint64, error) {
+	s, err := redisJSONNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(s, 10, bits)
+}
+
+// redisJSONFloat 解析浮点数（数字、数字字符串或 "NaN"、"Infinity"、"-Infinity"），bits 为位宽；
+// 与 protojson 一致，字符串中只接受 JSON 数字语法（不接受 "nan"、"inf"、十六进制等），超出位宽范围时报错
+func redisJSONFloat(v []byte, bits int) (float64, error) {
+	s, err := redisJSONNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	switch s {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	if s == "" || s[0] != '-' && (s[0] < '0' || s[0] > '9') || !json.Valid([]byte(s)) {
+		return 0, fmt.Errorf("无效的浮点数 %q", s)
+	}
+	return strconv.ParseFloat(s, bits)
+}
+
+// redisJSONBool 解析 true / false
+func redisJSONBool(v []byte) (bool, error) {
+	var b bool
+	err := json.Unmarshal(v, &b)
+	return b, err
+}
+
+// redisJSONString 解析字符串
+func redisJSONString(v []byte) (string, error) {
+	var s string
+	err := json.Unmarshal(v, &s)
+	return s, err
+}
+
+// redisJSONBytes 解析 base64 字符串：标准与 URL 安全字母表、带或不带填充均可
+func redisJSONBytes(v []byte) ([]byte, error) {
+	s, err := redisJSONString(v)
+	if err != nil {
+		return nil, err
+	}
+	s = strings.TrimRight(s, "=")
+	if strings.ContainsAny(s, "-_") {
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+// redisJSONEnum 解析枚举：名字字符串或数字
+func redisJSONEnum(v []byte, values map[string]int32) (int32, error) {
+	if len(v) > 0 && v[0] == '"' {
+		name, err := redisJSONString(v)
+		if err != nil {
+			return 0, err
+		}
+		n, ok := values[name]
+		if !ok {
+			return 0, fmt.Errorf("未知的枚举值 %q", name)
+		}
+		return n, nil
+	}
+	n, err := strconv.ParseInt(string(v), 10, 32)
+	return int32(n), err
+}
+`
+
+// codeTemplateCompressHelpers 是值压缩的辅助函数，包内任一文件设置了 compression 时输出到辅助代码文件（不含模板动作）。
+const codeTemplateCompressHelpers = `
+// --- 值压缩辅助函数（compression=COMPRESSION_FLATE 的 message / 集合字段） ---
+
+// redisCompressFlate 是压缩值的 1 字节头，其后为 DEFLATE 数据。protobuf 字节的首字节是字段 tag，
+// 0x01 对应字段编号 0（非法），JSON 以 { 或 [ 开头，因此未压缩的值不会以它开头，两者可以按首字节区分
+const redisCompressFlate = 0x01
+
+// redisFlateWriters 复用 DEFLATE 编码器（每个编码器自带数百 KB 的缓冲区）
+var redisFlateWriters = sync.Pool{New: func() interface{} {
+	w, _ := flate.NewWriter(nil, flate.DefaultCompression) // 级别合法时不会出错
+	return w
+}}
+
+// redisCompress 在 b 达到 minSize 字节时以 DEFLATE 压缩并加上压缩头；未达到阈值或压缩后不更小时原样返回
+func redisCompress(b []byte, minSize int) []byte {
+	if len(b) < minSize {
+		return b
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(b) / 2)
+	buf.WriteByte(redisCompressFlate)
+	w := redisFlateWriters.Get().(*flate.Writer)
+	defer redisFlateWriters.Put(w)
+	w.Reset(&buf)
+	// 写入 bytes.Buffer 不会失败
+	_, _ = w.Write(b)
+	_ = w.Close()
+	if buf.Len() >= len(b) {
+		return b
+	}
+	return buf.Bytes()
+}
+
+// redisDecompress 返回 Hash 中的值解压后的字节：以压缩头开头时解压，否则原样返回（未压缩或开启压缩前写入的值）
+func redisDecompress(b []byte) ([]byte, error) {
+	if len(b) == 0 || b[0] != redisCompressFlate {
+		return b, nil
+	}
+	r := flate.NewReader(bytes.NewReader(b[1:]))
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("DEFLATE 解压失败: %v", err)
+	}
+	return out, nil
+}
+`
+
+// codeTemplateCryptoHelpers 是敏感字段加解密的辅助函数，包内任一文件有字段设置了 sensitive 时输出到辅助代码文件（不含模板动作）。
+const codeTemplateCryptoHelpers = `
+// --- 敏感字段加解密（sensitive 字段，AES-GCM） ---
+
+// RedisKeyProvider 提供敏感字段（sensitive）加解密用的 AES 密钥。密钥 ID 随密文写入 Redis：
+// 轮换时 CurrentKey 改为返回新密钥，旧密钥仍能经 Key 取到，已有数据按各自的 ID 解密，重新写入时改用新密钥。
+// 每次读写敏感字段都会调用，访问 KMS 等外部服务的实现应自行缓存。
+type RedisKeyProvider interface {
+	// CurrentKey 返回加密新值使用的密钥 ID（1~255 字节）与密钥（16、24 或 32 字节，对应 AES-128/192/256）
+	CurrentKey(ctx context.Context) (id string, key []byte, err error)
+	// Key 按密文中的密钥 ID 返回解密用的密钥
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// RedisStaticKeys 是基于固定密钥表的 RedisKeyProvider：新值用 Current 对应的密钥加密，解密按密钥 ID 查 Keys。
+// 设置后不要修改；轮换时以加入了新密钥、Current 改为新 ID 的新表再次调用 SetRedisKeyProvider，旧密钥保留到旧数据都被重新写入
+type RedisStaticKeys struct {
+	Current string
+	Keys    map[string][]byte
+}
+
+// CurrentKey 返回 Current 对应的密钥
+func (k *RedisStaticKeys) CurrentKey(ctx context.Context) (string, []byte, error) {
+	key, err := k.Key(ctx, k.Current)
+	return k.Current, key, err
+}
+
+// Key 按密钥 ID 查找密钥
+func (k *RedisStaticKeys) Key(ctx context.Context, id string) ([]byte, error) {
+	key, ok := k.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("未知的密钥 ID %q", id)
+	}
+	return key, nil
+}
+
+var (
+	redisKeyProviderMu    sync.RWMutex
+	redisKeyProvider      RedisKeyProvider
+	redisRequireEncrypted bool
+)
+
+// SetRedisKeyProvider 设置本包敏感字段使用的密钥来源，应在读写敏感字段之前（如 init 或启动时）调用。
+// 未设置时写入敏感字段返回错误（不会以明文写入），读取已加密的值同样返回错误
+func SetRedisKeyProvider(p RedisKeyProvider) {
+	redisKeyProviderMu.Lock()
+	defer redisKeyProviderMu.Unlock()
+	redisKeyProvider = p
+}
+
+// SetRedisRequireEncrypted 设置读取敏感字段时是否只接受密文。默认 false：不以密文头开头的值视为开启加密前写入的明文，原样返回，
+// 便于已有数据逐步迁移；但能写 Redis 的人因此可以把密文换成任意明文而不被发现（降级）。全部旧数据都被重新写入后应设为 true，
+// 之后读到明文时返回错误
+func SetRedisRequireEncrypted(require bool) {
+	redisKeyProviderMu.Lock()
+	defer redisKeyProviderMu.Unlock()
+	redisRequireEncrypted = require
+}
+
+func redisCurrentKeyProvider() (RedisKeyProvider, error) {
+	redisKeyProviderMu.RLock()
+	defer redisKeyProviderMu.RUnlock()
+	if redisKeyProvider == nil {
+		return nil, fmt.Errorf("未设置 RedisKeyProvider（见 SetRedisKeyProvider）")
+	}
+	return redisKeyProvider, nil
+}
+
+// redisEncrypted 是密文的 1 字节头，其后依次为密钥 ID 长度（1 字节）、密钥 ID、12 字节 nonce 与 AES-GCM 密文（含 16 字节认证标签）。
+// 0x02 在 protobuf 中对应字段编号 0（非法），也不是 JSON 或压缩头，开启加密前写入的明文不会以它开头（string / bytes 字段除外，见 redisDecrypt）
+const redisEncrypted = 0x02
+
+// redisGCM 创建 AES-GCM 实例
+func redisGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// redisAAD 返回附加认证数据：Hash key、0x00 与字段的 proto 全名，密文不能被挪到其他记录或其他字段解密
+func redisAAD(key, field string) []byte {
+	aad := make([]byte, 0, len(key)+1+len(field))
+	aad = append(aad, key...)
+	aad = append(aad, 0)
+	return append(aad, field...)
+}
+
+// redisEncrypt 用当前密钥加密 plain，附加认证数据绑定记录的 Hash key 与字段的 proto 全名（见 redisAAD），返回带头部的密文
+func redisEncrypt(ctx context.Context, plain []byte, key, field string) ([]byte, error) {
+	provider, err := redisCurrentKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+	id, secret, err := provider.CurrentKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取当前密钥失败: %w", err)
+	}
+	if len(id) == 0 || len(id) > 255 {
+		return nil, fmt.Errorf("密钥 ID %q 的长度须为 1~255 字节", id)
+	}
+	gcm, err := redisGCM(secret)
+	if err != nil {
+		return nil, fmt.Errorf("密钥 %q 不可用: %v", id, err)
+	}
+	out := make([]byte, 0, 2+len(id)+gcm.NonceSize()+len(plain)+gcm.Overhead())
+	out = append(out, redisEncrypted, byte(len(id)))
+	out = append(out, id...)
+	nonce := out[len(out) : len(out)+gcm.NonceSize()]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成 nonce 失败: %v", err)
+	}
+	out = out[:len(out)+len(nonce)]
+	return gcm.Seal(out, nonce, plain, redisAAD(key, field)), nil
+}
+
+// redisDecrypt 解密 Hash key 中字段 field 的值：以密文头开头时按其中的密钥 ID 取密钥解密；否则视为开启加密前写入的明文原样返回，
+// SetRedisRequireEncrypted(true) 后返回错误。密文从其他记录或字段挪来时认证失败。
+// string / bytes 字段的明文恰好以 0x02 开头时会被当作密文，认证失败而返回错误，不会得到错误的明文
+func redisDecrypt(ctx context.Context, b []byte, key, field string) ([]byte, error) {
+	if len(b) == 0 || b[0] != redisEncrypted {
+		redisKeyProviderMu.RLock()
+		require := redisRequireEncrypted
+		redisKeyProviderMu.RUnlock()
+		if require {
+			return nil, fmt.Errorf("值未加密（已设置 SetRedisRequireEncrypted）")
+		}
+		return b, nil
+	}
+	if len(b) < 2 || len(b) < 2+int(b[1]) {
+		return nil, fmt.Errorf("密文格式错误")
+	}
+	id := string(b[2 : 2+int(b[1])])
+	provider, err := redisCurrentKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := provider.Key(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取密钥 %q 失败: %w", id, err)
+	}
+	gcm, err := redisGCM(secret)
+	if err != nil {
+		return nil, fmt.Errorf("密钥 %q 不可用: %v", id, err)
+	}
+	rest := b[2+len(id):]
+	if len(rest) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, fmt.Errorf("密文格式错误")
+	}
+	plain, err := gcm.Open(nil, rest[:gcm.NonceSize()], rest[gcm.NonceSize():], redisAAD(key, field))
+	if err != nil {
+		return nil, fmt.Errorf("密钥 %q 解密失败（密钥不匹配、数据被篡改或从其他记录挪来）", id)
+	}
+	return plain, nil
+}
+`
+
+// codeTemplate 按 message 生成 Redis 存取代码。
+// 字段的 protobuf 编码/解码逻辑抽成 fieldEncode / fieldDecode 两个模板块，
+// 整体序列化（MarshalRedisProto / UnmarshalRedisProto）与集合字段（map/repeated）
+// 的字段级序列化方法（MarshalRedisProto<Field> / UnmarshalRedisProto<Field>）共用同一份逻辑。
+const codeTemplate = `
+// {{.FieldType}} 用于标识 Redis Hash 中的字段编号
+type {{.FieldType}} uint32
+
+{{range .Fields}}
+// {{$.FieldType}}_{{.Name}} 是字段 {{.Name}} 对应的 Redis Hash field 编号
+const {{$.FieldType}}_{{.Name}} {{$.FieldType}} = {{.ProtoTag}}
+{{end}}
+
+// {{.FieldType}}IDs 是所有字段编号常量的集合，类型为 []{{.FieldType}}
+var {{.FieldType}}IDs = []{{.FieldType}}{
+	{{range .Fields}}{{$.FieldType}}_{{.Name}},
+	{{end}}
+}
+
+// {{.MessageName}} 提供针对 {{.MessageName}} 消息的 Redis 存取操作
+type {{.MessageName}} struct {
+	{{range .Fields}}
+	{{.Name}} {{.GoType}}
+	{{end}}
+}
+
+// New{{.MessageName}} 创建一个新的 {{.MessageName}} 实例
+func New{{.MessageName}}() *{{.MessageName}} {
+	return &{{.MessageName}}{}
+}
+{{- if .HasSensitive}}
+
+// String 返回 {{.MessageName}} 的文本表示（格式同 %+v），敏感字段显示为 [REDACTED]，避免明文进入日志
+func (p {{.MessageName}}) String() string {
+	var b strings.Builder
+	b.WriteString("{")
+	{{- range $i, $f := .Fields}}
+	{{- if $f.Sensitive}}
+	b.WriteString("{{if $i}} {{end}}{{$f.Name}}:[REDACTED]")
+	{{- else}}
+	fmt.Fprintf(&b, "{{if $i}} {{end}}{{$f.Name}}:%v", p.{{$f.Name}})
+	{{- end}}
+	{{- end}}
+	b.WriteString("}")
+	return b.String()
+}
+{{- end}}
+{{- if .PBType}}
+
+// ToProto 把 {{.MessageName}} 转换为 protoc-gen-go 生成的 {{.PBType}}（嵌套 message、集合与枚举逐一转换，不与 p 共享 map 与切片）；
+// p 为 nil 时返回 nil
+func (p *{{.MessageName}}) ToProto() *{{.PBType}} {
+	if p == nil {
+		return nil
+	}
+	m := &{{.PBType}}{}
+	{{- range .Fields}}
+	{{.ToProto}}
+	{{- end}}
+	return m
+}
+
+// From{{.MessageName}}Proto 把 protoc-gen-go 生成的 {{.PBType}} 转换为 {{.MessageName}}，m 为 nil 时按空 message 处理
+func From{{.MessageName}}Proto(m *{{.PBType}}) *{{.MessageName}} {
+	p := New{{.MessageName}}()
+	if m == nil {
+		return p
+	}
+	{{- range .Fields}}
+	{{.FromProto}}
+	{{- end}}
+	return p
+}
+{{- end}}
+{{- if .Perf}}
+
+// redisKey{{.MessageName}} 按 key_format 生成 {{.MessageName}} 对应的 Redis Hash key
+func redisKey{{.MessageName}}(REDBKey uint32, ida, idb uint64) string {
+	var buf [64]byte
+	return string(redisAppendKey{{.MessageName}}(buf[:0], REDBKey, ida, idb))
+}
+
+// redisAppendKey{{.MessageName}} 把按 key_format {{printf "%q" .KeyFormat}} 生成的 key 追加到 dst（perf=true，不经 fmt）
+func redisAppendKey{{.MessageName}}(dst []byte, REDBKey uint32, ida, idb uint64) []byte {
+	{{.KeyAppend}}
+}
+{{- else}}
+
+// redisKey{{.MessageName}} 按 key_format 生成 {{.MessageName}} 对应的 Redis Hash key
+func redisKey{{.MessageName}}(REDBKey uint32, ida, idb uint64) string {
+	return fmt.Sprintf({{printf "%q" .KeyFormat}}, REDBKey, ida, idb)
+}
+{{- end}}
+{{- if .HasNamed}}
+
+// redisHashField{{.MessageName}} 返回字段在 Redis Hash 中的 field：按名字存储的字段为名字，其余为字段编号
+func redisHashField{{.MessageName}}(id {{.FieldType}}) interface{} {
+	switch id {
+	{{- range .Fields}}{{if .HashName}}
+	case {{$.FieldType}}_{{.Name}}:
+		return {{.HashField}}
+	{{- end}}{{end}}
+	default:
+		return uint32(id)
+	}
+}
+{{- end}}
+{{- if .TagFallback}}
+
+// redisMoveTagFields{{.MessageName}} 把 fields（为空时全部字段）中按名字存储、但值仍在旧字段编号 field 下的字段搬到名字下（tag_fallback 迁移窗口，写入前调用）
+func redisMoveTagFields{{.MessageName}}(ctx context.Context, exec RedisExecutor, key string, fields []{{.FieldType}}) error {
+	if len(fields) == 0 {
+		fields = {{.FieldType}}IDs
+	}
+	var moves []redisHashMove
+	for _, id := range fields {
+		if name, ok := redisHashField{{.MessageName}}(id).(string); ok {
+			moves = append(moves, redisHashMove{Tag: uint32(id), Name: name})
+		}
+	}
+	return redisMoveHashFields(ctx, exec, key, moves)
+}
+{{- end}}
+{{- range .Fields}}{{if .Index}}
+
+// redisIndexKey{{$.MessageName}}_{{.Name}} 是字段 {{.Name}} 的 sorted set 索引 key（zset_index.key 模板）
+func redisIndexKey{{$.MessageName}}_{{.Name}}(REDBKey uint32, ida, idb uint64) string {
+	return {{.Index.Expr}}
+}
+{{- end}}{{end}}
+{{- range .Fields}}{{if .Unique}}
+
+// redisUniqueKey{{$.MessageName}}_{{.Name}} 是字段 {{.Name}} 的唯一索引 key（unique_index.key 模板）：hash，field 为字段值，值为占用它的记录
+func redisUniqueKey{{$.MessageName}}_{{.Name}}(REDBKey uint32, ida, idb uint64) string {
+	return {{.Unique.Expr}}
+}
+
+// redisUniqueValue{{$.MessageName}}_{{.Name}} 把 {{.Name}} 编码为唯一索引的 field（与 hash 中存储的字节一致），零值返回 nil（不占用索引）
+func redisUniqueValue{{$.MessageName}}_{{.Name}}(v {{.GoType}}) []byte {
+	{{- if eq .GoType "string"}}
+	if v == "" {
+		return nil
+	}
+	return []byte(v)
+	{{- else}}
+	if v == 0 {
+		return nil
+	}
+	return strconv.Append{{if or (eq .GoType "uint32") (eq .GoType "uint64")}}Uint(nil, uint64(v), 10){{else}}Int(nil, int64(v), 10){{end}}
+	{{- end}}
+}
+
+// Find{{$.MessageName}}By{{.Name}} 按唯一索引查找 {{.Name}} 等于 v 的记录，返回其 ida、idb；不存在（或 v 为零值）时 ok 为 false
+func Find{{$.MessageName}}By{{.Name}}(ctx context.Context, exec RedisExecutor, REDBKey uint32, {{.Unique.Params}}v {{.GoType}}) (uint64, uint64, bool, error) {
+	b := redisUniqueValue{{$.MessageName}}_{{.Name}}(v)
+	if b == nil {
+		return 0, 0, false, nil
+	}
+	reply, err := exec.Do(ctx, "HGET", redisUniqueKey{{$.MessageName}}_{{.Name}}(REDBKey, {{.Unique.Args}}), b)
+	if err != nil || reply == nil {
+		return 0, 0, false, err
+	}
+	owner, ok := reply.([]byte)
+	if !ok {
+		return 0, 0, false, fmt.Errorf("解析 HGET 结果失败: 意外的回复 %T", reply)
+	}
+	a, c, err := redisParseRecordMember(owner)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return a, c, true, nil
+}
+{{- end}}{{end}}
+{{- if .HasUnique}}
+
+// redisUniqueRelease{{.MessageName}} 返回删除 fields（为空时整条记录）时需释放的唯一索引条目的 HDEL：读出字段当前值，只释放仍由本记录占用的条目
+func redisUniqueRelease{{.MessageName}}(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields []{{.FieldType}}) ([]RedisCmd, error) {
+	var keys []string
+	args := []interface{}{redisKey{{.MessageName}}(REDBKey, ida, idb)}
+	{{- range .Fields}}{{if .Unique}}
+	if redisFieldSelected{{$.MessageName}}(fields, {{$.FieldType}}_{{.Name}}) {
+		keys = append(keys, redisUniqueKey{{$.MessageName}}_{{.Name}}(REDBKey, ida, idb))
+		args = append(args, {{.HashField}})
+	}
+	{{- end}}{{end}}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	reply, err := exec.Do(ctx, "HMGET", args...)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(keys) {
+		return nil, fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
+	}
+	var gets []RedisCmd
+	for i, v := range values {
+		if b, _ := v.([]byte); len(b) > 0 {
+			gets = append(gets, RedisCmd{Name: "HGET", Args: []interface{}{keys[i], b} })
+		}
+	}
+	return redisUniqueOwned(ctx, exec, redisRecordMember(ida, idb), gets)
+}
+
+// redisFieldSelected{{.MessageName}} 报告 fields（为空表示全部字段）是否包含 id
+func redisFieldSelected{{.MessageName}}(fields []{{.FieldType}}, id {{.FieldType}}) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, f := range fields {
+		if f == id {
+			return true
+		}
+	}
+	return false
+}
+{{- end}}
+{{range .Fields}}{{if .Native}}
+// redisNativeKey{{$.MessageName}}_{{.Name}} 是原生存储字段 {{.Name}} 的独立 key（Redis {{.Native}}）：Hash key 后接 ":{{.ProtoTag}}"
+func redisNativeKey{{$.MessageName}}_{{.Name}}(REDBKey uint32, ida, idb uint64) string {
+	return redisKey{{$.MessageName}}(REDBKey, ida, idb) + ":{{.ProtoTag}}"
+}
+
+// redisNativeEncode{{$.MessageName}}_{{.Name}} 把 {{.Name}} 的{{if eq .Native "hash"}} map 值{{else}}元素{{end}}编码为独立 key 中存储的字节
+func redisNativeEncode{{$.MessageName}}_{{.Name}}(v {{.NativeElem.GoType}}) ([]byte, error) {
+{{- template "nativeEncode" .NativeElem -}}
+}
+
+// redisNativeDecode{{$.MessageName}}_{{.Name}} 是 redisNativeEncode{{$.MessageName}}_{{.Name}} 的逆过程
+func redisNativeDecode{{$.MessageName}}_{{.Name}}(b []byte) ({{.NativeElem.GoType}}, error) {
+{{- template "nativeDecode" .NativeElem -}}
+}
+{{if eq .Native "hash"}}
+// redisNativeEncodeKey{{$.MessageName}}_{{.Name}} 把 {{.Name}} 的 map 键编码为独立 hash 的 field
+func redisNativeEncodeKey{{$.MessageName}}_{{.Name}}(v {{.NativeKey.GoType}}) ([]byte, error) {
+{{- template "nativeEncode" .NativeKey -}}
+}
+
+// redisNativeDecodeKey{{$.MessageName}}_{{.Name}} 是 redisNativeEncodeKey{{$.MessageName}}_{{.Name}} 的逆过程
+func redisNativeDecodeKey{{$.MessageName}}_{{.Name}}(b []byte) ({{.NativeKey.GoType}}, error) {
+{{- template "nativeDecode" .NativeKey -}}
+}
+{{end}}
+// redisNativeRead{{.Name}} 把 {{.NativeReadCmd}} 的回复解码到 p.{{.Name}}.{{.NativeItems}}（无元素时为 nil，与整体序列化的约定一致）
+func (p *{{$.MessageName}}) redisNativeRead{{.Name}}(reply interface{}) error {
+	values, ok := reply.([]interface{})
+	if !ok {
+		return fmt.Errorf("解析 {{.NativeReadCmd}} 结果失败: 意外的回复 %T", reply)
+	}
+	p.{{.Name}}.{{.NativeItems}} = nil
+	{{- if eq .Native "hash"}}
+	for i := 0; i+1 < len(values); i += 2 {
+		kb, _ := values[i].([]byte)
+		vb, _ := values[i+1].([]byte)
+		k, err := redisNativeDecodeKey{{$.MessageName}}_{{.Name}}(kb)
+		if err != nil {
+			return fmt.Errorf("解析字段 %s 的键失败: %v", "{{.Name}}", err)
+		}
+		v, err := redisNativeDecode{{$.MessageName}}_{{.Name}}(vb)
+		if err != nil {
+			return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+		}
+		if p.{{.Name}}.{{.NativeItems}} == nil {
+			p.{{.Name}}.{{.NativeItems}} = make(map[{{.NativeKey.GoType}}]{{.NativeElem.GoType}}, len(values)/2)
+		}
+		p.{{.Name}}.{{.NativeItems}}[k] = v
+	}
+	{{- else}}
+	for _, item := range values {
+		b, _ := item.([]byte)
+		v, err := redisNativeDecode{{$.MessageName}}_{{.Name}}(b)
+		if err != nil {
+			return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+		}
+		p.{{.Name}}.{{.NativeItems}} = append(p.{{.Name}}.{{.NativeItems}}, v)
+	}
+	{{- end}}
+	return nil
+}
+
+// redisNativeWrite{{.Name}} 返回整体覆盖 {{.Name}} 的命令：先 DEL 独立 key，有元素时再 {{.NativeWriteCmd}} 全部元素
+func (p *{{$.MessageName}}) redisNativeWrite{{.Name}}(key string) ([]RedisCmd, error) {
+	cmds := []RedisCmd{ {Name: "DEL", Args: []interface{}{key} } }
+	if len(p.{{.Name}}.{{.NativeItems}}) == 0 {
+		return cmds, nil
+	}
+	{{- if eq .Native "hash"}}
+	args := make([]interface{}, 0, 1+2*len(p.{{.Name}}.{{.NativeItems}}))
+	args = append(args, key)
+	for k, v := range p.{{.Name}}.{{.NativeItems}} {
+		kb, err := redisNativeEncodeKey{{$.MessageName}}_{{.Name}}(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := redisNativeEncode{{$.MessageName}}_{{.Name}}(v)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, kb, vb)
+	}
+	{{- else}}
+	args := make([]interface{}, 0, 1+len(p.{{.Name}}.{{.NativeItems}}))
+	args = append(args, key)
+	for _, v := range p.{{.Name}}.{{.NativeItems}} {
+		b, err := redisNativeEncode{{$.MessageName}}_{{.Name}}(v)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, b)
+	}
+	{{- end}}
+	return append(cmds, RedisCmd{Name: "{{.NativeWriteCmd}}", Args: args}), nil
+}
+{{end}}{{end}}
+{{- if .TableCodec}}
+// redisProtoTable{{.MessageName}} 是 {{.MessageName}} 的 protobuf 字段表（codec=table）：每个字段一项，整体编码按表中顺序进行
+var redisProtoTable{{.MessageName}} = []redisProtoField{
+{{- range .Fields}}
+	{{.ProtoTableEntry $.MessageName}},
+{{- end}}
+}
+
+// MarshalRedisProto 将 {{.MessageName}} 序列化为 protobuf wire format 字节流。
+// 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
+// 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
+// repeated 逐元素编码（含零值），map 每键值对编码为子消息（field 1=key, field 2=value）。
+func (p *{{.MessageName}}) MarshalRedisProto() ([]byte, error) {
+	return redisProtoMarshal(nil, unsafe.Pointer(p), redisProtoTable{{.MessageName}})
+}
+{{- if .Perf}}
+
+// MarshalRedisProtoAppend 与 MarshalRedisProto 相同，但把编码结果追加到 buf 并返回扩展后的切片，buf 可以是调用方复用的缓冲
+func (p *{{.MessageName}}) MarshalRedisProtoAppend(buf []byte) ([]byte, error) {
+	return redisProtoMarshal(buf, unsafe.Pointer(p), redisProtoTable{{.MessageName}})
+}
+{{- end}}
+
+// UnmarshalRedisProto 从 protobuf wire format 字节流反序列化到 {{.MessageName}}。
+// 反序列化前会先重置自身；未知字段跳过，缺失字段保持零值（proto3 语义）。
+func (p *{{.MessageName}}) UnmarshalRedisProto(b []byte) error {
+	*p = {{.MessageName}}{}
+	return redisProtoUnmarshal(b, unsafe.Pointer(p), redisProtoTable{{.MessageName}})
+}
+{{range $i, $f := .Fields}}
+{{- if or (eq .Kind "map") (eq .Kind "slice")}}
+// MarshalRedisProto{{.Name}} 将字段 {{.Name}}（集合字段）整体序列化为 protobuf wire format 字节，
+// 即 {{.Name}} 在 Redis Hash 中的值（hash field = tag {{.ProtoTag}}）
+func (p *{{$.MessageName}}) MarshalRedisProto{{.Name}}() ([]byte, error) {
+	return redisProtoMarshalField(nil, unsafe.Pointer(p), &redisProtoTable{{$.MessageName}}[{{$i}}])
+}
+{{- if .Perf}}
+
+// appendRedisProto{{.Name}} 把 MarshalRedisProto{{.Name}} 的结果追加到 buf
+func (p *{{$.MessageName}}) appendRedisProto{{.Name}}(buf []byte) ([]byte, error) {
+	return redisProtoMarshalField(buf, unsafe.Pointer(p), &redisProtoTable{{$.MessageName}}[{{$i}}])
+}
+{{- end}}
+
+// UnmarshalRedisProto{{.Name}} 从 {{.Name}} 字段的 protobuf wire format 字节反序列化
+// （字节须为 MarshalRedisProto{{.Name}} 的输出，或等价的单字段 protobuf 编码）
+func (p *{{$.MessageName}}) UnmarshalRedisProto{{.Name}}(b []byte) error {
+	p.{{.Name}} = nil
+	return redisProtoUnmarshalField(b, unsafe.Pointer(p), &redisProtoTable{{$.MessageName}}[{{$i}}])
+}
+{{end}}
+{{- end}}
+{{- else}}
+// MarshalRedisProto 将 {{.MessageName}} 序列化为 protobuf wire format 字节流。
+// 字节流与语言无关：任何语言使用同一份 .proto 定义即可解析。
+// 编码遵循 proto3 语义：零值标量/空字符串/空 bytes 不编码，message 字段恒编码，
+// repeated 逐元素编码（含零值），map 每键值对编码为子消息（field 1=key, field 2=value）。
+func (p *{{.MessageName}}) MarshalRedisProto() ([]byte, error) {
+{{- if .Perf}}
+	return p.MarshalRedisProtoAppend(nil)
+}
+
+// MarshalRedisProtoAppend 与 MarshalRedisProto 相同，但把编码结果追加到 buf 并返回扩展后的切片，buf 可以是调用方复用的缓冲；
+// 嵌套 message 直接编码在 buf 中，不再先单独编码再拷贝
+func (p *{{.MessageName}}) MarshalRedisProtoAppend(buf []byte) ([]byte, error) {
+{{- else}}
+	var buf []byte
+{{- end}}
+{{range .Fields}}{{template "fieldEncode" .}}{{end}}
+	return buf, nil
+}
+
+// UnmarshalRedisProto 从 protobuf wire format 字节流反序列化到 {{.MessageName}}。
+// 反序列化前会先重置自身；未知字段跳过，缺失字段保持零值（proto3 语义）。
+func (p *{{.MessageName}}) UnmarshalRedisProto(b []byte) error {
+	*p = {{.MessageName}}{}
+	for len(b) > 0 {
+		tag, n, err := redisProtoReadVarint(b)
+		if err != nil {
+			return fmt.Errorf("protobuf 读取字段 tag 失败: %v", err)
+		}
+		b = b[n:]
+		field := tag >> 3
+		wire := tag & 7
+		switch field {
+		{{range .Fields}}
+		case {{.ProtoTag}}: // {{.Name}}
+{{template "fieldDecode" .}}
+		{{end}}
+		default:
+			n, err = redisProtoSkip(b, wire)
+			if err != nil {
+				return err
+			}
+			b = b[n:]
+		}
+	}
+	return nil
+}
+
+{{range .Fields}}
+{{if or (eq .Kind "map") (eq .Kind "slice")}}
+// MarshalRedisProto{{.Name}} 将字段 {{.Name}}（集合字段）整体序列化为 protobuf wire format 字节，
+// 即 {{.Name}} 在 Redis Hash 中的值（hash field = tag {{.ProtoTag}}）
+func (p *{{$.MessageName}}) MarshalRedisProto{{.Name}}() ([]byte, error) {
+{{- if .Perf}}
+	return p.appendRedisProto{{.Name}}(nil)
+}
+
+// appendRedisProto{{.Name}} 把 MarshalRedisProto{{.Name}} 的结果追加到 buf
+func (p *{{$.MessageName}}) appendRedisProto{{.Name}}(buf []byte) ([]byte, error) {
+{{- else}}
+	var buf []byte
+{{- end}}
+{{template "fieldEncode" .}}
+	return buf, nil
+}
+
+// UnmarshalRedisProto{{.Name}} 从 {{.Name}} 字段的 protobuf wire format 字节反序列化
+// （字节须为 MarshalRedisProto{{.Name}} 的输出，或等价的单字段 protobuf 编码）
+func (p *{{$.MessageName}}) UnmarshalRedisProto{{.Name}}(b []byte) error {
+	p.{{.Name}} = nil
+	for len(b) > 0 {
+		tag, n, err := redisProtoReadVarint(b)
+		if err != nil {
+			return err
+		}
+		if tag>>3 != {{.ProtoTag}} {
+			return fmt.Errorf("protobuf 字段 %s tag 不匹配: %d", "{{.Name}}", tag>>3)
+		}
+		b = b[n:]
+		{
+			wire := tag & 7
+{{template "fieldDecode" .}}
+		}
+	}
+	return nil
+}
+{{end}}
+{{end}}
+{{- end}}
+{{- if .JSONCodec}}
+
+// MarshalRedisJSON 将 {{.MessageName}} 序列化为 proto3 JSON：字段名为 json_name（lowerCamelCase），零值标量与空集合省略，
+// message 字段恒输出，int64/uint64 为字符串，bytes 为 base64，枚举为名字，map 按键排序输出
+func (p *{{.MessageName}}) MarshalRedisJSON() ([]byte, error) {
+	return p.appendRedisJSON(nil), nil
+}
+
+// appendRedisJSON 把 {{.MessageName}} 的 JSON 对象追加到 buf
+func (p *{{.MessageName}}) appendRedisJSON(buf []byte) []byte {
+	buf = append(buf, '{')
+{{- range .Fields}}{{template "jsonEncode" .}}{{end}}
+	return append(buf, '}')
+}
+
+// UnmarshalRedisJSON 从 proto3 JSON 反序列化到 {{.MessageName}}：成员名接受 json_name 与 proto 字段名，
+// null 视为未设置，未知成员忽略；反序列化前会先重置自身
+func (p *{{.MessageName}}) UnmarshalRedisJSON(b []byte) error {
+	*p = {{.MessageName}}{}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return fmt.Errorf("JSON 解析 %s 失败: %v", "{{.MessageName}}", err)
+	}
+	{{- if .Fields}}
+	for name, v := range obj {
+		if redisJSONIsNull(v) {
+			continue
+		}
+		switch name {
+		{{- range .Fields}}
+		case {{.JSONCases}}:
+			{{- template "jsonDecode" .}}
+		{{- end}}
+		}
+	}
+	{{- end}}
+	return nil
+}
+{{- range .Fields}}{{if or (eq .Kind "map") (eq .Kind "slice")}}
+
+// MarshalRedisJSON{{.Name}} 将字段 {{.Name}}（集合字段）序列化为 JSON {{if eq .Kind "map"}}对象{{else}}数组{{end}}，即 encoding=VALUE_ENCODING_JSON 时它在 Redis Hash 中的值
+func (p *{{$.MessageName}}) MarshalRedisJSON{{.Name}}() ([]byte, error) {
+	return p.appendRedisJSON{{.Name}}(nil), nil
+}
+
+// appendRedisJSON{{.Name}} 把字段 {{.Name}} 的 JSON {{if eq .Kind "map"}}对象（按键排序）{{else}}数组{{end}}追加到 buf
+func (p *{{$.MessageName}}) appendRedisJSON{{.Name}}(buf []byte) []byte {
+	{{- if eq .Kind "map"}}
+	keys := make([]{{.KeyType}}, 0, len(p.{{.Name}}))
+	for k := range p.{{.Name}} {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return {{if eq .KeyType "bool"}}!keys[i] && keys[j]{{else}}keys[i] < keys[j]{{end}} })
+	buf = append(buf, '{')
+	for i, k := range keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = redisJSONAppendString(buf, {{.JSONKeyString "k"}})
+		buf = append(buf, ':')
+		v := p.{{.Name}}[k]
+		buf = {{.JSONAppendElem "v"}}
+	}
+	return append(buf, '}')
+	{{- else}}
+	buf = append(buf, '[')
+	for i, v := range p.{{.Name}} {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = {{.JSONAppendElem "v"}}
+	}
+	return append(buf, ']')
+	{{- end}}
+}
+
+// UnmarshalRedisJSON{{.Name}} 从 JSON {{if eq .Kind "map"}}对象{{else}}数组{{end}}反序列化字段 {{.Name}}（无元素时为 nil，与 protobuf 编码的约定一致）
+func (p *{{$.MessageName}}) UnmarshalRedisJSON{{.Name}}(b []byte) error {
+	p.{{.Name}} = nil
+	{{- if eq .Kind "map"}}
+	var items map[string]json.RawMessage
+	{{- else}}
+	var items []json.RawMessage
+	{{- end}}
+	if err := json.Unmarshal(b, &items); err != nil {
+		return fmt.Errorf("JSON 解析字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	{{- if eq .Kind "map"}}
+	for name, item := range items {
+		{{- if eq .KeyType "string"}}
+		k := name
+		{{- else}}
+		kv, err := {{.JSONKeyParse "name"}}
+		if err != nil {
+			return fmt.Errorf("JSON 解析字段 %s 的键失败: %v", "{{.Name}}", err)
+		}
+		k := {{.KeyType}}(kv)
+		{{- end}}
+	{{- else}}
+	for _, item := range items {
+	{{- end}}
+		{{- if .ElemIsMsg}}
+		var v {{.ElemType}}
+		if err := v.UnmarshalRedisJSON(item); err != nil {
+			return fmt.Errorf("JSON 解析字段 %s 失败: %v", "{{.Name}}", err)
+		}
+		{{- else}}
+		x, err := {{.JSONDecodeElem "item"}}
+		if err != nil {
+			return fmt.Errorf("JSON 解析字段 %s 失败: %v", "{{.Name}}", err)
+		}
+		v := {{.ElemType}}(x)
+		{{- end}}
+		{{- if eq .Kind "map"}}
+		if p.{{.Name}} == nil {
+			p.{{.Name}} = make({{.GoType}}, len(items))
+		}
+		p.{{.Name}}[k] = v
+		{{- else}}
+		p.{{.Name}} = append(p.{{.Name}}, v)
+		{{- end}}
+	}
+	return nil
+}
+{{- end}}{{end}}
+{{- end}}
+
+// GetFields 从 {{if .Blob}}blob 记录（整条 message 存于一个 string key）{{else}}Redis Hash {{end}}中读取指定字段的值，填充到当前结构体实例中
+{{if eq .Executor "goredis"}}// client: go-redis 客户端{{else}}// conn: Redis 连接{{end}}
+// REDBKey: 业务维度 Key
+// ida, idb: 用于组成唯一 Hash Key 的两个 uint64 分片维度
+// fields: 要读取的字段编号列表，如 {{.FieldType}}_Name, {{.FieldType}}_Age
+//          如果 fields 为空（长度为 0），则默认读取所有字段（即 {{.FieldType}}IDs）
+//          集合字段（map/repeated）整体 protobuf 反序列化
+{{if eq .Executor "goredis" -}}
+func (p *{{.MessageName}}) GetFields(client redis.UniversalClient, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.GetFieldsExec(context.Background(), NewGoRedisExecutor(client), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 {{if .Blob}}GET{{else}}HMGET{{end}}
+func (p *{{.MessageName}}) GetFieldsCtx(ctx context.Context, client redis.UniversalClient, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.GetFieldsExec(ctx, NewGoRedisExecutor(client), REDBKey, ida, idb, fields...)
+}
+{{- else -}}
+func (p *{{.MessageName}}) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.GetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 {{if .Blob}}GET{{else}}HMGET{{end}}（经 redis.DoContext）
+func (p *{{.MessageName}}) GetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.GetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+{{- end}}
+
+// GetFieldsExec 与 GetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *{{.MessageName}}) GetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+{{- if .PooledArgs}}
+	// perf：key 与命令参数写入池中的缓冲，命令返回后归还
+	a := redisGetArgs()
+	defer redisPutArgs(a)
+	a.Buf = redisAppendKey{{.MessageName}}(a.Buf, REDBKey, ida, idb)
+	key := a.Buf
+{{- else}}
+	key := redisKey{{.MessageName}}(REDBKey, ida, idb)
+{{- end}}
+{{- if .Blob}}
+	// blob 存储：一次 GET 取回整条记录，再按 fields 拷贝；key 不存在时 p 保持不变
+	reply, err := exec.Do(ctx, "GET", key)
+	if err != nil {
+		return fmt.Errorf("GET 失败: %w", err)
+	}
+	return p.redisBlobRead(reply, fields)
+}
+
+// redisHashGetFields 按 Hash 表方式（HMGET）读取 key 中的字段，供 Migrate{{.MessageName}}ToBlob 读取迁移前的 hash
+func (p *{{.MessageName}}) redisHashGetFields(ctx context.Context, exec RedisExecutor, key string, fields ...{{.FieldType}}) error {
+{{- else}}
+{{end}}
+	// 决定要操作的字段列表
+	fieldsToUse := fields
+	if len(fieldsToUse) == 0 {
+		fieldsToUse = {{.FieldType}}IDs
+	}
+
+{{if .HasNative}}
+	// 原生存储字段存于独立 key，从 HMGET 中拆出，各自的读命令与 HMGET 经 pipeline 一次往返取回
+	hashFields := make([]{{.FieldType}}, 0, len(fieldsToUse))
+	var nativeFields []{{.FieldType}}
+	var cmds []RedisCmd
+	for _, fieldID := range fieldsToUse {
+		switch fieldID {
+		{{- range .Fields}}{{if .Native}}
+		case {{$.FieldType}}_{{.Name}}:
+			cmds = append(cmds, RedisCmd{Name: "{{.NativeReadCmd}}", Args: []interface{}{redisNativeKey{{$.MessageName}}_{{.Name}}(REDBKey, ida, idb){{if eq .Native "list"}}, 0, -1{{end}} } })
+		{{- end}}{{end}}
+		default:
+			hashFields = append(hashFields, fieldID)
+			continue
+		}
+		nativeFields = append(nativeFields, fieldID)
+	}
+	if len(hashFields) > 0 {
+		args := {{if $.PooledArgs}}append(a.Args, key){{else}}[]interface{}{key}{{end}}
+		for _, fieldID := range hashFields {
+			args = append(args, {{$.HashFieldOf "fieldID"}})
+		}
+		{{- if .TagFallback}}
+		for _, fieldID := range hashFields {
+			args = append(args, uint32(fieldID))
+		}
+		{{- end}}
+		{{- if .PooledArgs}}
+		a.Args = args
+		{{- end}}
+		cmds = append(cmds, RedisCmd{Name: "HMGET", Args: args})
+	}
+	replies, err := exec.Pipeline(ctx, cmds)
+	if err != nil {
+		return fmt.Errorf("读取字段失败: %w", err)
+	}
+	if len(replies) != len(cmds) {
+		return fmt.Errorf("读取字段失败: 回复数 %d 与命令数 %d 不一致", len(replies), len(cmds))
+	}
+	for i, fieldID := range nativeFields {
+		switch fieldID {
+		{{- range .Fields}}{{if .Native}}
+		case {{$.FieldType}}_{{.Name}}:
+			err = p.redisNativeRead{{.Name}}(replies[i])
+		{{- end}}{{end}}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(hashFields) == 0 {
+		return nil
+	}
+	fieldsToUse = hashFields
+	reply := replies[len(replies)-1]
+{{else}}
+	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
+	args := {{if .PooledArgs}}append(a.Args, key){{else}}[]interface{}{key}{{end}}
+	for _, fieldID := range fieldsToUse {
+		args = append(args, {{.HashFieldOf "fieldID"}})
+	}
+	{{- if .TagFallback}}
+	// 迁移窗口：同时读取字段编号 field，名字 field 不存在时回退到它
+	for _, fieldID := range fieldsToUse {
+		args = append(args, uint32(fieldID))
+	}
+	{{- end}}
+	{{- if .PooledArgs}}
+	a.Args = args
+	{{- end}}
+
+	// 一次 HMGET 获取所有字段值
+	reply, err := exec.Do(ctx, "HMGET", args...)
+	if err != nil {
+		return fmt.Errorf("HMGET 失败: %w", err)
+	}
+{{end}}
+
+	// 解析返回的 []interface{} 列表
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != {{if .TagFallback}}2*{{end}}len(fieldsToUse) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
+	}
+	{{- if .TagFallback}}
+	for i := range fieldsToUse {
+		if values[i] == nil {
+			values[i] = values[len(fieldsToUse)+i]
+		}
+	}
+	{{- end}}
+
+	// 逐一处理每个字段
+	fieldIndex := 0
+	for _, fieldID := range fieldsToUse {
+		switch fieldID {
+		{{range .Fields}}{{if not .Native}}
+		case {{$.FieldType}}_{{.Name}}:
+			{{if eq .Kind "plain"}}
+			{{if .IsMsg}}
+			// --- Protobuf 反序列化字段: {{.Name}} ---
+			if val, ok := values[fieldIndex].([]byte); ok && val != nil {
+				{{- if .Sensitive}}
+				val, err := redisDecrypt(ctx, val, {{if $.PooledArgs}}string(key){{else}}key{{end}}, "{{.SensitiveAAD}}")
+				if err != nil {
+					return fmt.Errorf("解密字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				{{- end}}
+				{{- if $.Compressed}}
+				val, err {{if .Sensitive}}={{else}}:={{end}} redisDecompress(val)
+				if err != nil {
+					return fmt.Errorf("解压字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				{{- end}}
+				{{- if $.JSONCodec}}
+				if redisJSONValue(val) {
+					if err := p.{{.Name}}.UnmarshalRedisJSON(val); err != nil {
+						return fmt.Errorf("JSON 解析字段 %s 失败: %v", "{{.Name}}", err)
+					}
+				} else if err := p.{{.Name}}.UnmarshalRedisProto(val); err != nil {
+				{{- else}}
+				if err := p.{{.Name}}.UnmarshalRedisProto(val); err != nil {
+				{{- end}}
+					return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
+				}
+			}
+			{{else}}
+			// --- 直读字段: {{.Name}} ---
+			if val, ok := values[fieldIndex].([]byte); ok && val != nil {
+				{{- if .Sensitive}}
+				val, err := redisDecrypt(ctx, val, {{if $.PooledArgs}}string(key){{else}}key{{end}}, "{{.SensitiveAAD}}")
+				if err != nil {
+					return fmt.Errorf("解密字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				{{- end}}
+				{{if .EnumStorage}}
+				// enum_storage：名字与整数都接受
+				n, err := redisParseEnum(val, {{.GoType}}_value)
+				if err != nil {
+					return fmt.Errorf("解析枚举字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				p.{{.Name}} = {{.GoType}}(n)
+				{{else if .IsEnum}}
+				intValue, err := strconv.ParseInt({{$.BytesString "val"}}, 10, 64)
+				if err != nil {
+					return fmt.Errorf("解析枚举字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				p.{{.Name}} = {{.GoType}}(int32(intValue))
+				{{else if eq .GoType "string"}}
+				p.{{.Name}} = string(val)
+				{{else if eq .GoType "uint64"}}
+				id, err := strconv.ParseUint({{$.BytesString "val"}}, 10, 64)
+				if err != nil {
+					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				p.{{.Name}} = id
+				{{else if eq .GoType "int64"}}
+				id, err := strconv.ParseInt({{$.BytesString "val"}}, 10, 64)
+				if err != nil {
+					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				p.{{.Name}} = id
+				{{else if eq .GoType "uint32"}}
+				id, err := strconv.ParseUint({{$.BytesString "val"}}, 10, 32)
+				if err != nil {
+					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				p.{{.Name}} = uint32(id)
+				{{else if eq .GoType "int32"}}
+				id, err := strconv.ParseInt({{$.BytesString "val"}}, 10, 32)
+				if err != nil {
+					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				p.{{.Name}} = int32(id)
+				{{else if eq .GoType "float64"}}
+				f, err := strconv.ParseFloat({{$.BytesString "val"}}, 64)
+				if err != nil {
+					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				p.{{.Name}} = f
+				{{else if eq .GoType "float32"}}
+				f, err := strconv.ParseFloat({{$.BytesString "val"}}, 32)
+				if err != nil {
+					return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				p.{{.Name}} = float32(f)
+				{{else if eq .GoType "bool"}}
+				if len(val) > 0 && val[0] == '1' {
+					p.{{.Name}} = true
+				} else if len(val) > 0 && val[0] == '0' {
+					p.{{.Name}} = false
+				}
+				{{else if eq .GoType "[]byte"}}
+				p.{{.Name}} = val
+				{{else}}
+				// 兜底：尝试字符串
+				p.{{.Name}} = string(val)
+				{{end}}
+			}
+			{{end}}
+			{{else}}
+			// --- 集合字段: {{.Name}}（整体 protobuf 反序列化）---
+			if val, ok := values[fieldIndex].([]byte); ok && val != nil {
+				{{- if .Sensitive}}
+				val, err := redisDecrypt(ctx, val, {{if $.PooledArgs}}string(key){{else}}key{{end}}, "{{.SensitiveAAD}}")
+				if err != nil {
+					return fmt.Errorf("解密字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				{{- end}}
+				{{- if $.Compressed}}
+				val, err {{if .Sensitive}}={{else}}:={{end}} redisDecompress(val)
+				if err != nil {
+					return fmt.Errorf("解压字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				{{- end}}
+				{{- if $.JSONCodec}}
+				if redisJSONValue(val) {
+					if err := p.UnmarshalRedisJSON{{.Name}}(val); err != nil {
+						return err
+					}
+				} else if err := p.UnmarshalRedisProto{{.Name}}(val); err != nil {
+				{{- else}}
+				if err := p.UnmarshalRedisProto{{.Name}}(val); err != nil {
+				{{- end}}
+					return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
+				}
+			}
+			{{end}}
+		{{end}}{{end}}
+		default:
+			return fmt.Errorf("未知字段编号: %d", fieldID)
+		}
+		fieldIndex++
+	}
+
+	return nil
+}
+
+// SetFields 将当前结构体实例的字段值，存储到 {{if .Blob}}blob 记录（整条 message 存于一个 string key）{{else}}Redis Hash {{end}}中
+{{if eq .Executor "goredis"}}// client: go-redis 客户端{{else}}// conn: Redis 连接{{end}}
+// REDBKey: 业务维度 Key
+// ida, idb: 用于组成唯一 Hash Key 的两个 uint64 分片维度
+// fields: 要存储的字段编号列表，如 {{.FieldType}}_Name, {{.FieldType}}_Age
+//          如果 fields 为空（长度为 0），则默认存储所有字段（即 {{.FieldType}}IDs）
+//          集合字段（map/repeated）整体 protobuf 序列化后写入
+{{if eq .Executor "goredis" -}}
+func (p *{{.MessageName}}) SetFields(client redis.UniversalClient, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.SetFieldsExec(context.Background(), NewGoRedisExecutor(client), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 {{if .Blob}}GET/SET{{else}}HSET{{end}}
+func (p *{{.MessageName}}) SetFieldsCtx(ctx context.Context, client redis.UniversalClient, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.SetFieldsExec(ctx, NewGoRedisExecutor(client), REDBKey, ida, idb, fields...)
+}
+{{- else -}}
+func (p *{{.MessageName}}) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.SetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 {{if .Blob}}GET/SET{{else}}HSET{{end}}（经 redis.DoContext）
+func (p *{{.MessageName}}) SetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.SetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+{{- end}}
+
+// SetFieldsExec 与 SetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *{{.MessageName}}) SetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+{{- if .PooledArgs}}
+	// perf：key、命令参数与 message / 集合字段的编码结果写入池中的缓冲，命令返回后归还
+	a := redisGetArgs()
+	defer redisPutArgs(a)
+	a.Buf = redisAppendKey{{.MessageName}}(a.Buf, REDBKey, ida, idb)
+	key := a.Buf[:len(a.Buf):len(a.Buf)]
+{{- else}}
+	key := redisKey{{.MessageName}}(REDBKey, ida, idb)
+{{- end}}
+{{- if .Blob}}
+	// blob 存储：整条记录编码后一次 SET。指定 fields 时以乐观锁读出当前记录、只覆盖这些字段再整体写回
+	// （见 redisBlobModify{{.MessageName}}），与 Hash 表一样不影响其他字段的并发写入
+	if len(fields) > 0 {
+		return redisBlobModify{{.MessageName}}(ctx, exec, {{if .PooledArgs}}string(key){{else}}key{{end}}, true, func(v *{{.MessageName}}) error {
+			return v.redisBlobCopy(p, fields)
+		})
+	}
+	b, err := p.MarshalRedisProto()
+	if err != nil {
+		return fmt.Errorf("protobuf 序列化 %s 失败: %v", "{{.MessageName}}", err)
+	}
+	_, err = exec.Do(ctx, "SET", key, b)
+	return err
+}
+{{- else}}
+	args := {{if .PooledArgs}}append(a.Args, key){{else}}[]interface{}{key}{{end}}
+	{{- if or .HasNative .HasIndex .HasUnique}}
+	var txCmds []RedisCmd // 与 HSET 同一事务执行的命令：{{if .HasNative}}原生存储字段的整体覆盖{{end}}{{if and .HasNative .HasIndex}}、{{end}}{{if .HasIndex}}sorted set 索引的 ZADD{{end}}{{if and (or .HasNative .HasIndex) .HasUnique}}、{{end}}{{if .HasUnique}}唯一索引旧值的释放{{end}}
+	{{- end}}
+	{{- if .HasUnique}}
+	var claims []redisUniqueClaim // 唯一索引字段：写入前先占用新值
+	{{- end}}
+
+	// 决定要操作的字段列表
+	fieldsToUse := fields
+	if len(fieldsToUse) == 0 {
+		fieldsToUse = {{.FieldType}}IDs
+	}
+	{{- if .TagFallback}}
+	// 迁移窗口：先把要写的字段从旧的字段编号 field 搬到名字下，之后只按名字读写
+	if err := redisMoveTagFields{{.MessageName}}(ctx, exec, {{if .PooledArgs}}string(key){{else}}key{{end}}, fieldsToUse); err != nil {
+		return err
+	}
+	{{- end}}
+
+	for _, fieldID := range fieldsToUse {
+		switch fieldID {
+		{{range .Fields}}
+		case {{$.FieldType}}_{{.Name}}:
+			{{if .Native}}
+			// --- 原生存储字段: {{.Name}}（独立 {{.Native}} key，整体覆盖）---
+			cmds, err := p.redisNativeWrite{{.Name}}(redisNativeKey{{$.MessageName}}_{{.Name}}(REDBKey, ida, idb))
+			if err != nil {
+				return fmt.Errorf("编码字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			txCmds = append(txCmds, cmds...)
+			{{else if eq .Kind "plain"}}
+			{{if .IsMsg}}
+			{{- if .JSON}}
+			// --- JSON 编码字段: {{.Name}} ---
+			{
+				b, err := p.{{.Name}}.MarshalRedisJSON()
+				if err != nil {
+					return fmt.Errorf("JSON 序列化字段 %s 失败: %v", "{{.Name}}", err)
+				}
+			{{- else}}
+			// --- Protobuf 序列化字段: {{.Name}} ---
+			{
+				{{- if $.PooledArgs}}
+				b, err := p.{{.Name}}.MarshalRedisProtoAppend(a.Buf)
+				if err != nil {
+					return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				a.Buf, b = b, b[len(a.Buf):len(b):len(b)]
+				{{- else}}
+				b, err := p.{{.Name}}.MarshalRedisProto()
+				if err != nil {
+					return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				{{- end}}
+			{{- end}}
+				{{- if .Sensitive}}
+				b, err = redisEncrypt(ctx, {{if .CompressMinSize}}redisCompress(b, {{.CompressMinSize}}){{else}}b{{end}}, {{if $.PooledArgs}}string(key){{else}}key{{end}}, "{{.SensitiveAAD}}")
+				if err != nil {
+					return fmt.Errorf("加密字段 %s 失败: %v", "{{.Name}}", err)
+				}
+				args = append(args, {{.HashArg "fieldID"}}, b)
+				{{- else}}
+				args = append(args, {{.HashArg "fieldID"}}, {{if .CompressMinSize}}redisCompress(b, {{.CompressMinSize}}){{else}}b{{end}})
+				{{- end}}
+			}
+			{{else if .Sensitive}}
+			// --- 敏感字段: {{.Name}}（AES-GCM 加密后写入）---
+			b, err := redisEncrypt(ctx, {{if eq .GoType "string"}}[]byte(p.{{.Name}}){{else}}p.{{.Name}}{{end}}, {{if $.PooledArgs}}string(key){{else}}key{{end}}, "{{.SensitiveAAD}}")
+			if err != nil {
+				return fmt.Errorf("加密字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			args = append(args, {{.HashArg "fieldID"}}, b)
+			{{else if eq .EnumStorage "name"}}
+			// --- 直存字段: {{.Name}}（枚举按 proto 中的名字写入）---
+			args = append(args, {{.HashArg "fieldID"}}, redisEnumName(int32(p.{{.Name}}), {{.GoType}}_name))
+			{{else if .IsEnum}}
+			// --- 直存字段: {{.Name}}（枚举按整数写入）---
+			args = append(args, {{.HashArg "fieldID"}}, int32(p.{{.Name}}))
+			{{else}}
+			// --- 直存字段: {{.Name}} ---
+			args = append(args, {{.HashArg "fieldID"}}, p.{{.Name}})
+			{{- if .Unique}}
+			claims = append(claims, redisUniqueClaim{Field: "{{.Name}}", HashField: {{.HashArg "fieldID"}}, Key: redisUniqueKey{{$.MessageName}}_{{.Name}}(REDBKey, ida, idb), Value: redisUniqueValue{{$.MessageName}}_{{.Name}}(p.{{.Name}})})
+			{{- end}}
+			{{- if .Index}}
+			txCmds = append(txCmds, RedisCmd{Name: "ZADD", Args: []interface{}{redisIndexKey{{$.MessageName}}_{{.Name}}(REDBKey, ida, idb), {{if eq .IncrCmd "HINCRBY"}}p.{{.Name}}{{else}}float64(p.{{.Name}}){{end}}, redisRecordMember(ida, idb)} })
+			{{- end}}
+			{{end}}
+			{{else}}
+			{{- if .JSON}}
+			// --- 集合字段: {{.Name}}（整体 JSON 编码）---
+			b, err := p.MarshalRedisJSON{{.Name}}()
+			if err != nil {
+				return fmt.Errorf("JSON 序列化字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			{{- else}}
+			// --- 集合字段: {{.Name}}（整体 protobuf 序列化）---
+			{{- if $.PooledArgs}}
+			b, err := p.appendRedisProto{{.Name}}(a.Buf)
+			if err != nil {
+				return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			a.Buf, b = b, b[len(a.Buf):len(b):len(b)]
+			{{- else}}
+			b, err := p.MarshalRedisProto{{.Name}}()
+			if err != nil {
+				return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			{{- end}}
+			{{- end}}
+			{{- if .Sensitive}}
+			b, err = redisEncrypt(ctx, {{if .CompressMinSize}}redisCompress(b, {{.CompressMinSize}}){{else}}b{{end}}, {{if $.PooledArgs}}string(key){{else}}key{{end}}, "{{.SensitiveAAD}}")
+			if err != nil {
+				return fmt.Errorf("加密字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			{{- end}}
+			args = append(args, {{.HashArg "fieldID"}}, {{if .Sensitive}}b{{else}}{{if .CompressMinSize}}redisCompress(b, {{.CompressMinSize}}){{else}}b{{end}}{{end}})
+			{{end}}
+		{{end}}
+		default:
+			return fmt.Errorf("未知字段编号: %d", fieldID)
+		}
+	}
+	{{- if .PooledArgs}}
+	a.Args = args
+	{{- end}}
+
+	{{- if .HasUnique}}
+	if len(claims) > 0 {
+		// 先占用唯一索引的新值（冲突时返回 *RedisUniqueConflictError，不写入），再在同一事务中写入并释放旧值；
+		// 期间记录被并发修改时重新读取旧值后重试，写入失败时撤销占用（见 redisUniqueSet）
+		return redisUniqueSet(ctx, exec, {{if .PooledArgs}}string(key){{else}}key{{end}}, redisRecordMember(ida, idb), claims, append([]RedisCmd{ {Name: "HSET", Args: args} }, txCmds...))
+	}
+	{{- end}}
+	{{- if or .HasNative .HasIndex}}
+	if len(txCmds) > 0 {
+		// {{if .HasNative}}原生存储字段的 DEL + 重写{{end}}{{if and .HasNative .HasIndex}}、{{end}}{{if .HasIndex}}索引的 ZADD{{end}}与 HSET 放在同一事务中，读者看不到写了一半的数据
+		if len(args) > 1 {
+			txCmds = append([]RedisCmd{ {Name: "HSET", Args: args} }, txCmds...)
+		}
+		_, err := exec.Multi(ctx, txCmds)
+		return err
+	}
+	{{- end}}
+
+	// 所有字段统一一次 HSET 写入
+	if len(args) > 1 {
+		_, err := exec.Do(ctx, "HSET", args...)
+		return err
+	}
+	return nil
+}
+{{- end}}
+{{- if .Blob}}
+
+// redisBlobRead 解析 blob 存储的 GET 回复：key 不存在（nil）时 p 保持不变，否则把记录中的 fields（为空时全部字段）拷贝到 p
+func (p *{{.MessageName}}) redisBlobRead(reply interface{}, fields []{{.FieldType}}) error {
+	if reply == nil {
+		return nil
+	}
+	b, ok := reply.([]byte)
+	if !ok {
+		return fmt.Errorf("解析 GET 结果失败: 意外的回复 %T", reply)
+	}
+	v := New{{.MessageName}}()
+	if err := v.UnmarshalRedisProto(b); err != nil {
+		return fmt.Errorf("protobuf 反序列化 %s 失败: %v", "{{.MessageName}}", err)
+	}
+	return p.redisBlobCopy(v, fields)
+}
+
+// redisBlobModify{{.MessageName}} 以乐观锁读-改-写 blob 记录：GET 当前记录交给 fn 修改，再在事务中 SET 整体写回。
+// 执行器支持 WATCH 时全程 WATCH key（见 redisWatch），记录在读出之后被其他调用修改时事务放弃、重新读取后重试，
+// 其他字段的并发写入不会被覆盖。key 不存在时按空记录处理；create 为 false 时什么也不做
+func redisBlobModify{{.MessageName}}(ctx context.Context, exec RedisExecutor, key string, create bool, fn func(v *{{.MessageName}}) error) error {
+	return redisWatch(ctx, exec, []string{key}, func(exec RedisExecutor) error {
+		reply, err := exec.Do(ctx, "GET", key)
+		if err != nil {
+			return fmt.Errorf("GET 失败: %w", err)
+		}
+		if reply == nil && !create {
+			return nil
+		}
+		v := New{{.MessageName}}()
+		if err := v.redisBlobRead(reply, nil); err != nil {
+			return err
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+		b, err := v.MarshalRedisProto()
+		if err != nil {
+			return fmt.Errorf("protobuf 序列化 %s 失败: %v", "{{.MessageName}}", err)
+		}
+		_, err = exec.Multi(ctx, []RedisCmd{ {Name: "SET", Args: []interface{}{key, b} } })
+		return err
+	})
+}
+
+// redisBlobCopy 把 src 的 fields（为空时全部字段）拷贝到 p，未知字段编号报错
+func (p *{{.MessageName}}) redisBlobCopy(src *{{.MessageName}}, fields []{{.FieldType}}) error {
+	if len(fields) == 0 {
+		*p = *src
+		return nil
+	}
+	for _, fieldID := range fields {
+		switch fieldID {
+		{{- range .Fields}}
+		case {{$.FieldType}}_{{.Name}}:
+			p.{{.Name}} = src.{{.Name}}
+		{{- end}}
+		default:
+			return fmt.Errorf("未知字段编号: %d", fieldID)
+		}
+	}
+	return nil
+}
+
+// Migrate{{.MessageName}}ToBlob 把 ida/idb 对应的旧 Hash 表记录（按字段存储的 hash key）转换为 blob 存储：
+// HMGET 读出全部字段后，在同一事务中 DEL hash 并 SET 编码后的记录。
+// key 已是 blob 或不存在时什么也不做，返回 false。执行器支持 WATCH 时全程 WATCH key（见 redisWatch），
+// 读出之后 hash 被并发写入时事务放弃、重新读取后再迁移，不会抹掉这次写入。
+func Migrate{{.MessageName}}ToBlob(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64) (bool, error) {
+	key := redisKey{{.MessageName}}(REDBKey, ida, idb)
+	var migrated bool
+	err := redisWatch(ctx, exec, []string{key}, func(exec RedisExecutor) error {
+		migrated = false
+		reply, err := exec.Do(ctx, "TYPE", key)
+		if err != nil {
+			return fmt.Errorf("TYPE 失败: %w", err)
+		}
+		var typ string
+		switch r := reply.(type) {
+		case string:
+			typ = r
+		case []byte:
+			typ = string(r)
+		default:
+			return fmt.Errorf("解析 TYPE 结果失败: 意外的回复 %T", reply)
+		}
+		switch typ {
+		case "none", "string":
+			return nil
+		case "hash":
+		default:
+			return fmt.Errorf("key %s 的类型为 %s，无法迁移为 blob", key, typ)
+		}
+		v := New{{.MessageName}}()
+		if err := v.redisHashGetFields(ctx, exec, key); err != nil {
+			return err
+		}
+		b, err := v.MarshalRedisProto()
+		if err != nil {
+			return fmt.Errorf("protobuf 序列化 %s 失败: %v", "{{.MessageName}}", err)
+		}
+		if _, err := exec.Multi(ctx, []RedisCmd{
+			{Name: "DEL", Args: []interface{}{key} },
+			{Name: "SET", Args: []interface{}{key, b} },
+		}); err != nil {
+			return err
+		}
+		migrated = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return migrated, nil
+}
+{{- end}}
+
+{{if not .Blob}}{{range .Fields}}{{if .IncrCmd}}
+// Incr{{.Name}} 对字段 {{.Name}} 执行 {{.IncrCmd}}（服务端原子自增 delta），并把自增后的值写回 p.{{.Name}}
+{{if eq $.Executor "goredis" -}}
+func (p *{{$.MessageName}}) Incr{{.Name}}(client redis.UniversalClient, REDBKey uint32, ida, idb uint64, delta {{if eq .IncrCmd "HINCRBY"}}int64{{else}}float64{{end}}) error {
+	return p.Incr{{.Name}}Exec(context.Background(), NewGoRedisExecutor(client), REDBKey, ida, idb, delta)
+}
+
+// Incr{{.Name}}Ctx 与 Incr{{.Name}} 相同，ctx 的截止时间与取消作用于 {{.IncrCmd}}
+func (p *{{$.MessageName}}) Incr{{.Name}}Ctx(ctx context.Context, client redis.UniversalClient, REDBKey uint32, ida, idb uint64, delta {{if eq .IncrCmd "HINCRBY"}}int64{{else}}float64{{end}}) error {
+	return p.Incr{{.Name}}Exec(ctx, NewGoRedisExecutor(client), REDBKey, ida, idb, delta)
+}
+{{- else -}}
+func (p *{{$.MessageName}}) Incr{{.Name}}(conn redis.Conn, REDBKey uint32, ida, idb uint64, delta {{if eq .IncrCmd "HINCRBY"}}int64{{else}}float64{{end}}) error {
+	return p.Incr{{.Name}}Exec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+
+// Incr{{.Name}}Ctx 与 Incr{{.Name}} 相同，ctx 的截止时间与取消作用于 {{.IncrCmd}}（经 redis.DoContext）
+func (p *{{$.MessageName}}) Incr{{.Name}}Ctx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, delta {{if eq .IncrCmd "HINCRBY"}}int64{{else}}float64{{end}}) error {
+	return p.Incr{{.Name}}Exec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, delta)
+}
+{{- end}}
+
+// Incr{{.Name}}Exec 与 Incr{{.Name}}Ctx 相同，但经任意 RedisExecutor 执行
+{{- if .Index}}
+// 字段 {{.Name}} 设置了 sorted set 索引：{{.IncrCmd}} 与索引的 ZINCRBY 在同一事务中执行
+{{- end}}
+func (p *{{$.MessageName}}) Incr{{.Name}}Exec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta {{if eq .IncrCmd "HINCRBY"}}int64{{else}}float64{{end}}) error {
+	{{- if eq .GoType "int32" "uint32"}}
+	// 字段的值在 {{.GoType}} 范围内，|delta| 超过 MaxUint32 时结果必然越界（也保证下面撤销用的 -delta 不溢出）
+	if delta < -math.MaxUint32 || delta > math.MaxUint32 {
+		return fmt.Errorf("字段 %s 的自增量 %d 超出 {{.GoType}} 范围", "{{.Name}}", delta)
+	}
+	{{- else if eq .GoType "uint64"}}
+	if delta == math.MinInt64 {
+		return fmt.Errorf("字段 %s 的自增量 %d 超出 uint64 范围", "{{.Name}}", delta)
+	}
+	{{- else if and .Index (eq .IncrCmd "HINCRBY")}}
+	// 事务中一条命令失败时要以 -delta 撤销另一条，MinInt64 取反会溢出
+	if delta == math.MinInt64 {
+		return fmt.Errorf("字段 %s 的自增量 %d 超出 {{.GoType}} 范围", "{{.Name}}", delta)
+	}
+	{{- end}}
+	{{- if and $.TagFallback .HashName}}
+	// 迁移窗口：旧值仍在字段编号 field 下时先搬到名字下，否则自增会从 0 开始
+	if err := redisMoveTagFields{{$.MessageName}}(ctx, exec, redisKey{{$.MessageName}}(REDBKey, ida, idb), []{{$.FieldType}}{ {{$.FieldType}}_{{.Name}} }); err != nil {
+		return err
+	}
+	{{- end}}
+	{{- if .Index}}
+	replies, err := exec.Multi(ctx, []RedisCmd{
+		{Name: "{{.IncrCmd}}", Args: []interface{}{redisKey{{$.MessageName}}(REDBKey, ida, idb), {{.HashField}}, delta} },
+		{Name: "ZINCRBY", Args: []interface{}{redisIndexKey{{$.MessageName}}_{{.Name}}(REDBKey, ida, idb), delta, redisRecordMember(ida, idb)} },
+	})
+	if err != nil {
+		var txErr *RedisTxError
+		if errors.As(err, &txErr) {
+			// 事务不回滚：另一条命令已生效，减回 delta，字段与索引保持一致
+			return redisUndoIncrPart{{$.MessageName}}_{{.Name}}(ctx, exec, REDBKey, ida, idb, delta, txErr.Index,
+				fmt.Errorf("{{.IncrCmd}} 字段 %s 失败: %w", "{{.Name}}", err))
+		}
+		return fmt.Errorf("{{.IncrCmd}} 字段 %s 失败: %w", "{{.Name}}", err)
+	}
+	reply := replies[0]
+	{{- else}}
+	reply, err := exec.Do(ctx, "{{.IncrCmd}}", redisKey{{$.MessageName}}(REDBKey, ida, idb), {{.HashField}}, delta)
+	if err != nil {
+		return fmt.Errorf("{{.IncrCmd}} 字段 %s 失败: %w", "{{.Name}}", err)
+	}
+	{{- end}}
+	{{if eq .IncrCmd "HINCRBY" -}}
+	n, ok := reply.(int64)
+	if !ok {
+		return fmt.Errorf("解析 {{.IncrCmd}} 结果失败: 意外的回复 %T", reply)
+	}
+	{{- if .IncrBounds}}
+	if {{.IncrBounds}} {
+		return redisUndoIncr{{$.MessageName}}_{{.Name}}(ctx, exec, REDBKey, ida, idb, delta, fmt.Errorf("字段 %s 自增后的值 %d 超出 {{.GoType}} 范围", "{{.Name}}", n))
+	}
+	{{- end}}
+	p.{{.Name}} = {{.GoType}}(n)
+	{{- else -}}
+	val, ok := reply.([]byte)
+	if !ok {
+		return fmt.Errorf("解析 {{.IncrCmd}} 结果失败: 意外的回复 %T", reply)
+	}
+	f, err := strconv.ParseFloat(string(val), {{if eq .GoType "float32"}}32{{else}}64{{end}})
+	if err != nil {
+		{{- if eq .GoType "float32"}}
+		return redisUndoIncr{{$.MessageName}}_{{.Name}}(ctx, exec, REDBKey, ida, idb, delta, fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err))
+		{{- else}}
+		return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+		{{- end}}
+	}
+	p.{{.Name}} = {{.GoType}}(f)
+	{{- end}}
+	return nil
+}
+{{- if or .IncrBounds (eq .GoType "float32")}}
+
+// redisUndoIncr{{$.MessageName}}_{{.Name}} 在 {{.IncrCmd}} 的结果超出字段类型范围时减回 delta{{if .Index}}（连同索引的 ZINCRBY）{{end}}：
+// 越界的值已写入服务端，不撤销则该记录之后的读取都会失败。自增可交换，期间其他调用的自增不受影响；ctx 已取消时仍执行
+func redisUndoIncr{{$.MessageName}}_{{.Name}}(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta {{if eq .IncrCmd "HINCRBY"}}int64{{else}}float64{{end}}, cause error) error {
+	{{- if .Index}}
+	_, err := exec.Multi(context.WithoutCancel(ctx), []RedisCmd{
+		{Name: "{{.IncrCmd}}", Args: []interface{}{redisKey{{$.MessageName}}(REDBKey, ida, idb), {{.HashField}}, -delta} },
+		{Name: "ZINCRBY", Args: []interface{}{redisIndexKey{{$.MessageName}}_{{.Name}}(REDBKey, ida, idb), -delta, redisRecordMember(ida, idb)} },
+	})
+	{{- else}}
+	_, err := exec.Do(context.WithoutCancel(ctx), "{{.IncrCmd}}", redisKey{{$.MessageName}}(REDBKey, ida, idb), {{.HashField}}, -delta)
+	{{- end}}
+	if err != nil {
+		return fmt.Errorf("%w，撤销自增失败（需人工修正）: %v", cause, err)
+	}
+	return fmt.Errorf("%w，已撤销本次自增", cause)
+}
+{{- end}}
+{{- if .Index}}
+
+// redisUndoIncrPart{{$.MessageName}}_{{.Name}} 在 {{.IncrCmd}} 与 ZINCRBY 的事务中第 failed 条命令失败时（如字段值不是数字、索引 key 类型错误），
+// 减回另一条已生效的命令，避免只有字段或只有索引分数变化；ctx 已取消时仍执行
+func redisUndoIncrPart{{$.MessageName}}_{{.Name}}(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, delta {{if eq .IncrCmd "HINCRBY"}}int64{{else}}float64{{end}}, failed int, cause error) error {
+	var err error
+	switch failed {
+	case 0:
+		_, err = exec.Do(context.WithoutCancel(ctx), "ZINCRBY", redisIndexKey{{$.MessageName}}_{{.Name}}(REDBKey, ida, idb), -delta, redisRecordMember(ida, idb))
+	case 1:
+		_, err = exec.Do(context.WithoutCancel(ctx), "{{.IncrCmd}}", redisKey{{$.MessageName}}(REDBKey, ida, idb), {{.HashField}}, -delta)
+	default:
+		return cause
+	}
+	if err != nil {
+		return fmt.Errorf("%w，撤销自增失败（需人工修正）: %v", cause, err)
+	}
+	return fmt.Errorf("%w，已撤销本次自增", cause)
+}
+{{- end}}
+{{end}}{{end}}{{end}}
+
+{{if and .TopLevel (not .ZSet)}}
+// {{.MessageName}}Store 是绑定连接来源的 {{.MessageName}} 存取入口：每次调用自行借出并归还连接，
+// REDBKey 在创建时固定（WithREDBKey 可切换），方法只需传 ida/idb。
+// 单元测试可用 New{{.MessageName}}StoreExec 注入自定义 RedisExecutor。
+type {{.MessageName}}Store struct {
+	acquire redisAcquireFunc
+	REDBKey uint32
+}
+
+{{if eq .Executor "goredis" -}}
+// New{{.MessageName}}Store 基于 go-redis 客户端（自带连接池）创建 Store
+func New{{.MessageName}}Store(client redis.UniversalClient, REDBKey uint32) *{{.MessageName}}Store {
+	return &{{.MessageName}}Store{acquire: redisExecAcquire(NewGoRedisExecutor(client)), REDBKey: REDBKey}
+}
+{{- else -}}
+// New{{.MessageName}}Store 基于连接来源（如 *redis.Pool）创建 Store：每次调用 Get 一个连接，用完 Close 归还
+func New{{.MessageName}}Store(pool RedisConnSource, REDBKey uint32) *{{.MessageName}}Store {
+	return &{{.MessageName}}Store{acquire: redisPoolAcquire(pool), REDBKey: REDBKey}
+}
+{{- end}}
+
+// New{{.MessageName}}StoreExec 基于任意 RedisExecutor（自定义客户端、mock 等）创建 Store，不涉及连接借还
+func New{{.MessageName}}StoreExec(exec RedisExecutor, REDBKey uint32) *{{.MessageName}}Store {
+	return &{{.MessageName}}Store{acquire: redisExecAcquire(exec), REDBKey: REDBKey}
+}
+
+// {{.MessageName}}Repository 是 {{.MessageName}} 的数据访问接口，方法与 {{.MessageName}}Store 一致。
+// 业务代码依赖该接口，生产环境传 {{.MessageName}}Store，单元测试传 New{{.MessageName}}MemRepository()（mem=true）。
+type {{.MessageName}}Repository interface {
+	Get(ctx context.Context, ida, idb uint64, fields ...{{.FieldType}}) (*{{.MessageName}}, error)
+	Set(ctx context.Context, ida, idb uint64, v *{{.MessageName}}, fields ...{{.FieldType}}) error
+	Delete(ctx context.Context, ida, idb uint64, fields ...{{.FieldType}}) error
+	Update(ctx context.Context, ida, idb uint64, fn func(v *{{.MessageName}}) error, fields ...{{.FieldType}}) (*{{.MessageName}}, error)
+	{{- if .Blob}}
+	Load(ctx context.Context, ida, idb uint64) (*{{.MessageName}}, bool, error)
+	Save(ctx context.Context, ida, idb uint64, v *{{.MessageName}}) error
+	MigrateToBlob(ctx context.Context, ida, idb uint64) (bool, error)
+	{{- else}}
+	{{- range .Fields}}{{if .IncrCmd}}
+	Incr{{.Name}}(ctx context.Context, ida, idb uint64, delta {{if eq .IncrCmd "HINCRBY"}}int64{{else}}float64{{end}}) ({{.GoType}}, error)
+	{{- end}}{{end}}
+	{{- end}}
+	{{- range .Fields}}{{if .Native}}{{template "nativeRepoMethods" .}}{{end}}{{end}}
+	{{- range .Fields}}{{if .Index}}
+	Top{{.Name}}(ctx context.Context, {{.Index.Params}}n int64) ([]{{$.MessageName}}IndexEntry, error)
+	RevRank{{.Name}}(ctx context.Context, ida, idb uint64) (int64, bool, error)
+	{{- end}}{{end}}
+	{{- range .Fields}}{{if .Unique}}
+	FindBy{{.Name}}(ctx context.Context, {{.Unique.Params}}v {{.GoType}}) (uint64, uint64, bool, error)
+	{{- end}}{{end}}
+}
+
+var _ {{.MessageName}}Repository = (*{{.MessageName}}Store)(nil)
+
+{{- if .Mem}}
+
+// New{{.MessageName}}MemRepository 返回基于内存的 {{.MessageName}}Repository（不需要 Redis）。
+// 它就是运行在 NewRedisMemExecutor 上的 {{.MessageName}}Store，读写、编解码与错误路径和真实 Redis 完全相同：
+// 未写入的字段读回零值、未知字段编号报错、数值解析失败报错。
+func New{{.MessageName}}MemRepository() {{.MessageName}}Repository {
+	return New{{.MessageName}}StoreExec(NewRedisMemExecutor(), 0)
+}
+{{- end}}
+
+// WithREDBKey 返回绑定到另一个 REDBKey 的 Store（共享同一连接来源）
+func (s *{{.MessageName}}Store) WithREDBKey(REDBKey uint32) *{{.MessageName}}Store {
+	c := *s
+	c.REDBKey = REDBKey
+	return &c
+}
+
+// Get 读取 ida/idb 对应的 {{.MessageName}}；fields 为空时读取全部字段，不存在的字段为零值
+func (s *{{.MessageName}}Store) Get(ctx context.Context, ida, idb uint64, fields ...{{.FieldType}}) (*{{.MessageName}}, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	v := New{{.MessageName}}()
+	if err := v.GetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Set 写入 v 的指定字段；fields 为空时写入全部字段
+func (s *{{.MessageName}}Store) Set(ctx context.Context, ida, idb uint64, v *{{.MessageName}}, fields ...{{.FieldType}}) error {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return v.SetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...)
+}
+
+{{- if .Blob}}
+// Delete 删除整条记录（DEL）；指定 fields 时以乐观锁读出记录、把这些字段置为零值后整体写回（key 不存在时什么也不做，
+// 见 redisBlobModify{{.MessageName}}）
+func (s *{{.MessageName}}Store) Delete(ctx context.Context, ida, idb uint64, fields ...{{.FieldType}}) error {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	key := redisKey{{.MessageName}}(s.REDBKey, ida, idb)
+	if len(fields) == 0 {
+		_, err = exec.Do(ctx, "DEL", key)
+		return err
+	}
+	return redisBlobModify{{.MessageName}}(ctx, exec, key, false, func(v *{{.MessageName}}) error {
+		return v.redisBlobCopy(New{{.MessageName}}(), fields)
+	})
+}
+{{- else}}
+// Delete 删除指定字段（HDEL{{if .HasNative}}，原生存储字段 DEL 其独立 key{{end}}{{if .HasIndex}}，索引字段同时从 sorted set 索引中移除{{end}}{{if .HasUnique}}，唯一索引字段同时释放其条目{{end}}）；fields 为空时删除整个 key（DEL{{if .HasNative}}，连同原生存储字段的独立 key{{end}}{{if .HasIndex}}，并从全部索引中移除{{end}}{{if .HasUnique}}，并释放全部唯一索引条目{{end}}）
+func (s *{{.MessageName}}Store) Delete(ctx context.Context, ida, idb uint64, fields ...{{.FieldType}}) error {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	key := redisKey{{.MessageName}}(s.REDBKey, ida, idb)
+	{{- if and .TagFallback .HasUnique}}
+	// 迁移窗口：先把要删的字段搬到名字下，唯一索引按名字读取当前值
+	if err := redisMoveTagFields{{.MessageName}}(ctx, exec, key, fields); err != nil {
+		return err
+	}
+	{{- end}}
+	{{- if .HasUnique}}
+	// 唯一索引字段释放当前值占用的条目，与删除放在同一事务中；WATCH 记录 key（见 redisWatch），
+	// 读出当前值之后记录被并发修改时重新读取，不会释放已不属于本记录的条目或漏掉新写入的值
+	return redisWatch(ctx, exec, []string{key}, func(exec RedisExecutor) error {
+	{{- end}}
+	{{- if .HasIndex}}
+	member := redisRecordMember(ida, idb)
+	{{- end}}
+	{{- if .HasUnique}}
+	releaseUnique, err := redisUniqueRelease{{.MessageName}}(ctx, exec, s.REDBKey, ida, idb, fields)
+	if err != nil {
+		return err
+	}
+	{{- end}}
+	if len(fields) == 0 {
+		{{- if or .HasIndex .HasUnique}}
+		cmds := []RedisCmd{ {Name: "DEL", Args: []interface{}{key{{range .Fields}}{{if .Native}}, redisNativeKey{{$.MessageName}}_{{.Name}}(s.REDBKey, ida, idb){{end}}{{end}}} } }
+		{{- range .Fields}}{{if .Index}}
+		cmds = append(cmds, RedisCmd{Name: "ZREM", Args: []interface{}{redisIndexKey{{$.MessageName}}_{{.Name}}(s.REDBKey, ida, idb), member} })
+		{{- end}}{{end}}
+		{{- if .HasUnique}}
+		cmds = append(cmds, releaseUnique...)
+		{{- end}}
+		_, err = exec.Multi(ctx, cmds)
+		{{- else}}
+		_, err = exec.Do(ctx, "DEL", key{{range .Fields}}{{if .Native}}, redisNativeKey{{$.MessageName}}_{{.Name}}(s.REDBKey, ida, idb){{end}}{{end}})
+		{{- end}}
+		return err
+	}
+	{{- if or .HasNative .HasIndex .HasUnique}}
+	// {{if .HasNative}}原生存储字段删除其独立 key，{{end}}{{if .HasIndex}}索引字段 HDEL 的同时 ZREM 其索引成员，{{end}}{{if .HasUnique}}唯一索引字段 HDEL 的同时释放其条目，{{end}}其余字段 HDEL；多条命令时放在同一事务中
+	var cmds []RedisCmd
+	args := []interface{}{key}
+	for _, fieldID := range fields {
+		switch fieldID {
+		{{- range .Fields}}{{if .Native}}
+		case {{$.FieldType}}_{{.Name}}:
+			cmds = append(cmds, RedisCmd{Name: "DEL", Args: []interface{}{redisNativeKey{{$.MessageName}}_{{.Name}}(s.REDBKey, ida, idb)} })
+		{{- else if .Index}}
+		case {{$.FieldType}}_{{.Name}}:
+			args = append(args, {{$.HashFieldOf "fieldID"}}{{if $.TagFallback}}, uint32(fieldID){{end}})
+			cmds = append(cmds, RedisCmd{Name: "ZREM", Args: []interface{}{redisIndexKey{{$.MessageName}}_{{.Name}}(s.REDBKey, ida, idb), member} })
+		{{- end}}{{end}}
+		default:
+			args = append(args, {{$.HashFieldOf "fieldID"}}{{if $.TagFallback}}, uint32(fieldID){{end}})
+		}
+	}
+	if len(args) > 1 {
+		cmds = append(cmds, RedisCmd{Name: "HDEL", Args: args})
+	}
+	{{- if .HasUnique}}
+	cmds = append(cmds, releaseUnique...)
+	{{- end}}
+	if len(cmds) == 1 {
+		_, err = exec.Do(ctx, cmds[0].Name, cmds[0].Args...)
+		return err
+	}
+	_, err = exec.Multi(ctx, cmds)
+	return err
+	{{- if .HasUnique}}
+	})
+	{{- end}}
+	{{- else}}
+	args := []interface{}{key}
+	for _, fieldID := range fields {
+		{{- if .TagFallback}}
+		// 迁移窗口：名字 field 与旧的字段编号 field 一并删除
+		args = append(args, {{.HashFieldOf "fieldID"}}, uint32(fieldID))
+		{{- else}}
+		args = append(args, {{.HashFieldOf "fieldID"}})
+		{{- end}}
+	}
+	_, err = exec.Do(ctx, "HDEL", args...)
+	return err
+	{{- end}}
+}
+{{- end}}
+
+// Update 读-改-写：读取 fields（为空时全部字段）交给 fn 修改，再把同一组字段写回，返回写回后的值。
+// 读与写之间不加锁，并发修改同一字段时最后写入者胜出；fn 返回错误时不写回。
+func (s *{{.MessageName}}Store) Update(ctx context.Context, ida, idb uint64, fn func(v *{{.MessageName}}) error, fields ...{{.FieldType}}) (*{{.MessageName}}, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	v := New{{.MessageName}}()
+	if err := v.GetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...); err != nil {
+		return nil, err
+	}
+	if err := fn(v); err != nil {
+		return nil, err
+	}
+	if err := v.SetFieldsExec(ctx, exec, s.REDBKey, ida, idb, fields...); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+{{- if .Blob}}
+
+// Load 读取 ida/idb 对应的整条记录，记录不存在时返回 false
+func (s *{{.MessageName}}Store) Load(ctx context.Context, ida, idb uint64) (*{{.MessageName}}, bool, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer release()
+	reply, err := exec.Do(ctx, "GET", redisKey{{.MessageName}}(s.REDBKey, ida, idb))
+	if err != nil {
+		return nil, false, fmt.Errorf("GET 失败: %w", err)
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	v := New{{.MessageName}}()
+	if err := v.redisBlobRead(reply, nil); err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// Save 整体写入 v（一次 SET，覆盖已有记录）
+func (s *{{.MessageName}}Store) Save(ctx context.Context, ida, idb uint64, v *{{.MessageName}}) error {
+	return s.Set(ctx, ida, idb, v)
+}
+
+// MigrateToBlob 把 ida/idb 对应的旧 Hash 表记录转换为 blob 存储（见 Migrate{{.MessageName}}ToBlob）
+func (s *{{.MessageName}}Store) MigrateToBlob(ctx context.Context, ida, idb uint64) (bool, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+	return Migrate{{.MessageName}}ToBlob(ctx, exec, s.REDBKey, ida, idb)
+}
+{{- end}}
+{{if not .Blob}}{{range .Fields}}{{if .IncrCmd}}
+// Incr{{.Name}} 原子自增字段 {{.Name}}（{{.IncrCmd}}），返回自增后的值
+func (s *{{$.MessageName}}Store) Incr{{.Name}}(ctx context.Context, ida, idb uint64, delta {{if eq .IncrCmd "HINCRBY"}}int64{{else}}float64{{end}}) ({{.GoType}}, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	v := New{{$.MessageName}}()
+	if err := v.Incr{{.Name}}Exec(ctx, exec, s.REDBKey, ida, idb, delta); err != nil {
+		return 0, err
+	}
+	return v.{{.Name}}, nil
+}
+{{end}}{{end}}{{end}}
+{{- if .HasNative}}
+
+// redisDo 借出执行器执行单条命令后归还（原生存储字段的元素级方法使用）
+func (s *{{.MessageName}}Store) redisDo(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return exec.Do(ctx, cmd, args...)
+}
+{{- end}}
+{{range .Fields}}{{if .Native}}{{template "nativeStoreMethods" .}}{{end}}{{end}}
+{{- if .HasIndex}}{{template "indexStoreMethods" .}}{{end}}
+{{- range .Fields}}{{if .Unique}}
+
+// FindBy{{.Name}} 按唯一索引查找 {{.Name}} 等于 v 的记录，返回其 ida、idb（见 Find{{$.MessageName}}By{{.Name}}）
+func (s *{{$.MessageName}}Store) FindBy{{.Name}}(ctx context.Context, {{.Unique.Params}}v {{.GoType}}) (uint64, uint64, bool, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer release()
+	return Find{{$.MessageName}}By{{.Name}}(ctx, exec, s.REDBKey, {{.Unique.ParamNames}}v)
+}
+{{- end}}{{end}}
+{{end}}
+{{- if .ZSet}}{{template "zsetStore" .}}{{end}}
+
+// ---------- 字段级 protobuf 编码/解码模板块（MarshalRedisProto / UnmarshalRedisProto
+// 与集合字段的字段级序列化方法共用） ----------
+//
+// 约定上下文变量：fieldEncode 向 buf 追加字节；fieldDecode 从 b 消费一个字段段，
+// 校验 wire 变量，解码结果写入 p.<Name>。
+
+{{define "fieldEncode"}}
+	// 字段 {{.Name}}（tag {{.ProtoTag}}）
+{{if eq .Kind "plain"}}
+{{if .IsMsg}}
+{
+{{- if .Perf}}
+	buf = redisProtoOpenLen(buf, {{.ProtoTag}})
+	start := len(buf)
+	b, err := p.{{.Name}}.MarshalRedisProtoAppend(buf)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	buf = redisProtoCloseLen(b, start)
+{{- else}}
+	b, err := p.{{.Name}}.MarshalRedisProto()
+	if err != nil {
+		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
+	buf = redisProtoAppendLen(buf, b)
+{{- end}}
+}
+{{else if eq .GoType "string"}}
+if p.{{.Name}} != "" {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
+	buf = {{if .Perf}}redisProtoAppendString(buf, p.{{.Name}}){{else}}redisProtoAppendLen(buf, []byte(p.{{.Name}})){{end}}
+}
+{{else if eq .GoType "[]byte"}}
+if len(p.{{.Name}}) > 0 {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
+	buf = redisProtoAppendLen(buf, p.{{.Name}})
+}
+{{else if eq .GoType "float32"}}
+if p.{{.Name}} != 0 {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 5)
+	buf = redisProtoAppendFixed32(buf, math.Float32bits(p.{{.Name}}))
+}
+{{else if eq .GoType "float64"}}
+if p.{{.Name}} != 0 {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 1)
+	buf = redisProtoAppendFixed64(buf, math.Float64bits(p.{{.Name}}))
+}
+{{else if eq .GoType "bool"}}
+if p.{{.Name}} {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 0)
+	buf = redisProtoAppendVarint(buf, 1)
+}
+{{else}}
+// 枚举与整型（varint）
+if p.{{.Name}} != 0 {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 0)
+	buf = redisProtoAppendVarint(buf, uint64(p.{{.Name}}))
+}
+{{end}}
+{{else if eq .Kind "slice"}}
+{{if .ElemIsMsg}}
+for _, v := range p.{{.Name}} {
+{{- if .Perf}}
+	buf = redisProtoOpenLen(buf, {{.ProtoTag}})
+	start := len(buf)
+	b, err := v.MarshalRedisProtoAppend(buf)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	buf = redisProtoCloseLen(b, start)
+{{- else}}
+	b, err := v.MarshalRedisProto()
+	if err != nil {
+		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
+	buf = redisProtoAppendLen(buf, b)
+{{- end}}
+}
+{{else if eq .ElemType "string"}}
+for _, v := range p.{{.Name}} {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
+	buf = {{if .Perf}}redisProtoAppendString(buf, v){{else}}redisProtoAppendLen(buf, []byte(v)){{end}}
+}
+{{else if eq .ElemType "[]byte"}}
+for _, v := range p.{{.Name}} {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
+	buf = redisProtoAppendLen(buf, v)
+}
+{{else if eq .ElemType "float32"}}
+for _, v := range p.{{.Name}} {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 5)
+	buf = redisProtoAppendFixed32(buf, math.Float32bits(v))
+}
+{{else if eq .ElemType "float64"}}
+for _, v := range p.{{.Name}} {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 1)
+	buf = redisProtoAppendFixed64(buf, math.Float64bits(v))
+}
+{{else}}
+// 枚举与整型元素（varint）
+for _, v := range p.{{.Name}} {
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 0)
+	buf = redisProtoAppendVarint(buf, uint64(v))
+}
+{{end}}
+{{else if eq .Kind "map"}}
+for k, v := range p.{{.Name}} {
+{{- if .Perf}}
+	// 键值对直接编码在 buf 中，长度前缀由 redisProtoCloseLen 补写
+	entry := redisProtoOpenLen(buf, {{.ProtoTag}})
+	start := len(entry)
+{{- else}}
+	var entry []byte
+{{- end}}
+{{if eq .KeyType "string"}}
+	entry = redisProtoAppendTag(entry, 1, 2)
+	entry = {{if .Perf}}redisProtoAppendString(entry, k){{else}}redisProtoAppendLen(entry, []byte(k)){{end}}
+{{else if eq .KeyType "bool"}}
+	entry = redisProtoAppendTag(entry, 1, 0)
+	if k {
+		entry = redisProtoAppendVarint(entry, 1)
+	}
+{{else}}
+	entry = redisProtoAppendTag(entry, 1, 0)
+	entry = redisProtoAppendVarint(entry, uint64(k))
+{{end}}
+{{if .ElemIsMsg}}
+{{- if .Perf}}
+	entry = redisProtoOpenLen(entry, 2)
+	vstart := len(entry)
+	b, err := v.MarshalRedisProtoAppend(entry)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	entry = redisProtoCloseLen(b, vstart)
+{{- else}}
+	b, err := v.MarshalRedisProto()
+	if err != nil {
+		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	entry = redisProtoAppendTag(entry, 2, 2)
+	entry = redisProtoAppendLen(entry, b)
+{{- end}}
+{{else if eq .ElemType "string"}}
+	entry = redisProtoAppendTag(entry, 2, 2)
+	entry = {{if .Perf}}redisProtoAppendString(entry, v){{else}}redisProtoAppendLen(entry, []byte(v)){{end}}
+{{else if eq .ElemType "[]byte"}}
+	entry = redisProtoAppendTag(entry, 2, 2)
+	entry = redisProtoAppendLen(entry, v)
+{{else if eq .ElemType "float32"}}
+	entry = redisProtoAppendTag(entry, 2, 5)
+	entry = redisProtoAppendFixed32(entry, math.Float32bits(v))
+{{else if eq .ElemType "float64"}}
+	entry = redisProtoAppendTag(entry, 2, 1)
+	entry = redisProtoAppendFixed64(entry, math.Float64bits(v))
+{{else}}
+	// 枚举与整型值（varint）
+	entry = redisProtoAppendTag(entry, 2, 0)
+	entry = redisProtoAppendVarint(entry, uint64(v))
+{{end}}
+{{- if .Perf}}
+	buf = redisProtoCloseLen(entry, start)
+{{- else}}
+	buf = redisProtoAppendTag(buf, {{.ProtoTag}}, 2)
+	buf = redisProtoAppendLen(buf, entry)
+{{- end}}
+}
+{{end}}
+{{end}}
+
+{{define "fieldDecode"}}
+{{if eq .Kind "plain"}}
+{{if .IsMsg}}
+if wire != 2 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+v, n, err := {{.ReadLen}}(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+if err := p.{{.Name}}.UnmarshalRedisProto(v); err != nil {
+	return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
+}
+{{else if eq .GoType "string"}}
+if wire != 2 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+v, n, err := {{.ReadLen}}(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+p.{{.Name}} = string(v)
+{{else if eq .GoType "[]byte"}}
+if wire != 2 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+v, n, err := redisProtoReadBytes(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+p.{{.Name}} = v
+{{else if eq .GoType "float32"}}
+if wire != 5 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+v, n, err := redisProtoReadFixed32(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+p.{{.Name}} = math.Float32frombits(v)
+{{else if eq .GoType "float64"}}
+if wire != 1 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+v, n, err := redisProtoReadFixed64(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+p.{{.Name}} = math.Float64frombits(v)
+{{else if eq .GoType "bool"}}
+if wire != 0 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+v, n, err := redisProtoReadVarint(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+p.{{.Name}} = v != 0
+{{else}}
+// 枚举与整型（varint）
+if wire != 0 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+v, n, err := redisProtoReadVarint(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+p.{{.Name}} = {{.GoType}}(v)
+{{end}}
+{{else if eq .Kind "slice"}}
+{{if .ElemIsMsg}}
+if wire != 2 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+v, n, err := {{.ReadLen}}(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+var elem {{.ElemType}}
+if err := elem.UnmarshalRedisProto(v); err != nil {
+	return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
+}
+p.{{.Name}} = append(p.{{.Name}}, elem)
+{{else if eq .ElemType "string"}}
+if wire != 2 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+v, n, err := {{.ReadLen}}(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+p.{{.Name}} = append(p.{{.Name}}, string(v))
+{{else if eq .ElemType "[]byte"}}
+if wire != 2 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+v, n, err := redisProtoReadBytes(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+p.{{.Name}} = append(p.{{.Name}}, v)
+{{else if eq .ElemType "float32"}}
+if wire == 5 {
+	v, n, err := redisProtoReadFixed32(b)
+	if err != nil {
+		return err
+	}
+	b = b[n:]
+	p.{{.Name}} = append(p.{{.Name}}, math.Float32frombits(v))
+} else if wire == 2 {
+	// packed：其他语言的 protoc 实现默认对 repeated 标量打包编码
+	payload, n, err := {{.ReadLen}}(b)
+	if err != nil {
+		return err
+	}
+	b = b[n:]
+	for len(payload) > 0 {
+		v, m, err := redisProtoReadFixed32(payload)
+		if err != nil {
+			return err
+		}
+		payload = payload[m:]
+		p.{{.Name}} = append(p.{{.Name}}, math.Float32frombits(v))
+	}
+} else {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+{{else if eq .ElemType "float64"}}
+if wire == 1 {
+	v, n, err := redisProtoReadFixed64(b)
+	if err != nil {
+		return err
+	}
+	b = b[n:]
+	p.{{.Name}} = append(p.{{.Name}}, math.Float64frombits(v))
+} else if wire == 2 {
+	payload, n, err := {{.ReadLen}}(b)
+	if err != nil {
+		return err
+	}
+	b = b[n:]
+	for len(payload) > 0 {
+		v, m, err := redisProtoReadFixed64(payload)
+		if err != nil {
+			return err
+		}
+		payload = payload[m:]
+		p.{{.Name}} = append(p.{{.Name}}, math.Float64frombits(v))
+	}
+} else {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+{{else if eq .ElemType "bool"}}
+if wire == 0 {
+	v, n, err := redisProtoReadVarint(b)
+	if err != nil {
+		return err
+	}
+	b = b[n:]
+	p.{{.Name}} = append(p.{{.Name}}, v != 0)
+} else if wire == 2 {
+	payload, n, err := {{.ReadLen}}(b)
+	if err != nil {
+		return err
+	}
+	b = b[n:]
+	for len(payload) > 0 {
+		v, m, err := redisProtoReadVarint(payload)
+		if err != nil {
+			return err
+		}
+		payload = payload[m:]
+		p.{{.Name}} = append(p.{{.Name}}, v != 0)
+	}
+} else {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+{{else}}
+// 枚举与整型元素（varint，兼容 packed 编码）
+if wire == 0 {
+	v, n, err := redisProtoReadVarint(b)
+	if err != nil {
+		return err
+	}
+	b = b[n:]
+	p.{{.Name}} = append(p.{{.Name}}, {{.ElemType}}(v))
+} else if wire == 2 {
+	payload, n, err := {{.ReadLen}}(b)
+	if err != nil {
+		return err
+	}
+	b = b[n:]
+	for len(payload) > 0 {
+		v, m, err := redisProtoReadVarint(payload)
+		if err != nil {
+			return err
+		}
+		payload = payload[m:]
+		p.{{.Name}} = append(p.{{.Name}}, {{.ElemType}}(v))
+	}
+} else {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+{{end}}
+{{else if eq .Kind "map"}}
+if wire != 2 {
+	return fmt.Errorf("protobuf 字段 %s wire type 错误: %d", "{{.Name}}", wire)
+}
+entry, n, err := {{.ReadLen}}(b)
+if err != nil {
+	return err
+}
+b = b[n:]
+var k {{.KeyType}}
+var val {{.ElemType}}
+for len(entry) > 0 {
+	t2, m, err := redisProtoReadVarint(entry)
+	if err != nil {
+		return err
+	}
+	entry = entry[m:]
+	switch t2 >> 3 {
+	case 1: // map 键
+{{if eq .KeyType "string"}}
+		if t2&7 != 2 {
+			return fmt.Errorf("protobuf 字段 %s map 键 wire type 错误: %d", "{{.Name}}", t2&7)
+		}
+		payload, m, err := {{.ReadLen}}(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		k = string(payload)
+{{else if eq .KeyType "bool"}}
+		if t2&7 != 0 {
+			return fmt.Errorf("protobuf 字段 %s map 键 wire type 错误: %d", "{{.Name}}", t2&7)
+		}
+		kv, m, err := redisProtoReadVarint(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		k = kv != 0
+{{else}}
+		if t2&7 != 0 {
+			return fmt.Errorf("protobuf 字段 %s map 键 wire type 错误: %d", "{{.Name}}", t2&7)
+		}
+		kv, m, err := redisProtoReadVarint(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		k = {{.KeyType}}(kv)
+{{end}}
+	case 2: // map 值
+{{if .ElemIsMsg}}
+		if t2&7 != 2 {
+			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		}
+		payload, m, err := {{.ReadLen}}(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		if err := val.UnmarshalRedisProto(payload); err != nil {
+			return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
+		}
+{{else if eq .ElemType "string"}}
+		if t2&7 != 2 {
+			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		}
+		payload, m, err := {{.ReadLen}}(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		val = string(payload)
+{{else if eq .ElemType "[]byte"}}
+		if t2&7 != 2 {
+			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		}
+		payload, m, err := redisProtoReadBytes(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		val = payload
+{{else if eq .ElemType "float32"}}
+		if t2&7 != 5 {
+			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		}
+		fv, m, err := redisProtoReadFixed32(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		val = math.Float32frombits(fv)
+{{else if eq .ElemType "float64"}}
+		if t2&7 != 1 {
+			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		}
+		fv, m, err := redisProtoReadFixed64(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		val = math.Float64frombits(fv)
+{{else if eq .ElemType "bool"}}
+		if t2&7 != 0 {
+			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		}
+		bv, m, err := redisProtoReadVarint(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		val = bv != 0
+{{else}}
+		// 枚举与整型值（varint）
+		if t2&7 != 0 {
+			return fmt.Errorf("protobuf 字段 %s map 值 wire type 错误: %d", "{{.Name}}", t2&7)
+		}
+		ev, m, err := redisProtoReadVarint(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		val = {{.ElemType}}(ev)
+{{end}}
+	default:
+		m, err = redisProtoSkip(entry, t2&7)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+	}
+}
+if p.{{.Name}} == nil {
+	p.{{.Name}} = make(map[{{.KeyType}}]{{.ElemType}})
+}
+p.{{.Name}}[k] = val
+{{end}}
+{{end}}
+
+{{/* 原生存储集合（独立 key）的元素编码：上下文为 NativeType，输入 v，返回 ([]byte, error)；
+     与 hash 中标量的存储格式一致，message 元素为 protobuf 字节。 */}}
+{{define "nativeEncode"}}
+{{- if .IsMsg}}
+	return v.MarshalRedisProto()
+{{- else if .IsEnum}}
+	return strconv.AppendInt(nil, int64(v), 10), nil
+{{- else if eq .GoType "string"}}
+	return []byte(v), nil
+{{- else if eq .GoType "[]byte"}}
+	return v, nil
+{{- else if eq .GoType "bool"}}
+	if v {
+		return []byte("1"), nil
+	}
+	return []byte("0"), nil
+{{- else if or (eq .GoType "int32") (eq .GoType "int64")}}
+	return strconv.AppendInt(nil, int64(v), 10), nil
+{{- else if or (eq .GoType "uint32") (eq .GoType "uint64")}}
+	return strconv.AppendUint(nil, uint64(v), 10), nil
+{{- else if eq .GoType "float32"}}
+	return strconv.AppendFloat(nil, float64(v), 'g', -1, 32), nil
+{{- else}}
+	return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+{{- end}}
+{{end}}
+
+{{/* sorted set 表（message 选项 zset）的 Store / Repository：上下文为 MessageInfo */}}
+{{define "zsetStore"}}{{$m := .MessageName}}{{$z := .ZSet}}
+// redisZSetPayloadKey{{$m}} 是 sorted set 表 {{$m}} 的伴随 hash：field 为成员，值为成员其余字段的 protobuf 字节
+func redisZSetPayloadKey{{$m}}(REDBKey uint32, ida, idb uint64) string {
+	return redisKey{{$m}}(REDBKey, ida, idb) + ":payload"
+}
+
+// redisZSetEncodeMember{{$m}} 把成员字段 {{$z.Member}} 编码为 sorted set 的成员
+func redisZSetEncodeMember{{$m}}(v {{$z.MemberType.GoType}}) ([]byte, error) {
+{{- template "nativeEncode" $z.MemberType -}}
+}
+
+// redisZSetDecodeMember{{$m}} 是 redisZSetEncodeMember{{$m}} 的逆过程
+func redisZSetDecodeMember{{$m}}(b []byte) ({{$z.MemberType.GoType}}, error) {
+{{- template "nativeDecode" $z.MemberType -}}
+}
+
+// redisZSetScore{{$m}} 把 sorted set 回复中的分数（bulk string）转为 {{$z.Score}} 的类型，超出范围时报错
+func redisZSetScore{{$m}}(reply interface{}) ({{$z.ScoreType}}, error) {
+	b, ok := reply.([]byte)
+	if !ok {
+		return 0, fmt.Errorf("解析分数失败: 意外的回复 %T", reply)
+	}
+	f, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return 0, fmt.Errorf("解析分数失败: %v", err)
+	}
+	{{- if eq $z.ScoreType "int32"}}
+	if f < math.MinInt32 || f > math.MaxInt32 {
+	{{- else if eq $z.ScoreType "uint32"}}
+	if f < 0 || f > math.MaxUint32 {
+	{{- else if eq $z.ScoreType "int64"}}
+	if f < math.MinInt64 || f >= math.MaxInt64 {
+	{{- else if eq $z.ScoreType "uint64"}}
+	if f < 0 || f >= math.MaxUint64 {
+	{{- end}}
+	{{- if $z.IntScore}}
+		return 0, fmt.Errorf("分数 %s 超出 {{$z.ScoreType}} 范围", b)
+	}
+	{{- end}}
+	return {{$z.ScoreType}}(f), nil
+}
+
+// redisZSetPayload 返回写入伴随 hash 的字节：{{$z.Score}} 与 {{$z.Member}} 已在 sorted set 中，编码前清零
+func (p *{{$m}}) redisZSetPayload() ([]byte, error) {
+	c := *p
+	c.{{$z.Score}} = 0
+	c.{{$z.Member}} = {{if eq $z.MemberType.GoType "string"}}""{{else}}0{{end}}
+	return c.MarshalRedisProto()
+}
+
+// redisZSetDecode{{$m}} 由成员、分数回复与伴随数据（可为 nil）组装出一条记录
+func redisZSetDecode{{$m}}(member []byte, score, payload interface{}) (*{{$m}}, error) {
+	v := New{{$m}}()
+	if b, ok := payload.([]byte); ok && b != nil {
+		if err := v.UnmarshalRedisProto(b); err != nil {
+			return nil, fmt.Errorf("protobuf 反序列化成员 %s 的数据失败: %v", member, err)
+		}
+	}
+	m, err := redisZSetDecodeMember{{$m}}(member)
+	if err != nil {
+		return nil, fmt.Errorf("解析成员 %s 失败: %v", member, err)
+	}
+	v.{{$z.Member}} = m
+	if v.{{$z.Score}}, err = redisZSetScore{{$m}}(score); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// {{$m}}Store 是 sorted set 表 {{$m}} 的存取入口（如排行榜）：每个 ida/idb 对应一个 sorted set，
+// 成员为 {{$z.Member}}、分数为 {{$z.Score}}，其余字段以 protobuf 字节存入伴随 hash（key 后接 ":payload"）。
+// 每次调用自行借出并归还连接，REDBKey 在创建时固定（WithREDBKey 可切换）。
+type {{$m}}Store struct {
+	acquire redisAcquireFunc
+	REDBKey uint32
+}
+
+{{if eq .Executor "goredis" -}}
+// New{{$m}}Store 基于 go-redis 客户端（自带连接池）创建 Store
+func New{{$m}}Store(client redis.UniversalClient, REDBKey uint32) *{{$m}}Store {
+	return &{{$m}}Store{acquire: redisExecAcquire(NewGoRedisExecutor(client)), REDBKey: REDBKey}
+}
+{{- else -}}
+// New{{$m}}Store 基于连接来源（如 *redis.Pool）创建 Store：每次调用 Get 一个连接，用完 Close 归还
+func New{{$m}}Store(pool RedisConnSource, REDBKey uint32) *{{$m}}Store {
+	return &{{$m}}Store{acquire: redisPoolAcquire(pool), REDBKey: REDBKey}
+}
+{{- end}}
+
+// New{{$m}}StoreExec 基于任意 RedisExecutor（自定义客户端、mock 等）创建 Store，不涉及连接借还
+func New{{$m}}StoreExec(exec RedisExecutor, REDBKey uint32) *{{$m}}Store {
+	return &{{$m}}Store{acquire: redisExecAcquire(exec), REDBKey: REDBKey}
+}
+
+// {{$m}}Repository 是 sorted set 表 {{$m}} 的数据访问接口，方法与 {{$m}}Store 一致。
+// 业务代码依赖该接口，生产环境传 {{$m}}Store，单元测试传 New{{$m}}MemRepository()（mem=true）。
+type {{$m}}Repository interface {
+	Add(ctx context.Context, ida, idb uint64, v *{{$m}}) error
+	Get(ctx context.Context, ida, idb uint64, member {{$z.MemberType.GoType}}) (*{{$m}}, bool, error)
+	Incr{{$z.Score}}(ctx context.Context, ida, idb uint64, member {{$z.MemberType.GoType}}, delta {{if $z.IntScore}}int64{{else}}float64{{end}}) ({{$z.ScoreType}}, error)
+	RevRange(ctx context.Context, ida, idb uint64, start, stop int64) ([]*{{$m}}, error)
+	Rank(ctx context.Context, ida, idb uint64, member {{$z.MemberType.GoType}}) (int64, bool, error)
+	RevRank(ctx context.Context, ida, idb uint64, member {{$z.MemberType.GoType}}) (int64, bool, error)
+	Remove(ctx context.Context, ida, idb uint64, members ...{{$z.MemberType.GoType}}) error
+	Len(ctx context.Context, ida, idb uint64) (int64, error)
+	Delete(ctx context.Context, ida, idb uint64) error
+}
+
+var _ {{$m}}Repository = (*{{$m}}Store)(nil)
+
+{{- if .Mem}}
+
+// New{{$m}}MemRepository 返回基于内存的 {{$m}}Repository（不需要 Redis），即运行在 NewRedisMemExecutor 上的 {{$m}}Store
+func New{{$m}}MemRepository() {{$m}}Repository {
+	return New{{$m}}StoreExec(NewRedisMemExecutor(), 0)
+}
+{{- end}}
+
+// WithREDBKey 返回绑定到另一个 REDBKey 的 Store（共享同一连接来源）
+func (s *{{$m}}Store) WithREDBKey(REDBKey uint32) *{{$m}}Store {
+	c := *s
+	c.REDBKey = REDBKey
+	return &c
+}
+
+// Add 写入一条记录：ZADD 分数与 HSET 伴随数据在同一事务中执行，成员已存在时覆盖分数与数据
+func (s *{{$m}}Store) Add(ctx context.Context, ida, idb uint64, v *{{$m}}) error {
+	member, err := redisZSetEncodeMember{{$m}}(v.{{$z.Member}})
+	if err != nil {
+		return fmt.Errorf("编码成员失败: %v", err)
+	}
+	payload, err := v.redisZSetPayload()
+	if err != nil {
+		return fmt.Errorf("protobuf 序列化成员数据失败: %v", err)
+	}
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = exec.Multi(ctx, []RedisCmd{
+		{Name: "ZADD", Args: []interface{}{redisKey{{$m}}(s.REDBKey, ida, idb), {{if $z.IntScore}}v.{{$z.Score}}{{else}}float64(v.{{$z.Score}}){{end}}, member} },
+		{Name: "HSET", Args: []interface{}{redisZSetPayloadKey{{$m}}(s.REDBKey, ida, idb), member, payload} },
+	})
+	return err
+}
+
+// Get 读取成员 member 的记录（ZSCORE 与 HGET 经 pipeline 一次往返），成员不存在时 ok 为 false
+func (s *{{$m}}Store) Get(ctx context.Context, ida, idb uint64, member {{$z.MemberType.GoType}}) (v *{{$m}}, ok bool, err error) {
+	mb, err := redisZSetEncodeMember{{$m}}(member)
+	if err != nil {
+		return nil, false, fmt.Errorf("编码成员失败: %v", err)
+	}
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer release()
+	replies, err := exec.Pipeline(ctx, []RedisCmd{
+		{Name: "ZSCORE", Args: []interface{}{redisKey{{$m}}(s.REDBKey, ida, idb), mb} },
+		{Name: "HGET", Args: []interface{}{redisZSetPayloadKey{{$m}}(s.REDBKey, ida, idb), mb} },
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(replies) != 2 {
+		return nil, false, fmt.Errorf("读取成员失败: 回复数 %d 与命令数 2 不一致", len(replies))
+	}
+	if replies[0] == nil {
+		return nil, false, nil
+	}
+	if v, err = redisZSetDecode{{$m}}(mb, replies[0], replies[1]); err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// Incr{{$z.Score}} 原子增加成员 member 的分数（ZINCRBY），返回增加后的分数；成员不存在时以 0 为初值加入（不写伴随数据）
+func (s *{{$m}}Store) Incr{{$z.Score}}(ctx context.Context, ida, idb uint64, member {{$z.MemberType.GoType}}, delta {{if $z.IntScore}}int64{{else}}float64{{end}}) ({{$z.ScoreType}}, error) {
+	mb, err := redisZSetEncodeMember{{$m}}(member)
+	if err != nil {
+		return 0, fmt.Errorf("编码成员失败: %v", err)
+	}
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	reply, err := exec.Do(ctx, "ZINCRBY", redisKey{{$m}}(s.REDBKey, ida, idb), delta, mb)
+	if err != nil {
+		return 0, fmt.Errorf("ZINCRBY 失败: %w", err)
+	}
+	return redisZSetScore{{$m}}(reply)
+}
+
+// RevRange 按分数从高到低返回排名 start..stop（从 0 开始，含两端，负数从末尾计，与 ZREVRANGE 一致）的记录：
+// ZREVRANGE WITHSCORES 取成员与分数，再 HMGET 伴随数据
+func (s *{{$m}}Store) RevRange(ctx context.Context, ida, idb uint64, start, stop int64) ([]*{{$m}}, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	reply, err := exec.Do(ctx, "ZREVRANGE", redisKey{{$m}}(s.REDBKey, ida, idb), start, stop, "WITHSCORES")
+	if err != nil {
+		return nil, fmt.Errorf("ZREVRANGE 失败: %w", err)
+	}
+	values, err := redisWithScores("ZREVRANGE", reply)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, 0, 1+len(values)/2)
+	args = append(args, redisZSetPayloadKey{{$m}}(s.REDBKey, ida, idb))
+	for i := 0; i < len(values); i += 2 {
+		args = append(args, values[i])
+	}
+	reply, err = exec.Do(ctx, "HMGET", args...)
+	if err != nil {
+		return nil, fmt.Errorf("HMGET 失败: %w", err)
+	}
+	payloads, ok := reply.([]interface{})
+	if !ok || len(payloads) != len(values)/2 {
+		return nil, fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
+	}
+	out := make([]*{{$m}}, 0, len(payloads))
+	for i := range payloads {
+		member, _ := values[2*i].([]byte)
+		v, err := redisZSetDecode{{$m}}(member, values[2*i+1], payloads[i])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// Rank 返回成员 member 按分数从低到高的排名（ZRANK，从 0 开始），成员不存在时 ok 为 false
+func (s *{{$m}}Store) Rank(ctx context.Context, ida, idb uint64, member {{$z.MemberType.GoType}}) (int64, bool, error) {
+	return s.rank(ctx, "ZRANK", ida, idb, member)
+}
+
+// RevRank 返回成员 member 按分数从高到低的排名（ZREVRANK，从 0 开始，即排行榜名次减 1），成员不存在时 ok 为 false
+func (s *{{$m}}Store) RevRank(ctx context.Context, ida, idb uint64, member {{$z.MemberType.GoType}}) (int64, bool, error) {
+	return s.rank(ctx, "ZREVRANK", ida, idb, member)
+}
+
+func (s *{{$m}}Store) rank(ctx context.Context, cmd string, ida, idb uint64, member {{$z.MemberType.GoType}}) (int64, bool, error) {
+	mb, err := redisZSetEncodeMember{{$m}}(member)
+	if err != nil {
+		return 0, false, fmt.Errorf("编码成员失败: %v", err)
+	}
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	defer release()
+	reply, err := exec.Do(ctx, cmd, redisKey{{$m}}(s.REDBKey, ida, idb), mb)
+	if err != nil || reply == nil {
+		return 0, false, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, false, fmt.Errorf("解析 %s 结果失败: 意外的回复 %T", cmd, reply)
+	}
+	return n, true, nil
+}
+
+// Remove 删除成员（ZREM 与伴随数据的 HDEL 在同一事务中），不存在的成员忽略
+func (s *{{$m}}Store) Remove(ctx context.Context, ida, idb uint64, members ...{{$z.MemberType.GoType}}) error {
+	if len(members) == 0 {
+		return nil
+	}
+	zrem := []interface{}{redisKey{{$m}}(s.REDBKey, ida, idb)}
+	hdel := []interface{}{redisZSetPayloadKey{{$m}}(s.REDBKey, ida, idb)}
+	for _, member := range members {
+		mb, err := redisZSetEncodeMember{{$m}}(member)
+		if err != nil {
+			return fmt.Errorf("编码成员失败: %v", err)
+		}
+		zrem = append(zrem, mb)
+		hdel = append(hdel, mb)
+	}
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = exec.Multi(ctx, []RedisCmd{ {Name: "ZREM", Args: zrem}, {Name: "HDEL", Args: hdel} })
+	return err
+}
+
+// Len 返回成员个数（ZCARD）
+func (s *{{$m}}Store) Len(ctx context.Context, ida, idb uint64) (int64, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	reply, err := exec.Do(ctx, "ZCARD", redisKey{{$m}}(s.REDBKey, ida, idb))
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("解析 ZCARD 结果失败: 意外的回复 %T", reply)
+	}
+	return n, nil
+}
+
+// Delete 删除整张表（sorted set 与伴随 hash）
+func (s *{{$m}}Store) Delete(ctx context.Context, ida, idb uint64) error {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = exec.Do(ctx, "DEL", redisKey{{$m}}(s.REDBKey, ida, idb), redisZSetPayloadKey{{$m}}(s.REDBKey, ida, idb))
+	return err
+}
+{{end}}
+
+{{/* sorted set 索引的查询方法：上下文为 MessageInfo */}}
+{{define "indexStoreMethods"}}{{$m := .MessageName}}
+// {{$m}}IndexEntry 是 {{$m}} 的 sorted set 索引中的一项：Ida/Idb 定位记录，Score 为索引字段的值
+type {{$m}}IndexEntry struct {
+	Ida   uint64
+	Idb   uint64
+	Score float64
+}
+
+// redisIndexEntries{{$m}} 解析索引 ZREVRANGE WITHSCORES 的回复，成员 "<ida>:<idb>" 还原为 Ida/Idb
+func redisIndexEntries{{$m}}(reply interface{}) ([]{{$m}}IndexEntry, error) {
+	values, err := redisWithScores("ZREVRANGE", reply)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]{{$m}}IndexEntry, 0, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		member, _ := values[i].([]byte)
+		var e {{$m}}IndexEntry
+		if e.Ida, e.Idb, err = redisParseRecordMember(member); err != nil {
+			return nil, err
+		}
+		score, _ := values[i+1].([]byte)
+		if e.Score, err = strconv.ParseFloat(string(score), 64); err != nil {
+			return nil, fmt.Errorf("解析索引分数失败: %v", err)
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+{{range .Fields}}{{if .Index}}
+// Top{{.Name}} 按 {{.Name}} 从高到低返回索引中的前 n 条记录（ZREVRANGE WITHSCORES）{{if .Index.Desc}}，索引 key 由 {{.Index.Desc}} 确定{{end}}
+func (s *{{$m}}Store) Top{{.Name}}(ctx context.Context, {{.Index.Params}}n int64) ([]{{$m}}IndexEntry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	reply, err := exec.Do(ctx, "ZREVRANGE", redisIndexKey{{$m}}_{{.Name}}(s.REDBKey, {{.Index.Args}}), 0, n-1, "WITHSCORES")
+	if err != nil {
+		return nil, fmt.Errorf("ZREVRANGE 失败: %w", err)
+	}
+	return redisIndexEntries{{$m}}(reply)
+}
+
+// RevRank{{.Name}} 返回 ida/idb 对应记录在 {{.Name}} 索引中从高到低的排名（ZREVRANK，从 0 开始），不在索引中时 ok 为 false
+func (s *{{$m}}Store) RevRank{{.Name}}(ctx context.Context, ida, idb uint64) (int64, bool, error) {
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	defer release()
+	reply, err := exec.Do(ctx, "ZREVRANK", redisIndexKey{{$m}}_{{.Name}}(s.REDBKey, ida, idb), redisRecordMember(ida, idb))
+	if err != nil || reply == nil {
+		return 0, false, err
+	}
+	rank, ok := reply.(int64)
+	if !ok {
+		return 0, false, fmt.Errorf("解析 ZREVRANK 结果失败: 意外的回复 %T", reply)
+	}
+	return rank, true, nil
+}
+{{end}}{{end}}
+{{- end}}
+
+{{/* 原生存储字段的 Store 元素级方法：上下文为 FieldInfo，message 名取自 .NativeOwner */}}
+{{define "nativeStoreMethods"}}
+{{- $msg := .NativeOwner}}{{$key := printf "redisNativeKey%s_%s(s.REDBKey, ida, idb)" .NativeOwner .Name}}
+{{- if eq .Native "hash"}}
+// Put{{.Name}} 设置原生存储字段 {{.Name}} 中键 k 的值（HSET），不读写其他键
+func (s *{{$msg}}Store) Put{{.Name}}(ctx context.Context, ida, idb uint64, k {{.NativeKey.GoType}}, v {{.NativeElem.GoType}}) error {
+	kb, err := redisNativeEncodeKey{{$msg}}_{{.Name}}(k)
+	if err != nil {
+		return fmt.Errorf("编码字段 %s 的键失败: %v", "{{.Name}}", err)
+	}
+	vb, err := redisNativeEncode{{$msg}}_{{.Name}}(v)
+	if err != nil {
+		return fmt.Errorf("编码字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	_, err = s.redisDo(ctx, "HSET", {{$key}}, kb, vb)
+	return err
+}
+
+// Get{{.Name}} 读取原生存储字段 {{.Name}} 中键 k 的值（HGET），键不存在时 ok 为 false
+func (s *{{$msg}}Store) Get{{.Name}}(ctx context.Context, ida, idb uint64, k {{.NativeKey.GoType}}) (v {{.NativeElem.GoType}}, ok bool, err error) {
+	kb, err := redisNativeEncodeKey{{$msg}}_{{.Name}}(k)
+	if err != nil {
+		return v, false, fmt.Errorf("编码字段 %s 的键失败: %v", "{{.Name}}", err)
+	}
+	reply, err := s.redisDo(ctx, "HGET", {{$key}}, kb)
+	if err != nil || reply == nil {
+		return v, false, err
+	}
+	b, isBytes := reply.([]byte)
+	if !isBytes {
+		return v, false, fmt.Errorf("解析 HGET 结果失败: 意外的回复 %T", reply)
+	}
+	if v, err = redisNativeDecode{{$msg}}_{{.Name}}(b); err != nil {
+		return v, false, fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	return v, true, nil
+}
+
+// Remove{{.Name}} 删除原生存储字段 {{.Name}} 中的键（HDEL），不存在的键忽略
+func (s *{{$msg}}Store) Remove{{.Name}}(ctx context.Context, ida, idb uint64, keys ...{{.NativeKey.GoType}}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, 1+len(keys))
+	args = append(args, {{$key}})
+	for _, k := range keys {
+		kb, err := redisNativeEncodeKey{{$msg}}_{{.Name}}(k)
+		if err != nil {
+			return fmt.Errorf("编码字段 %s 的键失败: %v", "{{.Name}}", err)
+		}
+		args = append(args, kb)
+	}
+	_, err := s.redisDo(ctx, "HDEL", args...)
+	return err
+}
+
+// Contains{{.Name}} 报告原生存储字段 {{.Name}} 中是否存在键 k（HEXISTS）
+func (s *{{$msg}}Store) Contains{{.Name}}(ctx context.Context, ida, idb uint64, k {{.NativeKey.GoType}}) (bool, error) {
+	kb, err := redisNativeEncodeKey{{$msg}}_{{.Name}}(k)
+	if err != nil {
+		return false, fmt.Errorf("编码字段 %s 的键失败: %v", "{{.Name}}", err)
+	}
+	reply, err := s.redisDo(ctx, "HEXISTS", {{$key}}, kb)
+	if err != nil {
+		return false, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("解析 HEXISTS 结果失败: 意外的回复 %T", reply)
+	}
+	return n == 1, nil
+}
+{{- else}}
+// Put{{.Name}} 向原生存储字段 {{.Name}} {{if eq .Native "set"}}加入元素（SADD，已存在的忽略）{{else}}末尾追加元素（RPUSH）{{end}}，不读写已有元素
+func (s *{{$msg}}Store) Put{{.Name}}(ctx context.Context, ida, idb uint64, elems ...{{.NativeElem.GoType}}) error {
+	if len(elems) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, 1+len(elems))
+	args = append(args, {{$key}})
+	for _, e := range elems {
+		b, err := redisNativeEncode{{$msg}}_{{.Name}}(e)
+		if err != nil {
+			return fmt.Errorf("编码字段 %s 失败: %v", "{{.Name}}", err)
+		}
+		args = append(args, b)
+	}
+	_, err := s.redisDo(ctx, "{{.NativeWriteCmd}}", args...)
+	return err
+}
+
+// Remove{{.Name}} 从原生存储字段 {{.Name}} 中删除元素（{{if eq .Native "set"}}SREM{{else}}LREM，删除与之相等的全部元素，多个元素在同一事务中{{end}}），不存在的元素忽略
+func (s *{{$msg}}Store) Remove{{.Name}}(ctx context.Context, ida, idb uint64, elems ...{{.NativeElem.GoType}}) error {
+	if len(elems) == 0 {
+		return nil
+	}
+	{{- if eq .Native "set"}}
+	args := make([]interface{}, 0, 1+len(elems))
+	args = append(args, {{$key}})
+	for _, e := range elems {
+		b, err := redisNativeEncode{{$msg}}_{{.Name}}(e)
+		if err != nil {
+			return fmt.Errorf("编码字段 %s 失败: %v", "{{.Name}}", err)
+		}
+		args = append(args, b)
+	}
+	_, err := s.redisDo(ctx, "SREM", args...)
+	return err
+	{{- else}}
+	key := {{$key}}
+	cmds := make([]RedisCmd, 0, len(elems))
+	for _, e := range elems {
+		b, err := redisNativeEncode{{$msg}}_{{.Name}}(e)
+		if err != nil {
+			return fmt.Errorf("编码字段 %s 失败: %v", "{{.Name}}", err)
+		}
+		cmds = append(cmds, RedisCmd{Name: "LREM", Args: []interface{}{key, 0, b} })
+	}
+	exec, release, err := s.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = exec.Multi(ctx, cmds)
+	return err
+	{{- end}}
+}
+
+// Contains{{.Name}} 报告原生存储字段 {{.Name}} 中是否存在元素 e（{{if eq .Native "set"}}SISMEMBER{{else}}LRANGE 后逐个比较编码，O(n){{end}}）
+func (s *{{$msg}}Store) Contains{{.Name}}(ctx context.Context, ida, idb uint64, e {{.NativeElem.GoType}}) (bool, error) {
+	b, err := redisNativeEncode{{$msg}}_{{.Name}}(e)
+	if err != nil {
+		return false, fmt.Errorf("编码字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	{{- if eq .Native "set"}}
+	reply, err := s.redisDo(ctx, "SISMEMBER", {{$key}}, b)
+	if err != nil {
+		return false, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("解析 SISMEMBER 结果失败: 意外的回复 %T", reply)
+	}
+	return n == 1, nil
+	{{- else}}
+	reply, err := s.redisDo(ctx, "LRANGE", {{$key}}, 0, -1)
+	if err != nil {
+		return false, err
+	}
+	values, ok := reply.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("解析 LRANGE 结果失败: 意外的回复 %T", reply)
+	}
+	for _, item := range values {
+		if v, _ := item.([]byte); string(v) == string(b) {
+			return true, nil
+		}
+	}
+	return false, nil
+	{{- end}}
+}
+{{- end}}
+
+// Len{{.Name}} 返回原生存储字段 {{.Name}} 的元素个数（{{if eq .Native "hash"}}HLEN{{else if eq .Native "list"}}LLEN{{else}}SCARD{{end}}）
+func (s *{{$msg}}Store) Len{{.Name}}(ctx context.Context, ida, idb uint64) (int64, error) {
+	reply, err := s.redisDo(ctx, "{{if eq .Native "hash"}}HLEN{{else if eq .Native "list"}}LLEN{{else}}SCARD{{end}}", {{$key}})
+	if err != nil {
+		return 0, err
+	}
+	n, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("解析 {{if eq .Native "hash"}}HLEN{{else if eq .Native "list"}}LLEN{{else}}SCARD{{end}} 结果失败: 意外的回复 %T", reply)
+	}
+	return n, nil
+}
+
+// Range{{.Name}} 一次 {{.NativeReadCmd}} 取回原生存储字段 {{.Name}} 的全部元素，依次交给 fn{{if eq .Native "list"}}（按列表顺序）{{else}}（顺序不定）{{end}}，fn 返回 false 时停止
+func (s *{{$msg}}Store) Range{{.Name}}(ctx context.Context, ida, idb uint64, fn func({{if eq .Native "hash"}}k {{.NativeKey.GoType}}, v {{.NativeElem.GoType}}{{else}}e {{.NativeElem.GoType}}{{end}}) bool) error {
+	reply, err := s.redisDo(ctx, "{{.NativeReadCmd}}", {{$key}}{{if eq .Native "list"}}, 0, -1{{end}})
+	if err != nil {
+		return err
+	}
+	var v {{$msg}}
+	if err := v.redisNativeRead{{.Name}}(reply); err != nil {
+		return err
+	}
+	{{- if eq .Native "hash"}}
+	for k, e := range v.{{.Name}}.{{.NativeItems}} {
+		if !fn(k, e) {
+			break
+		}
+	}
+	{{- else}}
+	for _, e := range v.{{.Name}}.{{.NativeItems}} {
+		if !fn(e) {
+			break
+		}
+	}
+	{{- end}}
+	return nil
+}
+{{end}}
+
+{{/* 原生存储字段在 <Message>Repository 中的元素级方法签名，与 nativeStoreMethods 一致 */}}
+{{define "nativeRepoMethods"}}
+	{{- if eq .Native "hash"}}
+	Put{{.Name}}(ctx context.Context, ida, idb uint64, k {{.NativeKey.GoType}}, v {{.NativeElem.GoType}}) error
+	Get{{.Name}}(ctx context.Context, ida, idb uint64, k {{.NativeKey.GoType}}) (v {{.NativeElem.GoType}}, ok bool, err error)
+	Remove{{.Name}}(ctx context.Context, ida, idb uint64, keys ...{{.NativeKey.GoType}}) error
+	Contains{{.Name}}(ctx context.Context, ida, idb uint64, k {{.NativeKey.GoType}}) (bool, error)
+	Range{{.Name}}(ctx context.Context, ida, idb uint64, fn func(k {{.NativeKey.GoType}}, v {{.NativeElem.GoType}}) bool) error
+	{{- else}}
+	Put{{.Name}}(ctx context.Context, ida, idb uint64, elems ...{{.NativeElem.GoType}}) error
+	Remove{{.Name}}(ctx context.Context, ida, idb uint64, elems ...{{.NativeElem.GoType}}) error
+	Contains{{.Name}}(ctx context.Context, ida, idb uint64, e {{.NativeElem.GoType}}) (bool, error)
+	Range{{.Name}}(ctx context.Context, ida, idb uint64, fn func(e {{.NativeElem.GoType}}) bool) error
+	{{- end}}
+	Len{{.Name}}(ctx context.Context, ida, idb uint64) (int64, error)
+{{- end}}
+
+{{/* nativeEncode 的逆过程：输入 b，返回 (元素, error) */}}
+{{define "nativeDecode"}}
+{{- if .IsMsg}}
+	var v {{.GoType}}
+	if err := v.UnmarshalRedisProto(b); err != nil {
+		return v, err
+	}
+	return v, nil
+{{- else if .IsEnum}}
+	n, err := strconv.ParseInt(string(b), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return {{.GoType}}(n), nil
+{{- else if eq .GoType "string"}}
+	return string(b), nil
+{{- else if eq .GoType "[]byte"}}
+	return b, nil
+{{- else if eq .GoType "bool"}}
+	switch string(b) {
+	case "1":
+		return true, nil
+	case "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("无效的 bool 值 %q", b)
+{{- else if or (eq .GoType "int32") (eq .GoType "int64")}}
+	n, err := strconv.ParseInt(string(b), 10, {{if eq .GoType "int32"}}32{{else}}64{{end}})
+	if err != nil {
+		return 0, err
+	}
+	return {{.GoType}}(n), nil
+{{- else if or (eq .GoType "uint32") (eq .GoType "uint64")}}
+	n, err := strconv.ParseUint(string(b), 10, {{if eq .GoType "uint32"}}32{{else}}64{{end}})
+	if err != nil {
+		return 0, err
+	}
+	return {{.GoType}}(n), nil
+{{- else}}
+	f, err := strconv.ParseFloat(string(b), {{if eq .GoType "float32"}}32{{else}}64{{end}})
+	if err != nil {
+		return 0, err
+	}
+	return {{.GoType}}(f), nil
+{{- end}}
+{{end}}
+{{- /* 字段级 proto3 JSON 编码/解码模板块（appendRedisJSON / UnmarshalRedisJSON 使用）：
+jsonEncode 向 buf 追加 "name":value（零值标量与空集合跳过）；jsonDecode 解析 JSON 值 v，结果写入 p.<Name>。 */ -}}
+{{define "jsonEncode"}}
+	{{- if eq .Kind "plain"}}
+	{{- if .IsMsg}}
+	buf = redisJSONAppendName(buf, {{printf "%q" .JSONName}})
+	buf = p.{{.Name}}.appendRedisJSON(buf)
+	{{- else}}
+	if {{if eq .GoType "string"}}p.{{.Name}} != ""{{else if eq .GoType "[]byte"}}len(p.{{.Name}}) > 0{{else if eq .GoType "bool"}}p.{{.Name}}{{else}}p.{{.Name}} != 0{{end}} {
+		buf = redisJSONAppendName(buf, {{printf "%q" .JSONName}})
+		buf = {{.JSONAppend (printf "p.%s" .Name)}}
+	}
+	{{- end}}
+	{{- else}}
+	if len(p.{{.Name}}) > 0 {
+		buf = redisJSONAppendName(buf, {{printf "%q" .JSONName}})
+		buf = p.appendRedisJSON{{.Name}}(buf)
+	}
+	{{- end}}
+{{- end}}
+
+{{define "jsonDecode"}}
+	{{- if eq .Kind "plain"}}
+	{{- if .IsMsg}}
+	if err := p.{{.Name}}.UnmarshalRedisJSON(v); err != nil {
+		return fmt.Errorf("JSON 解析字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	{{- else}}
+	x, err := {{.JSONDecode "v"}}
+	if err != nil {
+		return fmt.Errorf("JSON 解析字段 %s 失败: %v", "{{.Name}}", err)
+	}
+	p.{{.Name}} = {{.GoType}}(x)
+	{{- end}}
+	{{- else}}
+	if err := p.UnmarshalRedisJSON{{.Name}}(v); err != nil {
+		return err
+	}
+	{{- end}}
+{{- end}}
+`
+
+// codeTemplateAttach 是 mode=attach 下为一个顶层 message 生成的代码：message 与枚举类型由 protoc-gen-go 声明（同一 Go 包），
+// 这里只生成字段编号常量、key 函数与挂在 protoc-gen-go 类型上的 GetFields/SetFields。
+// Redis 中的布局与默认模式的 Hash 表一致（hash field、标量与枚举的编码相同），message 字段经 proto.Marshal / proto.Unmarshal 编解码，
+// 两种模式生成的代码可以读写同一份数据。ValidateAttach 已排除该模式不支持的字段与选项。
+const codeTemplateAttach = `
+// {{.FieldType}} 用于标识 Redis Hash 中的字段编号
+type {{.FieldType}} uint32
+
+{{range .Fields}}
+// {{$.FieldType}}_{{.Name}} 是字段 {{.Name}} 对应的 Redis Hash field 编号
+const {{$.FieldType}}_{{.Name}} {{$.FieldType}} = {{.ProtoTag}}
+{{end}}
+
+// {{.FieldType}}IDs 是所有字段编号常量的集合，类型为 []{{.FieldType}}
+var {{.FieldType}}IDs = []{{.FieldType}}{
+	{{range .Fields}}{{$.FieldType}}_{{.Name}},
+	{{end}}
+}
+
+// redisKey{{.MessageName}} 按 key_format 生成 {{.MessageName}} 对应的 Redis Hash key
+func redisKey{{.MessageName}}(REDBKey uint32, ida, idb uint64) string {
+	return fmt.Sprintf({{printf "%q" .KeyFormat}}, REDBKey, ida, idb)
+}
+{{- if .HasNamed}}
+
+// redisHashField{{.MessageName}} 返回字段在 Redis Hash 中的 field：按名字存储的字段为名字，其余为字段编号
+func redisHashField{{.MessageName}}(id {{.FieldType}}) interface{} {
+	switch id {
+	{{- range .Fields}}{{if .HashName}}
+	case {{$.FieldType}}_{{.Name}}:
+		return {{.HashField}}
+	{{- end}}{{end}}
+	default:
+		return uint32(id)
+	}
+}
+{{- end}}
+
+// GetFields 从 Redis Hash 中读取指定字段的值，填充到 protoc-gen-go 生成的 {{.MessageName}} 中
+{{if eq .Executor "goredis"}}// client: go-redis 客户端{{else}}// conn: Redis 连接{{end}}
+// REDBKey: 业务维度 Key
+// ida, idb: 用于组成唯一 Hash Key 的两个 uint64 分片维度
+// fields: 要读取的字段编号列表，如 {{.FieldType}}_Name, {{.FieldType}}_Age
+//          如果 fields 为空（长度为 0），则默认读取所有字段（即 {{.FieldType}}IDs）
+//          message 字段经 proto.Unmarshal 整体反序列化
+{{if eq .Executor "goredis" -}}
+func (p *{{.MessageName}}) GetFields(client redis.UniversalClient, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.GetFieldsExec(context.Background(), NewGoRedisExecutor(client), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 HMGET
+func (p *{{.MessageName}}) GetFieldsCtx(ctx context.Context, client redis.UniversalClient, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.GetFieldsExec(ctx, NewGoRedisExecutor(client), REDBKey, ida, idb, fields...)
+}
+{{- else -}}
+func (p *{{.MessageName}}) GetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.GetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// GetFieldsCtx 与 GetFields 相同，ctx 的截止时间与取消作用于 HMGET（经 redis.DoContext）
+func (p *{{.MessageName}}) GetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.GetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+{{- end}}
+
+// GetFieldsExec 与 GetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *{{.MessageName}}) GetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	key := redisKey{{.MessageName}}(REDBKey, ida, idb)
+
+	// 决定要操作的字段列表
+	fieldsToUse := fields
+	if len(fieldsToUse) == 0 {
+		fieldsToUse = {{.FieldType}}IDs
+	}
+
+	// 构造 HMGET 参数：key + fieldID1 + fieldID2 + ...，一次取回全部字段值
+	args := []interface{}{key}
+	for _, fieldID := range fieldsToUse {
+		args = append(args, {{.HashFieldOf "fieldID"}})
+	}
+	reply, err := exec.Do(ctx, "HMGET", args...)
+	if err != nil {
+		return fmt.Errorf("HMGET 失败: %w", err)
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(fieldsToUse) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
+	}
+
+	// 逐一处理每个字段，field 不存在（nil）时保持原值
+	for i, fieldID := range fieldsToUse {
+		switch fieldID {
+		{{- range .Fields}}
+		case {{$.FieldType}}_{{.Name}}:
+			val, ok := values[i].([]byte)
+			if !ok || val == nil {
+				continue
+			}
+			{{- if .IsMsg}}
+			v := new({{.GoType}})
+			if err := {{$.ProtoUnmarshal}}(val, v); err != nil {
+				return fmt.Errorf("protobuf 反序列化字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			p.{{.Name}} = v
+			{{- else if .IsEnum}}
+			n, err := strconv.ParseInt(string(val), 10, 32)
+			if err != nil {
+				return fmt.Errorf("解析枚举字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			p.{{.Name}} = {{.GoType}}(n)
+			{{- else if eq .GoType "string"}}
+			p.{{.Name}} = string(val)
+			{{- else if eq .GoType "[]byte"}}
+			p.{{.Name}} = val
+			{{- else if eq .GoType "bool"}}
+			p.{{.Name}} = len(val) > 0 && val[0] == '1'
+			{{- else if or (eq .GoType "uint32") (eq .GoType "uint64")}}
+			n, err := strconv.ParseUint(string(val), 10, {{if eq .GoType "uint32"}}32{{else}}64{{end}})
+			if err != nil {
+				return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			p.{{.Name}} = {{.GoType}}(n)
+			{{- else if or (eq .GoType "int32") (eq .GoType "int64")}}
+			n, err := strconv.ParseInt(string(val), 10, {{if eq .GoType "int32"}}32{{else}}64{{end}})
+			if err != nil {
+				return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			p.{{.Name}} = {{.GoType}}(n)
+			{{- else}}
+			f, err := strconv.ParseFloat(string(val), {{if eq .GoType "float32"}}32{{else}}64{{end}})
+			if err != nil {
+				return fmt.Errorf("解析字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			p.{{.Name}} = {{.GoType}}(f)
+			{{- end}}
+		{{- end}}
+		default:
+			return fmt.Errorf("未知字段编号: %d", fieldID)
+		}
+	}
+	return nil
+}
+
+// SetFields 将 protoc-gen-go 生成的 {{.MessageName}} 的字段值存储到 Redis Hash 中
+{{if eq .Executor "goredis"}}// client: go-redis 客户端{{else}}// conn: Redis 连接{{end}}
+// REDBKey: 业务维度 Key
+// ida, idb: 用于组成唯一 Hash Key 的两个 uint64 分片维度
+// fields: 要存储的字段编号列表，如 {{.FieldType}}_Name, {{.FieldType}}_Age
+//          如果 fields 为空（长度为 0），则默认存储所有字段（即 {{.FieldType}}IDs）
+//          message 字段经 proto.Marshal 整体序列化后写入（nil 写入空值，读回为空 message）
+{{if eq .Executor "goredis" -}}
+func (p *{{.MessageName}}) SetFields(client redis.UniversalClient, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.SetFieldsExec(context.Background(), NewGoRedisExecutor(client), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 HSET
+func (p *{{.MessageName}}) SetFieldsCtx(ctx context.Context, client redis.UniversalClient, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.SetFieldsExec(ctx, NewGoRedisExecutor(client), REDBKey, ida, idb, fields...)
+}
+{{- else -}}
+func (p *{{.MessageName}}) SetFields(conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.SetFieldsExec(context.Background(), NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+
+// SetFieldsCtx 与 SetFields 相同，ctx 的截止时间与取消作用于 HSET（经 redis.DoContext）
+func (p *{{.MessageName}}) SetFieldsCtx(ctx context.Context, conn redis.Conn, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	return p.SetFieldsExec(ctx, NewRedigoExecutor(conn), REDBKey, ida, idb, fields...)
+}
+{{- end}}
+
+// SetFieldsExec 与 SetFieldsCtx 相同，但经任意 RedisExecutor 执行（自定义客户端、mock 等）
+func (p *{{.MessageName}}) SetFieldsExec(ctx context.Context, exec RedisExecutor, REDBKey uint32, ida, idb uint64, fields ...{{.FieldType}}) error {
+	args := []interface{}{redisKey{{.MessageName}}(REDBKey, ida, idb)}
+
+	// 决定要操作的字段列表
+	fieldsToUse := fields
+	if len(fieldsToUse) == 0 {
+		fieldsToUse = {{.FieldType}}IDs
+	}
+	for _, fieldID := range fieldsToUse {
+		switch fieldID {
+		{{- range .Fields}}
+		case {{$.FieldType}}_{{.Name}}:
+			{{- if .IsMsg}}
+			b, err := {{$.ProtoMarshal}}(p.{{.Name}})
+			if err != nil {
+				return fmt.Errorf("protobuf 序列化字段 %s 失败: %v", "{{.Name}}", err)
+			}
+			args = append(args, {{.HashArg "fieldID"}}, b)
+			{{- else if .IsEnum}}
+			args = append(args, {{.HashArg "fieldID"}}, int32(p.{{.Name}}))
+			{{- else}}
+			args = append(args, {{.HashArg "fieldID"}}, p.{{.Name}})
+			{{- end}}
+		{{- end}}
+		default:
+			return fmt.Errorf("未知字段编号: %d", fieldID)
+		}
+	}
+
+	// 所有字段统一一次 HSET 写入
+	if len(args) > 1 {
+		_, err := exec.Do(ctx, "HSET", args...)
+		return err
+	}
+	return nil
+}
 `
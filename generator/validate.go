@@ -2,36 +2,193 @@ package generator
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"google.golang.org/protobuf/compiler/protogen"
 	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
-// ValidateConventions 校验一个 proto 文件中的 message 定义是否符合项目约定：
-//
-//  1. 所有 message（顶层与嵌套，map entry 除外）名称必须以 "DB" 前缀开头；
-//  2. 顶层 message 的字段不能直接定义 repeated / map，集合字段必须用嵌套 message 包起来。
-//
-// 返回的 error 会作为插件错误上报给 protoc，使编译失败并提示违规位置。
-func ValidateConventions(file *protogen.File) error {
-	for _, m := range CollectMessages(file) {
-		name := string(m.Desc.Name())
-		if !strings.HasPrefix(name, "DB") {
-			return fmt.Errorf("message %q 必须以 DB 前缀开头（约定），建议改为 %q", name, "DB"+name)
+// 约定规则（--redis_opt=rule.<规则>=error|warn|off 调整级别）：
+const (
+	// RulePrefix：message（顶层与嵌套，map entry 除外）名称以 prefix 参数开头（默认 "DB"，为空时不检查）
+	RulePrefix = "prefix"
+	// RuleWrapper：顶层 message 的字段不直接定义 repeated / map，集合字段用嵌套 message 包起来
+	RuleWrapper = "wrapper"
+	// RuleMaxFieldNumber：字段编号不超过 max_field_number 参数（设置该参数即开启）
+	RuleMaxFieldNumber = "max_field_number"
+	// RuleReserved：redis_name 不使用 message 中 reserved 的名字；指定 compat 时，相对旧版本删除的字段须 reserved 其编号
+	// （按名字存储的还有 hash field 名），以后的版本才不会复用（同一版本中字段使用 reserved 编号与名字由 protoc 检查）
+	RuleReserved = "reserved"
+	// RuleKeyFormat：顶层 message 都声明了自己的 key_format（message 选项），不依赖插件参数
+	RuleKeyFormat = "key_format"
+)
+
+// 规则级别：error 使生成失败，warn 输出到标准错误后继续生成，off 不检查。
+const (
+	LevelError = "error"
+	LevelWarn  = "warn"
+	LevelOff   = "off"
+)
+
+// DefaultMaxFieldNumber 是开启 max_field_number 规则但未指定上限时的字段编号上限。
+const DefaultMaxFieldNumber = 1000
+
+// ruleDefaults 是各规则未经 rule.<规则> 参数调整时的级别。
+var ruleDefaults = map[string]string{
+	RulePrefix:         LevelError,
+	RuleWrapper:        LevelError,
+	RuleMaxFieldNumber: LevelOff,
+	RuleReserved:       LevelError,
+	RuleKeyFormat:      LevelOff,
+}
+
+// Violation 是一处违反约定规则的定义。
+type Violation struct {
+	Pos     Position // 违规 message / 字段的定义处
+	Rule    string
+	Level   string // LevelError 或 LevelWarn
+	Message string
+}
+
+// String 返回 "文件:行:列: [规则] 说明"。
+func (v Violation) String() string {
+	return v.Pos.String() + ": [" + v.Rule + "] " + v.Message
+}
+
+// ruleLevel 返回规则的生效级别：rule.<规则> 参数优先；max_field_number 未显式设置级别时，指定了上限即为 error。
+func (o *Options) ruleLevel(rule string) string {
+	if level, ok := o.RuleLevels[rule]; ok {
+		return level
+	}
+	if rule == RuleMaxFieldNumber && o.MaxFieldNumber > 0 {
+		return LevelError
+	}
+	return ruleDefaults[rule]
+}
+
+// ValidateConventions 按 opts 中的规则配置校验一个 proto 文件中的 message 定义，返回全部违规（不在第一处停止），
+// 按规则的声明顺序、文件中的声明顺序排列；级别为 off 的规则不检查。规则见 RulePrefix 等常量。
+func ValidateConventions(file *protogen.File, opts *Options) []Violation {
+	var violations []Violation
+	report := func(desc protoreflect.Descriptor, rule, format string, args ...interface{}) {
+		if level := opts.ruleLevel(rule); level != LevelOff {
+			violations = append(violations, Violation{Pos: PositionOf(desc), Rule: rule, Level: level, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	if prefix := opts.Prefix; prefix != "" && opts.ruleLevel(RulePrefix) != LevelOff {
+		for _, m := range CollectMessages(file) {
+			if name := string(m.Desc.Name()); !strings.HasPrefix(name, prefix) {
+				report(m.Desc, RulePrefix, "message %q 必须以 %s 前缀开头（约定），建议改为 %q", name, prefix, prefix+name)
+			}
+		}
+	}
+
+	if opts.ruleLevel(RuleWrapper) != LevelOff {
+		for _, m := range file.Messages {
+			for _, f := range m.Fields {
+				if f.Desc.Cardinality() == protoreflect.Repeated {
+					report(f.Desc, RuleWrapper,
+						"顶层 message %q 的字段 %q 不能直接定义 repeated/map（约定），集合字段必须用嵌套 message 包起来，如 message %s%s { repeated ... items = 1; }",
+						m.Desc.Name(), f.Desc.Name(), opts.Prefix, goCamelCase(string(f.Desc.Name())))
+				}
+			}
+		}
+	}
+
+	if opts.ruleLevel(RuleMaxFieldNumber) != LevelOff {
+		limit := opts.MaxFieldNumber
+		if limit <= 0 {
+			limit = DefaultMaxFieldNumber
+		}
+		for _, m := range CollectMessages(file) {
+			for _, f := range m.Fields {
+				if int(f.Desc.Number()) > limit {
+					report(f.Desc, RuleMaxFieldNumber, "message %q 的字段 %q 的编号 %d 超过上限 %d", m.Desc.Name(), f.Desc.Name(), f.Desc.Number(), limit)
+				}
+			}
 		}
 	}
-	for _, m := range file.Messages {
-		if m.Desc.IsMapEntry() {
+
+	if opts.ruleLevel(RuleReserved) != LevelOff {
+		for _, m := range CollectMessages(file) {
+			for _, f := range m.Fields {
+				// 按名字存储时 reserved 的字段名就是已删除字段的 hash field，复用会读到旧数据
+				if name := fieldOptions(f).GetRedisName(); name != "" && m.Desc.ReservedNames().Has(protoreflect.Name(name)) {
+					report(f.Desc, RuleReserved, "message %q 的字段 %q 的 redis_name %q 是 reserved 的名字（已删除字段的 hash field）",
+						m.Desc.Name(), f.Desc.Name(), name)
+				}
+			}
+		}
+	}
+
+	if opts.ruleLevel(RuleKeyFormat) != LevelOff {
+		for _, m := range file.Messages {
+			if messageOptions(m).GetKeyFormat() == "" {
+				report(m.Desc, RuleKeyFormat, "顶层 message %q 没有声明 key_format（option (redisopt.message) = {key_format: \"...\"}）", m.Desc.Name())
+			}
+		}
+	}
+	return violations
+}
+
+// ValidateReserved 按 reserved 规则比较 file 与其旧版本 prev（compat 参数）：旧版本中有、当前版本已删除的字段，
+// 其编号须在 message 的 reserved 范围内，按名字存储的（redis_name 或 hash_field=HASH_FIELD_NAME）其 hash field 名须在 reserved 名字中
+// （不是合法标识符的 redis_name 无法 reserved，除外）。
+// 否则以后新增的字段可以复用它们，读到已删除字段留在 Redis 中的旧数据——CheckCompat 只与上一个版本比较，发现不了隔版本的复用。
+// 规则级别为 off 时不检查。
+func ValidateReserved(prev, file *protogen.File, opts *Options) []Violation {
+	level := opts.ruleLevel(RuleReserved)
+	if level == LevelOff {
+		return nil
+	}
+	oldMessages := make(map[protoreflect.FullName]*protogen.Message)
+	for _, m := range CollectMessages(prev) {
+		oldMessages[m.Desc.FullName()] = m
+	}
+	var violations []Violation
+	for _, m := range CollectMessages(file) {
+		old := oldMessages[m.Desc.FullName()]
+		if old == nil {
 			continue
 		}
-		for _, f := range m.Fields {
-			if f.Desc.Cardinality() == protoreflect.Repeated {
-				return fmt.Errorf(
-					"顶层 message %q 的字段 %q 不能直接定义 repeated/map（约定），集合字段必须用嵌套 message 包起来，如 message DB%s { repeated ... items = 1; }",
-					m.Desc.Name(), f.Desc.Name(), goCamelCase(string(f.Desc.Name())))
+		for _, of := range old.Fields {
+			if fieldByNumber(m, of.Desc.Number()) != nil {
+				continue
+			}
+			if !m.Desc.ReservedRanges().Has(of.Desc.Number()) {
+				violations = append(violations, Violation{Pos: PositionOf(m.Desc), Rule: RuleReserved, Level: level,
+					Message: fmt.Sprintf("message %q 删除了字段 %q，须 reserved 其编号 %d（以后复用会读到它的旧数据）", m.Desc.Name(), of.Desc.Name(), of.Desc.Number())})
+			}
+			// 不是合法标识符的 redis_name 无法写进 reserved，不要求
+			if name := protoreflect.Name(hashFieldName(prev, old, of)); name.IsValid() && !m.Desc.ReservedNames().Has(name) {
+				violations = append(violations, Violation{Pos: PositionOf(m.Desc), Rule: RuleReserved, Level: level,
+					Message: fmt.Sprintf("message %q 删除了字段 %q，须 reserved 其 hash field 名 %q（以后复用会读到它的旧数据）", m.Desc.Name(), of.Desc.Name(), name)})
 			}
 		}
 	}
+	return violations
+}
+
+// setRuleLevel 解析 rule.<规则>=<级别> 参数，规则或级别未知时返回错误。
+func (o *Options) setRuleLevel(rule, level string) error {
+	if _, ok := ruleDefaults[rule]; !ok {
+		rules := make([]string, 0, len(ruleDefaults))
+		for r := range ruleDefaults {
+			rules = append(rules, r)
+		}
+		sort.Strings(rules)
+		return fmt.Errorf("未知的约定规则 %q（可选: %s）", rule, strings.Join(rules, ", "))
+	}
+	switch level {
+	case LevelError, LevelWarn, LevelOff:
+	default:
+		return fmt.Errorf("参数 rule.%s 取值 %q 无效，可选 %s / %s / %s", rule, level, LevelError, LevelWarn, LevelOff)
+	}
+	if o.RuleLevels == nil {
+		o.RuleLevels = make(map[string]string)
+	}
+	o.RuleLevels[rule] = level
 	return nil
 }
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"path"
+	"slices"
 	"strings"
 
 	"github.com/beijian128/protoc-gen-redis/generator"
@@ -11,68 +14,221 @@ import (
 )
 
 func main() {
-	keyFormat := generator.DefaultKeyFormat
+	opts := generator.DefaultOptions()
 	protogen.Options{
-		ParamFunc: func(name, value string) error {
-			switch name {
-			case "key_format":
-				keyFormat = value
-				return nil
-			default:
-				return fmt.Errorf("unknown parameter %q", name)
-			}
-		},
+		ParamFunc: opts.Set,
 	}.Run(func(gen *protogen.Plugin) error {
-		return run(gen, keyFormat)
+		return run(gen, opts)
 	})
 }
 
 // run 是插件主逻辑，独立出来便于测试。
-func run(gen *protogen.Plugin, keyFormat string) error {
+func run(gen *protogen.Plugin, opts *generator.Options) error {
 	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
 
-	// 先校验约定（message 命名 DB 前缀、顶层字段不得直接定义 repeated/map），违规直接报错
-	for _, f := range gen.Files {
-		if !f.Generate {
-			continue
+	if opts.Mode == generator.ModeAttach && opts.Convert() {
+		return fmt.Errorf("mode=attach 直接使用 protoc-gen-go 的类型，不能同时设置 pb_package")
+	}
+	if opts.Mode == generator.ModeAttach && opts.Codec == generator.CodecTable {
+		return fmt.Errorf("mode=attach 经 proto.Marshal 编解码，不能同时设置 codec=table")
+	}
+	if opts.Mode == generator.ModeAttach && opts.Perf {
+		return fmt.Errorf("mode=attach 经 proto.Marshal 编解码，不能同时设置 perf=true")
+	}
+
+	// compat 指定的旧版本同时用于 reserved 规则与兼容性检查
+	var prev *protogen.Plugin
+	if opts.Compat != "" {
+		var err error
+		if prev, err = generator.LoadDescriptorSet(opts.Compat); err != nil {
+			return err
 		}
-		if err := generator.ValidateConventions(f); err != nil {
-			return fmt.Errorf("%s: %v", f.Desc.Name(), err)
+	}
+
+	// 先校验约定规则（级别为 warn 的只输出到标准错误）与 redisopt 选项用法，违规直接报错
+	if err := validate(gen, prev, opts); err != nil {
+		return err
+	}
+
+	if prev != nil {
+		if err := checkCompat(gen, prev, opts); err != nil {
+			return err
 		}
 	}
 
+	// 输出到同一目录的文件属于同一 Go 包，共用一份辅助代码（见 generator.GenerateHelpers）
+	type goPackage struct {
+		dir   string
+		files []*protogen.File
+	}
+	var packages []*goPackage
+	byDir := make(map[string]*goPackage)
+
 	for _, f := range gen.Files {
 		if !f.Generate {
 			continue
 		}
 
 		// 每个 proto 文件生成一个总的 Redis 代码文件，如 user.redis.go
-		g := gen.NewGeneratedFile(outputFilename(f, gen), f.GoImportPath)
+		filename := outputFilename(f, gen)
+		dir := path.Dir(filename)
+		if base := path.Base(filename); slices.Contains(generator.HelperFilenames, base) {
+			return fmt.Errorf("%s: 生成文件 %s 与同目录的辅助代码文件重名，请重命名该 proto 文件", f.Desc.Path(), filename)
+		}
+		pkg := byDir[dir]
+		if pkg == nil {
+			pkg = &goPackage{dir: dir}
+			byDir[dir] = pkg
+			packages = append(packages, pkg)
+		} else if first := pkg.files[0]; first.GoImportPath != f.GoImportPath {
+			return fmt.Errorf("%s 与 %s 的 go_package 不同（%s / %s），却都输出到目录 %q：同一目录只能是一个 Go 包，请使用 paths=source_relative 或分开生成",
+				first.Desc.Path(), f.Desc.Path(), first.GoImportPath, f.GoImportPath, dir)
+		}
+		pkg.files = append(pkg.files, f)
+		g := gen.NewGeneratedFile(filename, f.GoImportPath)
 
-		head, err := generator.GenerateRedisCodeHeadWithEnums(f)
+		// 先生成枚举与各 message 的代码，文件头的 imports 按其中用到的包决定
+		var body bytes.Buffer
+		enums, err := generator.GenerateRedisEnums(f, opts)
 		if err != nil {
-			return fmt.Errorf("生成 %s 的包头/枚举代码失败: %v", f.Desc.Name(), err)
+			return fmt.Errorf("%s: 生成枚举代码失败: %v", f.Desc.Path(), err)
+		}
+		body.Write(enums)
+
+		// 遍历该 proto 文件中的所有 message（含嵌套 message）；
+		// mode=attach 只为顶层 message 生成方法，嵌套 message 随父字段经 proto.Marshal 整体序列化
+		messages, generate := generator.CollectMessages(f), generator.GenerateRedisCode
+		if opts.Mode == generator.ModeAttach {
+			messages, generate = f.Messages, generator.GenerateAttachCode
+		}
+		for _, msg := range messages {
+			code, err := generate(gen, f, msg, g, opts)
+			if err != nil {
+				return fmt.Errorf("%s: 生成 message %s 的 Redis 代码失败: %v", generator.PositionOf(msg.Desc), msg.Desc.Name(), err)
+			}
+			body.WriteString("\n// --- Message: " + string(msg.GoIdent.GoName) + " ---\n")
+			body.Write(code)
+			body.WriteString("\n\n")
+		}
+
+		head, err := generator.GenerateRedisCodeHead(f.GoPackageName, body.Bytes(), opts)
+		if err != nil {
+			return fmt.Errorf("%s: 生成包头代码失败: %v", f.Desc.Path(), err)
 		}
 		if _, err := g.Write(head); err != nil {
 			return err
 		}
+		if _, err := g.Write(body.Bytes()); err != nil {
+			return err
+		}
 
-		// 遍历该 proto 文件中的所有 message（含嵌套 message）
-		for _, msg := range generator.CollectMessages(f) {
-			code, err := generator.GenerateRedisCode(gen, f, msg, g, keyFormat)
+		// 存储清单与生成代码同名，如 user.redis.manifest.json
+		if opts.Manifest != "" {
+			manifest, err := generator.GenerateManifest(gen, f, g, opts)
 			if err != nil {
-				return fmt.Errorf("生成 message %s 的 Redis 代码失败: %v", msg.Desc.Name(), err)
+				return fmt.Errorf("%s: 生成存储清单失败: %v", f.Desc.Path(), err)
 			}
-			if _, err := g.Write([]byte("\n// --- Message: " + string(msg.GoIdent.GoName) + " ---\n")); err != nil {
+			if _, err := gen.NewGeneratedFile(strings.TrimSuffix(filename, ".go")+".manifest.json", "").Write(manifest); err != nil {
 				return err
 			}
-			if _, err := g.Write(code); err != nil {
+		}
+	}
+
+	for _, pkg := range packages {
+		helpers, err := generator.GenerateHelpers(pkg.files, opts)
+		if err != nil {
+			return fmt.Errorf("%s: 生成辅助代码失败: %v", pkg.files[0].GoImportPath, err)
+		}
+		for _, h := range helpers {
+			if _, err := gen.NewGeneratedFile(path.Join(pkg.dir, h.Name), pkg.files[0].GoImportPath).Write(h.Content); err != nil {
 				return err
 			}
-			if _, err := g.Write([]byte("\n\n")); err != nil {
-				return err
+		}
+		if !opts.Mem {
+			continue
+		}
+		mem, err := generator.GenerateMem(pkg.files, opts)
+		if err != nil {
+			return fmt.Errorf("%s: 生成内存执行器失败: %v", pkg.files[0].GoImportPath, err)
+		}
+		if _, err := gen.NewGeneratedFile(path.Join(pkg.dir, generator.MemFilename), pkg.files[0].GoImportPath).Write(mem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate 按约定规则与 redisopt 选项用法校验待生成的文件，汇总所有文件的全部问题后一起报错。
+// 每处问题独占一行、以 "文件:行:列: " 开头（行列取自 SourceCodeInfo），IDE 与 CI 可直接链接到源码；
+// 级别为 warn 的约定违规输出到标准错误（protoc 会原样打印）后继续生成。prev 为 compat 指定的旧版本（未指定时为 nil），
+// 用于检查相对旧版本删除的字段是否已 reserved。
+func validate(gen *protogen.Plugin, prev *protogen.Plugin, opts *generator.Options) error {
+	var problems []string
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+		violations := generator.ValidateConventions(f, opts)
+		if prev != nil {
+			if old := prev.FilesByPath[f.Desc.Path()]; old != nil {
+				violations = append(violations, generator.ValidateReserved(old, f, opts)...)
+			}
+		}
+		for _, v := range violations {
+			if v.Level == generator.LevelWarn {
+				fmt.Fprintf(os.Stderr, "%s: 警告 [%s] %s\n", v.Pos, v.Rule, v.Message)
+				continue
+			}
+			problems = append(problems, v.String())
+		}
+		for _, err := range generator.ValidateOptions(f) {
+			problems = append(problems, err.Error())
+		}
+		if opts.Mode == generator.ModeAttach {
+			for _, err := range generator.ValidateAttach(f) {
+				problems = append(problems, err.Error())
 			}
 		}
+		for _, err := range generator.ValidateConvert(f, opts) {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("发现 %d 处问题（约定规则可用 rule.<规则>=warn|off 调整级别）:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// checkCompat 把待生成的文件与 compat 参数指定的旧版本比较：有未经 allow_breaking 放行的不兼容变更时报错，
+// 已放行的变更输出到标准错误（protoc 会原样打印）；每处变更的格式同 validate。
+func checkCompat(gen *protogen.Plugin, prev *protogen.Plugin, opts *generator.Options) error {
+	var issues []generator.CompatIssue
+	if opts.CompatKeyFormat != "" && opts.CompatKeyFormat != opts.KeyFormat {
+		issues = append(issues, generator.CompatIssue{
+			Subject: "key_format",
+			Detail:  fmt.Sprintf("由 %q 改为 %q（已有数据的 key 不再匹配）", opts.CompatKeyFormat, opts.KeyFormat),
+		})
+	}
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
+		}
+		if old := prev.FilesByPath[f.Desc.Path()]; old != nil {
+			issues = append(issues, generator.CheckCompat(old, f)...)
+		}
+	}
+
+	var breaking []string
+	for _, issue := range issues {
+		if opts.AllowBreaking(issue) {
+			fmt.Fprintf(os.Stderr, "%s（已由 allow_breaking 放行）\n", issue)
+			continue
+		}
+		breaking = append(breaking, issue.String())
+	}
+	if len(breaking) > 0 {
+		return fmt.Errorf("与旧版本 %s 相比有 %d 处破坏已有 Redis 数据的变更（确认后用 allow_breaking=<全名> 放行）:\n%s",
+			opts.Compat, len(breaking), strings.Join(breaking, "\n"))
 	}
 	return nil
 }
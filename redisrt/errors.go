@@ -0,0 +1,41 @@
+package redisrt
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTruncated 表示 protobuf 数据在字段中途结束（含超过 10 字节的 varint），解码错误可用 errors.Is 识别
+var ErrTruncated = errors.New("protobuf 数据截断")
+
+// ErrWireType 表示 protobuf 数据中出现了未知的 wire type（数据损坏或不是 protobuf 编码）
+var ErrWireType = errors.New("protobuf 未知 wire type")
+
+// ErrTxAborted 表示 WATCH 的 key 在 EXEC 之前被修改，事务被放弃、没有执行任何命令（见 Watcher）
+var ErrTxAborted = errors.New("redis: WATCH 的 key 已被修改，事务被放弃")
+
+// TxError 表示事务（MULTI/EXEC）中第 Index 条命令执行失败，如对 sorted set 索引 key 执行 ZADD 时 key 的类型错误。
+// Redis 不回滚事务，其余命令照常生效；Err 为该命令的错误，可用 errors.Is / errors.As 识别
+type TxError struct {
+	Index int    // 失败的命令在 cmds 中的下标
+	Cmd   string // 失败的命令名
+	Err   error
+}
+
+func (e *TxError) Error() string {
+	return fmt.Sprintf("事务中第 %d 条命令 %s 失败: %v", e.Index+1, e.Cmd, e.Err)
+}
+
+func (e *TxError) Unwrap() error { return e.Err }
+
+// UniqueConflictError 表示唯一索引字段的值已被其他记录占用：写入该字段的 SetFields / Set 返回此错误，不修改任何数据
+type UniqueConflictError struct {
+	Field string // 字段的 Go 名
+	Value string // 冲突的值
+	Ida   uint64 // 占用该值的记录
+	Idb   uint64
+}
+
+func (e *UniqueConflictError) Error() string {
+	return fmt.Sprintf("字段 %s 的值 %q 已被记录 %d:%d 占用", e.Field, e.Value, e.Ida, e.Idb)
+}
@@ -0,0 +1,284 @@
+// Package redisrt 是 protoc-gen-redis 生成代码的运行时（--redis_opt=helpers=runtime）：执行 Redis 命令的 Executor 接口与内存实现、
+// protobuf wire format 编解码、唯一索引与 sorted set 索引的辅助函数以及错误类型。
+// 生成的 redis_helpers.redis.go 只是把这些声明转接到包内的 RedisExecutor、redisProtoAppendVarint 等名字上，
+// 修复运行时的问题升级本模块即可，不必重新生成代码；redigo / go-redis 适配器分别在 redigoexec 与 goredisexec 子包中。
+//
+// 本包的 API 服务于生成代码，业务代码通常只用到 Executor、NewMemExecutor 与 UniqueConflictError。
+package redisrt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Cmd 是一条待执行的 Redis 命令
+type Cmd struct {
+	Name string
+	Args []interface{}
+}
+
+// Executor 是生成代码执行 Redis 命令所需的最小接口。
+// 回复遵循 redigo 约定：bulk string 为 []byte，不存在为 nil，数组为 []interface{}。
+// ctx 的截止时间与取消须作用于整次调用（pipeline/事务的全部命令）。
+// 自定义实现（如 mock、其他客户端）只需满足该接口即可调用 GetFieldsExec/SetFieldsExec。
+// 参数在方法返回后不能再被引用：perf=true 的生成代码会把参数缓冲归还到池中复用（见 Args）。
+type Executor interface {
+	// Do 执行单条命令
+	Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error)
+	// Pipeline 一次往返批量发送多条命令（非原子），按顺序返回各命令的回复
+	Pipeline(ctx context.Context, cmds []Cmd) ([]interface{}, error)
+	// Multi 以 MULTI/EXEC 事务原子执行多条命令，按顺序返回各命令的回复；
+	// 事务中某条命令执行失败时返回 *TxError（Redis 不回滚事务中其余命令的效果）
+	Multi(ctx context.Context, cmds []Cmd) ([]interface{}, error)
+}
+
+// Watcher 是支持乐观锁的执行器，redigoexec、goredisexec 与 NewMemExecutor 均实现。
+// Watch 对 keys 执行 WATCH 后调用 fn：fn 收到的执行器与 WATCH 在同一连接上，其中的读取与随后的 Multi 构成一次 check-and-set，
+// keys 在 Multi 之前被修改时 Multi 返回 ErrTxAborted；fn 返回后 WATCH 随之解除。
+// 写入唯一索引字段与删除记录时生成代码经它保证索引与记录一致（见 Watch），未实现它的自定义执行器不加锁。
+type Watcher interface {
+	Watch(ctx context.Context, keys []string, fn func(exec Executor) error) error
+}
+
+// WatchRetries 是 Watch 因 WATCH 的 key 被并发修改而重新执行 fn 的次数上限
+const WatchRetries = 16
+
+// Watch 在支持 WATCH 的执行器上以乐观锁执行 fn，事务被放弃（ErrTxAborted）时重新执行，
+// 超过 WatchRetries 次仍冲突时返回 ErrTxAborted；执行器未实现 Watcher 时直接执行一次 fn
+func Watch(ctx context.Context, exec Executor, keys []string, fn func(exec Executor) error) error {
+	w, ok := exec.(Watcher)
+	if !ok {
+		return fn(exec)
+	}
+	for i := 1; ; i++ {
+		err := w.Watch(ctx, keys, fn)
+		if i == WatchRetries || !errors.Is(err, ErrTxAborted) {
+			return err
+		}
+	}
+}
+
+// AcquireFunc 为一次调用取得 Executor，调用结束后执行 release 归还底层连接（<Message>Store 使用）
+type AcquireFunc func(ctx context.Context) (exec Executor, release func(), err error)
+
+// ExecAcquire 直接使用给定执行器，无需归还
+func ExecAcquire(exec Executor) AcquireFunc {
+	return func(context.Context) (Executor, func(), error) {
+		return exec, func() {}, nil
+	}
+}
+
+// WithScores 把 ZRANGE / ZREVRANGE ... WITHSCORES 的回复解析为成员与分数交替的数组；
+// RESP3 下回复为 [成员, 分数] 数组的数组，展开为 RESP2 的交替形式
+func WithScores(cmd string, reply interface{}) ([]interface{}, error) {
+	values, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("解析 %s 结果失败: 意外的回复 %T", cmd, reply)
+	}
+	if len(values) > 0 {
+		if _, nested := values[0].([]interface{}); nested {
+			flat := make([]interface{}, 0, 2*len(values))
+			for _, pair := range values {
+				if p, ok := pair.([]interface{}); ok && len(p) == 2 {
+					flat = append(flat, p[0], p[1])
+				}
+			}
+			values = flat
+		}
+	}
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("解析 %s 结果失败: 元素个数 %d 不是偶数", cmd, len(values))
+	}
+	return values, nil
+}
+
+// RecordMember 是一条记录在 sorted set 索引与唯一索引中的成员："<ida>:<idb>"
+func RecordMember(ida, idb uint64) string {
+	return strconv.FormatUint(ida, 10) + ":" + strconv.FormatUint(idb, 10)
+}
+
+// ParseRecordMember 是 RecordMember 的逆过程
+func ParseRecordMember(member []byte) (ida, idb uint64, err error) {
+	a, b, ok := strings.Cut(string(member), ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("解析记录成员 %q 失败: 缺少分隔符", member)
+	}
+	if ida, err = strconv.ParseUint(a, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("解析记录成员 %q 失败: %v", member, err)
+	}
+	if idb, err = strconv.ParseUint(b, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("解析记录成员 %q 失败: %v", member, err)
+	}
+	return ida, idb, nil
+}
+
+// UniqueClaim 是写入唯一索引字段时对索引条目的占用请求
+type UniqueClaim struct {
+	Field     string      // 字段的 Go 名（冲突错误使用）
+	HashField interface{} // 字段在 Redis Hash 中的 field（读取旧值）
+	Key       string      // 唯一索引 hash 的 key
+	Value     []byte      // 新值的编码，零值为 nil（不占用索引）
+}
+
+// UniqueSet 写入带唯一索引字段的记录 key：占用 claims 的新值（HSETNX，值为 member），再在一个事务中执行 write 并释放旧值。
+// 执行器支持 WATCH 时全程 WATCH key（见 Watch），记录在读取旧值之后被其他调用修改时事务放弃，重新读取旧值后重试，
+// 不会按过期的旧值释放条目；值已被其他记录占用时返回 *UniqueConflictError，不写入。
+// 失败时撤销本次新占用、且记录最终没有使用的条目（见 uniqueRollback）。
+func UniqueSet(ctx context.Context, exec Executor, key, member string, claims []UniqueClaim, write []Cmd) error {
+	var claimed []UniqueClaim
+	err := Watch(ctx, exec, []string{key}, func(exec Executor) error {
+		release, newly, err := uniqueAcquire(ctx, exec, key, member, claims)
+		claimed = append(claimed, newly...)
+		if err != nil {
+			return err
+		}
+		cmds := make([]Cmd, 0, len(write)+len(release))
+		_, err = exec.Multi(ctx, append(append(cmds, write...), release...))
+		return err
+	})
+	if err != nil {
+		uniqueRollback(ctx, exec, key, member, claimed)
+	}
+	return err
+}
+
+// uniqueAcquire 为 claims 占用唯一索引条目，并找出改值后要释放的旧条目：
+// 读旧值、占用新值与读回占用者在一次往返中完成，release 是仍由 member 占用的旧条目的 HDEL（应与写入放在同一事务中），
+// claimed 是本次新占用的条目（出错时也返回，由调用方撤销）；值已被其他记录占用时返回 *UniqueConflictError。
+func uniqueAcquire(ctx context.Context, exec Executor, key, member string, claims []UniqueClaim) (release []Cmd, claimed []UniqueClaim, err error) {
+	cmds := make([]Cmd, 0, 3*len(claims))
+	for _, c := range claims {
+		cmds = append(cmds, Cmd{Name: "HGET", Args: []interface{}{key, c.HashField}})
+		if c.Value != nil {
+			cmds = append(cmds,
+				Cmd{Name: "HSETNX", Args: []interface{}{c.Key, c.Value, member}},
+				Cmd{Name: "HGET", Args: []interface{}{c.Key, c.Value}})
+		}
+	}
+	replies, err := exec.Pipeline(ctx, cmds)
+	if err != nil {
+		return nil, nil, err
+	}
+	var conflict error
+	var stale []Cmd
+	for _, c := range claims {
+		old, _ := replies[0].([]byte)
+		replies = replies[1:]
+		if c.Value != nil {
+			n, _ := replies[0].(int64)
+			owner, _ := replies[1].([]byte)
+			replies = replies[2:]
+			if n == 1 {
+				claimed = append(claimed, c)
+			} else if string(owner) != member && conflict == nil {
+				ida, idb, _ := ParseRecordMember(owner)
+				conflict = &UniqueConflictError{Field: c.Field, Value: string(c.Value), Ida: ida, Idb: idb}
+			}
+		}
+		if len(old) > 0 && string(old) != string(c.Value) {
+			stale = append(stale, Cmd{Name: "HGET", Args: []interface{}{c.Key, old}})
+		}
+	}
+	if conflict != nil {
+		return nil, claimed, conflict
+	}
+	release, err = UniqueOwned(ctx, exec, member, stale)
+	return release, claimed, err
+}
+
+// UniqueOwned 执行 gets（HGET 索引 key 与值），返回其中仍由 member 占用的条目的 HDEL 命令
+func UniqueOwned(ctx context.Context, exec Executor, member string, gets []Cmd) ([]Cmd, error) {
+	if len(gets) == 0 {
+		return nil, nil
+	}
+	replies, err := exec.Pipeline(ctx, gets)
+	if err != nil {
+		return nil, err
+	}
+	var release []Cmd
+	for i, reply := range replies {
+		if owner, _ := reply.([]byte); string(owner) == member {
+			release = append(release, Cmd{Name: "HDEL", Args: gets[i].Args})
+		}
+	}
+	return release, nil
+}
+
+// uniqueRollback 尽力撤销本次新占用的唯一索引条目（ctx 已取消时仍执行）：WATCH 记录 key 后读出字段当前值，
+// 跳过记录正在使用的值（同一记录的并发写入可能已把它写入记录），其余仍由 member 占用的条目在事务中 HDEL。
+// 失败时条目保留，需人工清理
+func uniqueRollback(ctx context.Context, exec Executor, key, member string, claimed []UniqueClaim) {
+	if len(claimed) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	_ = Watch(ctx, exec, []string{key}, func(exec Executor) error {
+		cmds := make([]Cmd, 0, 2*len(claimed))
+		for _, c := range claimed {
+			cmds = append(cmds,
+				Cmd{Name: "HGET", Args: []interface{}{key, c.HashField}},
+				Cmd{Name: "HGET", Args: []interface{}{c.Key, c.Value}})
+		}
+		replies, err := exec.Pipeline(ctx, cmds)
+		if err != nil {
+			return err
+		}
+		var dels []Cmd
+		for i, c := range claimed {
+			current, _ := replies[2*i].([]byte)
+			owner, _ := replies[2*i+1].([]byte)
+			if string(current) != string(c.Value) && string(owner) == member {
+				dels = append(dels, Cmd{Name: "HDEL", Args: []interface{}{c.Key, c.Value}})
+			}
+		}
+		if len(dels) == 0 {
+			return nil
+		}
+		_, err = exec.Multi(ctx, dels)
+		return err
+	})
+}
+
+// HashMove 是 tag_fallback 迁移窗口中一个字段从字段编号 field 到名字 field 的搬迁
+type HashMove struct {
+	Tag  uint32 // 旧的字段编号 field
+	Name string // 新的名字 field
+}
+
+// MoveHashFields 把 key 中仍存于字段编号 field 下的值搬到名字 field：名字 field 已存在时保留它（HSETNX），随后删除编号 field。
+// 读出与搬迁之间不加锁，期间旧版本程序写入编号 field 的值会被删除，迁移窗口内应只有新版本程序写入。
+func MoveHashFields(ctx context.Context, exec Executor, key string, moves []HashMove) error {
+	if len(moves) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, 1+len(moves))
+	args = append(args, key)
+	for _, m := range moves {
+		args = append(args, m.Tag)
+	}
+	reply, err := exec.Do(ctx, "HMGET", args...)
+	if err != nil {
+		return fmt.Errorf("HMGET 失败: %w", err)
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != len(moves) {
+		return fmt.Errorf("解析 HMGET 结果失败: 意外的回复 %T", reply)
+	}
+	var cmds []Cmd
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		cmds = append(cmds,
+			Cmd{Name: "HSETNX", Args: []interface{}{key, moves[i].Name, v}},
+			Cmd{Name: "HDEL", Args: []interface{}{key, moves[i].Tag}})
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	_, err = exec.Multi(ctx, cmds)
+	return err
+}
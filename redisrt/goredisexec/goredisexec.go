@@ -0,0 +1,124 @@
+// Package goredisexec 把 github.com/redis/go-redis/v9 的客户端包装为 redisrt.Executor，
+// 供 --redis_opt=helpers=runtime 且 executor=goredis 时的生成代码使用。
+package goredisexec
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/beijian128/protoc-gen-redis/redisrt"
+	"github.com/redis/go-redis/v9"
+)
+
+// New 把 go-redis v9 客户端（*redis.Client / *redis.ClusterClient / *redis.Ring 等）包装为 redisrt.Executor
+func New(client redis.UniversalClient) redisrt.Executor {
+	return executor{client: client}
+}
+
+type executor struct {
+	client redis.UniversalClient
+}
+
+func (e executor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	return normalize(e.client.Do(ctx, append([]interface{}{cmd}, args...)...).Result())
+}
+
+func (e executor) Pipeline(ctx context.Context, cmds []redisrt.Cmd) ([]interface{}, error) {
+	return execPipe(ctx, e.client.Pipeline(), cmds, false)
+}
+
+func (e executor) Multi(ctx context.Context, cmds []redisrt.Cmd) ([]interface{}, error) {
+	return execPipe(ctx, e.client.TxPipeline(), cmds, true)
+}
+
+// Watch 实现 redisrt.Watcher：经 client.Watch 在同一连接上 WATCH keys 并执行 fn（集群模式下 keys 须在同一 slot）
+func (e executor) Watch(ctx context.Context, keys []string, fn func(exec redisrt.Executor) error) error {
+	return e.client.Watch(ctx, func(tx *redis.Tx) error {
+		return fn(watched{tx: tx})
+	}, keys...)
+}
+
+// watched 是 Watch 交给 fn 的执行器，命令都在 WATCH 所在的连接上执行
+type watched struct {
+	tx *redis.Tx
+}
+
+func (e watched) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	c := redis.NewCmd(ctx, append([]interface{}{cmd}, args...)...)
+	_ = e.tx.Process(ctx, c)
+	return normalize(c.Result())
+}
+
+func (e watched) Pipeline(ctx context.Context, cmds []redisrt.Cmd) ([]interface{}, error) {
+	return execPipe(ctx, e.tx.Pipeline(), cmds, false)
+}
+
+func (e watched) Multi(ctx context.Context, cmds []redisrt.Cmd) ([]interface{}, error) {
+	replies, err := execPipe(ctx, e.tx.TxPipeline(), cmds, true)
+	if errors.Is(err, redis.TxFailedErr) {
+		return nil, redisrt.ErrTxAborted
+	}
+	return replies, err
+}
+
+// execPipe 在 pipe 中排队 cmds 并一次执行；单条命令的 nil 回复不视为错误。
+// 命令返回的错误（redis.Error）定位到第一条失败的命令，事务（tx）中包装为 *redisrt.TxError；连接等整体错误原样返回
+func execPipe(ctx context.Context, pipe redis.Pipeliner, cmds []redisrt.Cmd, tx bool) ([]interface{}, error) {
+	results := make([]*redis.Cmd, len(cmds))
+	for i, c := range cmds {
+		results[i] = pipe.Do(ctx, append([]interface{}{c.Name}, c.Args...)...)
+	}
+	_, execErr := pipe.Exec(ctx)
+	if _, ok := execErr.(redis.Error); execErr != nil && !ok {
+		return nil, execErr
+	}
+	replies := make([]interface{}, len(results))
+	for i, r := range results {
+		v, err := normalize(r.Result())
+		if err != nil {
+			if tx {
+				return nil, &redisrt.TxError{Index: i, Cmd: cmds[i].Name, Err: err}
+			}
+			return nil, err
+		}
+		replies[i] = v
+	}
+	if execErr != nil && execErr != redis.Nil {
+		return nil, execErr
+	}
+	return replies, nil
+}
+
+// normalize 把 go-redis 的回复归一为 redigo 风格：string / RESP3 double -> []byte，RESP3 map -> 键值交替数组，redis.Nil -> nil
+func normalize(reply interface{}, err error) (interface{}, error) {
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch v := reply.(type) {
+	case string:
+		return []byte(v), nil
+	case float64:
+		// RESP3 double（如 ZSCORE / ZINCRBY）按 RESP2 的 bulk string 返回
+		return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+	case []interface{}:
+		for i := range v {
+			v[i], _ = normalize(v[i], nil)
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		// RESP3 map（如 HGETALL）展开为 RESP2 的键值交替数组
+		flat := make([]interface{}, 0, 2*len(v))
+		for k, val := range v {
+			k, _ = normalize(k, nil)
+			val, _ = normalize(val, nil)
+			flat = append(flat, k, val)
+		}
+		return flat, nil
+	default:
+		return reply, nil
+	}
+}
@@ -0,0 +1,616 @@
+package redisrt
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NewMemExecutor 返回进程内的 Executor 实现（并发安全），数据只存在内存中，
+// 用于单元测试与 New<Message>MemRepository：实现生成代码用到的 string、hash、list、set、sorted set 与 key 命令，
+// 参数按 redigo 的规则转成字节存储（整数/浮点为十进制、bool 为 1/0），回复与真实 Redis 一致。
+func NewMemExecutor() Executor {
+	return &memExecutor{keys: make(map[string]interface{})}
+}
+
+// memExecutor 按 Redis 类型保存每个 key 的值：
+// string 为 []byte，hash 为 map[string][]byte，list 为 [][]byte，set 为 map[string]struct{}，sorted set 为 map[string]float64（成员 -> 分数）；
+// 集合被删空时 key 随之删除。
+type memExecutor struct {
+	mu   sync.Mutex
+	keys map[string]interface{}
+}
+
+func (e *memExecutor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.do(cmd, args)
+}
+
+func (e *memExecutor) Pipeline(ctx context.Context, cmds []Cmd) ([]interface{}, error) {
+	return e.run(ctx, cmds, false)
+}
+
+func (e *memExecutor) Multi(ctx context.Context, cmds []Cmd) ([]interface{}, error) {
+	return e.run(ctx, cmds, true)
+}
+
+// Watch 实现 Watcher：记下 keys 当前的值，fn 中的 Multi 在同一把锁内先比较，有变化时放弃事务并返回 ErrTxAborted
+func (e *memExecutor) Watch(ctx context.Context, keys []string, fn func(exec Executor) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	watched := e.snapshot(keys)
+	e.mu.Unlock()
+	return fn(&memWatched{memExecutor: e, keys: keys, watched: watched})
+}
+
+// snapshot 把 keys 的当前值格式化为字符串（fmt 按键排序输出 map），比较 WATCH 前后是否变化
+func (e *memExecutor) snapshot(keys []string) string {
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%T%v\x00", e.keys[k], e.keys[k])
+	}
+	return b.String()
+}
+
+// memWatched 是 Watch 交给 fn 的执行器：第一次 Multi 执行前核对 WATCH 的 key，之后 WATCH 解除
+type memWatched struct {
+	*memExecutor
+	keys    []string
+	watched string
+}
+
+func (w *memWatched) Multi(ctx context.Context, cmds []Cmd) ([]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	keys := w.keys
+	w.keys = nil
+	if keys != nil && w.snapshot(keys) != w.watched {
+		return nil, ErrTxAborted
+	}
+	return w.exec(cmds, true)
+}
+
+// run 在同一把锁内依次执行 cmds（见 exec）
+func (e *memExecutor) run(ctx context.Context, cmds []Cmd, tx bool) ([]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.exec(cmds, tx)
+}
+
+// exec 依次执行 cmds（调用方持有锁），其他调用看不到中间状态；与 Redis 一致，单条命令出错不回滚已执行的命令，
+// 全部执行后返回第一条出错命令的错误（事务中包装为 *TxError）
+func (e *memExecutor) exec(cmds []Cmd, tx bool) ([]interface{}, error) {
+	replies := make([]interface{}, len(cmds))
+	var firstErr error
+	for i, c := range cmds {
+		reply, err := e.do(c.Name, c.Args)
+		if err != nil && firstErr == nil {
+			firstErr = err
+			if tx {
+				firstErr = &TxError{Index: i, Cmd: c.Name, Err: err}
+			}
+		}
+		replies[i] = reply
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return replies, nil
+}
+
+func (e *memExecutor) do(cmd string, args []interface{}) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, memArity(cmd)
+	}
+	key := string(memArg(args[0]))
+	switch cmd {
+	case "DEL":
+		var removed int64
+		for _, k := range args {
+			if _, ok := e.keys[string(memArg(k))]; ok {
+				delete(e.keys, string(memArg(k)))
+				removed++
+			}
+		}
+		return removed, nil
+	case "TYPE":
+		// 与 redigo 一致，状态回复为 string
+		switch e.keys[key].(type) {
+		case nil:
+			return "none", nil
+		case []byte:
+			return "string", nil
+		case map[string][]byte:
+			return "hash", nil
+		case [][]byte:
+			return "list", nil
+		case map[string]struct{}:
+			return "set", nil
+		default:
+			return "zset", nil
+		}
+	case "GET":
+		v, ok := e.keys[key].([]byte)
+		if !ok && e.keys[key] != nil {
+			return nil, memWrongType()
+		}
+		if !ok {
+			return nil, nil
+		}
+		return append([]byte{}, v...), nil
+	case "SET":
+		if len(args) != 2 {
+			return nil, memArity(cmd)
+		}
+		// SET 覆盖任意类型的旧值；空值也要占住 key（非 nil 的空切片）
+		e.keys[key] = append([]byte{}, memArg(args[1])...)
+		return "OK", nil
+	case "HSET", "HSETNX", "HGET", "HMGET", "HGETALL", "HEXISTS", "HLEN", "HDEL", "HINCRBY", "HINCRBYFLOAT":
+		return e.doHash(cmd, key, args[1:])
+	case "RPUSH", "LRANGE", "LLEN", "LREM":
+		return e.doList(cmd, key, args[1:])
+	case "SADD", "SREM", "SMEMBERS", "SISMEMBER", "SCARD":
+		return e.doSet(cmd, key, args[1:])
+	case "ZADD", "ZINCRBY", "ZSCORE", "ZRANGE", "ZREVRANGE", "ZRANK", "ZREVRANK", "ZREM", "ZCARD":
+		return e.doZSet(cmd, key, args[1:])
+	default:
+		return nil, fmt.Errorf("ERR unknown command '%s'（NewMemExecutor 未实现）", cmd)
+	}
+}
+
+func (e *memExecutor) doHash(cmd, key string, args []interface{}) (interface{}, error) {
+	hash, ok := e.keys[key].(map[string][]byte)
+	if !ok && e.keys[key] != nil {
+		return nil, memWrongType()
+	}
+	switch cmd {
+	case "HSET":
+		if len(args) < 2 || len(args)%2 != 0 {
+			return nil, memArity(cmd)
+		}
+		if hash == nil {
+			hash = make(map[string][]byte)
+			e.keys[key] = hash
+		}
+		var added int64
+		for i := 0; i < len(args); i += 2 {
+			field := string(memArg(args[i]))
+			if _, ok := hash[field]; !ok {
+				added++
+			}
+			hash[field] = memArg(args[i+1])
+		}
+		return added, nil
+	case "HSETNX":
+		if len(args) != 2 {
+			return nil, memArity(cmd)
+		}
+		field := string(memArg(args[0]))
+		if _, ok := hash[field]; ok {
+			return int64(0), nil
+		}
+		if hash == nil {
+			hash = make(map[string][]byte)
+			e.keys[key] = hash
+		}
+		hash[field] = memArg(args[1])
+		return int64(1), nil
+	case "HGET":
+		if len(args) != 1 {
+			return nil, memArity(cmd)
+		}
+		if v, ok := hash[string(memArg(args[0]))]; ok {
+			return append([]byte(nil), v...), nil
+		}
+		return nil, nil
+	case "HMGET":
+		values := make([]interface{}, 0, len(args))
+		for _, f := range args {
+			if v, ok := hash[string(memArg(f))]; ok {
+				values = append(values, append([]byte(nil), v...))
+			} else {
+				values = append(values, nil)
+			}
+		}
+		return values, nil
+	case "HGETALL":
+		items := make([]interface{}, 0, 2*len(hash))
+		for f, v := range hash {
+			items = append(items, []byte(f), append([]byte(nil), v...))
+		}
+		return items, nil
+	case "HEXISTS":
+		if len(args) != 1 {
+			return nil, memArity(cmd)
+		}
+		if _, ok := hash[string(memArg(args[0]))]; ok {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "HLEN":
+		return int64(len(hash)), nil
+	case "HDEL":
+		var removed int64
+		for _, f := range args {
+			field := string(memArg(f))
+			if _, ok := hash[field]; ok {
+				delete(hash, field)
+				removed++
+			}
+		}
+		if hash != nil && len(hash) == 0 {
+			delete(e.keys, key)
+		}
+		return removed, nil
+	}
+	// HINCRBY / HINCRBYFLOAT
+	if len(args) != 2 {
+		return nil, memArity(cmd)
+	}
+	field := string(memArg(args[0]))
+	cur, exists := hash[field]
+	if cmd == "HINCRBY" {
+		var n int64
+		if exists {
+			v, err := strconv.ParseInt(string(cur), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("ERR hash value is not an integer")
+			}
+			n = v
+		}
+		delta, err := strconv.ParseInt(string(memArg(args[1])), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ERR value is not an integer or out of range")
+		}
+		if (delta > 0 && n > math.MaxInt64-delta) || (delta < 0 && n < math.MinInt64-delta) {
+			return nil, fmt.Errorf("ERR increment or decrement would overflow")
+		}
+		if hash == nil {
+			hash = make(map[string][]byte)
+			e.keys[key] = hash
+		}
+		hash[field] = []byte(strconv.FormatInt(n+delta, 10))
+		return n + delta, nil
+	}
+	var f float64
+	if exists {
+		v, err := strconv.ParseFloat(string(cur), 64)
+		if err != nil {
+			return nil, fmt.Errorf("ERR hash value is not a float")
+		}
+		f = v
+	}
+	delta, err := strconv.ParseFloat(string(memArg(args[1])), 64)
+	if err != nil {
+		return nil, fmt.Errorf("ERR value is not a valid float")
+	}
+	if hash == nil {
+		hash = make(map[string][]byte)
+		e.keys[key] = hash
+	}
+	hash[field] = []byte(strconv.FormatFloat(f+delta, 'f', -1, 64))
+	return append([]byte(nil), hash[field]...), nil
+}
+
+func (e *memExecutor) doList(cmd, key string, args []interface{}) (interface{}, error) {
+	list, ok := e.keys[key].([][]byte)
+	if !ok && e.keys[key] != nil {
+		return nil, memWrongType()
+	}
+	switch cmd {
+	case "RPUSH":
+		if len(args) == 0 {
+			return nil, memArity(cmd)
+		}
+		for _, v := range args {
+			list = append(list, memArg(v))
+		}
+		e.keys[key] = list
+		return int64(len(list)), nil
+	case "LRANGE":
+		if len(args) != 2 {
+			return nil, memArity(cmd)
+		}
+		start, stop, err := memRange(args, len(list))
+		if err != nil {
+			return nil, err
+		}
+		items := []interface{}{}
+		for i := start; i <= stop; i++ {
+			items = append(items, append([]byte(nil), list[i]...))
+		}
+		return items, nil
+	case "LLEN":
+		return int64(len(list)), nil
+	}
+	// LREM key count value：count>0 从头删、count<0 从尾删，count=0 删除全部相等元素
+	if len(args) != 2 {
+		return nil, memArity(cmd)
+	}
+	count, err := strconv.Atoi(string(memArg(args[0])))
+	if err != nil {
+		return nil, fmt.Errorf("ERR value is not an integer or out of range")
+	}
+	target := string(memArg(args[1]))
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	remove := make(map[int]bool)
+	for i := range list {
+		j := i
+		if count < 0 {
+			j = len(list) - 1 - i
+		}
+		if string(list[j]) == target {
+			remove[j] = true
+			if limit > 0 && len(remove) == limit {
+				break
+			}
+		}
+	}
+	kept := list[:0:0]
+	for i, v := range list {
+		if !remove[i] {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) == 0 {
+		delete(e.keys, key)
+	} else if len(remove) > 0 {
+		e.keys[key] = kept
+	}
+	return int64(len(remove)), nil
+}
+
+func (e *memExecutor) doSet(cmd, key string, args []interface{}) (interface{}, error) {
+	set, ok := e.keys[key].(map[string]struct{})
+	if !ok && e.keys[key] != nil {
+		return nil, memWrongType()
+	}
+	switch cmd {
+	case "SADD":
+		if len(args) == 0 {
+			return nil, memArity(cmd)
+		}
+		if set == nil {
+			set = make(map[string]struct{})
+			e.keys[key] = set
+		}
+		var added int64
+		for _, v := range args {
+			member := string(memArg(v))
+			if _, ok := set[member]; !ok {
+				set[member] = struct{}{}
+				added++
+			}
+		}
+		return added, nil
+	case "SREM":
+		var removed int64
+		for _, v := range args {
+			member := string(memArg(v))
+			if _, ok := set[member]; ok {
+				delete(set, member)
+				removed++
+			}
+		}
+		if set != nil && len(set) == 0 {
+			delete(e.keys, key)
+		}
+		return removed, nil
+	case "SMEMBERS":
+		members := make([]interface{}, 0, len(set))
+		for m := range set {
+			members = append(members, []byte(m))
+		}
+		return members, nil
+	case "SISMEMBER":
+		if len(args) != 1 {
+			return nil, memArity(cmd)
+		}
+		if _, ok := set[string(memArg(args[0]))]; ok {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	default: // SCARD
+		return int64(len(set)), nil
+	}
+}
+
+// doZSet 实现 sorted set 命令；成员按 (score, member) 升序排列，与 Redis 一致
+func (e *memExecutor) doZSet(cmd, key string, args []interface{}) (interface{}, error) {
+	zset, ok := e.keys[key].(map[string]float64)
+	if !ok && e.keys[key] != nil {
+		return nil, memWrongType()
+	}
+	switch cmd {
+	case "ZADD":
+		if len(args) == 0 || len(args)%2 != 0 {
+			return nil, memArity(cmd)
+		}
+		scores := make([]float64, 0, len(args)/2)
+		for i := 0; i < len(args); i += 2 {
+			score, err := strconv.ParseFloat(string(memArg(args[i])), 64)
+			if err != nil || math.IsNaN(score) {
+				return nil, fmt.Errorf("ERR value is not a valid float")
+			}
+			scores = append(scores, score)
+		}
+		if zset == nil {
+			zset = make(map[string]float64)
+			e.keys[key] = zset
+		}
+		var added int64
+		for i, score := range scores {
+			member := string(memArg(args[2*i+1]))
+			if _, ok := zset[member]; !ok {
+				added++
+			}
+			zset[member] = score
+		}
+		return added, nil
+	case "ZINCRBY":
+		if len(args) != 2 {
+			return nil, memArity(cmd)
+		}
+		delta, err := strconv.ParseFloat(string(memArg(args[0])), 64)
+		if err != nil || math.IsNaN(delta) {
+			return nil, fmt.Errorf("ERR value is not a valid float")
+		}
+		if zset == nil {
+			zset = make(map[string]float64)
+			e.keys[key] = zset
+		}
+		member := string(memArg(args[1]))
+		score := zset[member] + delta
+		if math.IsNaN(score) {
+			return nil, fmt.Errorf("ERR resulting score is not a number (NaN)")
+		}
+		zset[member] = score
+		return strconv.AppendFloat(nil, score, 'g', -1, 64), nil
+	case "ZSCORE":
+		if len(args) != 1 {
+			return nil, memArity(cmd)
+		}
+		score, ok := zset[string(memArg(args[0]))]
+		if !ok {
+			return nil, nil
+		}
+		return strconv.AppendFloat(nil, score, 'g', -1, 64), nil
+	case "ZRANGE", "ZREVRANGE":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, memArity(cmd)
+		}
+		withScores := len(args) == 3
+		if withScores && !strings.EqualFold(string(memArg(args[2])), "WITHSCORES") {
+			return nil, fmt.Errorf("ERR syntax error")
+		}
+		members := memZSorted(zset, cmd == "ZREVRANGE")
+		start, stop, err := memRange(args[:2], len(members))
+		if err != nil {
+			return nil, err
+		}
+		items := []interface{}{}
+		for i := start; i <= stop; i++ {
+			items = append(items, []byte(members[i]))
+			if withScores {
+				items = append(items, strconv.AppendFloat(nil, zset[members[i]], 'g', -1, 64))
+			}
+		}
+		return items, nil
+	case "ZRANK", "ZREVRANK":
+		if len(args) != 1 {
+			return nil, memArity(cmd)
+		}
+		member := string(memArg(args[0]))
+		for i, m := range memZSorted(zset, cmd == "ZREVRANK") {
+			if m == member {
+				return int64(i), nil
+			}
+		}
+		return nil, nil
+	case "ZREM":
+		if len(args) == 0 {
+			return nil, memArity(cmd)
+		}
+		var removed int64
+		for _, v := range args {
+			member := string(memArg(v))
+			if _, ok := zset[member]; ok {
+				delete(zset, member)
+				removed++
+			}
+		}
+		if zset != nil && len(zset) == 0 {
+			delete(e.keys, key)
+		}
+		return removed, nil
+	default: // ZCARD
+		return int64(len(zset)), nil
+	}
+}
+
+// memZSorted 返回按 (score, member) 升序（rev 为 true 时降序）排列的成员
+func memZSorted(zset map[string]float64, rev bool) []string {
+	members := make([]string, 0, len(zset))
+	for m := range zset {
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if rev {
+			a, b = b, a
+		}
+		if zset[a] != zset[b] {
+			return zset[a] < zset[b]
+		}
+		return a < b
+	})
+	return members
+}
+
+// memRange 解析 LRANGE/ZRANGE 的 start stop 参数：负数从末尾计，越界截断；区间为空时 start > stop
+func memRange(args []interface{}, n int) (start, stop int, err error) {
+	start, err1 := strconv.Atoi(string(memArg(args[0])))
+	stop, err2 := strconv.Atoi(string(memArg(args[1])))
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("ERR value is not an integer or out of range")
+	}
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	return start, stop, nil
+}
+
+func memArity(cmd string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", cmd)
+}
+
+func memWrongType() error {
+	return fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value")
+}
+
+// memArg 按 redigo 的规则把命令参数转为字节：[]byte/string 原样，bool 为 1/0，其余按十进制文本
+func memArg(arg interface{}) []byte {
+	switch v := arg.(type) {
+	case []byte:
+		return append([]byte(nil), v...)
+	case string:
+		return []byte(v)
+	case bool:
+		if v {
+			return []byte("1")
+		}
+		return []byte("0")
+	case nil:
+		return []byte{}
+	default:
+		return []byte(fmt.Sprint(v))
+	}
+}
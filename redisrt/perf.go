@@ -0,0 +1,66 @@
+package redisrt
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// Args 是 perf=true 的 GetFields/SetFields 一次命令的参数（Args）与字节缓冲（Buf，存放 key 与字段的编码结果），
+// 经 GetArgs 从池中取出，命令返回后由 PutArgs 归还。因此 Executor 不能在 Do / Pipeline / Multi 返回后继续持有参数，
+// 内置的适配器与 MemExecutor 都满足这一点
+type Args struct {
+	Args []interface{}
+	Buf  []byte
+}
+
+// argsMaxBuf 是归还到池中的字节缓冲的容量上限：个别大 message 撑大的缓冲直接丢弃，池不长期占用大块内存
+const argsMaxBuf = 64 << 10
+
+var argsPool = sync.Pool{New: func() interface{} { return new(Args) }}
+
+// GetArgs 从池中取出一组参数缓冲（Args 与 Buf 长度为 0）
+func GetArgs() *Args {
+	return argsPool.Get().(*Args)
+}
+
+// PutArgs 归还参数缓冲，并清除 Args 中的引用
+func PutArgs(a *Args) {
+	if cap(a.Buf) > argsMaxBuf {
+		return
+	}
+	clear(a.Args[:cap(a.Args)])
+	a.Args, a.Buf = a.Args[:0], a.Buf[:0]
+	argsPool.Put(a)
+}
+
+// BytesString 把 b 当作 string 使用而不拷贝，只用于交给 strconv 解析等不保留结果的场合
+func BytesString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// AppendString 追加 length-delimited 的字符串，省去 []byte(s) 的转换
+func AppendString(buf []byte, s string) []byte {
+	buf = AppendVarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// OpenLen 追加字段 tag（wire type 2）并为长度前缀预留 1 字节，随后的内容直接编码在 buf 中，
+// 结束时以内容的起始位置（此时的 len(buf)）调用 CloseLen 补写长度
+func OpenLen(buf []byte, field int32) []byte {
+	return append(AppendTag(buf, field, 2), 0)
+}
+
+// CloseLen 补写 OpenLen 预留的长度前缀：内容不足 128 字节时正好占满预留的 1 字节，否则把内容后移腾出位置
+func CloseLen(buf []byte, start int) []byte {
+	n := len(buf) - start
+	if n < 0x80 {
+		buf[start-1] = byte(n)
+		return buf
+	}
+	var prefix [10]byte
+	k := len(AppendVarint(prefix[:0], uint64(n)))
+	buf = append(buf, prefix[1:k]...)
+	copy(buf[start+k-1:], buf[start:start+n])
+	copy(buf[start-1:], prefix[:k])
+	return buf
+}
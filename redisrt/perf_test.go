@@ -0,0 +1,57 @@
+package redisrt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 直接编码在 buf 中的 length-delimited 字段须与 AppendLen 一致，长度前缀跨越 1~3 字节
+func TestOpenCloseLen(t *testing.T) {
+	for _, n := range []int{0, 1, 127, 128, 300, 1 << 14, 70000} {
+		payload := bytes.Repeat([]byte{'x'}, n)
+		head := []byte("head")
+		buf := OpenLen(head, 7)
+		start := len(buf)
+		buf = append(buf, payload...)
+		got := CloseLen(buf, start)
+		want := protowire.AppendBytes(protowire.AppendTag([]byte("head"), 7, protowire.BytesType), payload)
+		if !bytes.Equal(got, want) {
+			t.Errorf("n=%d: OpenLen/CloseLen 与 protowire 不一致", n)
+		}
+	}
+	if got, want := AppendString(nil, "中文"), AppendLen(nil, []byte("中文")); !bytes.Equal(got, want) {
+		t.Errorf("AppendString = %x, want %x", got, want)
+	}
+}
+
+func TestReadBytesView(t *testing.T) {
+	b := protowire.AppendBytes(nil, []byte("abc"))
+	v, n, err := ReadBytesView(append(b, 'z'))
+	if err != nil || string(v) != "abc" || n != len(b) || cap(v) != 3 {
+		t.Errorf("ReadBytesView = %q (cap %d), %d, %v", v, cap(v), n, err)
+	}
+	if _, _, err := ReadBytesView(b[:len(b)-1]); err == nil || !strings.Contains(err.Error(), "length-delimited") {
+		t.Errorf("截断的数据应报错, got %v", err)
+	}
+}
+
+// 归还时清除参数引用，过大的缓冲不回到池中
+func TestArgsPool(t *testing.T) {
+	a := GetArgs()
+	if len(a.Args) != 0 || len(a.Buf) != 0 {
+		t.Fatalf("GetArgs 返回非空缓冲: %d 个参数, %d 字节", len(a.Args), len(a.Buf))
+	}
+	a.Args = append(a.Args, "k", []byte("v"))
+	a.Buf = append(a.Buf, "key"...)
+	args := a.Args
+	PutArgs(a)
+	if args[0] != nil || args[1] != nil || len(a.Args) != 0 || len(a.Buf) != 0 {
+		t.Errorf("PutArgs 后参数未清除: %v, %d 字节", args, len(a.Buf))
+	}
+	if got := BytesString([]byte("123")); got != "123" {
+		t.Errorf("BytesString = %q", got)
+	}
+}
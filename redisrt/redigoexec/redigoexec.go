@@ -0,0 +1,166 @@
+// Package redigoexec 把 github.com/gomodule/redigo 的连接包装为 redisrt.Executor，
+// 供 --redis_opt=helpers=runtime 且 executor=redigo（默认）时的生成代码使用。
+package redigoexec
+
+import (
+	"context"
+	"time"
+
+	"github.com/beijian128/protoc-gen-redis/redisrt"
+	"github.com/gomodule/redigo/redis"
+)
+
+// ConnSource 是 redigo 连接来源，*redis.Pool 即满足；生成代码的 <Message>Store 每次调用借出一个连接，用完 Close 归还
+type ConnSource interface {
+	Get() redis.Conn
+}
+
+// PoolAcquire 从 pool 借出连接；pool 实现 GetContext 时（如 *redis.Pool）借连接也遵循 ctx
+func PoolAcquire(pool ConnSource) redisrt.AcquireFunc {
+	return func(ctx context.Context) (redisrt.Executor, func(), error) {
+		var conn redis.Conn
+		if p, ok := pool.(interface {
+			GetContext(context.Context) (redis.Conn, error)
+		}); ok {
+			c, err := p.GetContext(ctx)
+			if err != nil {
+				return nil, nil, err
+			}
+			conn = c
+		} else {
+			conn = pool.Get()
+			if err := conn.Err(); err != nil {
+				conn.Close()
+				return nil, nil, err
+			}
+		}
+		return New(conn), func() { conn.Close() }, nil
+	}
+}
+
+// New 把 redigo 连接包装为 redisrt.Executor（连接的生命周期仍由调用方管理）。
+// ctx 经 redis.DoContext 生效，conn 须实现 redis.ConnWithContext（redis.Dial 与 redis.Pool 返回的连接均已实现）；
+// pipeline 与事务逐条写入发送缓冲前检查 ctx，缓冲中的命令在 DoContext 中写出并读回回复，超时或取消后 redigo 会关闭该连接，
+// 阻塞中的写入随之返回。发送缓冲（4 KB）写满时 Send 直接写向网络，这部分只受连接自身的写超时（redis.DialWriteTimeout）约束。
+func New(conn redis.Conn) redisrt.Executor {
+	return executor{conn: conn}
+}
+
+type executor struct {
+	conn redis.Conn
+}
+
+func (e executor) Do(ctx context.Context, cmd string, args ...interface{}) (interface{}, error) {
+	reply, err := redis.DoContext(e.conn, ctx, cmd, args...)
+	if err != nil {
+		return nil, contextErr(ctx, err)
+	}
+	return reply, nil
+}
+
+func (e executor) Pipeline(ctx context.Context, cmds []redisrt.Cmd) ([]interface{}, error) {
+	if len(cmds) == 0 {
+		return nil, ctx.Err()
+	}
+	if err := sendAll(ctx, e.conn, cmds, false); err != nil {
+		return nil, err
+	}
+	// 一次读回全部回复（出错也读完，避免残留回复错位到后续命令）；超时/取消时 redigo 已关闭连接
+	replies, err := redis.Values(redis.DoContext(e.conn, ctx, ""))
+	if err != nil {
+		return nil, contextErr(ctx, err)
+	}
+	for _, reply := range replies {
+		if err, ok := reply.(redis.Error); ok {
+			return nil, err
+		}
+	}
+	return replies, nil
+}
+
+func (e executor) Multi(ctx context.Context, cmds []redisrt.Cmd) ([]interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := e.conn.Send("MULTI"); err != nil {
+		return nil, err
+	}
+	if err := sendAll(ctx, e.conn, cmds, true); err != nil {
+		return nil, err
+	}
+	values, err := redis.Values(redis.DoContext(e.conn, ctx, "EXEC"))
+	if err == redis.ErrNil {
+		// EXEC 回复 nil：WATCH 的 key 已被修改
+		return nil, redisrt.ErrTxAborted
+	}
+	if err != nil {
+		return nil, contextErr(ctx, err)
+	}
+	// redigo 把事务中单条命令的错误放在 EXEC 的回复数组中，而不是作为 err 返回
+	for i, v := range values {
+		if err, ok := v.(redis.Error); ok {
+			return nil, &redisrt.TxError{Index: i, Cmd: cmds[i].Name, Err: err}
+		}
+	}
+	return values, nil
+}
+
+// Watch 实现 redisrt.Watcher：在本连接上 WATCH keys 后执行 fn；fn 没有执行事务就返回时发送 UNWATCH，连接不带着 WATCH 被复用
+func (e executor) Watch(ctx context.Context, keys []string, fn func(exec redisrt.Executor) error) error {
+	args := make([]interface{}, len(keys))
+	for i, k := range keys {
+		args[i] = k
+	}
+	if _, err := e.Do(ctx, "WATCH", args...); err != nil {
+		return err
+	}
+	w := &watched{executor: e}
+	err := fn(w)
+	if !w.done {
+		e.conn.Do("UNWATCH")
+	}
+	return err
+}
+
+// watched 是 Watch 交给 fn 的执行器，记录事务是否已执行（EXEC 与 DISCARD 都会解除 WATCH）
+type watched struct {
+	executor
+	done bool
+}
+
+func (e *watched) Multi(ctx context.Context, cmds []redisrt.Cmd) ([]interface{}, error) {
+	e.done = true
+	return e.executor.Multi(ctx, cmds)
+}
+
+// sendAll 把 cmds 逐条写入连接的发送缓冲，每条之前检查 ctx。ctx 结束或写入失败时放弃已缓冲的命令：
+// 在 DoContext 中写出并读掉它们的回复（ctx 已结束时 redigo 直接关闭连接），inMulti 时先追加 DISCARD，
+// 连接不会残留待读的回复或停留在事务状态中被放回连接池
+func sendAll(ctx context.Context, conn redis.Conn, cmds []redisrt.Cmd, inMulti bool) error {
+	for _, c := range cmds {
+		err := ctx.Err()
+		if err == nil {
+			err = conn.Send(c.Name, c.Args...)
+		}
+		if err != nil {
+			if inMulti {
+				conn.Send("DISCARD")
+			}
+			redis.DoContext(conn, ctx, "")
+			return contextErr(ctx, err)
+		}
+	}
+	return nil
+}
+
+// contextErr 在 ctx 已取消或到期时返回 ctx 的错误，否则原样返回 err。
+// redigo 把 ctx 截止时间设为读超时，到期时报的是 i/o timeout，这里统一还原为 context.DeadlineExceeded。
+func contextErr(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
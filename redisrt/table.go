@@ -0,0 +1,427 @@
+package redisrt
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// 表驱动的 protobuf 编解码（codec=table）：生成代码为每个 message 声明一张字段表（[]Field），
+// 每个字段一项（tag、值的 Codec 与字段在结构体中的偏移，由 Singular / Repeated / Map 构造），
+// Marshal / Unmarshal 按表编解码，编码结果与逐字段生成的 MarshalRedisProto 相同。
+// 只有切片增长、map 读写与嵌套 message 的调用依赖具体类型，它们是很小的泛型函数与方法，按类型实例化。
+
+// Message 是生成的 message 结构体（指针）实现的编解码方法，Nested 据此编解码嵌套 message
+type Message interface {
+	MarshalRedisProto() ([]byte, error)
+	UnmarshalRedisProto(b []byte) error
+}
+
+// valueKind 是字段表中值的种类，决定 wire type 与 Go 内存表示
+type valueKind uint8
+
+const (
+	kindBool  valueKind = iota
+	kindInt32           // int32 与枚举：负数按 64 位补码编码，与逐字段生成的编码一致
+	kindInt64
+	kindUint32
+	kindUint64
+	kindFloat32
+	kindFloat64
+	kindString
+	kindBytes
+	kindMessage
+)
+
+// Codec 是一种值类型的编解码：标量由 kind 决定，message 经 marshal / unmarshal 调用其 MarshalRedisProto / UnmarshalRedisProto
+// （v 指向 message 结构体）
+type Codec struct {
+	kind      valueKind
+	wire      uint64
+	marshal   func(v unsafe.Pointer) ([]byte, error)
+	unmarshal func(v unsafe.Pointer, b []byte) error
+}
+
+// 标量类型的编解码：bool 与整型为 varint，float32 / float64 为 fixed32 / fixed64，string 与 bytes 为 length-delimited
+var (
+	Bool    = &Codec{kind: kindBool, wire: 0}
+	Int32   = &Codec{kind: kindInt32, wire: 0}
+	Int64   = &Codec{kind: kindInt64, wire: 0}
+	Uint32  = &Codec{kind: kindUint32, wire: 0}
+	Uint64  = &Codec{kind: kindUint64, wire: 0}
+	Float32 = &Codec{kind: kindFloat32, wire: 5}
+	Float64 = &Codec{kind: kindFloat64, wire: 1}
+	String  = &Codec{kind: kindString, wire: 2}
+	Bytes   = &Codec{kind: kindBytes, wire: 2}
+)
+
+// Nested 返回 message M 的编解码：内容为 M 的 MarshalRedisProto，解码时由 UnmarshalRedisProto 先重置
+func Nested[M any, PM interface {
+	*M
+	Message
+}]() *Codec {
+	return &Codec{
+		kind:      kindMessage,
+		wire:      2,
+		marshal:   func(v unsafe.Pointer) ([]byte, error) { return PM((*M)(v)).MarshalRedisProto() },
+		unmarshal: func(v unsafe.Pointer, b []byte) error { return PM((*M)(v)).UnmarshalRedisProto(b) },
+	}
+}
+
+// empty 报告单值字段是否为不编码的零值（proto3）；message 恒编码
+func (c *Codec) empty(v unsafe.Pointer) bool {
+	switch c.kind {
+	case kindBool:
+		return !*(*bool)(v)
+	case kindInt32, kindUint32:
+		return *(*uint32)(v) == 0
+	case kindInt64, kindUint64:
+		return *(*uint64)(v) == 0
+	case kindFloat32:
+		return *(*float32)(v) == 0
+	case kindFloat64:
+		return *(*float64)(v) == 0
+	case kindString:
+		return *(*string)(v) == ""
+	case kindBytes:
+		return len(*(*[]byte)(v)) == 0
+	default:
+		return false
+	}
+}
+
+// append 把 v 指向的值编码后追加到 buf（不含 tag）
+func (c *Codec) append(buf []byte, v unsafe.Pointer) ([]byte, error) {
+	if c.kind != kindMessage {
+		return c.appendScalar(buf, v), nil
+	}
+	b, err := c.marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return AppendLen(buf, b), nil
+}
+
+// appendScalar 是 append 的标量部分。与 message 分开：v 不经接口调用传出，map 的键等临时变量可以留在栈上
+func (c *Codec) appendScalar(buf []byte, v unsafe.Pointer) []byte {
+	switch c.kind {
+	case kindBool:
+		if *(*bool)(v) {
+			return append(buf, 1)
+		}
+		return append(buf, 0)
+	case kindInt32:
+		return AppendVarint(buf, uint64(*(*int32)(v)))
+	case kindInt64, kindUint64:
+		return AppendVarint(buf, *(*uint64)(v))
+	case kindUint32:
+		return AppendVarint(buf, uint64(*(*uint32)(v)))
+	case kindFloat32:
+		return AppendFixed32(buf, *(*uint32)(v))
+	case kindFloat64:
+		return AppendFixed64(buf, *(*uint64)(v))
+	case kindString:
+		s := *(*string)(v)
+		return append(AppendVarint(buf, uint64(len(s))), s...)
+	default:
+		return AppendLen(buf, *(*[]byte)(v))
+	}
+}
+
+// read 从 b 解码一个值到 v 指向的位置，返回读取的字节数（b 不含 tag，wire type 已由调用方核对）
+func (c *Codec) read(b []byte, v unsafe.Pointer) (int, error) {
+	if c.kind != kindMessage {
+		return c.readScalar(b, v)
+	}
+	x, n, err := ReadBytes(b)
+	if err != nil {
+		return 0, err
+	}
+	return n, c.unmarshal(v, x)
+}
+
+// readScalar 是 read 的标量部分（与 appendScalar 同理与 message 分开）
+func (c *Codec) readScalar(b []byte, v unsafe.Pointer) (int, error) {
+	switch c.kind {
+	case kindFloat32:
+		x, n, err := ReadFixed32(b)
+		*(*uint32)(v) = x
+		return n, err
+	case kindFloat64:
+		x, n, err := ReadFixed64(b)
+		*(*uint64)(v) = x
+		return n, err
+	case kindString, kindBytes:
+		x, n, err := ReadBytes(b)
+		if err != nil {
+			return 0, err
+		}
+		if c.kind == kindString {
+			*(*string)(v) = string(x)
+		} else {
+			*(*[]byte)(v) = x
+		}
+		return n, nil
+	}
+	x, n, err := ReadVarint(b)
+	switch c.kind {
+	case kindBool:
+		*(*bool)(v) = x != 0
+	case kindInt32, kindUint32:
+		*(*uint32)(v) = uint32(x)
+	default:
+		*(*uint64)(v) = x
+	}
+	return n, err
+}
+
+// Field 是字段表中的一项：单值字段 slice 与 mapping 均为 nil，repeated 与 map 分别经它们操作容器
+type Field struct {
+	tag     uint64
+	name    string
+	offset  uintptr // 字段在结构体中的偏移（unsafe.Offsetof）
+	val     *Codec  // 值；repeated 为元素，map 为 value
+	key     *Codec  // map 的键
+	slice   sliceOps
+	mapping mapOps
+}
+
+// sliceOps 操作 repeated 字段（s 指向切片）
+type sliceOps interface {
+	elems(s unsafe.Pointer) (base unsafe.Pointer, n int, size uintptr)
+	grow(s unsafe.Pointer) unsafe.Pointer // 追加一个零值元素，返回其地址
+}
+
+// mapOps 操作 map 字段（m 指向 map），键值对的编解码经 f 的 appendEntry / readEntry 进行
+type mapOps interface {
+	appendEntries(buf []byte, m unsafe.Pointer, f *Field) ([]byte, error)
+	storeEntry(m unsafe.Pointer, f *Field, entry []byte) error
+}
+
+type sliceOf[V any] struct{}
+
+func (sliceOf[V]) elems(s unsafe.Pointer) (unsafe.Pointer, int, uintptr) {
+	v := *(*[]V)(s)
+	return unsafe.Pointer(unsafe.SliceData(v)), len(v), unsafe.Sizeof(*new(V))
+}
+
+func (sliceOf[V]) grow(s unsafe.Pointer) unsafe.Pointer {
+	v := (*[]V)(s)
+	var zero V
+	*v = append(*v, zero)
+	return unsafe.Pointer(&(*v)[len(*v)-1])
+}
+
+type mapOf[K comparable, V any] struct{}
+
+func (mapOf[K, V]) appendEntries(buf []byte, m unsafe.Pointer, f *Field) ([]byte, error) {
+	var entry []byte
+	var k K // 在循环外声明：每个字段只分配一次键值，而不是每个键值对一次
+	var v V
+	for k, v = range *(*map[K]V)(m) {
+		var err error
+		if buf, entry, err = f.appendEntry(buf, entry, unsafe.Pointer(&k), unsafe.Pointer(&v)); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+func (mapOf[K, V]) storeEntry(m unsafe.Pointer, f *Field, entry []byte) error {
+	var k K
+	var v V
+	if err := f.readEntry(entry, unsafe.Pointer(&k), unsafe.Pointer(&v)); err != nil {
+		return err
+	}
+	mp := (*map[K]V)(m)
+	if *mp == nil {
+		*mp = make(map[K]V)
+	}
+	(*mp)[k] = v
+	return nil
+}
+
+// Singular 返回单值字段的表项：零值标量不编码，message 恒编码
+func Singular(tag uint64, name string, offset uintptr, c *Codec) Field {
+	return Field{tag: tag, name: name, offset: offset, val: c}
+}
+
+// Repeated 返回元素类型为 V 的 repeated 字段的表项：逐元素编码（含零值）；
+// 标量元素同时接受 packed 编码（其他语言的 protoc 实现默认对 repeated 标量打包编码）
+func Repeated[V any](tag uint64, name string, offset uintptr, c *Codec) Field {
+	return Field{tag: tag, name: name, offset: offset, val: c, slice: sliceOf[V]{}}
+}
+
+// Map 返回 map[K]V 字段的表项：每个键值对编码为一个子消息（field 1 = 键，field 2 = 值，值恒编码）
+func Map[K comparable, V any](tag uint64, name string, offset uintptr, kc, vc *Codec) Field {
+	return Field{tag: tag, name: name, offset: offset, val: vc, key: kc, mapping: mapOf[K, V]{}}
+}
+
+// Marshal 按字段表把 p 指向的 message 编码后追加到 buf
+func Marshal(buf []byte, p unsafe.Pointer, table []Field) ([]byte, error) {
+	for i := range table {
+		var err error
+		if buf, err = table[i].encode(buf, unsafe.Add(p, table[i].offset)); err != nil {
+			return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %w", table[i].name, err)
+		}
+	}
+	return buf, nil
+}
+
+// MarshalField 只编码字段表中的一项（集合字段在 Redis Hash 中的值）
+func MarshalField(buf []byte, p unsafe.Pointer, f *Field) ([]byte, error) {
+	buf, err := f.encode(buf, unsafe.Add(p, f.offset))
+	if err != nil {
+		return nil, fmt.Errorf("protobuf 序列化字段 %s 失败: %w", f.name, err)
+	}
+	return buf, nil
+}
+
+func (f *Field) encode(buf []byte, v unsafe.Pointer) ([]byte, error) {
+	var err error
+	switch {
+	case f.slice != nil:
+		base, n, size := f.slice.elems(v)
+		for i := 0; i < n; i++ {
+			buf = AppendVarint(buf, f.tag<<3|f.val.wire)
+			if f.val.kind != kindMessage {
+				buf = f.val.appendScalar(buf, unsafe.Add(base, uintptr(i)*size))
+			} else if buf, err = f.val.append(buf, unsafe.Add(base, uintptr(i)*size)); err != nil {
+				return nil, err
+			}
+		}
+	case f.mapping != nil:
+		buf, err = f.mapping.appendEntries(buf, v, f)
+	case f.val.kind != kindMessage:
+		if !f.val.empty(v) {
+			buf = f.val.appendScalar(AppendVarint(buf, f.tag<<3|f.val.wire), v)
+		}
+	default:
+		buf, err = f.val.append(AppendVarint(buf, f.tag<<3|f.val.wire), v)
+	}
+	return buf, err
+}
+
+// appendEntry 把 map 的一个键值对编码为子消息追加到 buf，entry 为复用的子消息缓冲
+func (f *Field) appendEntry(buf, entry []byte, k, v unsafe.Pointer) ([]byte, []byte, error) {
+	entry = f.key.appendScalar(AppendVarint(entry[:0], 1<<3|f.key.wire), k) // map 的键只能是标量
+	entry, err := f.val.append(AppendVarint(entry, 2<<3|f.val.wire), v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return AppendLen(AppendVarint(buf, f.tag<<3|2), entry), entry, nil
+}
+
+// Unmarshal 按字段表把 b 解码到 p 指向的 message（不先重置）：未知字段跳过，缺失字段保持原值
+func Unmarshal(b []byte, p unsafe.Pointer, table []Field) error {
+	i := 0 // 上次命中的表项：字段通常按表中顺序出现（repeated 的元素连续出现），先试它与下一项，不中再查整张表
+	for len(b) > 0 {
+		tag, n, err := ReadVarint(b)
+		if err != nil {
+			return fmt.Errorf("protobuf 读取字段 tag 失败: %w", err)
+		}
+		b = b[n:]
+		field := tag >> 3
+		switch {
+		case i < len(table) && table[i].tag == field:
+		case i+1 < len(table) && table[i+1].tag == field:
+			i++
+		default:
+			for i = 0; i < len(table) && table[i].tag != field; i++ {
+			}
+		}
+		if i == len(table) {
+			n, err = Skip(b, tag&7)
+			i = 0
+		} else {
+			n, err = table[i].decode(b, tag&7, unsafe.Add(p, table[i].offset))
+		}
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// UnmarshalField 解码只含字段表中一项的字节（MarshalField 的输出），出现其他字段时报错
+func UnmarshalField(b []byte, p unsafe.Pointer, f *Field) error {
+	for len(b) > 0 {
+		tag, n, err := ReadVarint(b)
+		if err != nil {
+			return err
+		}
+		if tag>>3 != f.tag {
+			return fmt.Errorf("protobuf 字段 %s tag 不匹配: %d", f.name, tag>>3)
+		}
+		b = b[n:]
+		if n, err = f.decode(b, tag&7, unsafe.Add(p, f.offset)); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// decode 按 wire type 解码字段的一个值到 v 指向的字段：repeated 追加一个元素（packed 时追加多个），map 存入一个键值对
+func (f *Field) decode(b []byte, wire uint64, v unsafe.Pointer) (int, error) {
+	var n int
+	var err error
+	switch {
+	case f.mapping != nil && wire == 2:
+		var entry []byte
+		if entry, n, err = ReadBytes(b); err != nil {
+			return 0, err
+		}
+		err = f.mapping.storeEntry(v, f, entry)
+	case f.mapping != nil:
+		return 0, fmt.Errorf("protobuf 字段 %s wire type 错误: %d", f.name, wire)
+	case wire == f.val.wire && f.slice != nil:
+		n, err = f.val.read(b, f.slice.grow(v))
+	case wire == f.val.wire && f.val.kind != kindMessage:
+		n, err = f.val.readScalar(b, v)
+	case wire == f.val.wire:
+		n, err = f.val.read(b, v)
+	case wire == 2 && f.slice != nil && f.val.wire != 2:
+		var packed []byte
+		if packed, n, err = ReadBytes(b); err != nil {
+			return 0, err
+		}
+		for len(packed) > 0 && err == nil {
+			var m int
+			m, err = f.val.read(packed, f.slice.grow(v))
+			packed = packed[m:]
+		}
+	default:
+		return 0, fmt.Errorf("protobuf 字段 %s wire type 错误: %d", f.name, wire)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("protobuf 反序列化字段 %s 失败: %w", f.name, err)
+	}
+	return n, nil
+}
+
+// readEntry 解码 map 的一个键值对子消息到 k、v（缺失的键或值保持零值）
+func (f *Field) readEntry(entry []byte, k, v unsafe.Pointer) error {
+	for len(entry) > 0 {
+		t, m, err := ReadVarint(entry)
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+		switch {
+		case t>>3 == 1 && t&7 == f.key.wire:
+			m, err = f.key.readScalar(entry, k)
+		case t>>3 == 2 && t&7 == f.val.wire:
+			m, err = f.val.read(entry, v)
+		case t>>3 == 1 || t>>3 == 2:
+			return fmt.Errorf("map 键值 wire type 错误: %d", t&7)
+		default:
+			m, err = Skip(entry, t&7)
+		}
+		if err != nil {
+			return err
+		}
+		entry = entry[m:]
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package redisrt
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// tableItem、tableMsg 是手写的 message 结构体，字段表与 codec=table 生成的形式相同
+type tableItem struct {
+	Name string
+}
+
+var tableItemFields = []Field{
+	Singular(1, "Name", unsafe.Offsetof(tableItem{}.Name), String),
+}
+
+func (p *tableItem) MarshalRedisProto() ([]byte, error) {
+	return Marshal(nil, unsafe.Pointer(p), tableItemFields)
+}
+
+func (p *tableItem) UnmarshalRedisProto(b []byte) error {
+	*p = tableItem{}
+	return Unmarshal(b, unsafe.Pointer(p), tableItemFields)
+}
+
+type tableMsg struct {
+	ID    int32
+	Score float64
+	Ratio float32
+	Ok    bool
+	Data  []byte
+	Ids   []int32
+	Item  tableItem
+	Items map[uint64]tableItem
+}
+
+var tableMsgFields = []Field{
+	Singular(1, "ID", unsafe.Offsetof(tableMsg{}.ID), Int32),
+	Singular(2, "Score", unsafe.Offsetof(tableMsg{}.Score), Float64),
+	Singular(3, "Ratio", unsafe.Offsetof(tableMsg{}.Ratio), Float32),
+	Singular(4, "Ok", unsafe.Offsetof(tableMsg{}.Ok), Bool),
+	Singular(5, "Data", unsafe.Offsetof(tableMsg{}.Data), Bytes),
+	Repeated[int32](6, "Ids", unsafe.Offsetof(tableMsg{}.Ids), Int32),
+	Singular(7, "Item", unsafe.Offsetof(tableMsg{}.Item), Nested[tableItem]()),
+	Map[uint64, tableItem](8, "Items", unsafe.Offsetof(tableMsg{}.Items), Uint64, Nested[tableItem]()),
+}
+
+// 编码须与 protowire 手工拼出的字节一致（map 只放一个键值对，顺序确定）
+func TestTableMatchesProtowire(t *testing.T) {
+	m := &tableMsg{ID: -1, Score: 2.5, Ratio: -0.5, Ok: true, Data: []byte{0, 1}, Ids: []int32{0, 3},
+		Item: tableItem{Name: "a"}, Items: map[uint64]tableItem{math.MaxUint64: {Name: "b"}}}
+	got, err := Marshal(nil, unsafe.Pointer(m), tableMsgFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := func(name string) []byte {
+		return protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), name)
+	}
+	want := protowire.AppendTag(nil, 1, protowire.VarintType)
+	want = protowire.AppendVarint(want, math.MaxUint64) // int32 负数按 64 位补码
+	want = protowire.AppendTag(want, 2, protowire.Fixed64Type)
+	want = protowire.AppendFixed64(want, math.Float64bits(2.5))
+	want = protowire.AppendTag(want, 3, protowire.Fixed32Type)
+	want = protowire.AppendFixed32(want, math.Float32bits(-0.5))
+	want = protowire.AppendTag(want, 4, protowire.VarintType)
+	want = protowire.AppendVarint(want, 1)
+	want = protowire.AppendTag(want, 5, protowire.BytesType)
+	want = protowire.AppendBytes(want, []byte{0, 1})
+	for _, v := range []uint64{0, 3} { // repeated 逐元素编码，含零值
+		want = protowire.AppendTag(want, 6, protowire.VarintType)
+		want = protowire.AppendVarint(want, v)
+	}
+	want = protowire.AppendTag(want, 7, protowire.BytesType)
+	want = protowire.AppendBytes(want, item("a"))
+	entry := protowire.AppendTag(nil, 1, protowire.VarintType)
+	entry = protowire.AppendVarint(entry, math.MaxUint64)
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, item("b"))
+	want = protowire.AppendTag(want, 8, protowire.BytesType)
+	want = protowire.AppendBytes(want, entry)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("Marshal = %x\n want %x", got, want)
+	}
+
+	// 未知字段跳过；Ids 以 packed 编码出现
+	in := protowire.AppendTag(want, 99, protowire.BytesType)
+	in = protowire.AppendBytes(in, []byte("unknown"))
+	in = protowire.AppendTag(in, 6, protowire.BytesType)
+	in = protowire.AppendBytes(in, protowire.AppendVarint(protowire.AppendVarint(nil, 4), 5))
+	var back tableMsg
+	if err := Unmarshal(in, unsafe.Pointer(&back), tableMsgFields); err != nil {
+		t.Fatal(err)
+	}
+	m.Ids = append(m.Ids, 4, 5)
+	if !reflect.DeepEqual(&back, m) {
+		t.Errorf("Unmarshal = %#v\n want %#v", &back, m)
+	}
+
+	// 只编码单个字段（集合字段在 Redis Hash 中的值），解码时拒绝其他字段
+	ids, err := MarshalField(nil, unsafe.Pointer(m), &tableMsgFields[5])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var only tableMsg
+	if err := UnmarshalField(ids, unsafe.Pointer(&only), &tableMsgFields[5]); err != nil || !reflect.DeepEqual(only.Ids, m.Ids) {
+		t.Errorf("UnmarshalField = %v, %v, want %v", only.Ids, err, m.Ids)
+	}
+	if err := UnmarshalField(want, unsafe.Pointer(&only), &tableMsgFields[5]); err == nil || !strings.Contains(err.Error(), "tag 不匹配") {
+		t.Errorf("UnmarshalField(其他字段) err = %v", err)
+	}
+}
+
+func TestTableWireTypeError(t *testing.T) {
+	b := protowire.AppendTag(nil, 2, protowire.VarintType) // Score 应为 fixed64
+	b = protowire.AppendVarint(b, 1)
+	var m tableMsg
+	if err := Unmarshal(b, unsafe.Pointer(&m), tableMsgFields); err == nil || !strings.Contains(err.Error(), "Score wire type 错误") {
+		t.Errorf("err = %v, want Score wire type 错误", err)
+	}
+}
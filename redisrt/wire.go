@@ -0,0 +1,122 @@
+package redisrt
+
+import "fmt"
+
+// protobuf wire format 编解码（规则见 https://protobuf.dev/programming-guides/encoding/）：
+// tag = field<<3 | wireType，wireType：0=varint，1=fixed64，2=length-delimited，5=fixed32。
+// Append* 追加到 buf 并返回新切片，Read* 返回（值，消耗字节数，错误），数据不完整时错误包装 ErrTruncated。
+
+// AppendVarint 追加一个 base-128 varint 编码的 uint64
+func AppendVarint(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+// ReadVarint 读取一个 varint，返回（值，消耗字节数）
+func ReadVarint(b []byte) (uint64, int, error) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7F) << (7 * i)
+		if b[i]&0x80 == 0 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: varint 不完整或过长", ErrTruncated)
+}
+
+// AppendTag 追加字段 tag（field<<3 | wireType）
+func AppendTag(buf []byte, field, wire int32) []byte {
+	return AppendVarint(buf, uint64(field)<<3|uint64(wire))
+}
+
+// AppendLen 追加 length-delimited 数据（长度前缀 + 数据）
+func AppendLen(buf, payload []byte) []byte {
+	buf = AppendVarint(buf, uint64(len(payload)))
+	return append(buf, payload...)
+}
+
+// ReadBytes 读取 length-delimited 数据，返回（数据拷贝，消耗字节数）；
+// 返回拷贝避免与输入缓冲区 alias。
+func ReadBytes(b []byte) ([]byte, int, error) {
+	n, k, err := ReadVarint(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n > uint64(len(b)-k) {
+		return nil, 0, fmt.Errorf("%w: length-delimited 期望 %d 字节, 剩余 %d", ErrTruncated, n, len(b)-k)
+	}
+	return append([]byte(nil), b[k:k+int(n)]...), k + int(n), nil
+}
+
+// ReadBytesView 与 ReadBytes 相同，但返回的数据与 b 共用底层数组（不拷贝），
+// 只用于跳过字段，或随即转换为 string、解码为 message、逐个读取 packed 元素等不保留数据本身的场合
+func ReadBytesView(b []byte) ([]byte, int, error) {
+	n, k, err := ReadVarint(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n > uint64(len(b)-k) {
+		return nil, 0, fmt.Errorf("%w: length-delimited 期望 %d 字节, 剩余 %d", ErrTruncated, n, len(b)-k)
+	}
+	end := k + int(n)
+	return b[k:end:end], end, nil
+}
+
+// AppendFixed32 追加小端 4 字节
+func AppendFixed32(buf []byte, v uint32) []byte {
+	return append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// ReadFixed32 读取小端 4 字节
+func ReadFixed32(b []byte) (uint32, int, error) {
+	if len(b) < 4 {
+		return 0, 0, fmt.Errorf("%w: fixed32", ErrTruncated)
+	}
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24, 4, nil
+}
+
+// AppendFixed64 追加小端 8 字节
+func AppendFixed64(buf []byte, v uint64) []byte {
+	return append(buf,
+		byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
+		byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
+}
+
+// ReadFixed64 读取小端 8 字节
+func ReadFixed64(b []byte) (uint64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, fmt.Errorf("%w: fixed64", ErrTruncated)
+	}
+	var v uint64
+	for i := 0; i < 8; i++ {
+		v |= uint64(b[i]) << (8 * i)
+	}
+	return v, 8, nil
+}
+
+// Skip 跳过未知字段，返回消耗字节数
+func Skip(b []byte, wire uint64) (int, error) {
+	switch wire {
+	case 0: // varint
+		_, n, err := ReadVarint(b)
+		return n, err
+	case 1: // fixed64
+		if len(b) < 8 {
+			return 0, fmt.Errorf("%w: fixed64", ErrTruncated)
+		}
+		return 8, nil
+	case 2: // length-delimited
+		_, n, err := ReadBytesView(b)
+		return n, err
+	case 5: // fixed32
+		if len(b) < 4 {
+			return 0, fmt.Errorf("%w: fixed32", ErrTruncated)
+		}
+		return 4, nil
+	default:
+		return 0, fmt.Errorf("%w %d", ErrWireType, wire)
+	}
+}
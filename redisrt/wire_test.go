@@ -0,0 +1,90 @@
+package redisrt
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 编码结果须与 protowire 逐字节一致，解码须能读回 protowire 的编码
+func TestWireMatchesProtowire(t *testing.T) {
+	for _, v := range []uint64{0, 1, 127, 128, 300, math.MaxUint32, math.MaxUint64} {
+		got, want := AppendVarint(nil, v), protowire.AppendVarint(nil, v)
+		if !bytes.Equal(got, want) {
+			t.Errorf("AppendVarint(%d) = %x, want %x", v, got, want)
+		}
+		if back, n, err := ReadVarint(want); err != nil || back != v || n != len(want) {
+			t.Errorf("ReadVarint(%x) = %d, %d, %v", want, back, n, err)
+		}
+	}
+
+	buf := AppendTag(nil, 3, 2)
+	buf = AppendLen(buf, []byte("hi"))
+	buf = AppendTag(buf, 4, 5)
+	buf = AppendFixed32(buf, math.Float32bits(1.5))
+	buf = AppendTag(buf, 5, 1)
+	buf = AppendFixed64(buf, math.Float64bits(-2.25))
+	want := protowire.AppendTag(nil, 3, protowire.BytesType)
+	want = protowire.AppendBytes(want, []byte("hi"))
+	want = protowire.AppendTag(want, 4, protowire.Fixed32Type)
+	want = protowire.AppendFixed32(want, math.Float32bits(1.5))
+	want = protowire.AppendTag(want, 5, protowire.Fixed64Type)
+	want = protowire.AppendFixed64(want, math.Float64bits(-2.25))
+	if !bytes.Equal(buf, want) {
+		t.Fatalf("编码 = %x, want %x", buf, want)
+	}
+
+	b := buf[1:]
+	s, n, err := ReadBytes(b)
+	if err != nil || string(s) != "hi" {
+		t.Fatalf("ReadBytes = %q, %v", s, err)
+	}
+	b = b[n+1:]
+	f32, n, err := ReadFixed32(b)
+	if err != nil || math.Float32frombits(f32) != 1.5 {
+		t.Fatalf("ReadFixed32 = %v, %v", math.Float32frombits(f32), err)
+	}
+	b = b[n+1:]
+	if f64, _, err := ReadFixed64(b); err != nil || math.Float64frombits(f64) != -2.25 {
+		t.Fatalf("ReadFixed64 = %v, %v", math.Float64frombits(f64), err)
+	}
+}
+
+func TestWireTruncated(t *testing.T) {
+	full := AppendLen(nil, []byte("hello"))
+	cases := map[string]func() error{
+		"varint":  func() error { _, _, err := ReadVarint([]byte{0x80, 0x80}); return err },
+		"bytes":   func() error { _, _, err := ReadBytes(full[:3]); return err },
+		"fixed32": func() error { _, _, err := ReadFixed32([]byte{1, 2, 3}); return err },
+		"fixed64": func() error { _, _, err := ReadFixed64([]byte{1, 2, 3, 4, 5, 6, 7}); return err },
+		"skip":    func() error { _, err := Skip([]byte{1, 2}, 5); return err },
+	}
+	for name, read := range cases {
+		if err := read(); !errors.Is(err, ErrTruncated) {
+			t.Errorf("%s: err = %v, want ErrTruncated", name, err)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	for _, c := range []struct {
+		wire uint64
+		b    []byte
+		n    int
+	}{
+		{0, AppendVarint(nil, 300), 2},
+		{1, AppendFixed64(nil, 1), 8},
+		{2, AppendLen(nil, []byte("abc")), 4},
+		{5, AppendFixed32(nil, 1), 4},
+	} {
+		if n, err := Skip(append(c.b, 0xff), c.wire); err != nil || n != c.n {
+			t.Errorf("Skip(wire %d) = %d, %v, want %d", c.wire, n, err, c.n)
+		}
+	}
+	if _, err := Skip([]byte{0}, 3); !errors.Is(err, ErrWireType) {
+		t.Errorf("Skip(group) err = %v, want ErrWireType", err)
+	}
+}
@@ -0,0 +1,975 @@
+package redistest
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command 是一条普通命令（事务控制命令在 dispatch 中单独处理）。
+// arity 与 Redis COMMAND INFO 一致：正数为参数个数（含命令名）必须相等，负数为至少 -arity 个。
+// fn 收到的 args 不含命令名，调用时已持有 Server.mu。
+type command struct {
+	arity int
+	fn    func(s *Server, args [][]byte) interface{}
+}
+
+func (c command) checkArity(n int) bool {
+	if c.arity >= 0 {
+		return n == c.arity
+	}
+	return n >= -c.arity
+}
+
+var commands = map[string]command{
+	// 连接
+	"PING":   {-1, cmdPing},
+	"ECHO":   {2, func(_ *Server, args [][]byte) interface{} { return args[0] }},
+	"AUTH":   {-2, cmdOK}, // 不校验密码，客户端配置了密码也能连上
+	"SELECT": {2, cmdSelect},
+	"CLIENT": {-2, cmdOK}, // go-redis 建连时发送 CLIENT SETINFO，直接应答
+	// key
+	"DEL":      {-2, cmdDel},
+	"UNLINK":   {-2, cmdDel},
+	"EXISTS":   {-2, cmdExists},
+	"TYPE":     {2, cmdType},
+	"KEYS":     {2, cmdKeys},
+	"DBSIZE":   {1, cmdDBSize},
+	"FLUSHDB":  {-1, cmdFlush},
+	"FLUSHALL": {-1, cmdFlush},
+	// 过期
+	"EXPIRE":    {3, expireCmd(time.Second, false)},
+	"PEXPIRE":   {3, expireCmd(time.Millisecond, false)},
+	"EXPIREAT":  {3, expireCmd(time.Second, true)},
+	"PEXPIREAT": {3, expireCmd(time.Millisecond, true)},
+	"TTL":       {2, ttlCmd(time.Second)},
+	"PTTL":      {2, ttlCmd(time.Millisecond)},
+	"PERSIST":   {2, cmdPersist},
+	// string
+	"GET": {2, cmdGet},
+	"SET": {3, cmdSet},
+	// hash
+	"HSET":         {-4, cmdHSet},
+	"HMSET":        {-4, cmdHMSet},
+	"HSETNX":       {4, cmdHSetNX},
+	"HGET":         {3, cmdHGet},
+	"HMGET":        {-3, cmdHMGet},
+	"HGETALL":      {2, cmdHGetAll},
+	"HDEL":         {-3, cmdHDel},
+	"HEXISTS":      {3, cmdHExists},
+	"HLEN":         {2, cmdHLen},
+	"HKEYS":        {2, cmdHKeys},
+	"HVALS":        {2, cmdHVals},
+	"HINCRBY":      {4, cmdHIncrBy},
+	"HINCRBYFLOAT": {4, cmdHIncrByFloat},
+	// list
+	"RPUSH":  {-3, pushCmd(false)},
+	"LPUSH":  {-3, pushCmd(true)},
+	"LRANGE": {4, cmdLRange},
+	"LLEN":   {2, cmdLLen},
+	"LREM":   {4, cmdLRem},
+	// set
+	"SADD":      {-3, cmdSAdd},
+	"SREM":      {-3, cmdSRem},
+	"SMEMBERS":  {2, cmdSMembers},
+	"SISMEMBER": {3, cmdSIsMember},
+	"SCARD":     {2, cmdSCard},
+	// sorted set
+	"ZADD":      {-4, cmdZAdd},
+	"ZINCRBY":   {4, cmdZIncrBy},
+	"ZSCORE":    {3, cmdZScore},
+	"ZRANGE":    {-4, zrangeCmd(false)},
+	"ZREVRANGE": {-4, zrangeCmd(true)},
+	"ZRANK":     {3, zrankCmd(false)},
+	"ZREVRANK":  {3, zrankCmd(true)},
+	"ZREM":      {-3, cmdZRem},
+	"ZCARD":     {2, cmdZCard},
+}
+
+const (
+	errWrongType = errorReply("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errNotInt    = errorReply("ERR value is not an integer or out of range")
+	errNotFloat  = errorReply("ERR value is not a valid float")
+	errSyntax    = errorReply("ERR syntax error")
+)
+
+func wrongArity(name string) errorReply {
+	return errorReply("ERR wrong number of arguments for '" + strings.ToLower(name) + "' command")
+}
+
+func cmdOK(*Server, [][]byte) interface{} { return simpleString("OK") }
+
+func cmdPing(_ *Server, args [][]byte) interface{} {
+	switch len(args) {
+	case 0:
+		return simpleString("PONG")
+	case 1:
+		return args[0]
+	default:
+		return wrongArity("PING")
+	}
+}
+
+func cmdSelect(_ *Server, args [][]byte) interface{} {
+	if _, err := strconv.Atoi(string(args[0])); err != nil {
+		return errNotInt
+	}
+	return simpleString("OK")
+}
+
+func cmdDel(s *Server, args [][]byte) interface{} {
+	var n int64
+	for _, k := range args {
+		if s.lookup(string(k)) != nil && s.remove(string(k)) {
+			n++
+		}
+	}
+	return n
+}
+
+func cmdExists(s *Server, args [][]byte) interface{} {
+	var n int64
+	for _, k := range args {
+		if s.lookup(string(k)) != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func cmdType(s *Server, args [][]byte) interface{} {
+	e := s.lookup(string(args[0]))
+	if e == nil {
+		return simpleString("none")
+	}
+	return simpleString(e.typ())
+}
+
+func cmdKeys(s *Server, args [][]byte) interface{} {
+	pattern := string(args[0])
+	keys := []interface{}{}
+	for key := range s.keys {
+		if s.lookup(key) != nil && globMatch(pattern, key) {
+			keys = append(keys, []byte(key))
+		}
+	}
+	return keys
+}
+
+func cmdDBSize(s *Server, _ [][]byte) interface{} {
+	var n int64
+	for key := range s.keys {
+		if s.lookup(key) != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func cmdFlush(s *Server, _ [][]byte) interface{} {
+	s.flush()
+	return simpleString("OK")
+}
+
+// expireCmd 生成 EXPIRE 系列命令；at 为 true 时参数是 Unix 时间戳，否则是相对时长。
+// 过期时间不晚于当前时间时与 Redis 一样直接删除 key。
+func expireCmd(unit time.Duration, at bool) func(s *Server, args [][]byte) interface{} {
+	return func(s *Server, args [][]byte) interface{} {
+		n, err := strconv.ParseInt(string(args[1]), 10, 64)
+		if err != nil {
+			return errNotInt
+		}
+		key := string(args[0])
+		e := s.lookup(key)
+		if e == nil {
+			return int64(0)
+		}
+		var deadline time.Time
+		if at {
+			deadline = time.Unix(0, 0).Add(time.Duration(n) * unit)
+		} else {
+			deadline = s.now().Add(time.Duration(n) * unit)
+		}
+		if !deadline.After(s.now()) {
+			s.remove(key)
+			return int64(1)
+		}
+		e.expireAt = deadline
+		s.touch(key)
+		return int64(1)
+	}
+}
+
+// ttlCmd 生成 TTL / PTTL：key 不存在返回 -2，没有过期时间返回 -1。
+func ttlCmd(unit time.Duration) func(s *Server, args [][]byte) interface{} {
+	return func(s *Server, args [][]byte) interface{} {
+		e := s.lookup(string(args[0]))
+		switch {
+		case e == nil:
+			return int64(-2)
+		case e.expireAt.IsZero():
+			return int64(-1)
+		}
+		left := e.expireAt.Sub(s.now())
+		return int64((left + unit/2) / unit)
+	}
+}
+
+func cmdPersist(s *Server, args [][]byte) interface{} {
+	key := string(args[0])
+	e := s.lookup(key)
+	if e == nil || e.expireAt.IsZero() {
+		return int64(0)
+	}
+	e.expireAt = time.Time{}
+	s.touch(key)
+	return int64(1)
+}
+
+func (e *entry) typ() string {
+	switch {
+	case e.str != nil:
+		return "string"
+	case e.list != nil:
+		return "list"
+	case e.set != nil:
+		return "set"
+	case e.zset != nil:
+		return "zset"
+	default:
+		return "hash"
+	}
+}
+
+func cmdGet(s *Server, args [][]byte) interface{} {
+	e := s.lookup(string(args[0]))
+	switch {
+	case e == nil:
+		return nil
+	case e.str == nil:
+		return errWrongType
+	}
+	return e.str
+}
+
+// cmdSet 与 Redis 的 SET（不带选项）一致：覆盖任意类型的旧值并清除过期时间。
+func cmdSet(s *Server, args [][]byte) interface{} {
+	key := string(args[0])
+	s.keys[key] = &entry{str: append([]byte{}, args[1]...)}
+	s.touch(key)
+	return simpleString("OK")
+}
+
+// getHash 返回 key 对应的 hash；key 不存在时 create 为 true 则新建，否则返回 nil。
+// key 存在但不是 hash 时返回 WRONGTYPE 错误。
+func (s *Server) getHash(key string, create bool) (map[string][]byte, interface{}) {
+	e := s.lookup(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		e = &entry{hash: make(map[string][]byte)}
+		s.keys[key] = e
+	}
+	if e.hash == nil {
+		return nil, errWrongType
+	}
+	return e.hash, nil
+}
+
+// dropIfEmpty 与 Redis 一致：hash 的最后一个字段被删除后 key 也随之删除。
+func (s *Server) dropIfEmpty(key string, hash map[string][]byte) {
+	if len(hash) == 0 {
+		delete(s.keys, key)
+	}
+}
+
+func cmdHSet(s *Server, args [][]byte) interface{} {
+	if len(args)%2 == 0 {
+		return wrongArity("HSET")
+	}
+	key := string(args[0])
+	hash, errReply := s.getHash(key, true)
+	if errReply != nil {
+		return errReply
+	}
+	var added int64
+	for i := 1; i < len(args); i += 2 {
+		field := string(args[i])
+		if _, ok := hash[field]; !ok {
+			added++
+		}
+		hash[field] = append([]byte(nil), args[i+1]...)
+	}
+	s.touch(key)
+	return added
+}
+
+func cmdHMSet(s *Server, args [][]byte) interface{} {
+	if len(args)%2 == 0 {
+		return wrongArity("HMSET")
+	}
+	if reply := cmdHSet(s, args); isError(reply) {
+		return reply
+	}
+	return simpleString("OK")
+}
+
+func cmdHSetNX(s *Server, args [][]byte) interface{} {
+	key := string(args[0])
+	hash, errReply := s.getHash(key, true)
+	if errReply != nil {
+		return errReply
+	}
+	if _, ok := hash[string(args[1])]; ok {
+		return int64(0)
+	}
+	hash[string(args[1])] = append([]byte(nil), args[2]...)
+	s.touch(key)
+	return int64(1)
+}
+
+func cmdHGet(s *Server, args [][]byte) interface{} {
+	hash, errReply := s.getHash(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	if v, ok := hash[string(args[1])]; ok {
+		return v
+	}
+	return nil
+}
+
+func cmdHMGet(s *Server, args [][]byte) interface{} {
+	hash, errReply := s.getHash(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	values := make([]interface{}, len(args)-1)
+	for i, f := range args[1:] {
+		if v, ok := hash[string(f)]; ok {
+			values[i] = v
+		}
+	}
+	return values
+}
+
+func cmdHGetAll(s *Server, args [][]byte) interface{} {
+	hash, errReply := s.getHash(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	items := make([]interface{}, 0, 2*len(hash))
+	for f, v := range hash {
+		items = append(items, []byte(f), v)
+	}
+	return items
+}
+
+func cmdHDel(s *Server, args [][]byte) interface{} {
+	key := string(args[0])
+	hash, errReply := s.getHash(key, false)
+	if errReply != nil {
+		return errReply
+	}
+	var removed int64
+	for _, f := range args[1:] {
+		if _, ok := hash[string(f)]; ok {
+			delete(hash, string(f))
+			removed++
+		}
+	}
+	if removed > 0 {
+		s.dropIfEmpty(key, hash)
+		s.touch(key)
+	}
+	return removed
+}
+
+func cmdHExists(s *Server, args [][]byte) interface{} {
+	hash, errReply := s.getHash(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	if _, ok := hash[string(args[1])]; ok {
+		return int64(1)
+	}
+	return int64(0)
+}
+
+func cmdHLen(s *Server, args [][]byte) interface{} {
+	hash, errReply := s.getHash(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	return int64(len(hash))
+}
+
+func cmdHKeys(s *Server, args [][]byte) interface{} {
+	hash, errReply := s.getHash(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	keys := make([]interface{}, 0, len(hash))
+	for f := range hash {
+		keys = append(keys, []byte(f))
+	}
+	return keys
+}
+
+func cmdHVals(s *Server, args [][]byte) interface{} {
+	hash, errReply := s.getHash(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	vals := make([]interface{}, 0, len(hash))
+	for _, v := range hash {
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+func cmdHIncrBy(s *Server, args [][]byte) interface{} {
+	delta, err := strconv.ParseInt(string(args[2]), 10, 64)
+	if err != nil {
+		return errNotInt
+	}
+	key := string(args[0])
+	hash, errReply := s.getHash(key, true)
+	if errReply != nil {
+		return errReply
+	}
+	var cur int64
+	if v, ok := hash[string(args[1])]; ok {
+		if cur, err = strconv.ParseInt(string(v), 10, 64); err != nil {
+			s.dropIfEmpty(key, hash)
+			return errorReply("ERR hash value is not an integer")
+		}
+	}
+	if (delta > 0 && cur > math.MaxInt64-delta) || (delta < 0 && cur < math.MinInt64-delta) {
+		s.dropIfEmpty(key, hash)
+		return errorReply("ERR increment or decrement would overflow")
+	}
+	hash[string(args[1])] = strconv.AppendInt(nil, cur+delta, 10)
+	s.touch(key)
+	return cur + delta
+}
+
+func cmdHIncrByFloat(s *Server, args [][]byte) interface{} {
+	delta, err := strconv.ParseFloat(string(args[2]), 64)
+	if err != nil {
+		return errNotFloat
+	}
+	key := string(args[0])
+	hash, errReply := s.getHash(key, true)
+	if errReply != nil {
+		return errReply
+	}
+	var cur float64
+	if v, ok := hash[string(args[1])]; ok {
+		if cur, err = strconv.ParseFloat(string(v), 64); err != nil {
+			s.dropIfEmpty(key, hash)
+			return errorReply("ERR hash value is not a float")
+		}
+	}
+	sum := cur + delta
+	if math.IsNaN(sum) || math.IsInf(sum, 0) {
+		s.dropIfEmpty(key, hash)
+		return errorReply("ERR increment would produce NaN or Infinity")
+	}
+	v := strconv.AppendFloat(nil, sum, 'f', -1, 64)
+	hash[string(args[1])] = v
+	s.touch(key)
+	return v
+}
+
+// getList 返回 key 对应的 list entry；key 不存在时 create 为 true 则新建（调用方须随即写入元素），否则返回 nil。
+// key 存在但不是 list 时返回 WRONGTYPE 错误。
+func (s *Server) getList(key string, create bool) (*entry, interface{}) {
+	e := s.lookup(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		e = &entry{list: [][]byte{}}
+		s.keys[key] = e
+	}
+	if e.list == nil {
+		return nil, errWrongType
+	}
+	return e, nil
+}
+
+func pushCmd(left bool) func(s *Server, args [][]byte) interface{} {
+	return func(s *Server, args [][]byte) interface{} {
+		key := string(args[0])
+		e, errReply := s.getList(key, true)
+		if errReply != nil {
+			return errReply
+		}
+		for _, v := range args[1:] {
+			v = append([]byte(nil), v...)
+			if left {
+				e.list = append([][]byte{v}, e.list...)
+			} else {
+				e.list = append(e.list, v)
+			}
+		}
+		s.touch(key)
+		return int64(len(e.list))
+	}
+}
+
+func cmdLRange(s *Server, args [][]byte) interface{} {
+	start, err1 := strconv.Atoi(string(args[1]))
+	stop, err2 := strconv.Atoi(string(args[2]))
+	if err1 != nil || err2 != nil {
+		return errNotInt
+	}
+	e, errReply := s.getList(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	items := []interface{}{}
+	if e == nil {
+		return items
+	}
+	n := len(e.list)
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	for i := start; i <= stop; i++ {
+		items = append(items, e.list[i])
+	}
+	return items
+}
+
+func cmdLLen(s *Server, args [][]byte) interface{} {
+	e, errReply := s.getList(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	if e == nil {
+		return int64(0)
+	}
+	return int64(len(e.list))
+}
+
+// cmdLRem 与 Redis 一致：count > 0 从头删除至多 count 个，count < 0 从尾删除至多 -count 个，count = 0 删除全部。
+func cmdLRem(s *Server, args [][]byte) interface{} {
+	count, err := strconv.Atoi(string(args[1]))
+	if err != nil {
+		return errNotInt
+	}
+	key := string(args[0])
+	e, errReply := s.getList(key, false)
+	if errReply != nil {
+		return errReply
+	}
+	if e == nil {
+		return int64(0)
+	}
+	target := string(args[2])
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	remove := make(map[int]bool)
+	for i := range e.list {
+		idx := i
+		if count < 0 {
+			idx = len(e.list) - 1 - i
+		}
+		if string(e.list[idx]) == target {
+			remove[idx] = true
+			if limit > 0 && len(remove) == limit {
+				break
+			}
+		}
+	}
+	if len(remove) == 0 {
+		return int64(0)
+	}
+	kept := make([][]byte, 0, len(e.list)-len(remove))
+	for i, v := range e.list {
+		if !remove[i] {
+			kept = append(kept, v)
+		}
+	}
+	e.list = kept
+	if len(kept) == 0 {
+		delete(s.keys, key)
+	}
+	s.touch(key)
+	return int64(len(remove))
+}
+
+// getSet 返回 key 对应的 set；key 不存在时 create 为 true 则新建，否则返回 nil。
+// key 存在但不是 set 时返回 WRONGTYPE 错误。
+func (s *Server) getSet(key string, create bool) (map[string]struct{}, interface{}) {
+	e := s.lookup(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		e = &entry{set: make(map[string]struct{})}
+		s.keys[key] = e
+	}
+	if e.set == nil {
+		return nil, errWrongType
+	}
+	return e.set, nil
+}
+
+func cmdSAdd(s *Server, args [][]byte) interface{} {
+	key := string(args[0])
+	set, errReply := s.getSet(key, true)
+	if errReply != nil {
+		return errReply
+	}
+	var added int64
+	for _, m := range args[1:] {
+		if _, ok := set[string(m)]; !ok {
+			set[string(m)] = struct{}{}
+			added++
+		}
+	}
+	s.touch(key)
+	return added
+}
+
+func cmdSRem(s *Server, args [][]byte) interface{} {
+	key := string(args[0])
+	set, errReply := s.getSet(key, false)
+	if errReply != nil {
+		return errReply
+	}
+	var removed int64
+	for _, m := range args[1:] {
+		if _, ok := set[string(m)]; ok {
+			delete(set, string(m))
+			removed++
+		}
+	}
+	if removed > 0 {
+		if len(set) == 0 {
+			delete(s.keys, key)
+		}
+		s.touch(key)
+	}
+	return removed
+}
+
+func cmdSMembers(s *Server, args [][]byte) interface{} {
+	set, errReply := s.getSet(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	items := make([]interface{}, 0, len(set))
+	for m := range set {
+		items = append(items, []byte(m))
+	}
+	return items
+}
+
+func cmdSIsMember(s *Server, args [][]byte) interface{} {
+	set, errReply := s.getSet(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	if _, ok := set[string(args[1])]; ok {
+		return int64(1)
+	}
+	return int64(0)
+}
+
+func cmdSCard(s *Server, args [][]byte) interface{} {
+	set, errReply := s.getSet(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	return int64(len(set))
+}
+
+// getZSet 返回 key 对应的 sorted set；key 不存在时 create 为 true 则新建，否则返回 nil。
+// key 存在但不是 sorted set 时返回 WRONGTYPE 错误。
+func (s *Server) getZSet(key string, create bool) (map[string]float64, interface{}) {
+	e := s.lookup(key)
+	if e == nil {
+		if !create {
+			return nil, nil
+		}
+		e = &entry{zset: make(map[string]float64)}
+		s.keys[key] = e
+	}
+	if e.zset == nil {
+		return nil, errWrongType
+	}
+	return e.zset, nil
+}
+
+// parseScore 解析分数，支持 inf / -inf，拒绝 NaN。
+func parseScore(b []byte) (float64, bool) {
+	f, err := strconv.ParseFloat(string(b), 64)
+	return f, err == nil && !math.IsNaN(f)
+}
+
+// formatScore 与 Redis 一致地输出分数：整数不带小数点，其余取最短表示。
+func formatScore(f float64) []byte {
+	return strconv.AppendFloat(nil, f, 'g', -1, 64)
+}
+
+// sortedMembers 返回按 (score, member) 升序排列的成员，rev 为 true 时降序。
+func sortedMembers(zset map[string]float64, rev bool) []string {
+	members := make([]string, 0, len(zset))
+	for m := range zset {
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if rev {
+			a, b = b, a
+		}
+		if zset[a] != zset[b] {
+			return zset[a] < zset[b]
+		}
+		return a < b
+	})
+	return members
+}
+
+func cmdZAdd(s *Server, args [][]byte) interface{} {
+	if len(args)%2 == 0 {
+		return wrongArity("ZADD")
+	}
+	key := string(args[0])
+	scores := make([]float64, 0, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		f, ok := parseScore(args[i])
+		if !ok {
+			return errNotFloat
+		}
+		scores = append(scores, f)
+	}
+	zset, errReply := s.getZSet(key, true)
+	if errReply != nil {
+		return errReply
+	}
+	var added int64
+	for i, f := range scores {
+		member := string(args[2*i+2])
+		if _, ok := zset[member]; !ok {
+			added++
+		}
+		zset[member] = f
+	}
+	s.touch(key)
+	return added
+}
+
+func cmdZIncrBy(s *Server, args [][]byte) interface{} {
+	delta, ok := parseScore(args[1])
+	if !ok {
+		return errNotFloat
+	}
+	key := string(args[0])
+	zset, errReply := s.getZSet(key, true)
+	if errReply != nil {
+		return errReply
+	}
+	member := string(args[2])
+	score := zset[member] + delta
+	if math.IsNaN(score) {
+		return errorReply("ERR resulting score is not a number (NaN)")
+	}
+	zset[member] = score
+	s.touch(key)
+	return formatScore(score)
+}
+
+func cmdZScore(s *Server, args [][]byte) interface{} {
+	zset, errReply := s.getZSet(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	score, ok := zset[string(args[1])]
+	if !ok {
+		return nil
+	}
+	return formatScore(score)
+}
+
+func zrangeCmd(rev bool) func(s *Server, args [][]byte) interface{} {
+	return func(s *Server, args [][]byte) interface{} {
+		withScores := false
+		switch {
+		case len(args) == 4 && strings.EqualFold(string(args[3]), "WITHSCORES"):
+			withScores = true
+		case len(args) != 3:
+			return errSyntax
+		}
+		start, err1 := strconv.Atoi(string(args[1]))
+		stop, err2 := strconv.Atoi(string(args[2]))
+		if err1 != nil || err2 != nil {
+			return errNotInt
+		}
+		zset, errReply := s.getZSet(string(args[0]), false)
+		if errReply != nil {
+			return errReply
+		}
+		members := sortedMembers(zset, rev)
+		n := len(members)
+		if start < 0 {
+			start += n
+		}
+		if stop < 0 {
+			stop += n
+		}
+		if start < 0 {
+			start = 0
+		}
+		if stop >= n {
+			stop = n - 1
+		}
+		items := []interface{}{}
+		for i := start; i <= stop; i++ {
+			items = append(items, []byte(members[i]))
+			if withScores {
+				items = append(items, formatScore(zset[members[i]]))
+			}
+		}
+		return items
+	}
+}
+
+func zrankCmd(rev bool) func(s *Server, args [][]byte) interface{} {
+	return func(s *Server, args [][]byte) interface{} {
+		zset, errReply := s.getZSet(string(args[0]), false)
+		if errReply != nil {
+			return errReply
+		}
+		if _, ok := zset[string(args[1])]; !ok {
+			return nil
+		}
+		for i, m := range sortedMembers(zset, rev) {
+			if m == string(args[1]) {
+				return int64(i)
+			}
+		}
+		return nil
+	}
+}
+
+func cmdZRem(s *Server, args [][]byte) interface{} {
+	key := string(args[0])
+	zset, errReply := s.getZSet(key, false)
+	if errReply != nil {
+		return errReply
+	}
+	var removed int64
+	for _, m := range args[1:] {
+		if _, ok := zset[string(m)]; ok {
+			delete(zset, string(m))
+			removed++
+		}
+	}
+	if removed > 0 {
+		if len(zset) == 0 {
+			delete(s.keys, key)
+		}
+		s.touch(key)
+	}
+	return removed
+}
+
+func cmdZCard(s *Server, args [][]byte) interface{} {
+	zset, errReply := s.getZSet(string(args[0]), false)
+	if errReply != nil {
+		return errReply
+	}
+	return int64(len(zset))
+}
+
+func isError(reply interface{}) bool {
+	_, ok := reply.(errorReply)
+	return ok
+}
+
+// globMatch 实现 KEYS 的通配规则：* 任意串、? 单个字符、[...] 字符集（支持 ^ 取反与 a-z 区间）、\ 转义。
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if globMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 {
+				return false
+			}
+			set := pattern[1 : end+1]
+			pattern = pattern[end+2:]
+			negate := len(set) > 0 && set[0] == '^'
+			if negate {
+				set = set[1:]
+			}
+			matched := false
+			for i := 0; i < len(set); i++ {
+				if i+2 < len(set) && set[i+1] == '-' {
+					if set[i] <= s[0] && s[0] <= set[i+2] {
+						matched = true
+					}
+					i += 2
+				} else if set[i] == s[0] {
+					matched = true
+				}
+			}
+			if matched == negate {
+				return false
+			}
+			s = s[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(s) == 0 || s[0] != pattern[0] {
+				return false
+			}
+			s = s[1:]
+			pattern = pattern[1:]
+		}
+	}
+	return len(s) == 0
+}
@@ -0,0 +1,386 @@
+// Package redistest 提供进程内的 Redis 替身：在随机本地端口上监听并说 RESP2 协议，
+// 实现生成代码用到的 string、hash、list、set、sorted set、key、事务（WATCH/MULTI/EXEC）与过期命令，
+// 让依赖 Redis 的测试在 CI 等没有 Redis 的环境下也能完整运行。
+//
+//	srv, err := redistest.NewServer()
+//	defer srv.Close()
+//	conn, _ := redis.Dial("tcp", srv.Addr())
+//
+// 它不是 Redis 的完整实现：只有一个 keyspace（SELECT 任意库都指向同一份数据），
+// 不支持持久化、发布订阅与 RESP3，未实现的命令返回 "ERR unknown command"。
+package redistest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server 是进程内的 RESP 服务端，多个连接并发访问同一份数据，每条命令（以及整个 EXEC）原子执行。
+type Server struct {
+	ln net.Listener
+	wg sync.WaitGroup
+
+	mu       sync.Mutex
+	keys     map[string]*entry
+	versions map[string]uint64 // key 最近一次修改的序号，WATCH 用它判断 key 是否被改过
+	seq      uint64
+	offset   time.Duration // FastForward 累计拨快的时间
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// entry 是 keyspace 中的一个 key，hash/list/set/zset 恰有一个非 nil（集合被删空时 key 随之删除）。
+type entry struct {
+	str      []byte // string 类型的值，非 nil 即为 string（空值为长度 0 的非 nil 切片）
+	hash     map[string][]byte
+	list     [][]byte
+	set      map[string]struct{}
+	zset     map[string]float64 // 成员 -> 分数
+	expireAt time.Time          // 零值表示不过期
+}
+
+// NewServer 在 127.0.0.1 的随机端口启动服务端。
+func NewServer() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("redistest: 监听失败: %w", err)
+	}
+	s := &Server{
+		ln:       ln,
+		keys:     make(map[string]*entry),
+		versions: make(map[string]uint64),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr 返回监听地址（host:port），可直接传给 redis.Dial / go-redis 的 Options.Addr。
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Close 停止监听并断开全部连接，等待连接协程退出。
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	err := s.ln.Close()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// FlushAll 清空全部数据，相当于 FLUSHALL。
+func (s *Server) FlushAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flush()
+}
+
+// FastForward 把服务端时钟拨快 d，用于测试过期而不必真的等待。
+func (s *Server) FastForward(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset += d
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		c, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			c.Close()
+			return
+		}
+		s.conns[c] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.handle(c)
+	}
+}
+
+// session 是单个连接上的事务状态。
+type session struct {
+	inMulti bool
+	dirty   bool // MULTI 期间有命令入队失败，EXEC 直接放弃
+	queued  [][][]byte
+	watched map[string]uint64
+}
+
+func (s *Server) handle(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		c.Close()
+	}()
+	r := bufio.NewReader(c)
+	w := bufio.NewWriter(c)
+	sess := &session{}
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			var perr protocolError
+			if errors.As(err, &perr) {
+				writeReply(w, errorReply("ERR Protocol error: "+string(perr)))
+				w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+		name := strings.ToUpper(string(args[0]))
+		if name == "QUIT" {
+			writeReply(w, simpleString("OK"))
+			w.Flush()
+			return
+		}
+		writeReply(w, s.dispatch(sess, name, args))
+		// 客户端流水线发送时缓冲区里还有命令，攒到一起再写回
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// dispatch 处理事务控制命令，其余命令在 MULTI 期间入队、否则立即执行。
+func (s *Server) dispatch(sess *session, name string, args [][]byte) interface{} {
+	switch name {
+	case "MULTI":
+		if sess.inMulti {
+			return errorReply("ERR MULTI calls can not be nested")
+		}
+		sess.inMulti = true
+		return simpleString("OK")
+	case "DISCARD":
+		if !sess.inMulti {
+			return errorReply("ERR DISCARD without MULTI")
+		}
+		*sess = session{}
+		return simpleString("OK")
+	case "EXEC":
+		if !sess.inMulti {
+			return errorReply("ERR EXEC without MULTI")
+		}
+		return s.exec(sess)
+	case "WATCH":
+		if sess.inMulti {
+			return errorReply("ERR WATCH inside MULTI is not allowed")
+		}
+		if len(args) < 2 {
+			return wrongArity(name)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if sess.watched == nil {
+			sess.watched = make(map[string]uint64)
+		}
+		for _, k := range args[1:] {
+			key := string(k)
+			s.lookup(key) // 已过期的 key 先清掉，过期本身也算一次修改
+			if _, ok := sess.watched[key]; !ok {
+				sess.watched[key] = s.versions[key]
+			}
+		}
+		return simpleString("OK")
+	case "UNWATCH":
+		sess.watched = nil
+		return simpleString("OK")
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		if sess.inMulti {
+			sess.dirty = true
+		}
+		return errorReply(fmt.Sprintf("ERR unknown command '%s'", string(args[0])))
+	}
+	if !cmd.checkArity(len(args)) {
+		if sess.inMulti {
+			sess.dirty = true
+		}
+		return wrongArity(name)
+	}
+	if sess.inMulti {
+		sess.queued = append(sess.queued, args)
+		return simpleString("QUEUED")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return cmd.fn(s, args[1:])
+}
+
+// exec 原子执行事务：WATCH 的 key 被改过时返回 nil 数组，不执行任何命令。
+func (s *Server) exec(sess *session) interface{} {
+	defer func() { *sess = session{} }()
+	if sess.dirty {
+		return errorReply("EXECABORT Transaction discarded because of previous errors.")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, version := range sess.watched {
+		s.lookup(key)
+		if s.versions[key] != version {
+			return nilArray{}
+		}
+	}
+	replies := make([]interface{}, len(sess.queued))
+	for i, args := range sess.queued {
+		replies[i] = commands[strings.ToUpper(string(args[0]))].fn(s, args[1:])
+	}
+	return replies
+}
+
+// now 返回服务端当前时间（含 FastForward 的偏移），调用方需持有 s.mu。
+func (s *Server) now() time.Time {
+	return time.Now().Add(s.offset)
+}
+
+// lookup 返回未过期的 key；已过期的 key 在这里惰性删除。调用方需持有 s.mu。
+func (s *Server) lookup(key string) *entry {
+	e, ok := s.keys[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !s.now().Before(e.expireAt) {
+		s.remove(key)
+		return nil
+	}
+	return e
+}
+
+// touch 记录 key 被修改，使 WATCH 该 key 的事务失败。调用方需持有 s.mu。
+func (s *Server) touch(key string) {
+	s.seq++
+	s.versions[key] = s.seq
+}
+
+// remove 删除 key 并记录修改。调用方需持有 s.mu。
+func (s *Server) remove(key string) bool {
+	if _, ok := s.keys[key]; !ok {
+		return false
+	}
+	delete(s.keys, key)
+	s.touch(key)
+	return true
+}
+
+// flush 清空全部 key。调用方需持有 s.mu。
+func (s *Server) flush() {
+	for key := range s.keys {
+		s.remove(key)
+	}
+}
+
+// 回复类型：int64 为整数、[]byte 为 bulk string、nil 为 nil bulk、[]interface{} 为数组。
+type (
+	simpleString string
+	errorReply   string
+	nilArray     struct{}
+)
+
+func writeReply(w *bufio.Writer, reply interface{}) {
+	switch v := reply.(type) {
+	case simpleString:
+		fmt.Fprintf(w, "+%s\r\n", v)
+	case errorReply:
+		fmt.Fprintf(w, "-%s\r\n", v)
+	case int64:
+		fmt.Fprintf(w, ":%d\r\n", v)
+	case []byte:
+		fmt.Fprintf(w, "$%d\r\n", len(v))
+		w.Write(v)
+		w.WriteString("\r\n")
+	case nil:
+		w.WriteString("$-1\r\n")
+	case nilArray:
+		w.WriteString("*-1\r\n")
+	case []interface{}:
+		fmt.Fprintf(w, "*%d\r\n", len(v))
+		for _, item := range v {
+			writeReply(w, item)
+		}
+	default:
+		panic(fmt.Sprintf("redistest: 不支持的回复类型 %T", reply))
+	}
+}
+
+// protocolError 表示客户端发来的数据不符合 RESP，连接回复错误后关闭。
+type protocolError string
+
+func (e protocolError) Error() string { return string(e) }
+
+// readCommand 读取一条命令：RESP 数组（客户端库的格式）或以空格分隔的内联命令（telnet/redis-cli 手输）。
+func readCommand(r *bufio.Reader) ([][]byte, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		var args [][]byte
+		for _, f := range strings.Fields(string(line)) {
+			args = append(args, []byte(f))
+		}
+		return args, nil
+	}
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n < 0 {
+		return nil, protocolError("invalid multibulk length")
+	}
+	args := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, protocolError(fmt.Sprintf("expected '$', got '%s'", line))
+		}
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil || size < 0 {
+			return nil, protocolError("invalid bulk length")
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, buf[:size])
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = line[:len(line)-1]
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	return line, nil
+}
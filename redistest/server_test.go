@@ -0,0 +1,345 @@
+package redistest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func startServer(t *testing.T) *Server {
+	t.Helper()
+	srv, err := NewServer()
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+func dial(t *testing.T, srv *Server) redis.Conn {
+	t.Helper()
+	conn, err := redis.Dial("tcp", srv.Addr())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestHashCommands(t *testing.T) {
+	conn := dial(t, startServer(t))
+
+	if n, err := redis.Int(conn.Do("HSET", "h", 1, "a", 2, []byte("b"))); err != nil || n != 2 {
+		t.Fatalf("HSET = %d, %v", n, err)
+	}
+	if n, _ := redis.Int(conn.Do("HSET", "h", 1, "c")); n != 0 {
+		t.Errorf("覆盖已有字段 HSET 应返回 0, got %d", n)
+	}
+	values, err := redis.Values(conn.Do("HMGET", "h", 1, 3, 2))
+	if err != nil || len(values) != 3 || string(values[0].([]byte)) != "c" || values[1] != nil || string(values[2].([]byte)) != "b" {
+		t.Errorf("HMGET = %q, %v", values, err)
+	}
+	if n, _ := redis.Int64(conn.Do("HINCRBY", "h", "n", -5)); n != -5 {
+		t.Errorf("HINCRBY = %d, want -5", n)
+	}
+	if f, _ := redis.Float64(conn.Do("HINCRBYFLOAT", "h", "f", 0.1)); f != 0.1 {
+		t.Errorf("HINCRBYFLOAT = %v, want 0.1", f)
+	}
+	if _, err := conn.Do("HINCRBY", "h", 1, 1); err == nil {
+		t.Error("对非整数字段 HINCRBY 应报错")
+	}
+	if _, err := conn.Do("HINCRBY", "h", "n", int64(-1<<63)); err == nil {
+		t.Error("HINCRBY 溢出应报错")
+	}
+	if n, _ := redis.Int(conn.Do("HLEN", "h")); n != 4 {
+		t.Errorf("HLEN = %d, want 4", n)
+	}
+	all, _ := redis.StringMap(conn.Do("HGETALL", "h"))
+	if len(all) != 4 || all["2"] != "b" {
+		t.Errorf("HGETALL = %v", all)
+	}
+	if n, _ := redis.Int(conn.Do("HDEL", "h", 1, 2, "n", "f", "missing")); n != 4 {
+		t.Errorf("HDEL = %d, want 4", n)
+	}
+	if n, _ := redis.Int(conn.Do("EXISTS", "h")); n != 0 {
+		t.Error("删除最后一个字段后 key 应不存在")
+	}
+
+	if _, err := conn.Do("HSET", "h", 1); err == nil {
+		t.Error("HSET 参数个数错误应报错")
+	}
+	if _, err := conn.Do("NOSUCHCMD"); err == nil {
+		t.Error("未实现的命令应报错")
+	}
+}
+
+func TestStringCommands(t *testing.T) {
+	srv := startServer(t)
+	conn := dial(t, srv)
+
+	if v, err := conn.Do("GET", "s"); v != nil || err != nil {
+		t.Errorf("GET 不存在的 key = %v, %v, want nil", v, err)
+	}
+	if ok, _ := redis.String(conn.Do("SET", "s", []byte{0, 1})); ok != "OK" {
+		t.Errorf("SET = %q, want OK", ok)
+	}
+	if b, _ := redis.Bytes(conn.Do("GET", "s")); string(b) != "\x00\x01" {
+		t.Errorf("GET = %q", b)
+	}
+	if typ, _ := redis.String(conn.Do("TYPE", "s")); typ != "string" {
+		t.Errorf("TYPE = %q, want string", typ)
+	}
+	conn.Do("SET", "empty", "")
+	if n, _ := redis.Int(conn.Do("EXISTS", "empty")); n != 1 {
+		t.Error("空值的 string key 应存在")
+	}
+	if _, err := conn.Do("HGET", "s", 1); err == nil || !strings.HasPrefix(err.Error(), "WRONGTYPE") {
+		t.Errorf("对 string 执行 HGET 应返回 WRONGTYPE, got %v", err)
+	}
+
+	// SET 覆盖其他类型的旧值并清除过期时间
+	conn.Do("HSET", "h", 1, "a")
+	conn.Do("EXPIRE", "h", 10)
+	conn.Do("SET", "h", "v")
+	if typ, _ := redis.String(conn.Do("TYPE", "h")); typ != "string" {
+		t.Errorf("SET 覆盖 hash 后 TYPE = %q, want string", typ)
+	}
+	if ttl, _ := redis.Int(conn.Do("TTL", "h")); ttl != -1 {
+		t.Errorf("SET 后 TTL = %d, want -1", ttl)
+	}
+	if _, err := conn.Do("GET", "missing", "extra"); err == nil {
+		t.Error("GET 参数个数错误应报错")
+	}
+}
+
+func TestListAndSetCommands(t *testing.T) {
+	conn := dial(t, startServer(t))
+
+	if n, _ := redis.Int(conn.Do("RPUSH", "l", "a", "b", "a", "c")); n != 4 {
+		t.Errorf("RPUSH = %d, want 4", n)
+	}
+	conn.Do("LPUSH", "l", "z")
+	if items, _ := redis.Strings(conn.Do("LRANGE", "l", 0, -1)); strings.Join(items, ",") != "z,a,b,a,c" {
+		t.Errorf("LRANGE 0 -1 = %v", items)
+	}
+	if items, _ := redis.Strings(conn.Do("LRANGE", "l", -2, 10)); strings.Join(items, ",") != "a,c" {
+		t.Errorf("LRANGE -2 10 = %v", items)
+	}
+	if n, _ := redis.Int(conn.Do("LREM", "l", -1, "a")); n != 1 {
+		t.Errorf("LREM -1 = %d, want 1", n)
+	}
+	if items, _ := redis.Strings(conn.Do("LRANGE", "l", 0, -1)); strings.Join(items, ",") != "z,a,b,c" {
+		t.Errorf("LREM 应从尾部删除, got %v", items)
+	}
+	conn.Do("LREM", "l", 0, "z")
+	if n, _ := redis.Int(conn.Do("LLEN", "l")); n != 3 {
+		t.Errorf("LLEN = %d, want 3", n)
+	}
+	if typ, _ := redis.String(conn.Do("TYPE", "l")); typ != "list" {
+		t.Errorf("TYPE = %q, want list", typ)
+	}
+	if _, err := conn.Do("HGET", "l", "a"); err == nil || !strings.HasPrefix(err.Error(), "WRONGTYPE") {
+		t.Errorf("对 list 执行 HGET 应返回 WRONGTYPE, got %v", err)
+	}
+
+	if n, _ := redis.Int(conn.Do("SADD", "s", 1, 2, 2, 3)); n != 3 {
+		t.Errorf("SADD = %d, want 3", n)
+	}
+	if ok, _ := redis.Int(conn.Do("SISMEMBER", "s", 2)); ok != 1 {
+		t.Error("SISMEMBER 2 应返回 1")
+	}
+	if n, _ := redis.Int(conn.Do("SREM", "s", 2, 9)); n != 1 {
+		t.Errorf("SREM = %d, want 1", n)
+	}
+	if members, _ := redis.Strings(conn.Do("SMEMBERS", "s")); len(members) != 2 {
+		t.Errorf("SMEMBERS = %v", members)
+	}
+	if _, err := conn.Do("RPUSH", "s", "x"); err == nil || !strings.HasPrefix(err.Error(), "WRONGTYPE") {
+		t.Errorf("对 set 执行 RPUSH 应返回 WRONGTYPE, got %v", err)
+	}
+	conn.Do("SREM", "s", 1, 3)
+	if n, _ := redis.Int(conn.Do("SCARD", "s")); n != 0 {
+		t.Errorf("SCARD = %d, want 0", n)
+	}
+	if n, _ := redis.Int(conn.Do("EXISTS", "s")); n != 0 {
+		t.Error("删除最后一个成员后 key 应不存在")
+	}
+	if items, err := redis.Values(conn.Do("LRANGE", "missing", 0, -1)); err != nil || len(items) != 0 {
+		t.Errorf("不存在的 key LRANGE = %v, %v，期望空数组", items, err)
+	}
+}
+
+func TestSortedSetCommands(t *testing.T) {
+	conn := dial(t, startServer(t))
+
+	if n, _ := redis.Int(conn.Do("ZADD", "z", 10, "a", 30, "c", 20, "b")); n != 3 {
+		t.Errorf("ZADD = %d, want 3", n)
+	}
+	if n, _ := redis.Int(conn.Do("ZADD", "z", 5, "a")); n != 0 {
+		t.Errorf("更新已有成员 ZADD 应返回 0, got %d", n)
+	}
+	if f, _ := redis.Float64(conn.Do("ZINCRBY", "z", 2.5, "a")); f != 7.5 {
+		t.Errorf("ZINCRBY = %v, want 7.5", f)
+	}
+	if score, _ := redis.String(conn.Do("ZSCORE", "z", "c")); score != "30" {
+		t.Errorf("ZSCORE = %q, want \"30\"（整数分数不带小数点）", score)
+	}
+	if reply, err := conn.Do("ZSCORE", "z", "missing"); reply != nil || err != nil {
+		t.Errorf("不存在的成员 ZSCORE = %v, %v", reply, err)
+	}
+	if items, _ := redis.Strings(conn.Do("ZREVRANGE", "z", 0, 1, "WITHSCORES")); strings.Join(items, ",") != "c,30,b,20" {
+		t.Errorf("ZREVRANGE WITHSCORES = %v", items)
+	}
+	if items, _ := redis.Strings(conn.Do("ZRANGE", "z", 0, -1)); strings.Join(items, ",") != "a,b,c" {
+		t.Errorf("ZRANGE = %v", items)
+	}
+	if rank, _ := redis.Int(conn.Do("ZRANK", "z", "b")); rank != 1 {
+		t.Errorf("ZRANK = %d, want 1", rank)
+	}
+	if rank, _ := redis.Int(conn.Do("ZREVRANK", "z", "a")); rank != 2 {
+		t.Errorf("ZREVRANK = %d, want 2", rank)
+	}
+	if reply, _ := conn.Do("ZRANK", "z", "missing"); reply != nil {
+		t.Errorf("不存在的成员 ZRANK = %v, want nil", reply)
+	}
+	conn.Do("ZADD", "z", 20, "bb")
+	if items, _ := redis.Strings(conn.Do("ZREVRANGE", "z", 1, 2)); strings.Join(items, ",") != "bb,b" {
+		t.Errorf("同分成员应按成员字典序排列（逆序时降序）, got %v", items)
+	}
+	if _, err := conn.Do("ZADD", "z", "nan", "x"); err == nil {
+		t.Error("非法分数应报错")
+	}
+	if n, _ := redis.Int(conn.Do("ZREM", "z", "a", "b", "bb", "c", "missing")); n != 4 {
+		t.Errorf("ZREM = %d, want 4", n)
+	}
+	if n, _ := redis.Int(conn.Do("ZCARD", "z")); n != 0 {
+		t.Errorf("ZCARD = %d, want 0", n)
+	}
+	if n, _ := redis.Int(conn.Do("EXISTS", "z")); n != 0 {
+		t.Error("删除最后一个成员后 key 应不存在")
+	}
+}
+
+func TestKeyAndExpiry(t *testing.T) {
+	srv := startServer(t)
+	conn := dial(t, srv)
+
+	conn.Do("HSET", "user:1", "a", 1)
+	conn.Do("HSET", "user:2", "a", 1)
+	conn.Do("HSET", "other", "a", 1)
+	if keys, _ := redis.Strings(conn.Do("KEYS", "user:[12]")); len(keys) != 2 {
+		t.Errorf("KEYS = %v", keys)
+	}
+	if typ, _ := redis.String(conn.Do("TYPE", "user:1")); typ != "hash" {
+		t.Errorf("TYPE = %q", typ)
+	}
+
+	if ttl, _ := redis.Int(conn.Do("TTL", "user:1")); ttl != -1 {
+		t.Errorf("未设置过期时 TTL = %d, want -1", ttl)
+	}
+	if ok, _ := redis.Int(conn.Do("EXPIRE", "user:1", 10)); ok != 1 {
+		t.Error("EXPIRE 应返回 1")
+	}
+	if ttl, _ := redis.Int(conn.Do("TTL", "user:1")); ttl != 10 {
+		t.Errorf("TTL = %d, want 10", ttl)
+	}
+	conn.Do("PEXPIRE", "user:2", 10000)
+	if n, _ := redis.Int(conn.Do("PERSIST", "user:2")); n != 1 {
+		t.Error("PERSIST 应返回 1")
+	}
+
+	srv.FastForward(11 * time.Second)
+	if n, _ := redis.Int(conn.Do("EXISTS", "user:1", "user:2")); n != 1 {
+		t.Errorf("过期后 EXISTS = %d, want 1", n)
+	}
+	if ttl, _ := redis.Int(conn.Do("TTL", "user:1")); ttl != -2 {
+		t.Errorf("过期 key 的 TTL = %d, want -2", ttl)
+	}
+
+	if n, _ := redis.Int(conn.Do("DEL", "user:2", "other", "user:1")); n != 2 {
+		t.Errorf("DEL = %d, want 2", n)
+	}
+	if n, _ := redis.Int(conn.Do("DBSIZE")); n != 0 {
+		t.Errorf("DBSIZE = %d, want 0", n)
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	srv := startServer(t)
+	conn := dial(t, srv)
+	other := dial(t, srv)
+
+	conn.Send("MULTI")
+	conn.Send("HSET", "k", "a", 1)
+	conn.Send("HINCRBY", "k", "a", 2)
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err != nil || len(replies) != 2 || replies[1].(int64) != 3 {
+		t.Fatalf("EXEC = %v, %v", replies, err)
+	}
+
+	// WATCH 的 key 被其他连接修改后 EXEC 放弃执行
+	conn.Do("WATCH", "k")
+	other.Do("HSET", "k", "a", 100)
+	conn.Send("MULTI")
+	conn.Send("HSET", "k", "a", 5)
+	if reply, err := conn.Do("EXEC"); err != nil || reply != nil {
+		t.Errorf("WATCH 冲突时 EXEC 应返回 nil, got %v, %v", reply, err)
+	}
+	if v, _ := redis.Int(conn.Do("HGET", "k", "a")); v != 100 {
+		t.Errorf("冲突的事务不应执行, a = %d", v)
+	}
+
+	// 未被修改则正常执行
+	conn.Do("WATCH", "k")
+	conn.Send("MULTI")
+	conn.Send("HSET", "k", "a", 5)
+	if replies, err := redis.Values(conn.Do("EXEC")); err != nil || len(replies) != 1 {
+		t.Errorf("EXEC = %v, %v", replies, err)
+	}
+
+	// 入队失败的事务整体放弃
+	conn.Send("MULTI")
+	conn.Send("HSET", "k", "a", 6)
+	conn.Send("NOSUCHCMD")
+	if _, err := conn.Do("EXEC"); err == nil {
+		t.Error("入队失败后 EXEC 应返回 EXECABORT")
+	}
+	if v, _ := redis.Int(conn.Do("HGET", "k", "a")); v != 5 {
+		t.Errorf("放弃的事务不应执行, a = %d", v)
+	}
+	if _, err := conn.Do("EXEC"); err == nil {
+		t.Error("没有 MULTI 时 EXEC 应报错")
+	}
+}
+
+// TestGoRedisClient 验证 go-redis v9 能正常建连（HELLO 不支持时回退 RESP2）并使用事务流水线。
+func TestGoRedisClient(t *testing.T) {
+	srv := startServer(t)
+	ctx := context.Background()
+	client := goredis.NewClient(&goredis.Options{Addr: srv.Addr(), Password: "ignored"})
+	defer client.Close()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	cmds, err := client.TxPipelined(ctx, func(p goredis.Pipeliner) error {
+		p.HSet(ctx, "g", "a", "1")
+		p.HIncrBy(ctx, "g", "a", 1)
+		return nil
+	})
+	if err != nil || cmds[1].(*goredis.IntCmd).Val() != 2 {
+		t.Fatalf("TxPipelined: %v, %v", cmds, err)
+	}
+	if v, err := client.HGet(ctx, "g", "missing").Result(); !errors.Is(err, goredis.Nil) {
+		t.Errorf("缺失字段应返回 redis.Nil, got %q, %v", v, err)
+	}
+	srv.FlushAll()
+	if n := client.Exists(ctx, "g").Val(); n != 0 {
+		t.Errorf("FlushAll 后 key 仍存在")
+	}
+}